@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type AnonymizeParams struct {
+	Files         []string `descr:"Path(s) to transaction file(s)" positional:"true"`
+	Source        string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay  []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Key           string   `descr:"Secret for reproducible output (default: random, so pseudonyms differ on every run)" optional:"true"`
+	Out           string   `descr:"Write output to file instead of stdout" short:"o" optional:"true"`
+}
+
+func anonymizeCmd() boa.CmdT[AnonymizeParams] {
+	return boa.CmdT[AnonymizeParams]{
+		Use:   "anonymize",
+		Short: "Anonymize transaction files for bug reports",
+		Long:  "Converts transaction files into simple-json with payee names pseudonymized and amounts scaled by a random per-payee factor. Dates and the recurrence structure are kept, so detection results can be reproduced from the output. Review the output before sharing it.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runAnonymize),
+	}
+}
+
+func runAnonymize(params *AnonymizeParams, cmd *cobra.Command, _ []string) (err error) {
+	// Anonymized data goes to stdout, so informational messages go to stderr
+	info := func(format string, args ...any) { fmt.Fprintf(os.Stderr, format, args...) }
+
+	transactions, err := loadTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, info)
+	if err != nil {
+		return err
+	}
+
+	key := []byte(params.Key)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	anonymized := internal.Anonymize(transactions, key)
+
+	var out io.Writer = os.Stdout
+	if params.Out != "" {
+		f, err := createOutputFile(params.Out)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing output file: %w", closeErr)
+			}
+			if err == nil {
+				info("Wrote %d anonymized transactions to %s\n", len(anonymized), params.Out)
+			}
+		}()
+		out = f
+	}
+
+	if err := internal.WriteSimpleJSON(out, anonymized); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type BudgetParams struct {
+	Files                []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML) with a budgets section" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Output               string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision            int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale               string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color                string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor              bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth             int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func budgetCmd() boa.CmdT[BudgetParams] {
+	return boa.CmdT[BudgetParams]{
+		Use:   "budget",
+		Short: "Compare active subscription spend against the configured budgets",
+		Long:  "Compares the monthly spend of active subscriptions against the budgets section of the config file, overall and per tag. Shows the remaining headroom and, for exceeded budgets, which subscriptions to cut to get under budget.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runBudget),
+	}
+}
+
+func runBudget(params *BudgetParams, cmd *cobra.Command, _ []string) error {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+
+	// Informational messages would corrupt JSON output
+	info := func(format string, args ...any) {
+		if params.Output != "json" {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+
+	subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+	if err != nil {
+		return err
+	}
+	statuses := internal.CheckBudgets(subscriptions, cfg)
+
+	if params.Output == "json" {
+		internal.PrintBudgetJSON(os.Stdout, statuses, currency)
+		return nil
+	}
+
+	info("\n")
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintBudgetTable(os.Stdout, statuses, internal.OutputOptions{
+		Currency: currency,
+		Locale:   locale,
+		MaxWidth: params.MaxWidth,
+	})
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ConvertParams struct {
+	Files         []string `descr:"Path(s) to transaction file(s)" positional:"true"`
+	Source        string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay  []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	To            string   `descr:"Output format" default:"simple-json" alts:"simple-json,csv" strict:"true"`
+	Out           string   `descr:"Write output to file instead of stdout" short:"o" optional:"true"`
+}
+
+func convertCmd() boa.CmdT[ConvertParams] {
+	return boa.CmdT[ConvertParams]{
+		Use:   "convert",
+		Short: "Convert bank exports to simple-json or CSV",
+		Long:  "Parses transaction files with any supported format and writes all transactions, sorted by date, as normalized simple-json (which can be read back with --source simple-json) or CSV.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runConvert),
+	}
+}
+
+func runConvert(params *ConvertParams, cmd *cobra.Command, _ []string) (err error) {
+	// Converted data goes to stdout, so informational messages go to stderr
+	info := func(format string, args ...any) { fmt.Fprintf(os.Stderr, format, args...) }
+
+	transactions, err := loadTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, info)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if params.Out != "" {
+		f, err := createOutputFile(params.Out)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing output file: %w", closeErr)
+			}
+			if err == nil {
+				info("Wrote %d transactions to %s\n", len(transactions), params.Out)
+			}
+		}()
+		out = f
+	}
+
+	switch params.To {
+	case "csv":
+		err = internal.WriteTransactionsCSV(out, transactions)
+	default:
+		err = internal.WriteSimpleJSON(out, transactions)
+	}
+	if err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type DescribeParams struct {
+	Name        string `descr:"Subscription name (as shown in the output)" positional:"true"`
+	Description string `descr:"Description to set (\"\" removes it); without a description, the current one is printed" positional:"true" optional:"true"`
+	Config      string `descr:"Path to config file (YAML, default ~/.subscription-detector/config.yaml)" optional:"true"`
+}
+
+func describeCmd() boa.CmdT[DescribeParams] {
+	return boa.CmdT[DescribeParams]{
+		Use:   "describe",
+		Short: "Set the description of a subscription in the config file",
+		Long:  "Sets (or removes) the description of a subscription in the config file, which is created if needed. Comments and other settings in the file are kept.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runDescribe),
+	}
+}
+
+func runDescribe(params *DescribeParams, cmd *cobra.Command, args []string) error {
+	path, err := configPathForEdit(params.Config)
+	if err != nil {
+		return err
+	}
+
+	// A description given as "" removes it; a missing description prints the current one
+	if len(args) < 2 {
+		cfg, err := loadConfigForEdit(path)
+		if err != nil {
+			return err
+		}
+		if desc := cfg.GetDescription(params.Name); desc != "" {
+			fmt.Println(desc)
+		}
+		return nil
+	}
+
+	if err := internal.SetConfigDescription(path, params.Name, params.Description); err != nil {
+		return fmt.Errorf("updating config: %w", err)
+	}
+	if params.Description == "" {
+		fmt.Printf("Removed description of %s (saved to %s)\n", params.Name, path)
+		return nil
+	}
+	fmt.Printf("Description of %s: %s (saved to %s)\n", params.Name, params.Description, path)
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type EventsParams struct {
+	State     string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Output    string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Name      string   `descr:"Only show events for subscriptions whose name contains this text" optional:"true"`
+	Kind      []string `descr:"Only show these event kinds" alts:"started,stopped,resumed,price_changed" optional:"true"`
+	Since     string   `descr:"Only show events on or after this date (YYYY-MM-DD)" optional:"true"`
+	Until     string   `descr:"Only show events on or before this date (YYYY-MM-DD)" optional:"true"`
+	Currency  string   `descr:"Currency code (default: currency of the latest snapshot)" optional:"true"`
+	Precision int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale    string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color     string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor   bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth  int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func eventsCmd() boa.CmdT[EventsParams] {
+	return boa.CmdT[EventsParams]{
+		Use:   "events",
+		Short: "Show recorded subscription lifecycle events",
+		Long:  "Shows subscription lifecycle events (started, stopped, resumed, price changed) recorded in the state file with --save-snapshot.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runEvents),
+	}
+}
+
+func runEvents(params *EventsParams, cmd *cobra.Command, _ []string) error {
+	for _, date := range []string{params.Since, params.Until} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+		}
+	}
+
+	statePath := resolveStatePath(params.State)
+	state, err := internal.LoadState(statePath)
+	if err != nil {
+		return fmt.Errorf("loading state: %w", err)
+	}
+
+	events := internal.FilterEvents(state.Events, internal.EventFilter{
+		Name:  params.Name,
+		Kinds: params.Kind,
+		Since: params.Since,
+		Until: params.Until,
+	})
+
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		if last := state.LastSnapshot(); last != nil {
+			currencyCode = last.Currency
+		}
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, nil))
+
+	if params.Output == "json" {
+		internal.PrintEventsJSON(os.Stdout, events, currency)
+		return nil
+	}
+
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintEventsTable(os.Stdout, events, internal.OutputOptions{
+		Currency: currency,
+		Locale:   locale,
+		MaxWidth: params.MaxWidth,
+	})
+	return nil
+}
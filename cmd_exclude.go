@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ExcludeParams struct {
+	Pattern              string   `descr:"Regex matched against subscription names" positional:"true"`
+	Files                []string `descr:"Transaction file(s) to preview which detected subscriptions the rule removes" positional:"true" optional:"true"`
+	Before               string   `descr:"Exclude only subscriptions that ended before this date (YYYY-MM-DD)" optional:"true"`
+	After                string   `descr:"Exclude only subscriptions that started after this date (YYYY-MM-DD)" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Preview against transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML, default ~/.subscription-detector/config.yaml)" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	DryRun               bool     `descr:"Only show which subscriptions the rule would remove, don't save it" optional:"true"`
+}
+
+func excludeCmd() boa.CmdT[ExcludeParams] {
+	return boa.CmdT[ExcludeParams]{
+		Use:   "exclude",
+		Short: "Add an exclusion rule to the config file",
+		Long:  "Validates the pattern and appends an exclusion rule to the config file, which is created if needed. Given transaction files (or --imported), it also shows which currently detected subscriptions the rule removes.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runExclude),
+	}
+}
+
+func runExclude(params *ExcludeParams, cmd *cobra.Command, _ []string) error {
+	rule, err := internal.NewExcludeRule(params.Pattern, params.Before, params.After)
+	if err != nil {
+		return err
+	}
+	path, err := configPathForEdit(params.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(params.Files) > 0 || params.Imported {
+		info := func(format string, args ...any) { fmt.Fprintf(os.Stderr, format, args...) }
+		transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfigForEdit(path)
+		if err != nil {
+			return err
+		}
+		subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+		if err != nil {
+			return err
+		}
+
+		var matched []internal.Subscription
+		for _, sub := range subscriptions {
+			if rule.Matches(sub) {
+				matched = append(matched, sub)
+			}
+		}
+		if len(matched) == 0 {
+			fmt.Println("The rule matches none of the detected subscriptions")
+		} else {
+			fmt.Printf("The rule removes %d detected subscription(s):\n", len(matched))
+			for _, sub := range matched {
+				fmt.Printf("  %s (%s to %s)\n", sub.Name, sub.StartDate.Format("2006-01-02"), sub.LastDate.Format("2006-01-02"))
+			}
+		}
+	}
+
+	if params.DryRun {
+		return nil
+	}
+	if err := internal.AddConfigExclude(path, rule); err != nil {
+		return fmt.Errorf("updating config: %w", err)
+	}
+	fmt.Printf("Added exclusion %q to %s\n", params.Pattern, path)
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ExportPaymentsParams struct {
+	Files                []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML)" optional:"true"`
+	Name                 string   `descr:"Subscription to export the payments of (as shown in the output)" optional:"true"`
+	All                  bool     `descr:"Export the payments of all subscriptions" optional:"true"`
+	Show                 string   `descr:"Which subscriptions to include with --all" default:"all" alts:"active,stopped,all" strict:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK), which sets the amount bounds of built-in known patterns" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Out                  string   `descr:"Write output to file instead of stdout" short:"o" optional:"true"`
+}
+
+func exportPaymentsCmd() boa.CmdT[ExportPaymentsParams] {
+	return boa.CmdT[ExportPaymentsParams]{
+		Use:   "export-payments",
+		Short: "Export the payment history of subscriptions as CSV",
+		Long:  "Detects subscriptions and writes every payment of one (--name) or all (--all) of them as CSV, with the subscription, date, transaction text and amount, e.g. to analyze price history in a spreadsheet.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runExportPayments),
+	}
+}
+
+func runExportPayments(params *ExportPaymentsParams, cmd *cobra.Command, _ []string) (err error) {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+	if (params.Name == "") == !params.All {
+		return errors.New("give either --name or --all")
+	}
+
+	// The CSV goes to stdout, so informational messages go to stderr
+	info := func(format string, args ...any) { fmt.Fprintf(os.Stderr, format, args...) }
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, _, err := resolveCurrencyAndLocale(currencyCode, "")
+	if err != nil {
+		return err
+	}
+	cfg.ScaleKnownBounds(currency.Code)
+
+	subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+	if err != nil {
+		return err
+	}
+	if params.All {
+		subscriptions = internal.FilterByStatus(subscriptions, params.Show)
+	} else {
+		sub := internal.FindSubscription(subscriptions, params.Name)
+		if sub == nil {
+			return fmt.Errorf("no subscription named %q detected", params.Name)
+		}
+		subscriptions = []internal.Subscription{*sub}
+	}
+
+	var out io.Writer = os.Stdout
+	if params.Out != "" {
+		f, err := createOutputFile(params.Out)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing output file: %w", closeErr)
+			}
+			if err == nil {
+				info("Wrote the payments of %d subscription(s) to %s\n", len(subscriptions), params.Out)
+			}
+		}()
+		out = f
+	}
+
+	if err := internal.WritePaymentsCSV(out, subscriptions); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type GenerateParams struct {
+	Months        int    `descr:"Months of data" default:"24"`
+	End           string `descr:"Last day of the data (YYYY-MM-DD, default: the end of last month)" optional:"true"`
+	Subscriptions int    `descr:"Number of monthly subscriptions" default:"10"`
+	Stopped       int    `descr:"How many of the subscriptions stop halfway through the data" default:"2"`
+	Noise         int    `descr:"Non-recurring purchases per month" default:"30"`
+	PriceChanges  string `descr:"How subscription prices change over time" default:"increases" alts:"none,increases,fluctuate" strict:"true"`
+	Seed          int    `descr:"Random seed; the same seed and options generate the same data" default:"1"`
+	Out           string `descr:"Write output to file instead of stdout" short:"o" optional:"true"`
+}
+
+func generateCmd() boa.CmdT[GenerateParams] {
+	return boa.CmdT[GenerateParams]{
+		Use:   "generate-testdata",
+		Short: "Generate a synthetic transaction file",
+		Long:  "Generates a simple-json file with a monthly salary, subscriptions and non-recurring purchases, for benchmarks and for trying out the tool without real bank data.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runGenerate),
+	}
+}
+
+func runGenerate(params *GenerateParams, _ *cobra.Command, _ []string) (err error) {
+	end := time.Now()
+	end = time.Date(end.Year(), end.Month(), 0, 0, 0, 0, 0, time.UTC)
+	if params.End != "" {
+		if end, err = time.Parse("2006-01-02", params.End); err != nil {
+			return fmt.Errorf("invalid --end %q (expected YYYY-MM-DD)", params.End)
+		}
+	}
+
+	transactions, err := internal.GenerateTransactions(internal.GenerateOptions{
+		Months:        params.Months,
+		End:           end,
+		Subscriptions: params.Subscriptions,
+		Stopped:       params.Stopped,
+		Noise:         params.Noise,
+		PriceChange:   params.PriceChanges,
+		Seed:          uint64(params.Seed),
+	})
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if params.Out != "" {
+		f, err := createOutputFile(params.Out)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing output file: %w", closeErr)
+			}
+			if err == nil {
+				fmt.Fprintf(os.Stderr, "Wrote %d transactions to %s\n", len(transactions), params.Out)
+			}
+		}()
+		out = f
+	}
+
+	if err := internal.WriteSimpleJSON(out, transactions); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type HistoryParams struct {
+	State     string `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Output    string `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Last      int    `descr:"Only include the last N snapshots (0 = all)" default:"0"`
+	Currency  string `descr:"Currency code (default: currency of the latest snapshot)" optional:"true"`
+	Precision int    `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale    string `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color     string `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor   bool   `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth  int    `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+	Real      bool   `descr:"Also show costs adjusted for inflation, in the prices of the latest snapshot (CPI table from the config, built-in for USD)" optional:"true"`
+	Config    string `descr:"Path to config file (YAML), for its cpi table with --real" optional:"true"`
+}
+
+func historyCmd() boa.CmdT[HistoryParams] {
+	return boa.CmdT[HistoryParams]{
+		Use:   "history",
+		Short: "Show how subscription costs evolved across saved snapshots",
+		Long:  "Shows the total monthly subscription cost and per-subscription price trends across snapshots saved with --save-snapshot.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runHistory),
+	}
+}
+
+func runHistory(params *HistoryParams, cmd *cobra.Command, _ []string) error {
+	statePath := resolveStatePath(params.State)
+	state, err := internal.LoadState(statePath)
+	if err != nil {
+		return fmt.Errorf("loading state: %w", err)
+	}
+
+	snapshots := state.Snapshots
+	if params.Last > 0 && len(snapshots) > params.Last {
+		snapshots = snapshots[len(snapshots)-params.Last:]
+	}
+
+	currencyCode := params.Currency
+	if currencyCode == "" && len(snapshots) > 0 {
+		currencyCode = snapshots[len(snapshots)-1].Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, nil))
+
+	entries, trends := internal.AnalyzeHistory(snapshots)
+	if params.Real {
+		cfg, err := loadConfig(params.Config, func(string, ...any) {})
+		if err != nil {
+			return err
+		}
+		cpi, err := internal.ResolveCPI(cfg, currency.Code)
+		if err != nil {
+			return err
+		}
+		internal.AdjustHistory(snapshots, entries, trends, cpi)
+	}
+	if params.Output == "json" {
+		internal.PrintHistoryJSON(os.Stdout, entries, trends, currency)
+		return nil
+	}
+
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintHistoryTable(os.Stdout, entries, trends, internal.OutputOptions{
+		Currency:  currency,
+		Locale:    locale,
+		MaxWidth:  params.MaxWidth,
+		RealTerms: params.Real,
+	})
+	return nil
+}
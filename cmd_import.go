@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ImportParams struct {
+	Source        string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay  []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Files         []string `descr:"Path(s) to transaction file(s)" positional:"true"`
+	State         string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Quiet         bool     `descr:"Only print the number of new transactions" optional:"true"`
+	Progress      bool     `descr:"Log progress to stderr every few seconds (a progress bar is shown on terminals for large inputs)" optional:"true"`
+	Cache         bool     `descr:"Cache parsed transactions by file content, so parsing unchanged files again is instant" optional:"true"`
+	CacheDir      string   `descr:"Directory for --cache (default ~/.subscription-detector/cache)" optional:"true"`
+	Timezone      string   `descr:"Timezone that timestamps in exports are converted to before taking their date (e.g., UTC, Europe/Stockholm; default local)" optional:"true"`
+	XlsxSheets    []string `descr:"Only read these sheets of Excel workbooks (default: all sheets with transactions)" optional:"true"`
+	StrictParse   bool     `descr:"Fail if a row of an export can't be parsed (e.g., an invalid date or amount) instead of skipping it with a warning" optional:"true"`
+}
+
+func importCmd() boa.CmdT[ImportParams] {
+	return boa.CmdT[ImportParams]{
+		Use:   "import",
+		Short: "Import transactions into the state file, skipping ones already stored",
+		Long:  "Stores transactions in the state file for incremental workflows. Transactions already stored (same date, text and amount) are skipped, so overlapping exports can be imported repeatedly. Analyze imported transactions with --imported.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runImport),
+	}
+}
+
+func runImport(params *ImportParams, cmd *cobra.Command, _ []string) error {
+	info := func(format string, args ...any) {
+		if !params.Quiet {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	ctx, err := withTimezone(withParseCache(internal.WithSheets(cmd.Context(), params.XlsxSheets), params.Cache, params.CacheDir), params.Timezone)
+	if err != nil {
+		return err
+	}
+	parseWarnings := internal.NewParseWarnings(params.StrictParse)
+	ctx = internal.WithParseWarnings(ctx, parseWarnings)
+	ctx, progress := startProgress(ctx, params.Files, params.Progress, params.Quiet)
+	defer stopProgress(progress)
+
+	transactions, err := loadTransactions(ctx, params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, aboveProgress(progress, info))
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress.Above(func() { printParseWarnings(parseWarnings) })
+	} else {
+		printParseWarnings(parseWarnings)
+	}
+
+	statePath := resolveStatePath(params.State)
+	if progress != nil {
+		progress.Step("Loading state")
+	}
+	state, err := internal.LoadState(statePath)
+	if err != nil {
+		return fmt.Errorf("loading state: %w", err)
+	}
+
+	if progress != nil {
+		progress.Step("Importing")
+	}
+	result := state.ImportTransactions(transactions)
+	if result.Added > 0 {
+		if progress != nil {
+			progress.Step("Saving state")
+		}
+		if err := state.Save(statePath); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+	}
+	stopProgress(progress)
+
+	if params.Quiet {
+		fmt.Println(result.Added)
+		return nil
+	}
+	fmt.Printf("Imported %d new transactions (%d already stored, %d total in %s)\n",
+		result.Added, result.Duplicates, len(state.Transactions), statePath)
+	return nil
+}
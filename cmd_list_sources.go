@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ListSourcesParams struct {
+	Output   string `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Locale   string `descr:"Locale for labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color    string `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor  bool   `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth int    `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func listSourcesCmd() boa.CmdT[ListSourcesParams] {
+	return boa.CmdT[ListSourcesParams]{
+		Use:   "list-sources",
+		Short: "List the supported transaction file formats",
+		Long:  "Lists every registered source type with a description, the expected file extensions, whether it is auto-detected from file contents and an example invocation.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runListSources),
+	}
+}
+
+func runListSources(params *ListSourcesParams, _ *cobra.Command, _ []string) error {
+	infos := internal.SourceInfos()
+	if params.Output == "json" {
+		internal.PrintSourcesJSON(os.Stdout, infos)
+		return nil
+	}
+
+	_, locale, err := resolveCurrencyAndLocale("", params.Locale)
+	if err != nil {
+		return err
+	}
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintSourcesTable(os.Stdout, infos, internal.OutputOptions{Locale: locale, MaxWidth: params.MaxWidth})
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ReportParams struct {
+	Files                []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML)" optional:"true"`
+	By                   string   `descr:"Period to group spend by" default:"month" alts:"month,quarter,year" strict:"true"`
+	Real                 bool     `descr:"Also show spend adjusted for inflation, in the prices of the last period's year (CPI table from the config, built-in for USD)" optional:"true"`
+	Business             bool     `descr:"Only include business expenses (see business in the config), with the VAT included and the deductible amount" optional:"true"`
+	Show                 string   `descr:"Which subscriptions to include" default:"all" alts:"active,stopped,all" strict:"true"`
+	Tags                 []string `descr:"Filter by tags: any of TAG,TAG; all of TAG+TAG; !TAG to exclude (e.g., entertainment,!gaming)" optional:"true"`
+	Filter               string   `descr:"Only show subscriptions whose name or description matches this regex (case-insensitive, e.g., \"spotify|netflix\")" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Output               string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision            int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale               string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color                string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor              bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth             int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func reportCmd() boa.CmdT[ReportParams] {
+	return boa.CmdT[ReportParams]{
+		Use:   "report",
+		Short: "Show actual subscription spend per month, quarter or year",
+		Long:  "Shows what was actually paid for subscriptions in each historical month (or quarter or year): the sum of the transactions matched to detected subscriptions, as opposed to the latest amount × 12 in the summary. With --business, only business expenses are included, with the VAT and the deductible amount of each period.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runReport),
+	}
+}
+
+func runReport(params *ReportParams, cmd *cobra.Command, _ []string) error {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+	if _, err := internal.ParseTagExpression(params.Tags); err != nil {
+		return err
+	}
+	filter, err := compileFilter(params.Filter)
+	if err != nil {
+		return err
+	}
+
+	// Informational messages would corrupt JSON output
+	info := func(format string, args ...any) {
+		if params.Output != "json" {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+
+	subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+	if err != nil {
+		return err
+	}
+	subscriptions = internal.FilterByStatus(subscriptions, params.Show)
+	if len(params.Tags) > 0 {
+		subscriptions = internal.FilterByTags(subscriptions, params.Tags, cfg)
+	}
+	if filter != nil {
+		subscriptions = internal.FilterByPattern(subscriptions, filter, cfg)
+	}
+	opts := internal.OutputOptions{
+		Currency:  currency,
+		Locale:    locale,
+		MaxWidth:  params.MaxWidth,
+		RealTerms: params.Real,
+	}
+
+	if params.Business {
+		periods := internal.BusinessByPeriod(subscriptions, params.By, cfg)
+		if params.Output == "json" {
+			internal.PrintBusinessReportJSON(os.Stdout, periods, params.By, currency)
+			return nil
+		}
+		configureColors(params.Color, params.NoColor, os.Stdout)
+		internal.PrintBusinessReportTable(os.Stdout, periods, params.By, opts)
+		return nil
+	}
+
+	periods := internal.SpendByPeriod(subscriptions, params.By)
+	if params.Real {
+		cpi, err := internal.ResolveCPI(cfg, currency.Code)
+		if err != nil {
+			return err
+		}
+		internal.AdjustSpend(subscriptions, periods, params.By, cpi)
+	}
+	if params.Output == "json" {
+		internal.PrintReportJSON(os.Stdout, periods, params.By, currency)
+		return nil
+	}
+
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintReportTable(os.Stdout, periods, params.By, opts)
+	return nil
+}
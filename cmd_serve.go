@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type ServeParams struct {
+	Addr      string  `descr:"Address to listen on (e.g., :8080 for all interfaces; there is no authentication)" default:"127.0.0.1:8080"`
+	State     string  `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config    string  `descr:"Path to config file (YAML)" optional:"true"`
+	Tolerance float64 `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	Currency  string  `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision int     `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale    string  `descr:"Locale for dates and numbers (e.g., sv-SE, en-US)" optional:"true"`
+}
+
+func serveCmd() boa.CmdT[ServeParams] {
+	return boa.CmdT[ServeParams]{
+		Use:   "serve",
+		Short: "Serve a web UI and JSON API for imported transactions",
+		Long:  "Serves a web UI with a filterable, sortable subscriptions table, per-subscription payment history charts and an upload form for new export files, plus a JSON API under /api, Prometheus metrics on /metrics, an iCalendar feed of expected payments on /calendar.ics and an Atom feed of subscription changes on /feed.atom. Detection runs on the transactions imported into the state file.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runServe),
+	}
+}
+
+func runServe(params *ServeParams, cmd *cobra.Command, _ []string) error {
+	info := func(format string, args ...any) { fmt.Printf(format, args...) }
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+
+	server := &internal.Server{
+		StatePath: resolveStatePath(params.State),
+		Config:    cfg,
+		Tolerance: params.Tolerance,
+		Currency:  currency,
+		Locale:    locale,
+	}
+
+	// Requests get contexts derived from the command's, so Ctrl+C also cancels running detections
+	ctx := cmd.Context()
+	httpServer := &http.Server{
+		Addr:        params.Addr,
+		Handler:     server.Handler(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	info("Serving web UI on %s (state: %s)\n", params.Addr, server.StatePath)
+	if !isLoopbackAddr(params.Addr) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is reachable from other machines, and the web UI and API (including imports) have no authentication\n", params.Addr)
+	}
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections from this machine
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
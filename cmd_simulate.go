@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type SimulateParams struct {
+	Files                []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML)" optional:"true"`
+	Cancel               []string `descr:"Subscriptions to cancel, by name or part of it (e.g., Netflix,HBO)" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Output               string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision            int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale               string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color                string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor              bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth             int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func simulateCmd() boa.CmdT[SimulateParams] {
+	return boa.CmdT[SimulateParams]{
+		Use:   "simulate",
+		Short: "Show how cancelling subscriptions would change the totals",
+		Long:  "Detects subscriptions and recomputes the monthly and yearly totals without the ones given with --cancel, showing what the cancellations would save before making them.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runSimulate),
+	}
+}
+
+func runSimulate(params *SimulateParams, cmd *cobra.Command, _ []string) error {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+	if len(params.Cancel) == 0 {
+		return errors.New("give the subscriptions to cancel with --cancel")
+	}
+
+	// Informational messages would corrupt JSON output
+	info := func(format string, args ...any) {
+		if params.Output != "json" {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+
+	subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+	if err != nil {
+		return err
+	}
+
+	sim := internal.SimulateCancellations(subscriptions, params.Cancel)
+	for _, name := range sim.NotFound {
+		fmt.Fprintf(os.Stderr, "Warning: no active subscription matches %q\n", name)
+	}
+
+	if params.Output == "json" {
+		internal.PrintSimulationJSON(os.Stdout, sim, currency)
+		return nil
+	}
+
+	info("\n")
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintSimulationTable(os.Stdout, sim, internal.OutputOptions{
+		Currency: currency,
+		Locale:   locale,
+		MaxWidth: params.MaxWidth,
+	})
+	return nil
+}
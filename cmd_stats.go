@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type StatsParams struct {
+	Files         []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source        string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay  []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported      bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State         string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Top           int      `descr:"Number of top payees to show" default:"10"`
+	GapDays       int      `descr:"Report periods of at least this many days without transactions (0 = disabled)" default:"21"`
+	Output        string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
+	Currency      string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision     int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale        string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Color         string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor       bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth      int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+}
+
+func statsCmd() boa.CmdT[StatsParams] {
+	return boa.CmdT[StatsParams]{
+		Use:   "stats",
+		Short: "Show raw transaction statistics to sanity-check exports",
+		Long:  "Shows dataset-level statistics of the raw transactions, before any detection: transactions, expenses and income per month, the top payees and gaps without transactions. Use it to check that exports are complete before trusting detection results.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runStats),
+	}
+}
+
+func runStats(params *StatsParams, cmd *cobra.Command, _ []string) error {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+
+	// Informational messages would corrupt JSON output
+	info := func(format string, args ...any) {
+		if params.Output != "json" {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+	info("\n")
+
+	currency, locale, err := resolveCurrencyAndLocale(params.Currency, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, nil))
+	stats := internal.ComputeStats(transactions, params.Top, params.GapDays)
+
+	if params.Output == "json" {
+		internal.PrintStatsJSON(os.Stdout, stats, currency)
+		return nil
+	}
+
+	configureColors(params.Color, params.NoColor, os.Stdout)
+	internal.PrintStatsTable(os.Stdout, stats, internal.OutputOptions{
+		Currency: currency,
+		Locale:   locale,
+		MaxWidth: params.MaxWidth,
+	})
+	return nil
+}
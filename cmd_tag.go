@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type TagParams struct {
+	Name   string   `descr:"Subscription name (as shown in the output)" positional:"true"`
+	Tags   []string `descr:"Tags to add (or remove with --remove); without tags, the current tags are printed" positional:"true" optional:"true"`
+	Config string   `descr:"Path to config file (YAML, default ~/.subscription-detector/config.yaml)" optional:"true"`
+	Remove bool     `descr:"Remove the given tags instead of adding them" optional:"true"`
+}
+
+func tagCmd() boa.CmdT[TagParams] {
+	return boa.CmdT[TagParams]{
+		Use:   "tag",
+		Short: "Add or remove tags of a subscription in the config file",
+		Long:  "Adds tags to (or removes tags from) a subscription in the config file, which is created if needed. Comments and other settings in the file are kept.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runTag),
+	}
+}
+
+func runTag(params *TagParams, _ *cobra.Command, _ []string) error {
+	path, err := configPathForEdit(params.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(params.Tags) == 0 {
+		cfg, err := loadConfigForEdit(path)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strings.Join(cfg.GetTags(params.Name), "\n"))
+		return nil
+	}
+
+	for _, tag := range params.Tags {
+		if params.Remove {
+			found, err := internal.RemoveConfigTag(path, params.Name, tag)
+			if err != nil {
+				return fmt.Errorf("updating config: %w", err)
+			}
+			if !found {
+				fmt.Fprintf(os.Stderr, "Warning: %s is not tagged %q\n", params.Name, tag)
+			}
+			continue
+		}
+		if err := internal.AddConfigTag(path, params.Name, tag); err != nil {
+			return fmt.Errorf("updating config: %w", err)
+		}
+	}
+
+	cfg, err := loadConfigForEdit(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Tags of %s: %s (saved to %s)\n", params.Name, strings.Join(cfg.GetTags(params.Name), ", "), path)
+	return nil
+}
+
+// configPathForEdit returns the config file that tag and describe edit
+func configPathForEdit(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	path = internal.DefaultConfigPath()
+	if path == "" {
+		return "", errors.New("could not determine the home directory (use --config)")
+	}
+	return path, nil
+}
+
+// loadConfigForEdit loads the config file at path, or an empty config if it doesn't exist yet
+func loadConfigForEdit(path string) (*internal.Config, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &internal.Config{}, nil
+	}
+	cfg, err := internal.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading config %s: %w", path, err)
+	}
+	return cfg, nil
+}
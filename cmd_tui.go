@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type TUIParams struct {
+	Files                []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Source               string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Imported             bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML); tags and exclusions are saved here (default ~/.subscription-detector/config.yaml)" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision            int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale               string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	ReadOnly             bool     `descr:"Don't allow tagging and excluding (never writes the config file)" optional:"true"`
+}
+
+func tuiCmd() boa.CmdT[TUIParams] {
+	return boa.CmdT[TUIParams]{
+		Use:   "tui",
+		Short: "Browse subscriptions in an interactive full-screen dashboard",
+		Long:  "Shows a sortable, filterable subscription list with a detail pane (payment history and amounts). Subscriptions can be tagged and excluded with keybindings; changes are saved to the config file.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runTUI),
+	}
+}
+
+func runTUI(params *TUIParams, cmd *cobra.Command, _ []string) error {
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+
+	// The dashboard takes over the screen, so loading messages are not shown
+	info := func(string, ...any) {}
+
+	transactions, err := loadAllTransactions(cmd.Context(), params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay}, params.Imported, params.State, info)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+
+	subscriptions, _, err := internal.NewDetector(internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)).Analyze(cmd.Context(), transactions, cfg)
+	if err != nil {
+		return err
+	}
+
+	configPath := params.Config
+	if configPath == "" {
+		configPath = internal.DefaultConfigPath()
+	}
+	if params.ReadOnly {
+		configPath = ""
+	}
+
+	opts := internal.OutputOptions{Currency: currency, Locale: locale}
+	return internal.RunTUI(subscriptions, cfg, configPath, opts)
+}
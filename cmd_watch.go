@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/fsnotify/fsnotify"
+	"github.com/gigurra/subscription-detector/internal"
+	"github.com/spf13/cobra"
+)
+
+type WatchParams struct {
+	Dir                  string   `descr:"Directory to watch for bank export files" positional:"true"`
+	Source               string   `descr:"Format of all files (default: detected from contents and extension)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts        []string `descr:"Formats or files (file name patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay         []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Config               string   `descr:"Path to config file (YAML)" optional:"true"`
+	State                string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	Tolerance            float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	IncludePartialMonths bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps       bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	Currency             string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision            int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale               string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US)" optional:"true"`
+	Debounce             int      `descr:"Milliseconds to wait after the last file change before processing" default:"2000"`
+	Once                 bool     `descr:"Process files already in the directory and exit instead of watching" optional:"true"`
+}
+
+func watchCmd() boa.CmdT[WatchParams] {
+	return boa.CmdT[WatchParams]{
+		Use:   "watch",
+		Short: "Watch a directory for bank exports and report subscription changes",
+		Long:  "Monitors a directory for new or updated bank export files. New transactions are imported into the state file, detection is re-run on all imported transactions, and changes since the last snapshot are printed and sent to the notifiers in the config. Files already in the directory are processed on startup.",
+		ParamEnrich: boa.ParamEnricherCombine(
+			boa.ParamEnricherName,
+			boa.ParamEnricherShort,
+			boa.ParamEnricherBool,
+		),
+		RunFunc: withErrors(runWatch),
+	}
+}
+
+// watcher imports export files into the state file and reports changes
+type watcher struct {
+	params    *WatchParams
+	statePath string
+	cfg       *internal.Config
+	opts      internal.OutputOptions
+}
+
+func runWatch(params *WatchParams, cmd *cobra.Command, _ []string) error {
+	info := func(format string, args ...any) { fmt.Printf(format, args...) }
+
+	if stat, err := os.Stat(params.Dir); err != nil || !stat.IsDir() {
+		return fmt.Errorf("%s is not a directory", params.Dir)
+	}
+
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
+	}
+	currencyCode := params.Currency
+	if currencyCode == "" {
+		currencyCode = cfg.Currency
+	}
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
+	}
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
+	configureColors("auto", false, os.Stdout)
+
+	w := &watcher{
+		params:    params,
+		statePath: resolveStatePath(params.State),
+		cfg:       cfg,
+		opts:      internal.OutputOptions{Currency: currency, Locale: locale},
+	}
+
+	// Process files that are already there (already imported transactions are skipped)
+	entries, err := os.ReadDir(params.Dir)
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+	var existing []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			existing = append(existing, filepath.Join(params.Dir, entry.Name()))
+		}
+	}
+	w.process(cmd.Context(), existing)
+
+	if params.Once {
+		return nil
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer fsw.Close()
+	if err := fsw.Add(params.Dir); err != nil {
+		return fmt.Errorf("watching %s: %w", params.Dir, err)
+	}
+	info("Watching %s for bank exports (Ctrl+C to stop)\n", params.Dir)
+
+	// Files are often written in several steps (downloads, sync clients), so changes are
+	// collected until no event has arrived for the debounce period
+	debounce := time.Duration(params.Debounce) * time.Millisecond
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-cmd.Context().Done():
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) || event.Has(fsnotify.Rename) {
+				pending[event.Name] = true
+				timer.Reset(debounce)
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+		case <-timer.C:
+			var paths []string
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			pending = make(map[string]bool)
+			w.process(cmd.Context(), paths)
+		}
+	}
+}
+
+// process imports the given files and, if any new transactions were added, re-runs detection
+// on all imported transactions, saves a snapshot and prints the changes since the last one.
+// Errors are reported without stopping the watcher.
+func (w *watcher) process(ctx context.Context, paths []string) {
+	sort.Strings(paths)
+
+	state, err := internal.LoadState(w.statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+		return
+	}
+
+	added := 0
+	for _, path := range paths {
+		if isTemporaryFile(path) || w.isStateFile(path) {
+			continue
+		}
+		if stat, err := os.Stat(path); err != nil || stat.IsDir() {
+			continue // removed again or renamed away
+		}
+		format := w.params.Source
+		if format == "" {
+			if format, err = internal.DetectFormat(path); err != nil || format == "" {
+				continue
+			}
+		}
+
+		src, err := sourceOptions{invert: w.params.InvertAmounts, statementDays: w.params.StatementDay}.sourceFile(format, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		txs, err := internal.ParseFile(ctx, format, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		for i, tx := range txs {
+			txs[i] = src.Apply(tx)
+		}
+		result := state.ImportTransactions(txs)
+		fmt.Printf("%s: imported %d new transactions (%d already stored)\n", filepath.Base(path), result.Added, result.Duplicates)
+		added += result.Added
+	}
+	if added == 0 {
+		return
+	}
+
+	transactions, err := state.StoredTransactions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading imported transactions: %v\n", err)
+		return
+	}
+	subscriptions, dateRange, err := internal.NewDetector(internal.WithTolerance(w.params.Tolerance), internal.WithPartialMonths(w.params.IncludePartialMonths), internal.WithIgnoreDataGaps(w.params.IgnoreDataGaps)).Analyze(ctx, transactions, w.cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+
+	snapshot := internal.NewSnapshot(subscriptions, dateRange, w.opts.Currency.Code, time.Now())
+	last := state.LastSnapshot()
+	var report *internal.ChangeReport
+	if last != nil {
+		report = &internal.ChangeReport{Since: last.Timestamp, Changes: internal.CompareSnapshots(*last, snapshot)}
+	}
+	state.AddSnapshot(snapshot)
+	state.RecordEvents(internal.DetectEvents(subscriptions))
+	if err := state.Save(w.statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving state: %v\n", err)
+		return
+	}
+
+	if report == nil {
+		active := len(internal.FilterByStatus(subscriptions, "active"))
+		fmt.Printf("Baseline snapshot saved: %d subscriptions (%d active)\n", len(subscriptions), active)
+		return
+	}
+	internal.PrintChanges(os.Stdout, report, w.opts)
+	if len(w.cfg.Notify) > 0 {
+		notify(ctx, w.cfg, report, w.opts.Currency, func(format string, args ...any) { fmt.Printf(format, args...) })
+	}
+}
+
+// isStateFile reports whether path is the state file itself (which may live in the watched directory)
+func (w *watcher) isStateFile(path string) bool {
+	abs, err1 := filepath.Abs(path)
+	stateAbs, err2 := filepath.Abs(w.statePath)
+	return err1 == nil && err2 == nil && abs == stateAbs
+}
+
+// isTemporaryFile reports whether path looks like a partial download or an editor lock file
+func isTemporaryFile(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$")
+}
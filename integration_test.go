@@ -2,10 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -13,6 +15,29 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+// updateGolden rewrites the golden files in testdata/golden instead of comparing against them:
+// go test -run TestCLI_Golden -update
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// cliCommand builds the command to run the CLI with a fixed English locale,
+// so assertions on labels and formatting don't depend on the developer's system locale
+func cliCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Env = append(os.Environ(), "LC_MONETARY=en_US.UTF-8", "LC_ALL=en_US.UTF-8", "LANG=en_US.UTF-8")
+	return cmd
+}
+
+// buildCLI builds the CLI binary and returns its path.
+// Needed to observe exit codes, since go run reports every failure as exit code 1.
+func buildCLI(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join(t.TempDir(), "subscription-detector")
+	if output, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, output)
+	}
+	return binary
+}
+
 // runCLI runs the subscription-detector CLI with the given args and returns stdout
 // It uses an empty config to avoid interference from user's config
 func runCLI(t *testing.T, args ...string) string {
@@ -24,7 +49,7 @@ func runCLI(t *testing.T, args ...string) string {
 	os.WriteFile(emptyConfigPath, []byte(""), 0644)
 
 	fullArgs := append([]string{"--config", emptyConfigPath}, args...)
-	cmd := exec.Command("go", append([]string{"run", "."}, fullArgs...)...)
+	cmd := cliCommand(fullArgs...)
 
 	// Capture stdout only (stderr has go download messages)
 	output, err := cmd.Output()
@@ -37,6 +62,19 @@ func runCLI(t *testing.T, args ...string) string {
 	return string(output)
 }
 
+// runSubcommand runs a CLI subcommand (which takes no --config flag) and returns stdout
+func runSubcommand(t *testing.T, args ...string) []byte {
+	t.Helper()
+	output, err := cliCommand(args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			t.Fatalf("CLI failed: %v\nStderr: %s", err, exitErr.Stderr)
+		}
+		t.Fatalf("CLI failed: %v", err)
+	}
+	return output
+}
+
 // runCLIJSON runs the CLI with JSON output and parses the result
 func runCLIJSON(t *testing.T, args ...string) internal.JSONOutput {
 	t.Helper()
@@ -61,7 +99,7 @@ func runCLIWithConfig(t *testing.T, configContent string, args ...string) string
 	}
 
 	fullArgs := append([]string{"--config", configPath}, args...)
-	cmd := exec.Command("go", append([]string{"run", "."}, fullArgs...)...)
+	cmd := cliCommand(fullArgs...)
 
 	// Capture stdout only (stderr has go download messages)
 	output, err := cmd.Output()
@@ -167,6 +205,59 @@ tags:
 	}
 }
 
+func TestCLI_TagExpressions(t *testing.T) {
+	config := `
+tags:
+  Netflix: [entertainment, video]
+  Spotify: [entertainment, music]
+`
+	for filter, expected := range map[string]string{
+		"entertainment,!music":  "Netflix",
+		"entertainment+music":   "Spotify",
+		"!video":                "Spotify",
+		"video,music":           "Netflix,Spotify",
+		"entertainment+!video,": "Spotify",
+	} {
+		result := runCLIWithConfigJSON(t, config, "--source", "simple-json", "testdata/sample.json", "--tags", filter)
+		var names []string
+		for _, sub := range result.Subscriptions {
+			names = append(names, sub.Name)
+		}
+		slices.Sort(names)
+		if got := strings.Join(names, ","); got != expected {
+			t.Errorf("--tags %s: expected %s, got %s", filter, expected, got)
+		}
+	}
+
+	if _, err := cliCommand("--tags", "entertainment+", "--source", "simple-json", "testdata/sample.json").Output(); err == nil {
+		t.Error("expected an invalid tag filter to fail")
+	}
+}
+
+func TestCLI_Filter(t *testing.T) {
+	config := `
+descriptions:
+  Spotify: "Music streaming"
+`
+	result := runCLIWithConfigJSON(t, config, "--source", "simple-json", "testdata/sample.json", "--filter", "NETFLIX|music")
+	var names []string
+	for _, sub := range result.Subscriptions {
+		names = append(names, sub.Name)
+	}
+	slices.Sort(names)
+	if got := strings.Join(names, ","); got != "Netflix,Spotify" {
+		t.Errorf("expected Netflix by name and Spotify by description, got %s", got)
+	}
+	result = runCLIWithConfigJSON(t, config, "--source", "simple-json", "testdata/sample.json", "--filter", "^net")
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Name != "Netflix" {
+		t.Errorf("expected only Netflix, got %+v", result.Subscriptions)
+	}
+
+	if _, err := cliCommand("--filter", "(", "--source", "simple-json", "testdata/sample.json").Output(); err == nil {
+		t.Error("expected an invalid --filter to fail")
+	}
+}
+
 func TestCLI_Descriptions(t *testing.T) {
 	config := `
 descriptions:
@@ -427,3 +518,1465 @@ func TestCLI_MixedPrefixAndSourceFlag(t *testing.T) {
 		t.Errorf("expected 2 subscriptions, got %d", result.Summary.Count)
 	}
 }
+
+func TestCLI_OutFile(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "reports", "subs.json")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--output", "json", "--out", outPath)
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("expected output file to be written: %v", err)
+	}
+	var result internal.JSONOutput
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to parse JSON output file: %v\nContent: %s", err, data)
+	}
+	if result.Summary.Count != 2 {
+		t.Errorf("expected 2 subscriptions in output file, got %d", result.Summary.Count)
+	}
+}
+
+func TestCLI_ColorAutoDisabledWhenPiped(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json")
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("expected no ANSI escape codes when output is not a terminal, got: %q", output)
+	}
+
+	output = runCLI(t, "--source", "simple-json", "testdata/sample.json", "--color", "always")
+	if !strings.Contains(output, "\x1b[") {
+		t.Errorf("expected ANSI escape codes with --color always, got: %q", output)
+	}
+}
+
+func TestCLI_MaxWidth(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--max-width", "40")
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "╭") || strings.HasPrefix(line, "│") {
+			if width := len([]rune(line)); width > 40 {
+				t.Errorf("expected table lines of at most 40 chars, got %d: %q", width, line)
+			}
+		}
+	}
+}
+
+func TestCLI_SummaryOnly(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--summary-only", "--quiet")
+
+	if strings.Contains(output, "Loaded") {
+		t.Errorf("expected no informational messages in quiet mode, got: %s", output)
+	}
+	if strings.Contains(output, "Netflix") {
+		t.Errorf("expected no subscription rows in summary-only mode, got: %s", output)
+	}
+	if !strings.Contains(output, "Found 2 subscriptions (2 active, 0 stopped)") {
+		t.Errorf("expected subscription counts, got: %s", output)
+	}
+	if !strings.Contains(output, "Monthly total (active):") {
+		t.Errorf("expected monthly total, got: %s", output)
+	}
+}
+
+func TestCLI_SummaryOnlyJSON(t *testing.T) {
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json", "--summary-only")
+
+	if len(result.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions in summary-only JSON, got %d", len(result.Subscriptions))
+	}
+	if result.Summary.Count != 2 || result.Summary.MonthlyTotal != 228 {
+		t.Errorf("expected count 2 and monthly total 228, got %d and %.0f", result.Summary.Count, result.Summary.MonthlyTotal)
+	}
+}
+
+func TestCLI_TotalPaid(t *testing.T) {
+	tmpDir := t.TempDir()
+	testData := `{"transactions": [
+		{"date": "2025-01-15", "text": "ServiceA", "amount": -50.00},
+		{"date": "2025-02-15", "text": "ServiceA", "amount": -50.00},
+		{"date": "2025-03-15", "text": "ServiceA", "amount": -60.00},
+		{"date": "2025-01-20", "text": "ServiceB", "amount": -75.00},
+		{"date": "2025-02-20", "text": "ServiceB", "amount": -75.00},
+		{"date": "2025-03-20", "text": "ServiceB", "amount": -75.00},
+		{"date": "2025-04-20", "text": "ServiceB", "amount": -75.00},
+		{"date": "2025-05-20", "text": "ServiceB", "amount": -75.00},
+		{"date": "2025-06-20", "text": "ServiceB", "amount": -75.00}
+	]}`
+	dataPath := filepath.Join(tmpDir, "data.json")
+	os.WriteFile(dataPath, []byte(testData), 0644)
+
+	result := runCLIJSON(t, "--source", "simple-json", dataPath, "--show", "all")
+
+	for _, sub := range result.Subscriptions {
+		if sub.Name == "ServiceA" && sub.TotalPaid != 160 {
+			t.Errorf("expected ServiceA total_paid 160, got %.0f", sub.TotalPaid)
+		}
+		if sub.Name == "ServiceB" && sub.TotalPaid != 450 {
+			t.Errorf("expected ServiceB total_paid 450, got %.0f", sub.TotalPaid)
+		}
+	}
+	// ServiceA stopped in March
+	if result.Summary.StoppedSpend != 160 {
+		t.Errorf("expected stopped_total_paid 160, got %.0f", result.Summary.StoppedSpend)
+	}
+
+	output := runCLI(t, "--source", "simple-json", dataPath, "--show", "all")
+	if !strings.Contains(output, "Lifetime spend on stopped subscriptions:") {
+		t.Errorf("expected lifetime spend line for stopped subscriptions, got: %s", output)
+	}
+}
+
+func TestCLI_Locale(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--locale", "sv-SE")
+
+	if !strings.Contains(output, "Hittade 2 prenumerationer") {
+		t.Errorf("expected Swedish summary line, got: %s", output)
+	}
+	if !strings.Contains(output, "~15:e") {
+		t.Errorf("expected Swedish ordinal day, got: %s", output)
+	}
+
+	output = runCLI(t, "--source", "simple-json", "testdata/sample.json", "--locale", "en-US")
+	if !strings.Contains(output, "12/15/2025") {
+		t.Errorf("expected US date format, got: %s", output)
+	}
+}
+
+func TestCLI_PrintSchema(t *testing.T) {
+	output := runCLI(t, "--print-schema")
+
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(output), &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v\nOutput: %s", err, output)
+	}
+	if schema["$schema"] == nil || schema["properties"] == nil {
+		t.Errorf("expected a JSON Schema document, got: %s", output)
+	}
+
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json")
+	if result.SchemaVersion != internal.JSONSchemaVersion {
+		t.Errorf("expected schema_version %d, got %d", internal.JSONSchemaVersion, result.SchemaVersion)
+	}
+}
+
+func TestCLI_SnapshotCompare(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("expected state file to be created: %v", err)
+	}
+
+	// Same data plus a new subscription
+	data, _ := os.ReadFile("testdata/sample.json")
+	extended := strings.Replace(string(data), `"transactions": [`, `"transactions": [
+    {"date": "2025-10-05", "text": "NewService", "amount": -49.00},
+    {"date": "2025-11-05", "text": "NewService", "amount": -49.00},
+    {"date": "2025-12-05", "text": "NewService", "amount": -49.00},`, 1)
+	dataPath := filepath.Join(tmpDir, "data.json")
+	os.WriteFile(dataPath, []byte(extended), 0644)
+
+	result := runCLIJSON(t, "--source", "simple-json", dataPath, "--state", statePath, "--compare-with-last")
+	if result.ComparedWith == "" {
+		t.Error("expected compared_with to be set")
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Name != "NewService" || result.Changes[0].Kind != "new" {
+		t.Errorf("expected a single 'new' change for NewService, got %+v", result.Changes)
+	}
+
+	output := runCLI(t, "--source", "simple-json", dataPath, "--state", statePath, "--compare-with-last")
+	if !strings.Contains(output, "Changes since last snapshot") || !strings.Contains(output, "NEW") {
+		t.Errorf("expected highlighted changes in table output, got: %s", output)
+	}
+}
+
+func TestCLI_Golden(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+
+	// With --stable, the snapshot's timestamp doesn't appear in the output
+	args := []string{"--source", "simple-json", "testdata/sample.json", "--state", statePath, "--compare-with-last", "--stable", "--show", "all", "--quiet"}
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"sample.json", []string{"--output", "json"}},
+		{"sample.txt", []string{"--output", "table"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			output := runCLI(t, append(slices.Clone(args), tt.args...)...)
+			path := filepath.Join("testdata", "golden", tt.golden)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading golden file (create it with -update): %v", err)
+			}
+			if output != string(expected) {
+				t.Errorf("output differs from %s (run with -update if intended):\n%s", path, output)
+			}
+		})
+	}
+}
+
+func TestCLI_History(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+
+	output := runSubcommand(t, "history", "--state", statePath, "--output", "json")
+
+	var result internal.JSONHistory
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("failed to parse history JSON: %v\nOutput: %s", err, output)
+	}
+	if len(result.Snapshots) != 2 {
+		t.Errorf("expected 2 snapshots, got %d", len(result.Snapshots))
+	}
+	if len(result.Trends) != 2 {
+		t.Errorf("expected 2 price trends, got %d", len(result.Trends))
+	}
+	if result.Snapshots[1].MonthlyTotal != 228 {
+		t.Errorf("expected monthly total 228, got %.0f", result.Snapshots[1].MonthlyTotal)
+	}
+}
+
+func TestCLI_Import(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	output := string(runSubcommand(t, "import", "--source", "simple-json", "testdata/sample.json", "--state", statePath))
+	if !strings.Contains(output, "Imported 27 new transactions") {
+		t.Errorf("expected 27 new transactions on first import, got: %s", output)
+	}
+
+	output = string(runSubcommand(t, "import", "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--quiet"))
+	if strings.TrimSpace(output) != "0" {
+		t.Errorf("expected 0 new transactions on re-import, got: %s", output)
+	}
+
+	result := runCLIJSON(t, "--imported", "--state", statePath)
+	if result.Summary.Count != 2 {
+		t.Errorf("expected 2 subscriptions from imported transactions, got %d", result.Summary.Count)
+	}
+}
+
+func TestCLI_Events(t *testing.T) {
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json", "--events")
+	if len(result.Events) != 3 {
+		t.Fatalf("expected 3 events in JSON output, got %d: %+v", len(result.Events), result.Events)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+
+	output := runSubcommand(t, "events", "--state", statePath, "--output", "json", "--kind", "price_changed")
+	var events internal.JSONEvents
+	if err := json.Unmarshal(output, &events); err != nil {
+		t.Fatalf("failed to parse events JSON: %v\nOutput: %s", err, output)
+	}
+	if len(events.Events) != 1 {
+		t.Fatalf("expected 1 recorded price change (no duplicates), got %d", len(events.Events))
+	}
+	if e := events.Events[0]; e.Subscription != "Spotify" || e.Date != "2025-07-01" || e.OldAmount != 119 || e.NewAmount != 129 {
+		t.Errorf("unexpected price change event: %+v", e)
+	}
+}
+
+func TestCLI_WatchOnce(t *testing.T) {
+	dir := t.TempDir()
+	emptyConfigPath := filepath.Join(t.TempDir(), "empty-config.yaml")
+	os.WriteFile(emptyConfigPath, []byte(""), 0644)
+	data, _ := os.ReadFile("testdata/sample.json")
+	os.WriteFile(filepath.Join(dir, "export.json"), data, 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an export"), 0644)
+	statePath := filepath.Join(dir, "state.json") // state file inside the watched directory is ignored
+
+	output := string(runSubcommand(t, "watch", dir, "--once", "--state", statePath, "--config", emptyConfigPath))
+	if !strings.Contains(output, "export.json: imported 27 new transactions") {
+		t.Errorf("expected export.json to be imported, got: %s", output)
+	}
+	if !strings.Contains(output, "Baseline snapshot saved: 2 subscriptions") {
+		t.Errorf("expected a baseline snapshot, got: %s", output)
+	}
+
+	// A newer export with a price increase
+	newer := strings.Replace(string(data), `"transactions": [`, `"transactions": [
+    {"date": "2026-01-01", "text": "Spotify", "amount": -139.00},
+    {"date": "2026-01-15", "text": "Netflix", "amount": -99.00},`, 1)
+	os.WriteFile(filepath.Join(dir, "export-2.json"), []byte(newer), 0644)
+
+	output = string(runSubcommand(t, "watch", dir, "--once", "--state", statePath, "--config", emptyConfigPath))
+	if strings.Contains(output, "state.json") {
+		t.Errorf("expected the state file not to be imported, got: %s", output)
+	}
+	if !strings.Contains(output, "export-2.json: imported 2 new transactions") {
+		t.Errorf("expected only the 2 new transactions to be imported, got: %s", output)
+	}
+	if !strings.Contains(output, "PRICE UP") || !strings.Contains(output, "Spotify") {
+		t.Errorf("expected a Spotify price increase, got: %s", output)
+	}
+}
+
+func TestCLI_FailThresholds(t *testing.T) {
+	emptyConfigPath := filepath.Join(t.TempDir(), "empty-config.yaml")
+	os.WriteFile(emptyConfigPath, []byte(""), 0644)
+	binary := buildCLI(t)
+	exitCode := func(args ...string) (int, string) {
+		cmd := exec.Command(binary, append([]string{"--config", emptyConfigPath, "--source", "simple-json", "testdata/sample.json"}, args...)...)
+		output, err := cmd.Output()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), string(output)
+		} else if err != nil {
+			t.Fatalf("CLI failed: %v", err)
+		}
+		return 0, string(output)
+	}
+
+	// Monthly total is 228
+	if code, _ := exitCode("--fail-if-monthly-over", "300"); code != 0 {
+		t.Errorf("expected exit code 0 below threshold, got %d", code)
+	}
+	code, output := exitCode("--fail-if-monthly-over", "200")
+	if code != 2 {
+		t.Errorf("expected exit code 2 above threshold, got %d", code)
+	}
+	if !strings.Contains(output, "Netflix") {
+		t.Errorf("expected normal output despite threshold failure, got: %s", output)
+	}
+
+	// New subscription since the last snapshot
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	runCLI(t, "--source", "simple-json", "testdata/sample.json", "--state", statePath, "--save-snapshot")
+	if code, _ := exitCode("--state", statePath, "--fail-on-new", "--fail-on-price-increase"); code != 0 {
+		t.Errorf("expected exit code 0 without changes, got %d", code)
+	}
+
+	data, _ := os.ReadFile("testdata/sample.json")
+	extended := strings.Replace(string(data), `"transactions": [`, `"transactions": [
+    {"date": "2025-10-05", "text": "NewService", "amount": -49.00},
+    {"date": "2025-11-05", "text": "NewService", "amount": -49.00},
+    {"date": "2025-12-05", "text": "NewService", "amount": -49.00},`, 1)
+	dataPath := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(dataPath, []byte(extended), 0644)
+
+	cmd := exec.Command(binary, "--config", emptyConfigPath, "--source", "simple-json", dataPath, "--state", statePath, "--fail-on-new")
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Errorf("expected exit code 2 for a new subscription, got %v", err)
+	}
+}
+
+func TestCLI_Errors(t *testing.T) {
+	binary := buildCLI(t)
+	run := func(args ...string) (int, string) {
+		output, err := exec.Command(binary, args...).CombinedOutput()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), string(output)
+		} else if err != nil {
+			t.Fatalf("CLI failed: %v", err)
+		}
+		return 0, string(output)
+	}
+
+	notesPath := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notesPath, []byte("not transactions\n"), 0644)
+	code, output := run(notesPath)
+	if code != 1 || !strings.Contains(output, "Error: unknown source type: could not detect the format of "+notesPath) {
+		t.Errorf("expected exit code 1 with an unknown source error, got %d: %s", code, output)
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.json")
+	os.WriteFile(badPath, []byte("{\n  \"transactions\": [\n    {\"date\": \"2025-13-01\", \"text\": \"A\", \"amount\": -1}\n  ]\n}\n"), 0644)
+	code, output = run("stats", "--source", "simple-json", badPath)
+	if code != 1 || !strings.Contains(output, "bad.json line 3") {
+		t.Errorf("expected exit code 1 with the failing line, got %d: %s", code, output)
+	}
+}
+
+func TestCLI_Report(t *testing.T) {
+	emptyConfigPath := filepath.Join(t.TempDir(), "empty-config.yaml")
+	os.WriteFile(emptyConfigPath, []byte(""), 0644)
+
+	output := runSubcommand(t, "report", "--config", emptyConfigPath, "--source", "simple-json", "testdata/sample.json", "--output", "json")
+	var report internal.JSONReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		t.Fatalf("failed to parse report JSON: %v\nOutput: %s", err, output)
+	}
+	if len(report.Periods) != 12 || report.Periods[0].Period != "2025-01" {
+		t.Fatalf("expected 12 months starting 2025-01, got %+v", report.Periods)
+	}
+	// Actual spend: 99 + 119 before the Spotify price change, 99 + 129 after
+	if report.Periods[0].Total != 218 || report.Periods[11].Total != 228 || report.Total != 2676 {
+		t.Errorf("unexpected spend: first %.2f, last %.2f, total %.2f", report.Periods[0].Total, report.Periods[11].Total, report.Total)
+	}
+
+	output = runSubcommand(t, "report", "--config", emptyConfigPath, "--source", "simple-json", "testdata/sample.json", "--by", "year", "--output", "json")
+	report = internal.JSONReport{}
+	json.Unmarshal(output, &report)
+	if len(report.Periods) != 1 || report.Periods[0].BySubscription["Spotify"] != 1488 {
+		t.Errorf("unexpected yearly report: %+v", report.Periods)
+	}
+}
+
+func TestCLI_ListSources(t *testing.T) {
+	output := runSubcommand(t, "list-sources", "--output", "json")
+	var sources []internal.JSONSource
+	if err := json.Unmarshal(output, &sources); err != nil {
+		t.Fatalf("failed to parse list-sources JSON: %v\nOutput: %s", err, output)
+	}
+	if len(sources) != 2 || sources[0].Name != "handelsbanken-xlsx" || !slices.Equal(sources[1].Extensions, []string{".json"}) || !sources[1].AutoDetect {
+		t.Errorf("unexpected sources: %+v", sources)
+	}
+}
+
+func TestCLI_Budget(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("tags:\n  Spotify: [music]\nbudgets:\n  total: 300\n  tags:\n    music: 100\n"), 0644)
+
+	output := runSubcommand(t, "budget", "--config", configPath, "--source", "simple-json", "testdata/sample.json", "--output", "json")
+	var result internal.JSONBudget
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("failed to parse budget JSON: %v\nOutput: %s", err, output)
+	}
+	if len(result.Budgets) != 2 {
+		t.Fatalf("expected 2 budgets, got %+v", result.Budgets)
+	}
+	if total := result.Budgets[0]; total.Spend != 228 || total.Headroom != 72 || total.OverBudget {
+		t.Errorf("unexpected total budget: %+v", total)
+	}
+	if music := result.Budgets[1]; music.Tag != "music" || !music.OverBudget || len(music.Cuts) != 1 || music.Cuts[0] != "Spotify" {
+		t.Errorf("unexpected music budget: %+v", music)
+	}
+}
+
+func TestCLI_Simulate(t *testing.T) {
+	output := runSubcommand(t, "simulate", "--source", "simple-json", "testdata/sample.json", "--cancel", "netflix,HBO", "--output", "json")
+	var result internal.JSONSimulation
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("failed to parse simulate JSON: %v\nOutput: %s", err, output)
+	}
+	if len(result.Cancelled) != 1 || result.Cancelled[0].Name != "Netflix" {
+		t.Errorf("expected Netflix cancelled, got %+v", result.Cancelled)
+	}
+	if len(result.NotFound) != 1 || result.NotFound[0] != "HBO" {
+		t.Errorf("expected HBO not found, got %+v", result.NotFound)
+	}
+	if result.MonthlyBefore != 228 || result.MonthlyAfter != 129 || result.YearlySavings != 1188 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+}
+
+func TestCLI_Stats(t *testing.T) {
+	output := runSubcommand(t, "stats", "--source", "simple-json", "testdata/sample.json", "--top", "2", "--output", "json")
+	var stats internal.JSONStats
+	if err := json.Unmarshal(output, &stats); err != nil {
+		t.Fatalf("failed to parse stats JSON: %v\nOutput: %s", err, output)
+	}
+	if stats.Transactions != 27 || len(stats.Months) != 12 || stats.Start != "2025-01-01" || stats.End != "2025-12-15" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(stats.TopPayees) != 2 || stats.TopPayees[0].Text != "Spotify" {
+		t.Errorf("unexpected top payees: %+v", stats.TopPayees)
+	}
+	if len(stats.Gaps) != 0 {
+		t.Errorf("expected no gaps of 21+ days, got %+v", stats.Gaps)
+	}
+}
+
+func TestCLI_Convert(t *testing.T) {
+	output := runSubcommand(t, "convert", "--source", "simple-json", "testdata/sample.json", "--to", "csv")
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 28 || lines[0] != "date,text,amount" || lines[1] != "2025-01-01,Spotify,-119" {
+		t.Errorf("unexpected CSV output (%d lines): %s", len(lines), output)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "converted.json")
+	runSubcommand(t, "convert", "--source", "simple-json", "testdata/sample.json", "-o", outPath)
+	result := runCLIJSON(t, "--source", "simple-json", outPath)
+	if result.Summary.Count != 2 || result.Summary.MonthlyTotal != 228 {
+		t.Errorf("expected converted file to detect the same subscriptions, got %+v", result.Summary)
+	}
+}
+
+func TestCLI_ExportPayments(t *testing.T) {
+	emptyConfigPath := filepath.Join(t.TempDir(), "empty-config.yaml")
+	os.WriteFile(emptyConfigPath, []byte(""), 0644)
+
+	output := runSubcommand(t, "export-payments", "--config", emptyConfigPath, "--source", "simple-json", "testdata/sample.json", "--name", "netflix")
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 13 || lines[0] != "subscription,date,text,amount" || lines[1] != "Netflix,2025-01-15,Netflix,-99" {
+		t.Errorf("unexpected CSV output (%d lines): %s", len(lines), output)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "payments.csv")
+	runSubcommand(t, "export-payments", "--config", emptyConfigPath, "--source", "simple-json", "testdata/sample.json", "--all", "-o", outPath)
+	data, _ := os.ReadFile(outPath)
+	lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 25 || !strings.HasPrefix(lines[13], "Spotify,") {
+		t.Errorf("expected the payments of Netflix and Spotify, got (%d lines): %s", len(lines), data)
+	}
+}
+
+func TestCLI_Anonymize(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "anonymized.json")
+	runSubcommand(t, "anonymize", "--source", "simple-json", "testdata/sample.json", "-o", outPath)
+
+	data, _ := os.ReadFile(outPath)
+	for _, name := range []string{"Netflix", "Spotify", "Grocery"} {
+		if strings.Contains(string(data), name) {
+			t.Errorf("expected %s to be anonymized", name)
+		}
+	}
+
+	result := runCLIJSON(t, "--source", "simple-json", outPath)
+	if result.Summary.Count != 2 {
+		t.Errorf("expected the same 2 subscriptions from anonymized data, got %d", result.Summary.Count)
+	}
+}
+
+func TestCLI_TagDescribe(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	runSubcommand(t, "tag", "Netflix", "entertainment", "streaming", "--config", configPath)
+	runSubcommand(t, "tag", "Netflix", "streaming", "--remove", "--config", configPath)
+	runSubcommand(t, "describe", "Netflix", "Video streaming", "--config", configPath)
+
+	if output := string(runSubcommand(t, "describe", "Netflix", "--config", configPath)); strings.TrimSpace(output) != "Video streaming" {
+		t.Errorf("expected the description to be printed, got %q", output)
+	}
+
+	cfg, err := internal.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load edited config: %v", err)
+	}
+	if tags := cfg.GetTags("Netflix"); len(tags) != 1 || tags[0] != "entertainment" {
+		t.Errorf("expected tags [entertainment], got %v", tags)
+	}
+	if desc := cfg.GetDescription("Netflix"); desc != "Video streaming" {
+		t.Errorf("expected description 'Video streaming', got %q", desc)
+	}
+}
+
+func TestCLI_Exclude(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	output := string(runSubcommand(t, "exclude", "^Netflix$", "--config", configPath, "--source", "simple-json", "testdata/sample.json"))
+	if !strings.Contains(output, "removes 1 detected subscription") || !strings.Contains(output, "Netflix") {
+		t.Errorf("expected a preview of the removed subscription, got:\n%s", output)
+	}
+
+	result := runCLIJSON(t, "--config", configPath, "--source", "simple-json", "testdata/sample.json")
+	if result.Summary.Count != 1 || result.Subscriptions[0].Name != "Spotify" {
+		t.Errorf("expected only Spotify after excluding Netflix, got %+v", result.Subscriptions)
+	}
+
+	if _, err := cliCommand("exclude", "(", "--config", configPath).Output(); err == nil {
+		t.Error("expected an invalid pattern to fail")
+	}
+}
+
+func TestCLI_Strategies(t *testing.T) {
+	result := runCLIWithConfigJSON(t, "strategies: [annual]\n", "--source", "simple-json", "testdata/sample.json")
+	if len(result.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions with only the annual strategy, got %+v", result.Subscriptions)
+	}
+
+	result = runCLIWithConfigJSON(t, "strategies: [known-patterns, annual, monthly, variable]\n", "--source", "simple-json", "testdata/sample.json")
+	if len(result.Subscriptions) != 2 || result.Summary.MonthlyTotal != 228 {
+		t.Errorf("expected the 2 monthly subscriptions, got %+v", result.Subscriptions)
+	}
+}
+
+func TestCLI_Cache(t *testing.T) {
+	cacheDir := t.TempDir()
+	args := []string{"--cache", "--cache-dir", cacheDir, "--source", "simple-json", "testdata/sample.json"}
+	first := runCLIJSON(t, args...)
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %v", entries)
+	}
+
+	second := runCLIJSON(t, args...)
+	if len(second.Subscriptions) != len(first.Subscriptions) || second.Summary.MonthlyTotal != first.Summary.MonthlyTotal {
+		t.Errorf("expected the same result from the cache, got %+v and %+v", first.Summary, second.Summary)
+	}
+}
+
+func TestCLI_Timezone(t *testing.T) {
+	// Payments made late in the evening in New York, exported as UTC timestamps
+	var txs []string
+	for month := 1; month <= 6; month++ {
+		txs = append(txs, fmt.Sprintf(`{"date": "2025-%02d-01T02:30:00Z", "text": "Netflix", "amount": -99}`, month))
+	}
+	path := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	tests := []struct {
+		timezone  string
+		startDate string
+	}{
+		{"UTC", "2025-01-01"},
+		{"America/New_York", "2024-12-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			result := runCLIJSON(t, "--timezone", tt.timezone, "--show", "all", "--source", "simple-json", path)
+			if len(result.Subscriptions) != 1 || result.Subscriptions[0].StartDate != tt.startDate {
+				t.Errorf("expected Netflix starting %s, got %+v", tt.startDate, result.Subscriptions)
+			}
+		})
+	}
+
+	if _, err := cliCommand("--timezone", "Mars/Olympus", "--source", "simple-json", path).Output(); err == nil {
+		t.Error("expected an invalid timezone to fail")
+	}
+}
+
+func TestCLI_InvertAmounts(t *testing.T) {
+	// The sample data with charges as positive amounts, like some card exports
+	data, _ := os.ReadFile("testdata/sample.json")
+	positive := strings.ReplaceAll(string(data), `"amount": -`, `"amount": `)
+	path := filepath.Join(t.TempDir(), "card-2025.json")
+	os.WriteFile(path, []byte(positive), 0644)
+
+	result := runCLIJSON(t, "--source", "simple-json", path)
+	if len(result.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions in positive charges, got %+v", result.Subscriptions)
+	}
+	if output := runCLI(t, "--source", "simple-json", path); !strings.Contains(output, "use --invert-amounts") {
+		t.Errorf("expected a hint about --invert-amounts, got: %s", output)
+	}
+
+	for _, args := range [][]string{
+		{"--invert-amounts", "card-*.json"},
+		{"--invert-amounts", "simple-json"},
+		{"--invert-amounts", path, "--stream"},
+	} {
+		result := runCLIJSON(t, append(args, "--source", "simple-json", path)...)
+		if len(result.Subscriptions) != 2 || result.Summary.MonthlyTotal != 228 {
+			t.Errorf("%v: expected the sample's 2 subscriptions, got %+v", args, result.Subscriptions)
+		}
+	}
+}
+
+func TestCLI_XlsxSheets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), "2024")
+	f.NewSheet("2025")
+	for sheet, name := range map[string]string{"2024": "Netflix", "2025": "Spotify"} {
+		f.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+		for i, month := range []string{"01", "02", "03"} {
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &[]string{sheet + "-" + month + "-15", "", name, "-99,00"})
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	result := runCLIJSON(t, "--show", "all", "--source", "handelsbanken-xlsx", path)
+	if len(result.Subscriptions) != 2 {
+		t.Errorf("expected a subscription from each sheet, got %+v", result.Subscriptions)
+	}
+	result = runCLIJSON(t, "--show", "all", "--xlsx-sheets", "2025", "--source", "handelsbanken-xlsx", path)
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Name != "Spotify" {
+		t.Errorf("expected only the 2025 sheet's subscription, got %+v", result.Subscriptions)
+	}
+}
+
+func TestCLI_StrictParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+	for i, month := range []string{"01", "02", "03"} {
+		f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &[]string{"2025-" + month + "-15", "", "Netflix", "-99,00"})
+	}
+	f.SetSheetRow(sheet, "A5", &[]string{"2025-04-15", "", "Netflix", "n/a"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := cliCommand("--show", "all", "--output", "json", "--source", "handelsbanken-xlsx", path)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if _, err := cmd.Output(); err != nil {
+		t.Fatalf("CLI failed: %v\nStderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Skipped 1 row(s) of "+path) || !strings.Contains(stderr.String(), `row 5: sheet "Sheet1": invalid amount "n/a"`) {
+		t.Errorf("expected a warning about the skipped row, got %q", stderr.String())
+	}
+
+	output, err := cliCommand("--strict-parse", "--source", "handelsbanken-xlsx", path).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "line 5") {
+		t.Errorf("expected --strict-parse to fail on the skipped row, got %v: %s", err, output)
+	}
+}
+
+func TestCLI_Precision(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-15", "text": "Netflix", "amount": -9.99},
+		{"date": "2025-02-15", "text": "Netflix", "amount": -9.99},
+		{"date": "2025-03-15", "text": "Netflix", "amount": -9.99}
+	]}`), 0644)
+
+	output := runCLI(t, "--currency", "USD", "--locale", "en-US", "--source", "simple-json", path)
+	if !strings.Contains(output, "$10") || strings.Contains(output, "$9.99") {
+		t.Errorf("expected whole units by default, got: %s", output)
+	}
+	output = runCLI(t, "--currency", "USD", "--locale", "en-US", "--precision", "2", "--source", "simple-json", path)
+	if !strings.Contains(output, "$9.99") || !strings.Contains(output, "$119.88") {
+		t.Errorf("expected amounts with cents, got: %s", output)
+	}
+
+	// JSON totals match the displayed ones
+	result := runCLIJSON(t, "--precision", "2", "--source", "simple-json", path)
+	if result.Summary.MonthlyTotal != 9.99 || result.Summary.YearlyTotal != 119.88 || result.Subscriptions[0].TotalPaid != 29.97 {
+		t.Errorf("expected JSON amounts rounded to cents, got %+v", result.Summary)
+	}
+
+	// The config sets the default, the flag overrides it
+	configPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(configPath, []byte("precision: 2\n"), 0644)
+	output = runCLI(t, "--currency", "USD", "--locale", "en-US", "--config", configPath, "--source", "simple-json", path)
+	if !strings.Contains(output, "$9.99") {
+		t.Errorf("expected the config's precision, got: %s", output)
+	}
+	output = runCLI(t, "--currency", "USD", "--locale", "en-US", "--config", configPath, "--precision", "0", "--source", "simple-json", path)
+	if !strings.Contains(output, "$10") || strings.Contains(output, "$9.99") {
+		t.Errorf("expected --precision to override the config, got: %s", output)
+	}
+}
+
+func TestCLI_StatementDay(t *testing.T) {
+	// Card charges posted around the turn of the month, twice in March by calendar
+	dir := t.TempDir()
+	path := filepath.Join(dir, "card-2025.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-01", "text": "Salary", "amount": 30000},
+		{"date": "2025-01-30", "text": "Fitness Club", "amount": -119},
+		{"date": "2025-03-01", "text": "Fitness Club", "amount": -119},
+		{"date": "2025-03-30", "text": "Fitness Club", "amount": -119},
+		{"date": "2025-05-02", "text": "Fitness Club", "amount": -119},
+		{"date": "2025-05-30", "text": "Fitness Club", "amount": -119},
+		{"date": "2025-06-30", "text": "Salary", "amount": 30000}
+	]}`), 0644)
+
+	result := runCLIJSON(t, "--show", "all", "--source", "simple-json", path)
+	if len(result.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions by calendar month, got %+v", result.Subscriptions)
+	}
+	for _, args := range [][]string{
+		{"--statement-day", "15"},
+		{"--statement-day", "card-*.json=15"},
+		{"--statement-day", "simple-json=15", "--stream"},
+	} {
+		result := runCLIJSON(t, append(args, "--show", "all", "--source", "simple-json", path)...)
+		if len(result.Subscriptions) != 1 || result.Subscriptions[0].Name != "Fitness Club" {
+			t.Errorf("%v: expected Fitness Club detected by billing cycle, got %+v", args, result.Subscriptions)
+		}
+	}
+
+	// Converted files keep the cut-off day
+	output, err := cliCommand("convert", "--statement-day", "15", "--source", "simple-json", path).Output()
+	if err != nil || !strings.Contains(string(output), `"statement_day": 15`) {
+		t.Errorf("expected statement_day in converted transactions, got %v: %s", err, output)
+	}
+
+	if _, err := cliCommand("--statement-day", "card-*.json=32", "--source", "simple-json", path).Output(); err == nil {
+		t.Error("expected an error for an invalid statement day")
+	}
+}
+
+func TestCLI_IncludePartialMonths(t *testing.T) {
+	// Six weeks of data: only January is complete
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-01", "text": "Salary", "amount": 30000},
+		{"date": "2025-01-05", "text": "Streamio", "amount": -99},
+		{"date": "2025-02-05", "text": "Streamio", "amount": -99},
+		{"date": "2025-02-12", "text": "Groceries", "amount": -450}
+	]}`), 0644)
+
+	result := runCLIJSON(t, "--source", "simple-json", path)
+	if len(result.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions from complete months only, got %+v", result.Subscriptions)
+	}
+	result = runCLIJSON(t, "--include-partial-months", "--source", "simple-json", path)
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Name != "Streamio" {
+		t.Errorf("expected Streamio with --include-partial-months, got %+v", result.Subscriptions)
+	}
+	if output := runCLI(t, "--include-partial-months", "--source", "simple-json", path); !strings.Contains(output, "partial months are used for detection too") {
+		t.Errorf("expected a note about partial months, got: %s", output)
+	}
+}
+
+func TestCLI_DataGaps(t *testing.T) {
+	// The March and April exports are missing
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-01", "text": "Salary", "amount": 30000},
+		{"date": "2025-01-15", "text": "Streamio", "amount": -99},
+		{"date": "2025-02-01", "text": "Salary", "amount": 30000},
+		{"date": "2025-02-15", "text": "Streamio", "amount": -99},
+		{"date": "2025-05-01", "text": "Salary", "amount": 30000},
+		{"date": "2025-05-10", "text": "Groceries", "amount": -450}
+	]}`), 0644)
+
+	cmd := cliCommand("--show", "all", "--output", "json", "--source", "simple-json", path)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("CLI failed: %v\nStderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Warning: No transactions in 2025-03, 2025-04") {
+		t.Errorf("expected a warning about the missing months, got %q", stderr.String())
+	}
+	var result internal.JSONOutput
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Status != "stopped" {
+		t.Errorf("expected Streamio stopped across the gap, got %+v", result.Subscriptions)
+	}
+
+	for _, args := range [][]string{{"--ignore-data-gaps"}, {"--ignore-data-gaps", "--stream"}} {
+		result = runCLIJSON(t, append(args, "--show", "all", "--source", "simple-json", path)...)
+		if len(result.Subscriptions) != 1 || result.Subscriptions[0].Status != "active" {
+			t.Errorf("%v: expected Streamio active with the gap ignored, got %+v", args, result.Subscriptions)
+		}
+	}
+}
+
+func TestCLI_AsOf(t *testing.T) {
+	// Streamio is cancelled after June
+	var txs []string
+	for m := 1; m <= 12; m++ {
+		txs = append(txs, fmt.Sprintf(`{"date": "2025-%02d-01", "text": "Salary", "amount": 30000}`, m))
+		if m <= 6 {
+			txs = append(txs, fmt.Sprintf(`{"date": "2025-%02d-15", "text": "Streamio", "amount": -99}`, m))
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	result := runCLIJSON(t, "--show", "all", "--source", "simple-json", path)
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Status != "stopped" {
+		t.Errorf("expected Streamio stopped at the end of the data, got %+v", result.Subscriptions)
+	}
+	for _, args := range [][]string{{"--as-of", "2025-06-30"}, {"--as-of", "2025-06-30", "--stream"}} {
+		result = runCLIJSON(t, append(args, "--show", "all", "--source", "simple-json", path)...)
+		if len(result.Subscriptions) != 1 || result.Subscriptions[0].Status != "active" {
+			t.Errorf("%v: expected Streamio active as of June, got %+v", args, result.Subscriptions)
+		}
+	}
+
+	if _, err := cliCommand("--as-of", "30/06/2025", "--source", "simple-json", path).Output(); err == nil {
+		t.Error("expected an error for an invalid --as-of date")
+	}
+}
+
+func TestCLI_SuggestGroupsApply(t *testing.T) {
+	var txs []string
+	for m := 1; m <= 6; m++ {
+		txs = append(txs, fmt.Sprintf(`{"date": "2025-%02d-05", "text": "GOOGLE*GSUITE %d", "amount": -29}`, m, 1000+m))
+	}
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	output := runCLI(t, "--suggest-groups", "--output", "json", "--source", "simple-json", path)
+	var result internal.JSONGroupSuggestions
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Months != 6 {
+		t.Fatalf("expected 1 suggestion covering 6 months, got %+v", result.Suggestions)
+	}
+
+	output = runCLI(t, "--suggest-groups", "--output", "yaml", "--source", "simple-json", path)
+	if !strings.HasPrefix(output, "suggestions:\n") {
+		t.Errorf("expected YAML output, got:\n%s", output)
+	}
+
+	// Without a terminal, all suggestions are added
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("currency: SEK\n"), 0644)
+	if output, err := cliCommand("--config", configPath, "--suggest-groups", "--apply-suggestions", "--source", "simple-json", path).CombinedOutput(); err != nil {
+		t.Fatalf("CLI failed: %v\n%s", err, output)
+	}
+	cfg, err := internal.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Groups) != 1 || cfg.Groups[0].Patterns[0] != result.Suggestions[0].Pattern {
+		t.Errorf("expected the suggested group in the config, got %+v", cfg.Groups)
+	}
+
+	if _, err := cliCommand("--output", "yaml", "--source", "simple-json", path).Output(); err == nil {
+		t.Error("expected --output yaml without --suggest-groups to fail")
+	}
+}
+
+func TestCLI_SuggestKnown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-03-02", "text": "PAYPAL *NEWSSITE", "amount": -79},
+		{"date": "2025-03-10", "text": "ICA Kvantum", "amount": -450}
+	]}`), 0644)
+
+	output := runCLI(t, "--suggest-known", "--source", "simple-json", path)
+	if !strings.Contains(output, `"PAYPAL *NEWSSITE" (2025-03-02, `) || !strings.Contains(output, `- pattern: "^PAYPAL \\*NEWSSITE"`) || strings.Contains(output, "ICA") {
+		t.Errorf("expected a known pattern for PAYPAL *NEWSSITE only, got:\n%s", output)
+	}
+}
+
+func TestCLI_KnownRequireRecurrence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-05", "text": "NETFLIX.COM", "amount": -99},
+		{"date": "2025-02-05", "text": "NETFLIX.COM", "amount": -99},
+		{"date": "2025-03-05", "text": "NETFLIX.COM", "amount": -99},
+		{"date": "2025-03-12", "text": "ADOBE *STOCK", "amount": -299},
+		{"date": "2025-03-31", "text": "ICA Kvantum", "amount": -450}
+	]}`), 0644)
+
+	output := runCLI(t, "--known-require-recurrence", "--currency", "SEK", "--source", "simple-json", path)
+	tableEnd := strings.Index(output, "Possible subscriptions (not in totals):")
+	if tableEnd < 0 || !strings.Contains(output[tableEnd:], "ADOBE *STOCK") || strings.Contains(output[:tableEnd], "ADOBE") {
+		t.Errorf("expected ADOBE *STOCK listed apart as a possible subscription, got:\n%s", output)
+	}
+
+	result := runCLIJSON(t, "--known-require-recurrence", "--currency", "SEK", "--source", "simple-json", path)
+	if result.Summary.MonthlyTotal != 99 {
+		t.Errorf("expected only NETFLIX.COM in the monthly total, got %v", result.Summary.MonthlyTotal)
+	}
+	var statuses []string
+	for _, sub := range result.Subscriptions {
+		statuses = append(statuses, sub.Name+"="+sub.Status)
+	}
+	if !slices.Contains(statuses, "ADOBE *STOCK=possible") {
+		t.Errorf("expected ADOBE *STOCK with status possible, got %v", statuses)
+	}
+
+	result = runCLIJSON(t, "--currency", "SEK", "--source", "simple-json", path)
+	if result.Summary.MonthlyTotal != 398 {
+		t.Errorf("expected ADOBE *STOCK in the monthly total without the flag, got %v", result.Summary.MonthlyTotal)
+	}
+}
+
+func TestCLI_ReportRejections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-05", "text": "Streamio", "amount": -99},
+		{"date": "2025-02-05", "text": "Streamio", "amount": -99},
+		{"date": "2025-03-05", "text": "Streamio", "amount": -99},
+		{"date": "2025-01-10", "text": "Power Co", "amount": -300},
+		{"date": "2025-02-10", "text": "Power Co", "amount": -900},
+		{"date": "2025-03-10", "text": "Power Co", "amount": -450},
+		{"date": "2025-04-01", "text": "Salary", "amount": 30000}
+	]}`), 0644)
+
+	result := runCLIJSON(t, "--source", "simple-json", path)
+	if result.Rejections != nil {
+		t.Errorf("expected no rejections without --report-rejections, got %+v", result.Rejections)
+	}
+
+	for _, args := range [][]string{{"--report-rejections"}, {"--report-rejections", "--stream"}} {
+		result = runCLIJSON(t, append(args, "--source", "simple-json", path)...)
+		if len(result.Rejections) != 1 || result.Rejections[0].Payee != "Power Co" || result.Rejections[0].Reasons[0].Code != internal.RejectionToleranceExceeded {
+			t.Errorf("%v: expected Power Co rejected for its tolerance, got %+v", args, result.Rejections)
+		}
+	}
+}
+
+func TestCLI_SuggestExclusions(t *testing.T) {
+	var txs []string
+	for m := 1; m <= 6; m++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-01", "text": "Salary", "amount": 30000}`, m),
+			fmt.Sprintf(`{"date": "2025-%02d-05", "text": "Streamio", "amount": -99}`, m),
+			fmt.Sprintf(`{"date": "2025-%02d-10", "text": "Musicly", "amount": -119}`, m),
+			fmt.Sprintf(`{"date": "2025-%02d-12", "text": "Fitness Club", "amount": -349}`, m),
+			fmt.Sprintf(`{"date": "2025-%02d-28", "text": "Bostads AB", "amount": -8500}`, m),
+		)
+	}
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	output := runCLI(t, "--suggest-exclusions", "--show", "all", "--source", "simple-json", path)
+	if !strings.Contains(output, "Found 1 likely false positive(s)") || !strings.Contains(output, `- pattern: "^Bostads AB$"`) {
+		t.Errorf("expected an exclude rule for Bostads AB only, got:\n%s", output)
+	}
+}
+
+func TestCLI_Progress(t *testing.T) {
+	cmd := cliCommand("--progress", "--output", "json", "--source", "simple-json", "testdata/sample.json")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("CLI failed: %v\nStderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Parsing: 100% (1/1 files, ") {
+		t.Errorf("expected a progress line on stderr, got %q", stderr.String())
+	}
+	var result internal.JSONOutput
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Errorf("expected JSON on stdout unaffected by progress, got %v: %s", err, output)
+	}
+}
+
+func TestCLI_Stream(t *testing.T) {
+	result := runCLIJSON(t, "--stream", "--source", "simple-json", "testdata/sample.json")
+	if len(result.Subscriptions) != 2 || result.Summary.MonthlyTotal != 228 {
+		t.Errorf("expected the same 2 subscriptions as without --stream, got %+v", result.Subscriptions)
+	}
+
+	if _, err := cliCommand("--stream", "--suggest-groups", "--source", "simple-json", "testdata/sample.json").Output(); err == nil {
+		t.Error("expected --stream with --suggest-groups to fail")
+	}
+}
+
+func TestCLI_Person(t *testing.T) {
+	// Alice pays Netflix and Spotify, Bob has a Netflix account of his own
+	dir := t.TempDir()
+	data, _ := os.ReadFile("testdata/sample.json")
+	alice := filepath.Join(dir, "alice.json")
+	os.WriteFile(alice, data, 0644)
+	var netflix []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, `"Netflix"`) {
+			netflix = append(netflix, strings.TrimSuffix(line, ","))
+		}
+	}
+	bob := filepath.Join(dir, "bob.json")
+	os.WriteFile(bob, []byte(`{"transactions": [`+strings.Join(netflix, ",\n")+`]}`), 0644)
+
+	for _, extra := range [][]string{nil, {"--stream"}} {
+		args := append([]string{"--person", "alice=alice.json", "--person", "bob=" + bob, "--source", "simple-json", alice, bob}, extra...)
+		result := runCLIJSON(t, args...)
+		if len(result.Subscriptions) != 3 || result.Summary.MonthlyTotal != 327 {
+			t.Fatalf("%v: expected Netflix per person and Spotify, got %+v", extra, result.Subscriptions)
+		}
+		household := result.Household
+		if household == nil || len(household.Persons) != 2 || household.Persons[0].MonthlyTotal != 228 || household.Persons[1].MonthlyTotal != 99 {
+			t.Errorf("%v: expected subtotals of 228 for alice and 99 for bob, got %+v", extra, household)
+		} else if len(household.Duplicates) != 1 || household.Duplicates[0].Name != "Netflix" || household.Duplicates[0].MonthlyTotal != 198 {
+			t.Errorf("%v: expected Netflix paid by both, got %+v", extra, household.Duplicates)
+		}
+	}
+
+	output := runCLI(t, "--person", "alice=alice.json", "--person", "bob=bob.json", "--source", "simple-json", alice, bob)
+	if !strings.Contains(output, "Per person (active):") || !strings.Contains(output, "Netflix (alice, bob)") {
+		t.Errorf("expected per-person subtotals and duplicates, got: %s", output)
+	}
+
+	if result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json"); result.Household != nil {
+		t.Errorf("expected no household section without --person, got %+v", result.Household)
+	}
+	if _, err := cliCommand("--person", "alice", "--source", "simple-json", alice).Output(); err == nil {
+		t.Error("expected --person without a pattern to fail")
+	}
+}
+
+func TestCLI_ReportBusiness(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("business:\n  Spotify: 0.25\n"), 0644)
+
+	output := runSubcommand(t, "report", "--business", "--by", "quarter", "--config", configPath, "--source", "simple-json", "testdata/sample.json", "--output", "json")
+	var report internal.JSONBusinessReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		t.Fatalf("failed to parse business report JSON: %v\nOutput: %s", err, output)
+	}
+	// Spotify only: 3 × 119 in each of the first two quarters, 3 × 129 in the last two
+	if len(report.Periods) != 4 || report.Periods[0].Period != "2025-Q1" || report.Periods[0].Total != 357 {
+		t.Fatalf("expected 4 quarters of Spotify payments, got %+v", report.Periods)
+	}
+	if report.Total != 1488 || report.VAT != 297.6 || report.Deductible != 1190.4 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+}
+
+func TestCLI_ReportReal(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("currency: SEK\n"), 0644)
+	if _, err := cliCommand("report", "--real", "--config", configPath, "--source", "simple-json", "testdata/sample.json").Output(); err == nil {
+		t.Error("expected --real to fail without a CPI table for SEK")
+	}
+
+	os.WriteFile(configPath, []byte("currency: SEK\ncpi:\n  2024: 100\n  2025: 102\n"), 0644)
+	output := runSubcommand(t, "report", "--real", "--by", "year", "--config", configPath, "--source", "simple-json", "testdata/sample.json", "--output", "json")
+	var report internal.JSONReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		t.Fatalf("failed to parse report JSON: %v\nOutput: %s", err, output)
+	}
+	// All payments are from 2025, the base year
+	if report.RealTotal != report.Total || report.Periods[0].RealTotal != 2676 {
+		t.Errorf("expected real spend equal to nominal spend within the base year, got %+v", report)
+	}
+}
+
+func TestCLI_GenerateTestdata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "generated.json")
+	runSubcommand(t, "generate-testdata", "--end", "2025-12-31", "--subscriptions", "5", "--stopped", "1", "-o", path)
+
+	// Generated amounts are on a krona scale (bounds of known patterns depend on the currency)
+	result := runCLIJSON(t, "--show", "all", "--currency", "SEK", "--source", "simple-json", path)
+	active := 0
+	for _, sub := range result.Subscriptions {
+		if sub.Status == "active" {
+			active++
+		}
+	}
+	if len(result.Subscriptions) != 5 || active != 4 {
+		t.Errorf("expected 4 active and 1 stopped subscription, got %+v", result.Subscriptions)
+	}
+}
+
+func TestCLI_KPIs(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--kpis")
+	for _, want := range []string{"Key figures (2025):", "Average active subscription:", "Most expensive:"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json", "--kpis")
+	if result.KPIs == nil || result.KPIs.MostExpensive == "" || result.KPIs.AverageMonthly <= 0 {
+		t.Fatalf("expected key figures in JSON output, got %+v", result.KPIs)
+	}
+	if result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json"); result.KPIs != nil {
+		t.Errorf("expected no key figures without --kpis, got %+v", result.KPIs)
+	}
+}
+
+func TestCLI_Top(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--top", "1")
+	if !strings.Contains(output, "Spotify") || strings.Contains(output, "Netflix") || !strings.Contains(output, "Others (1)") {
+		t.Errorf("expected only Spotify and an others row, got:\n%s", output)
+	}
+
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json", "--top", "1")
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Name != "Spotify" {
+		t.Errorf("expected only Spotify in JSON output, got %+v", result.Subscriptions)
+	}
+	if result.Others == nil || result.Others.Count != 1 || result.Others.MonthlyTotal != 99 {
+		t.Errorf("expected Netflix in others, got %+v", result.Others)
+	}
+	if result.Summary.MonthlyTotal != 228 {
+		t.Errorf("expected totals of all subscriptions, got %v", result.Summary.MonthlyTotal)
+	}
+}
+
+func TestCLI_GroupBy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("tags:\n  Spotify: [music]\n"), 0644)
+
+	output := runSubcommand(t, "--config", configPath, "--source", "simple-json", "testdata/sample.json", "--group-by", "tag")
+	music, untagged := strings.Index(string(output), "music"), strings.Index(string(output), "Untagged")
+	if music < 0 || untagged < music || strings.Count(string(output), "Subtotal") != 2 {
+		t.Errorf("expected a music and an untagged section with subtotals, got:\n%s", output)
+	}
+	if !strings.Contains(string(output), "Total (active)") {
+		t.Errorf("expected the grand total, got:\n%s", output)
+	}
+}
+
+func TestCLI_SuggestRenames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-05", "text": "HBO MAX 4411", "amount": -89},
+		{"date": "2025-02-05", "text": "hbo max  4412", "amount": -89},
+		{"date": "2025-03-05", "text": "Netflix", "amount": -99}
+	]}`), 0644)
+
+	output := runCLI(t, "--suggest-renames", "--source", "simple-json", path)
+	if !strings.Contains(output, `"HBO MAX": "HBO MAX"`) || strings.Contains(output, "Netflix") {
+		t.Errorf("expected a rename rule for HBO MAX only, got:\n%s", output)
+	}
+
+	// With the rule in the config, the names are one subscription, also when streaming
+	for _, extra := range [][]string{nil, {"--stream"}} {
+		result := runCLIWithConfigJSON(t, "rename:\n  HBO MAX: HBO Max\n", append([]string{"--source", "simple-json", path}, extra...)...)
+		var names []string
+		for _, sub := range result.Subscriptions {
+			names = append(names, sub.Name)
+		}
+		if !slices.Contains(names, "HBO Max") || slices.ContainsFunc(names, func(name string) bool { return strings.HasPrefix(name, "HBO MAX") }) {
+			t.Errorf("%v: expected HBO Max detected from the renamed payments, got %v", extra, names)
+		}
+	}
+}
+
+func TestCLI_SplitByAmount(t *testing.T) {
+	var txs []string
+	for month := 1; month <= 4; month++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-03", "text": "APPLE.COM/BILL", "amount": -39}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-12", "text": "APPLE.COM/BILL", "amount": -119}`, month))
+	}
+	txs = append(txs, `{"date": "2025-04-30", "text": "Grocery", "amount": -300}`)
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	for _, extra := range [][]string{nil, {"--stream"}} {
+		result := runCLIWithConfigJSON(t, `split_by_amount:
+  APPLE.COM/BILL:
+    - name: iCloud+
+      max_amount: 50
+    - name: Apple Music
+      min_amount: 100
+`, append([]string{"--source", "simple-json", path}, extra...)...)
+		amounts := make(map[string]float64)
+		for _, sub := range result.Subscriptions {
+			amounts[sub.Name] = sub.LatestAmount
+		}
+		if len(amounts) != 2 || amounts["iCloud+"] != 39 || amounts["Apple Music"] != 119 {
+			t.Errorf("%v: expected one subscription per amount band, got %v", extra, amounts)
+		}
+	}
+}
+
+func TestCLI_AppStoreCharges(t *testing.T) {
+	var txs []string
+	for month := 1; month <= 4; month++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-03", "text": "APPLE.COM/BILL", "amount": -39}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-12", "text": "APPLE.COM/BILL", "amount": -119}`, month))
+	}
+	txs = append(txs, `{"date": "2025-03-20", "text": "APPLE.COM/BILL", "amount": -15}`)
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	output := runCLI(t, "--app-store-charges", "--source", "simple-json", path)
+	for _, s := range []string{"$15 once", "Found 2 probable subscription(s)", `- name: "APPLE.COM/BILL 39"`, `- name: "APPLE.COM/BILL 119"`} {
+		if !strings.Contains(output, s) {
+			t.Errorf("expected %q in output:\n%s", s, output)
+		}
+	}
+
+	// The suggested bands split the payee into the two subscriptions
+	config := "split_by_amount:\n" + output[strings.Index(output, "  split_by_amount:\n")+len("  split_by_amount:\n"):]
+	result := runCLIWithConfigJSON(t, config, "--source", "simple-json", path)
+	var names []string
+	for _, sub := range result.Subscriptions {
+		names = append(names, sub.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"APPLE.COM/BILL 119", "APPLE.COM/BILL 39"}) {
+		t.Errorf("expected a subscription per suggested band, got %v", names)
+	}
+}
+
+func TestCLI_AnnualCharges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-02-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-03-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-03-06", "text": "Card Plus", "amount": -495},
+		{"date": "2025-04-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-05-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-06-05", "text": "Card Plus", "amount": -29},
+		{"date": "2025-06-20", "text": "Grocery", "amount": -300}
+	]}`), 0644)
+
+	for _, extra := range [][]string{nil, {"--stream"}} {
+		result := runCLIJSON(t, append([]string{"--source", "simple-json", path}, extra...)...)
+		if len(result.Subscriptions) != 1 {
+			t.Fatalf("%v: expected Card Plus detected, got %+v", extra, result.Subscriptions)
+		}
+		if sub := result.Subscriptions[0]; sub.LatestAmount != 29 || sub.AnnualCharge != 495 || sub.AnnualChargeDate != "2025-03-06" || sub.YearlyCost != 29*12+495 {
+			t.Errorf("%v: expected both components of Card Plus, got %+v", extra, sub)
+		}
+	}
+
+	output := runCLI(t, "--source", "simple-json", "--currency", "SEK", path)
+	if !strings.Contains(output, "Card Plus: 29 kr/month + 495 kr/year") {
+		t.Errorf("expected the yearly charge listed, got:\n%s", output)
+	}
+}
+
+func TestCLI_OlderThan(t *testing.T) {
+	var txs []string
+	for year := 2022; year <= 2025; year++ {
+		for month := 1; month <= 12; month++ {
+			txs = append(txs, fmt.Sprintf(`{"date": "%d-%02d-10", "text": "Newspaper", "amount": -249}`, year, month))
+			if year == 2025 {
+				txs = append(txs, fmt.Sprintf(`{"date": "%d-%02d-01", "text": "Spotify", "amount": -129}`, year, month))
+			}
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	result := runCLIJSON(t, "--older-than", "3", "--source", "simple-json", path)
+	if !slices.Equal(result.OldSubscriptions, []string{"Newspaper"}) {
+		t.Errorf("expected Newspaper as an old subscription, got %v", result.OldSubscriptions)
+	}
+	for _, sub := range result.Subscriptions {
+		if want := map[string]int{"Newspaper": 47, "Spotify": 11}[sub.Name]; sub.AgeMonths != want {
+			t.Errorf("expected %s to be %d months old, got %d", sub.Name, want, sub.AgeMonths)
+		}
+	}
+
+	output := runCLI(t, "--older-than", "3", "--source", "simple-json", path)
+	if !strings.Contains(output, "Subscriptions paid for 3+ years:") || !strings.Contains(output, "Newspaper: since 01/10/2022 (3y 11m)") {
+		t.Errorf("expected the old subscriptions listed, got:\n%s", output)
+	}
+}
+
+func TestCLI_ToleranceSweep(t *testing.T) {
+	output := runCLI(t, "--tolerance-sweep", "--tolerance", "0.12", "--source", "simple-json", "testdata/sample.json", "--output", "json")
+	var result internal.JSONSweep
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("failed to parse sweep JSON: %v\nOutput: %s", err, output)
+	}
+	if result.CurrentTolerance != 0.12 || len(result.Steps) != 13 || result.Steps[2].Tolerance != 0.12 {
+		t.Fatalf("expected 13 steps including the current tolerance, got %+v", result)
+	}
+	if step := result.Steps[6]; step.Active != 2 || step.MonthlyTotal != 228 || !slices.Equal(step.Subscriptions, []string{"Netflix", "Spotify"}) {
+		t.Errorf("unexpected step %+v", step)
+	}
+}
+
+func TestCLI_ShowRuleSource(t *testing.T) {
+	config := "tags:\n  Spotify: [music]\nexclude:\n  - Netflix\n"
+	output := runCLIWithConfig(t, config, "--source", "simple-json", "testdata/sample.json", "--show-rule-source")
+	if !strings.Contains(output, "known pattern SPOTIFY; tags") || !strings.Contains(output, `Netflix: exclude "Netflix"`) {
+		t.Errorf("expected rule sources and the exclusion, got:\n%s", output)
+	}
+
+	result := runCLIWithConfigJSON(t, config, "--source", "simple-json", "testdata/sample.json", "--show-rule-source")
+	if len(result.Subscriptions) != 1 || result.Subscriptions[0].Source == nil || result.Subscriptions[0].Source.Mechanism != "known pattern SPOTIFY" {
+		t.Fatalf("expected the source of Spotify in JSON output, got %+v", result.Subscriptions)
+	}
+	if len(result.Rejections) != 1 || result.Rejections[0].Reasons[0].Rule != "Netflix" {
+		t.Errorf("expected only the exclusion of Netflix in rejections, got %+v", result.Rejections)
+	}
+}
+
+func TestCLI_UnusedConfigWarning(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("descriptions:\n  Netflix: Movies\n  Old Gym: Training\nexclude:\n  - \"^Mobile\"\n"), 0644)
+
+	for _, extra := range [][]string{nil, {"--stream"}} {
+		output, err := cliCommand(append([]string{"--config", configPath, "--source", "simple-json", "testdata/sample.json"}, extra...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("CLI failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(string(output), "Warning: 2 config entries matched no transactions") ||
+			!strings.Contains(string(output), `descriptions: "Old Gym"`) || !strings.Contains(string(output), `exclude: "^Mobile"`) ||
+			strings.Contains(string(output), `descriptions: "Netflix"`) {
+			t.Errorf("%v: expected warnings for the unused entries only, got:\n%s", extra, output)
+		}
+	}
+}
+
+func TestCLI_Categories(t *testing.T) {
+	config := "categories:\n  Netflix: other\n"
+	output := runCLIWithConfig(t, config, "--source", "simple-json", "testdata/sample.json", "--group-by", "category")
+	music, other := strings.Index(output, "music"), strings.Index(output, "other")
+	if !strings.Contains(output, "Category") || music < 0 || other < music || strings.Count(output, "Subtotal") != 2 {
+		t.Errorf("expected a category column and sections per category, got:\n%s", output)
+	}
+
+	result := runCLIWithConfigJSON(t, config, "--source", "simple-json", "testdata/sample.json")
+	for _, sub := range result.Subscriptions {
+		want := map[string]string{"Netflix": "other", "Spotify": "music"}[sub.Name]
+		if sub.Category != want {
+			t.Errorf("expected category %q for %s, got %q", want, sub.Name, sub.Category)
+		}
+	}
+}
+
+func TestCLI_Commitments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	var txs []string
+	for month := 1; month <= 4; month++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-25", "text": "Överföring sparkonto", "amount": -2000}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-28", "text": "Bolån SBAB", "amount": -5400}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-05", "text": "Netflix", "amount": -99}`, month))
+	}
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	output := runCLI(t, "--source", "simple-json", path)
+	commitments := strings.Index(output, "Recurring commitments")
+	if commitments < 0 || strings.Index(output, "Bolån SBAB") < commitments || !strings.Contains(output, "Found 1 subscriptions") {
+		t.Errorf("expected the loan and transfer listed apart from Netflix, got:\n%s", output)
+	}
+
+	result := runCLIWithConfigJSON(t, "commitments:\n  - pattern: Bolån\n    kind: subscription\n", "--source", "simple-json", path)
+	if len(result.Subscriptions) != 2 || len(result.Commitments) != 1 || result.Commitments[0].Commitment != "transfer" {
+		t.Errorf("expected the loan kept a subscription and the transfer a commitment, got %+v and %+v", result.Subscriptions, result.Commitments)
+	}
+
+	// Subcommands leave the commitments out of their totals too
+	emptyConfigPath := filepath.Join(t.TempDir(), "empty-config.yaml")
+	os.WriteFile(emptyConfigPath, []byte(""), 0644)
+	var report internal.JSONReport
+	json.Unmarshal(runSubcommand(t, "report", "--config", emptyConfigPath, "--source", "simple-json", path, "--by", "year", "--output", "json"), &report)
+	if report.Total != 4*99 || len(report.Periods) != 1 || len(report.Periods[0].BySubscription) != 1 {
+		t.Errorf("expected only Netflix in the report, got %+v", report)
+	}
+
+	budgetConfigPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(budgetConfigPath, []byte("budgets:\n  total: 50\n"), 0644)
+	var budget internal.JSONBudget
+	json.Unmarshal(runSubcommand(t, "budget", "--config", budgetConfigPath, "--source", "simple-json", path, "--output", "json"), &budget)
+	if len(budget.Budgets) != 1 || budget.Budgets[0].Spend != 99 || !slices.Equal(budget.Budgets[0].Cuts, []string{"Netflix"}) {
+		t.Errorf("expected only Netflix in the budget, got %+v", budget.Budgets)
+	}
+}
+
+func TestCLI_DefaultCommitmentsInSubcommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	var txs []string
+	for month := 1; month <= 12; month++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-01", "text": "HYRA BRF EKEN", "amount": -8500}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-15", "text": "Netflix", "amount": -99}`, month))
+	}
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("budgets:\n  total: 50\n"), 0644)
+
+	var report internal.JSONReport
+	json.Unmarshal(runSubcommand(t, "report", "--config", configPath, "--source", "simple-json", path, "--by", "year", "--output", "json"), &report)
+	if report.Total != 12*99 || report.Periods[0].BySubscription["HYRA BRF EKEN"] != 0 {
+		t.Errorf("expected the rent left out of the report, got %+v", report)
+	}
+
+	output := string(runSubcommand(t, "budget", "--config", configPath, "--source", "simple-json", path, "--currency", "SEK"))
+	if strings.Contains(output, "HYRA") || !strings.Contains(output, "Netflix") {
+		t.Errorf("expected only Netflix in the budget, got:\n%s", output)
+	}
+}
+
+func TestCLI_ComparePrices(t *testing.T) {
+	output := runCLI(t, "--source", "simple-json", "testdata/sample.json", "--currency", "SEK", "--compare-prices")
+	if !strings.Contains(output, "Paying more than the standard plan:\n  None") {
+		t.Errorf("expected no subscriptions above the SEK list prices, got:\n%s", output)
+	}
+
+	// In USD, the sample amounts are far above the list prices
+	result := runCLIJSON(t, "--source", "simple-json", "testdata/sample.json", "--compare-prices", "--country", "US")
+	if len(result.PriceComparisons) != 2 || result.PriceComparisons[0].Name != "Spotify" {
+		t.Errorf("expected Spotify and Netflix above the US list prices, got %+v", result.PriceComparisons)
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]bool{"127.0.0.1:8080": true, "localhost:8080": true, "[::1]:8080": true, ":8080": false, "0.0.0.0:8080": false, "192.168.1.10:8080": false} {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
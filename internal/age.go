@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// FormatAge formats an age in months as years and months, e.g. "2y 3m"
+func FormatAge(months int, loc Locale) string {
+	years, months := months/12, months%12
+	switch {
+	case years == 0:
+		return loc.Sprintf("%dm", months)
+	case months == 0:
+		return loc.Sprintf("%dy", years)
+	default:
+		return loc.Sprintf("%dy %dm", years, months)
+	}
+}
+
+// OlderThan returns the active subscriptions paid for at least the given number of years, the
+// oldest first
+func OlderThan(subs []Subscription, years int) []Subscription {
+	var old []Subscription
+	for _, sub := range subs {
+		if sub.Status == StatusActive && sub.AgeMonths() >= years*12 {
+			old = append(old, sub)
+		}
+	}
+	sort.SliceStable(old, func(i, j int) bool { return old[i].StartDate.Before(old[j].StartDate) })
+	return old
+}
+
+// PrintOldSubscriptions lists long-running subscriptions (see OlderThan) with their start date
+// and age, as they may be forgotten recurring charges worth a review
+func PrintOldSubscriptions(w io.Writer, old []Subscription, opts OutputOptions) {
+	loc := opts.Locale
+	fmt.Fprint(w, loc.Sprintf("Subscriptions paid for %d+ years:\n", opts.OlderThan))
+	if len(old) == 0 {
+		fmt.Fprint(w, loc.T("  None\n"))
+		return
+	}
+	for _, sub := range old {
+		fmt.Fprint(w, loc.Sprintf("  %s: since %s (%s), %s/month\n", sub.Name, loc.FormatDate(sub.StartDate),
+			FormatAge(sub.AgeMonths(), loc), opts.Currency.Format(sub.MonthlyCost())))
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestAgeMonths(t *testing.T) {
+	tests := []struct {
+		start, last string
+		want        int
+	}{
+		{"2025-01-15", "2025-12-15", 11},
+		{"2025-01-31", "2025-02-01", 1},
+		{"2019-03-15", "2025-05-14", 74},
+		{"2025-06-01", "2025-06-01", 0},
+	}
+	for _, tt := range tests {
+		sub := Subscription{StartDate: date(tt.start), LastDate: date(tt.last)}
+		if got := sub.AgeMonths(); got != tt.want {
+			t.Errorf("AgeMonths(%s to %s) = %d, want %d", tt.start, tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	en := GetLocale(language.AmericanEnglish)
+	for months, want := range map[int]string{0: "0m", 11: "11m", 12: "1y", 74: "6y 2m"} {
+		if got := FormatAge(months, en); got != want {
+			t.Errorf("FormatAge(%d) = %q, want %q", months, got, want)
+		}
+	}
+	if got := FormatAge(74, GetLocale(language.Swedish)); got != "6 år 2 mån" {
+		t.Errorf("expected a Swedish age, got %q", got)
+	}
+}
+
+func TestOlderThan(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Newspaper", Status: StatusActive, StartDate: date("2019-03-15"), LastDate: date("2025-05-15"), LatestAmount: -249},
+		{Name: "Spotify", Status: StatusActive, StartDate: date("2024-01-01"), LastDate: date("2025-05-01"), LatestAmount: -129},
+		{Name: "Gym", Status: StatusActive, StartDate: date("2018-01-10"), LastDate: date("2025-05-10"), LatestAmount: -399},
+		{Name: "Old phone", Status: StatusStopped, StartDate: date("2015-01-10"), LastDate: date("2024-01-10"), LatestAmount: -199},
+	}
+	old := OlderThan(subs, 5)
+	if len(old) != 2 || old[0].Name != "Gym" || old[1].Name != "Newspaper" {
+		t.Fatalf("expected the active subscriptions of 5+ years, oldest first, got %+v", old)
+	}
+
+	var buf bytes.Buffer
+	PrintOldSubscriptions(&buf, old, OutputOptions{OlderThan: 5, Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "paid for 5+ years") || !strings.Contains(buf.String(), "Gym: since 01/10/2018 (7y 4m)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseAmount parses an amount as formatted in bank exports and spreadsheets: "-99,00",
+// "1 234,56", "1.234,56", "1,234.56", "(99.00)" or "99.00-" (negative), with spaces, non-breaking
+// spaces or apostrophes between thousands. Which of ',' and '.' is the decimal separator is
+// decided by the amount itself where possible; decimal (',' or '.') is the separator the
+// source normally uses, for amounts that could be either, such as "1,234".
+func ParseAmount(s string, decimal rune) (float64, error) {
+	original := s
+	s = strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.ReplaceAll(s, "\u2212", "-") // minus sign
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = !negative
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasSuffix(s, "-"):
+		negative = !negative
+		s = s[:len(s)-1]
+	}
+
+	// Spaces and apostrophes only ever separate thousands
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u2009', '\u202f', '\u2019': // spaces (also non-breaking and thin), apostrophes
+			return '\''
+		}
+		return r
+	}, s)
+
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", original)
+	}
+	intPart, fracPart, err := splitAmount(s, decimal)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", original, err)
+	}
+	if !isDigits(intPart + fracPart) {
+		return 0, fmt.Errorf("invalid amount %q", original)
+	}
+	amount, err := strconv.ParseFloat(intPart+"."+fracPart, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", original, err)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// splitAmount splits an unsigned amount, with spaces already mapped to apostrophes, into its
+// integer digits (without thousands separators) and its fraction digits
+func splitAmount(s string, decimal rune) (intPart, fracPart string, err error) {
+	commas, dots := strings.Count(s, ","), strings.Count(s, ".")
+	var sep byte // decimal separator in s, if any
+	switch {
+	case commas > 0 && dots > 0:
+		// Both: the last one separates decimals
+		sep = s[strings.LastIndexAny(s, ",.")]
+	case commas == 1 || dots == 1:
+		sep = ','
+		if dots == 1 {
+			sep = '.'
+		}
+		// A single separator followed by three digits may also be a thousands separator
+		i := strings.IndexByte(s, sep)
+		if len(s)-i-1 == 3 && strings.TrimLeft(s[:i], "0'") != "" && rune(sep) != decimal {
+			sep = 0
+		}
+	}
+
+	if sep != 0 {
+		i := strings.LastIndexByte(s, sep)
+		intPart, fracPart = s[:i], s[i+1:]
+		if strings.ContainsAny(fracPart, ",.'") || fracPart == "" {
+			return "", "", fmt.Errorf("misplaced separator")
+		}
+	} else {
+		intPart = s
+	}
+
+	// What's left of ',', '.' and spaces in the integer part separates groups of three digits
+	if strings.ContainsAny(intPart, ",.'") {
+		if strings.Contains(intPart, ",") && strings.Contains(intPart, ".") {
+			return "", "", fmt.Errorf("mixed thousands separators")
+		}
+		isSeparator := func(r rune) bool { return r == ',' || r == '.' || r == '\'' }
+		groups := strings.FieldsFunc(intPart, isSeparator)
+		if len(groups) != strings.Count(intPart, ",")+strings.Count(intPart, ".")+strings.Count(intPart, "'")+1 {
+			return "", "", fmt.Errorf("misplaced separator")
+		}
+		for j, g := range groups {
+			if (j == 0 && len(g) > 3) || (j > 0 && len(g) != 3) {
+				return "", "", fmt.Errorf("thousands groups must have three digits")
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	return intPart, fracPart, nil
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package internal
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimal  rune
+		expected float64
+	}{
+		{"-99,00", ',', -99},
+		{"99", ',', 99},
+		{"+12,5", ',', 12.5},
+		{"1 234,56", ',', 1234.56},
+		{"1 234,56", ',', 1234.56},
+		{"-1 234 567,89", ',', -1234567.89},
+		{"1.234,56", ',', 1234.56},
+		{"1,234.56", ',', 1234.56},
+		{"1'234.56", '.', 1234.56},
+		{"1,234,567", '.', 1234567},
+		{"1.234.567", ',', 1234567},
+		{"(99.00)", '.', -99},
+		{"( 1 234,50 )", ',', -1234.5},
+		{"99.00-", '.', -99},
+		{"−119,00", ',', -119},
+		{".5", '.', 0.5},
+		// A single separator before three digits is ambiguous: the source's decimal separator decides
+		{"1,234", ',', 1.234},
+		{"1,234", '.', 1234},
+		{"1.234", ',', 1234},
+		{"1.234", '.', 1.234},
+		{"0,125", '.', 0.125}, // a leading zero can't have thousands
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAmount(tt.input, tt.decimal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseAmount(%q, %q) = %v, want %v", tt.input, tt.decimal, got, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"", " ", "-", "()", "abc", "12a", "1,2,3", "12,34.5,6", "1.23,456.7", "1 2345,6", "12,", "Inf", "0x1p3", "1e5", "--5"} {
+		if got, err := ParseAmount(input, ','); err == nil {
+			t.Errorf("expected an error for %q, got %v", input, got)
+		}
+	}
+}
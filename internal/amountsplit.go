@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AmountBand names the payments of a payee within an amount range, to split a payee that bills
+// several subscriptions at different price points (e.g., APPLE.COM/BILL) into one per band
+type AmountBand struct {
+	Name      string   `yaml:"name"`
+	MinAmount *float64 `yaml:"min_amount,omitempty"` // lowest amount (absolute, inclusive)
+	MaxAmount *float64 `yaml:"max_amount,omitempty"` // highest amount (absolute, inclusive)
+}
+
+func (b AmountBand) validate() error {
+	if strings.TrimSpace(b.Name) == "" {
+		return fmt.Errorf("missing name")
+	}
+	if b.MinAmount == nil && b.MaxAmount == nil {
+		return fmt.Errorf("%q needs min_amount, max_amount or both", b.Name)
+	}
+	if (b.MinAmount != nil && *b.MinAmount < 0) || (b.MaxAmount != nil && *b.MaxAmount < 0) {
+		return fmt.Errorf("%q has a negative amount (amounts are absolute)", b.Name)
+	}
+	if b.MinAmount != nil && b.MaxAmount != nil && *b.MinAmount > *b.MaxAmount {
+		return fmt.Errorf("%q has min_amount above max_amount", b.Name)
+	}
+	return nil
+}
+
+// contains reports whether an amount (of either sign) is within the band
+func (b AmountBand) contains(amount float64) bool {
+	amount = math.Abs(amount)
+	return (b.MinAmount == nil || amount >= *b.MinAmount) && (b.MaxAmount == nil || amount <= *b.MaxAmount)
+}
+
+// amountBand returns the band of the split_by_amount entry for a payee name (after renames and
+// groups) that an amount is within, the first one if several are, or nil
+func (c *Config) amountBand(name string, amount float64) *AmountBand {
+	bands := c.splitByAmount[foldPayee(name)]
+	for i := range bands {
+		if bands[i].contains(amount) {
+			return &bands[i]
+		}
+	}
+	return nil
+}
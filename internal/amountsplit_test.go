@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSplitByAmount = `split_by_amount:
+  APPLE.COM/BILL:
+    - name: iCloud+
+      min_amount: 30
+      max_amount: 50
+    - name: Apple Music
+      min_amount: 100
+      max_amount: 130
+    - name: Apple TV+
+      min_amount: 60
+      max_amount: 80
+descriptions:
+  iCloud+: Photos
+`
+
+func TestSplitByAmount(t *testing.T) {
+	cfg, err := parseConfig([]byte(testSplitByAmount))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := []Transaction{
+		{Date: date("2025-01-03"), Text: "APPLE.COM/BILL", Amount: -39},
+		{Date: date("2025-01-12"), Text: "apple.com/bill", Amount: -119},
+		{Date: date("2025-01-20"), Text: "APPLE.COM/BILL", Amount: -15},
+		{Date: date("2025-01-25"), Text: "Netflix", Amount: -39},
+	}
+
+	grouped, _ := cfg.ApplyGroups(txs)
+	var names []string
+	for _, tx := range grouped {
+		names = append(names, tx.Text)
+	}
+	if want := []string{"iCloud+", "Apple Music", "APPLE.COM/BILL", "Netflix"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ApplyGroups() names = %v, want %v", names, want)
+	}
+	if grouped[1].RawText != "apple.com/bill" || grouped[2].RawText != "" {
+		t.Errorf("expected the text as exported kept for split payments only, got %+v", grouped)
+	}
+
+	want := []UnusedEntry{{Section: "split_by_amount", Entry: "APPLE.COM/BILL: Apple TV+"}}
+	if unused := UnusedConfigEntries(cfg, txs); !reflect.DeepEqual(unused, want) {
+		t.Errorf("UnusedConfigEntries() = %+v, want %+v", unused, want)
+	}
+
+	sub := Subscription{Name: "iCloud+", Strategy: StrategyMonthly, Transactions: grouped[:1]}
+	if rules := ExplainRuleSource(sub, cfg).Rules; !reflect.DeepEqual(rules, []string{`split_by_amount "iCloud+" of "APPLE.COM/BILL"`, "description"}) {
+		t.Errorf("unexpected rule source %v", rules)
+	}
+}
+
+func TestSplitByAmount_Invalid(t *testing.T) {
+	tests := []struct {
+		name, band, want string
+	}{
+		{"no name", "min_amount: 10", "missing name"},
+		{"no bounds", "name: A", "needs min_amount, max_amount or both"},
+		{"negative", "name: A\n      max_amount: -10", "negative amount"},
+		{"reversed", "name: A\n      min_amount: 20\n      max_amount: 10", "min_amount above max_amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig([]byte("split_by_amount:\n  APPLE:\n    - " + tt.band + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// annualChargeFactor is how many times the median payment a charge must be to be a yearly
+// charge on a monthly subscription, such as a card's annual fee
+const annualChargeFactor = 3
+
+// annualChargeMinGap is the minimum number of months between the yearly charges of a payee
+const annualChargeMinGap = 11
+
+// AnnualCharge returns the latest of the much larger yearly charges billed on top of a monthly
+// subscription (absolute), or 0 if it has none (see Subscription.AnnualCharges)
+func (s Subscription) AnnualCharge() float64 {
+	if len(s.AnnualCharges) == 0 {
+		return 0
+	}
+	return math.Abs(s.AnnualCharges[len(s.AnnualCharges)-1].Amount)
+}
+
+// splitAnnualCharges separates the charges of txs (sorted by date) that are at least
+// annualChargeFactor times the median payment from the regular payments. It returns false if
+// there are no such charges, or they are less than a year apart and so aren't yearly.
+func splitAnnualCharges(txs []Transaction) (regular, annual []Transaction, ok bool) {
+	if len(txs) < 3 {
+		return nil, nil, false
+	}
+	amounts := make([]float64, len(txs))
+	for i, tx := range txs {
+		amounts[i] = math.Abs(tx.Amount)
+	}
+	slices.Sort(amounts)
+	median := amounts[(len(amounts)-1)/2]
+	for _, tx := range txs {
+		if math.Abs(tx.Amount) >= median*annualChargeFactor {
+			annual = append(annual, tx)
+		} else {
+			regular = append(regular, tx)
+		}
+	}
+	if len(annual) == 0 || len(regular) <= len(annual) {
+		return nil, nil, false
+	}
+	for i := 1; i < len(annual); i++ {
+		if monthIndex(billingMonth(annual[i]))-monthIndex(billingMonth(annual[i-1])) < annualChargeMinGap {
+			return nil, nil, false
+		}
+	}
+	return regular, annual, true
+}
+
+// matchWithAnnualCharges detects a monthly subscription of a payee that also bills a much larger
+// yearly charge (see splitAnnualCharges), which would otherwise fail the tolerance or be a second
+// payment in a month. The regular payments must be a monthly pattern on their own; the yearly
+// charges are kept apart in AnnualCharges.
+func matchWithAnnualCharges(payee PayeeGroup, in *GroupedTransactions) (Subscription, bool) {
+	regular, annual, ok := splitAnnualCharges(payee.Transactions)
+	if !ok {
+		return Subscription{}, false
+	}
+	var complete []Transaction
+	for _, tx := range payee.Complete {
+		if !slices.ContainsFunc(annual, func(a Transaction) bool { return a == tx }) {
+			complete = append(complete, tx)
+		}
+	}
+	if len(complete) < in.MinOccurrences || !IsMonthlyPattern(regular) || !AmountsWithinTolerance(complete, in.Tolerance) {
+		return Subscription{}, false
+	}
+	sub := newSubscription(payee.Name, complete, regular, IntervalMonthly, in)
+	sub.AnnualCharges = annual
+	sub.TotalPaid += CalculateTotalPaid(annual)
+	return sub, true
+}
+
+// WithAnnualCharges returns the subscriptions with yearly charges on top of their monthly
+// payments, in order
+func WithAnnualCharges(subs []Subscription) []Subscription {
+	var result []Subscription
+	for _, sub := range subs {
+		if len(sub.AnnualCharges) > 0 {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// PrintAnnualCharges lists subscriptions with a yearly charge on top of their monthly payments,
+// with both components
+func PrintAnnualCharges(w io.Writer, subs []Subscription, opts OutputOptions) {
+	loc := opts.Locale
+	fmt.Fprint(w, loc.T("Yearly charges on monthly subscriptions:\n"))
+	for _, sub := range subs {
+		last := sub.AnnualCharges[len(sub.AnnualCharges)-1]
+		fmt.Fprint(w, loc.Sprintf("  %s: %s/month + %s/year (last %s)\n", sub.Name,
+			opts.Currency.Format(math.Abs(sub.LatestAmount)), opts.Currency.Format(sub.AnnualCharge()), loc.FormatDate(last.Date)))
+	}
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestSplitAnnualCharges(t *testing.T) {
+	monthly := func(amounts ...float64) []Transaction {
+		var txs []Transaction
+		for i, amount := range amounts {
+			txs = append(txs, Transaction{Date: date("2024-01-05").AddDate(0, i, 0), Amount: amount})
+		}
+		return txs
+	}
+
+	tests := []struct {
+		name       string
+		txs        []Transaction
+		wantOK     bool
+		wantAnnual int
+	}{
+		{"no large charges", monthly(-29, -29, -29, -29), false, 0},
+		{"one annual fee", monthly(-29, -29, -495, -29, -29), true, 1},
+		{"annual fee every year", monthly(-29, -495, -29, -29, -29, -29, -29, -29, -29, -29, -29, -29, -29, -495, -29), true, 2},
+		{"large charges less than a year apart", monthly(-29, -495, -29, -495, -29, -29), false, 0},
+		{"mostly large charges", monthly(-29, -495, -29, -495), false, 0},
+		{"too few payments", monthly(-29, -495), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regular, annual, ok := splitAnnualCharges(tt.txs)
+			if ok != tt.wantOK || len(annual) != tt.wantAnnual {
+				t.Fatalf("got ok=%v with %d annual charges, want ok=%v with %d", ok, len(annual), tt.wantOK, tt.wantAnnual)
+			}
+			if ok && len(regular)+len(annual) != len(tt.txs) {
+				t.Errorf("expected every charge in one of the components, got %d + %d of %d", len(regular), len(annual), len(tt.txs))
+			}
+		})
+	}
+}
+
+func TestDetectAnnualCharges(t *testing.T) {
+	var txs []Transaction
+	for month := 1; month <= 8; month++ {
+		txs = append(txs, Transaction{Date: date("2025-01-05").AddDate(0, month-1, 0), Text: "Card Plus", Amount: -29})
+	}
+	// The annual fee in the same month as a monthly payment
+	txs = append(txs, Transaction{Date: date("2025-03-06"), Text: "Card Plus", Amount: -495})
+	txs = append(txs, Transaction{Date: date("2025-09-20"), Text: "Grocery", Amount: -300})
+	sortByDate(txs)
+	completeMonths, dateRange := AnalyzeDataCoverage(txs)
+
+	subs, err := NewDetector().DetectAll(context.Background(), txs, completeMonths, dateRange, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("expected Card Plus detected, got %+v", subs)
+	}
+	sub := subs[0]
+	if len(sub.Transactions) != 8 || len(sub.AnnualCharges) != 1 || sub.AnnualCharge() != 495 {
+		t.Errorf("expected 8 monthly payments and a 495 annual charge, got %d and %+v", len(sub.Transactions), sub.AnnualCharges)
+	}
+	if sub.MonthlyCost() != 29+495.0/12 || sub.TotalPaid != 8*29+495 {
+		t.Errorf("expected the annual charge in the costs, got %v/month, %v in total", sub.MonthlyCost(), sub.TotalPaid)
+	}
+
+	var buf bytes.Buffer
+	PrintAnnualCharges(&buf, WithAnnualCharges(subs), OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "Card Plus: 29 kr/month + 495 kr/year (last 03/06/2025)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
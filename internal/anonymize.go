@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strings"
+)
+
+// Anonymize returns a copy of txs that is safe to share in bug reports while keeping
+// the recurrence structure that detection depends on:
+//   - Payee names are replaced by pseudonyms ("Payee 1a2b3c4d"), the same for all
+//     transactions with the same name (case-insensitive), so grouping is unchanged.
+//   - Amounts are scaled by a per-payee factor between 0.7 and 1.3 and rounded to cents,
+//     so relative price changes (and thereby --tolerance) behave as in the original.
+//   - Dates are kept, since payment days and month coverage drive detection.
+//
+// Pseudonyms and factors are derived from key with HMAC-SHA256, so names can't be
+// recovered by hashing guesses without the key. The same key gives the same output.
+func Anonymize(txs []Transaction, key []byte) []Transaction {
+	result := make([]Transaction, len(txs))
+	for i, tx := range txs {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(strings.ToLower(tx.Text)))
+		sum := mac.Sum(nil)
+
+		// Upper 53 bits of the last 8 bytes as a uniform value in [0, 1)
+		u := float64(binary.BigEndian.Uint64(sum[24:])>>11) / (1 << 53)
+		factor := 0.7 + 0.6*u
+
+		// IDs, accounts, balances and raw texts could identify the owner, so they are dropped
+		result[i] = Transaction{
+			Date:     tx.Date,
+			Text:     "Payee " + hex.EncodeToString(sum[:4]),
+			Amount:   math.Round(tx.Amount*factor*100) / 100,
+			Currency: tx.Currency,
+			Category: tx.Category,
+		}
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -100},
+		{Date: date("2025-02-15"), Text: "NETFLIX", Amount: -120},
+		{Date: date("2025-02-20"), Text: "Mr Smith", Amount: -500},
+	}
+
+	anon := Anonymize(txs, []byte("secret"))
+
+	if anon[0].Text != anon[1].Text {
+		t.Errorf("expected the same pseudonym for the same payee, got %q and %q", anon[0].Text, anon[1].Text)
+	}
+	if anon[0].Text == anon[2].Text {
+		t.Error("expected different pseudonyms for different payees")
+	}
+	for i, tx := range anon {
+		if strings.Contains(strings.ToLower(tx.Text), "netflix") || strings.Contains(tx.Text, "Smith") {
+			t.Errorf("payee name leaked: %q", tx.Text)
+		}
+		if !tx.Date.Equal(txs[i].Date) {
+			t.Errorf("expected dates to be kept, got %v", tx.Date)
+		}
+	}
+
+	// The per-payee factor keeps relative price changes
+	if ratio := anon[1].Amount / anon[0].Amount; math.Abs(ratio-1.2) > 0.01 {
+		t.Errorf("expected price change ratio 1.2, got %.3f", ratio)
+	}
+	if factor := anon[0].Amount / txs[0].Amount; factor < 0.7 || factor > 1.3 {
+		t.Errorf("expected factor between 0.7 and 1.3, got %.3f", factor)
+	}
+
+	if again := Anonymize(txs, []byte("secret")); again[0] != anon[0] {
+		t.Error("expected the same output for the same key")
+	}
+	if other := Anonymize(txs, []byte("other")); other[0].Text == anon[0].Text {
+		t.Error("expected different pseudonyms for a different key")
+	}
+}
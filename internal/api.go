@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// JSONTransactions is the API response for a subscription's payments
+type JSONTransactions struct {
+	SchemaVersion int               `json:"schema_version"`
+	Name          string            `json:"name"`
+	Transactions  []JSONTransaction `json:"transactions"`
+	Currency      string            `json:"currency"`
+}
+
+// JSONTransaction is the JSON format for a single payment
+type JSONTransaction struct {
+	Date   string  `json:"date"`
+	Text   string  `json:"text"`
+	Amount float64 `json:"amount"` // absolute amount
+
+	// Optional fields, present when the export contained them
+	ID       string   `json:"id,omitempty"`
+	Account  string   `json:"account,omitempty"`
+	Currency string   `json:"currency,omitempty"` // currency of the payment, if different exports are mixed
+	Category string   `json:"category,omitempty"`
+	Balance  *float64 `json:"balance,omitempty"`
+	RawText  string   `json:"raw_text,omitempty"`
+}
+
+// JSONImportResult is the API response for an import
+type JSONImportResult struct {
+	File       string `json:"file"`
+	Added      int    `json:"added"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// JSONError is the API response for a failed request
+type JSONError struct {
+	Error string `json:"error"`
+}
+
+// handleAPISubscriptions returns detected subscriptions in the same format as --output json.
+// Supports the show, tag, q, sort and dir query parameters like the web UI.
+func (s *Server) handleAPISubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	display := s.filterSubscriptions(subs, r.URL.Query())
+	w.Header().Set("Content-Type", "application/json")
+	PrintSubscriptionsJSON(w, display, s.Config, OutputOptions{Currency: s.Currency})
+}
+
+// handleAPITransactions returns the payments of a single subscription, oldest first
+func (s *Server) handleAPITransactions(w http.ResponseWriter, r *http.Request) {
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	sub := FindSubscription(subs, r.PathValue("name"))
+	if sub == nil {
+		writeJSON(w, http.StatusNotFound, JSONError{Error: "subscription not found: " + r.PathValue("name")})
+		return
+	}
+
+	txs := make([]Transaction, len(sub.Transactions))
+	copy(txs, sub.Transactions)
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Date.Before(txs[j].Date) })
+
+	response := JSONTransactions{
+		SchemaVersion: JSONSchemaVersion,
+		Name:          sub.Name,
+		Transactions:  []JSONTransaction{},
+		Currency:      s.Currency.Code,
+	}
+	for _, tx := range txs {
+		response.Transactions = append(response.Transactions, JSONTransaction{
+			Date:     tx.Date.Format("2006-01-02"),
+			Text:     tx.Text,
+			Amount:   math.Abs(tx.Amount),
+			ID:       tx.ID,
+			Account:  tx.Account,
+			Currency: tx.Currency,
+			Category: tx.Category,
+			Balance:  tx.Balance,
+			RawText:  tx.RawText,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAPIImport imports an uploaded export file (multipart field "file", optional "format")
+func (s *Server) handleAPIImport(w http.ResponseWriter, r *http.Request) {
+	filename, result, status, err := s.importUpload(w, r)
+	if err != nil {
+		writeJSONError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, JSONImportResult{File: filename, Added: result.Added, Duplicates: result.Duplicates})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, JSONError{Error: err.Error()})
+}
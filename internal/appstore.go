@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// appStorePattern matches the payees of app stores, which bill the subscriptions bought through
+// them under their own name
+var appStorePattern = regexp.MustCompile(`(?i)APPLE\.COM/BILL|ITUNES|GOOGLE\s*\*?\s*PLAY`)
+
+// appStoreClusterGap is how much larger (relative) an app store charge must be than the next
+// smaller one to start a new cluster
+const appStoreClusterGap = 0.15
+
+// appStoreBandMargin widens the amount range of a cluster on both sides for its split_by_amount
+// band, for small price changes. It's small enough that the bands of clusters don't overlap.
+const appStoreBandMargin = 0.05
+
+// AppStoreCluster is a cluster of charges of an app store payee at similar amounts, which is a
+// probable subscription if they recur at a regular interval
+type AppStoreCluster struct {
+	Payee        string
+	Transactions []Transaction // sorted by date
+	MinAmount    float64       // absolute
+	MaxAmount    float64       // absolute
+	Interval     Interval      // months between the charges, or 0 if they don't recur regularly
+}
+
+// Recurring reports whether the charges of the cluster recur at a regular interval
+func (c AppStoreCluster) Recurring() bool {
+	return c.Interval != 0
+}
+
+// Band returns the split_by_amount band for the cluster, named after the payee and amount
+func (c AppStoreCluster) Band() AmountBand {
+	// Rounded to cents, outwards (the epsilon keeps e.g. 37.05 from flooring to 37.04)
+	minAmount := math.Floor(c.MinAmount*(1-appStoreBandMargin)*100+1e-6) / 100
+	maxAmount := math.Ceil(c.MaxAmount*(1+appStoreBandMargin)*100-1e-6) / 100
+	return AmountBand{
+		Name:      fmt.Sprintf("%s %s", c.Payee, strconv.FormatFloat(c.MaxAmount, 'f', -1, 64)),
+		MinAmount: &minAmount,
+		MaxAmount: &maxAmount,
+	}
+}
+
+// ClusterAppStoreCharges clusters the expenses of app store payees (APPLE.COM/BILL, ITUNES,
+// GOOGLE PLAY) by amount, as these hide the services they bill for. Each payee's charges are
+// sorted by amount and split where one is appStoreClusterGap larger than the one before. A
+// cluster recurs if it has at most one charge per month and a monthly, quarterly or yearly median
+// gap. Charges already named by split_by_amount bands aren't app store payees anymore and are
+// left out. Clusters are sorted by payee, then amount.
+func ClusterAppStoreCharges(txs []Transaction) []AppStoreCluster {
+	byPayee := make(map[string][]Transaction)
+	for _, tx := range FilterExpenses(txs) {
+		if appStorePattern.MatchString(tx.Text) {
+			byPayee[tx.Text] = append(byPayee[tx.Text], tx)
+		}
+	}
+
+	var clusters []AppStoreCluster
+	for payee, charges := range byPayee {
+		sort.SliceStable(charges, func(i, j int) bool { return math.Abs(charges[i].Amount) < math.Abs(charges[j].Amount) })
+		start := 0
+		for i := 1; i <= len(charges); i++ {
+			if i < len(charges) && math.Abs(charges[i].Amount) <= math.Abs(charges[i-1].Amount)*(1+appStoreClusterGap) {
+				continue
+			}
+			clusters = append(clusters, newAppStoreCluster(payee, charges[start:i]))
+			start = i
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Payee != clusters[j].Payee {
+			return clusters[i].Payee < clusters[j].Payee
+		}
+		return clusters[i].MinAmount < clusters[j].MinAmount
+	})
+	return clusters
+}
+
+// newAppStoreCluster creates the cluster of charges (sorted by amount) and finds its interval
+func newAppStoreCluster(payee string, charges []Transaction) AppStoreCluster {
+	c := AppStoreCluster{
+		Payee:        payee,
+		Transactions: append([]Transaction{}, charges...),
+		MinAmount:    math.Abs(charges[0].Amount),
+		MaxAmount:    math.Abs(charges[len(charges)-1].Amount),
+	}
+	sort.SliceStable(c.Transactions, func(i, j int) bool { return c.Transactions[i].Date.Before(c.Transactions[j].Date) })
+	if len(c.Transactions) >= 2 && IsMonthlyPattern(c.Transactions) {
+		switch gap := Interval(medianMonthGap(c.Transactions)); gap {
+		case IntervalMonthly, IntervalQuarterly, IntervalYearly:
+			c.Interval = gap
+		}
+	}
+	return c
+}
+
+// PrintAppStoreClusters displays the clusters of app store charges, with split_by_amount bands for
+// the config that turn the recurring ones into subscriptions of their own
+func PrintAppStoreClusters(w io.Writer, clusters []AppStoreCluster, currency Currency) {
+	if len(clusters) == 0 {
+		fmt.Fprintln(w, "No app store charges found.")
+		return
+	}
+
+	var recurring []AppStoreCluster
+	payee := ""
+	for _, c := range clusters {
+		if c.Payee != payee {
+			if payee != "" {
+				fmt.Fprintln(w)
+			}
+			payee = c.Payee
+			fmt.Fprintf(w, "\"%s\":\n", payee)
+		}
+		amount := currency.Format(c.MinAmount)
+		if c.MaxAmount != c.MinAmount {
+			amount += " - " + currency.Format(c.MaxAmount)
+		}
+		first, last := c.Transactions[0].Date.Format("2006-01-02"), c.Transactions[len(c.Transactions)-1].Date.Format("2006-01-02")
+		if c.Recurring() {
+			recurring = append(recurring, c)
+			fmt.Fprintf(w, "  %s %s, %d charges (%s to %s)\n", amount, c.Interval, len(c.Transactions), first, last)
+		} else if len(c.Transactions) == 1 {
+			fmt.Fprintf(w, "  %s once (%s)\n", amount, first)
+		} else {
+			fmt.Fprintf(w, "  %s irregular, %d charges (%s to %s)\n", amount, len(c.Transactions), first, last)
+		}
+	}
+	fmt.Fprintln(w)
+
+	if len(recurring) == 0 {
+		fmt.Fprintln(w, "No probable subscriptions found (no amount recurs at a regular interval).")
+		return
+	}
+	fmt.Fprintf(w, "Found %d probable subscription(s). Add to config, naming the bands after the services on your receipts:\n", len(recurring))
+	fmt.Fprintln(w, "  split_by_amount:")
+	payee = ""
+	for _, c := range recurring {
+		if c.Payee != payee {
+			payee = c.Payee
+			fmt.Fprintf(w, "    %q:\n", payee)
+		}
+		band := c.Band()
+		fmt.Fprintf(w, "      - name: %q\n", band.Name)
+		fmt.Fprintf(w, "        min_amount: %s\n", strconv.FormatFloat(*band.MinAmount, 'f', -1, 64))
+		fmt.Fprintf(w, "        max_amount: %s\n", strconv.FormatFloat(*band.MaxAmount, 'f', -1, 64))
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClusterAppStoreCharges(t *testing.T) {
+	var txs []Transaction
+	for _, month := range []string{"01", "02", "03", "04"} {
+		txs = append(txs,
+			Transaction{Date: date("2025-" + month + "-03"), Text: "APPLE.COM/BILL", Amount: -39},
+			Transaction{Date: date("2025-" + month + "-20"), Text: "GOOGLE *Google Play", Amount: -25})
+	}
+	txs = append(txs,
+		Transaction{Date: date("2025-01-12"), Text: "APPLE.COM/BILL", Amount: -119},
+		Transaction{Date: date("2025-03-12"), Text: "APPLE.COM/BILL", Amount: -129}, // two months apart
+		Transaction{Date: date("2025-03-20"), Text: "APPLE.COM/BILL", Amount: -15},  // one-off purchase
+		Transaction{Date: date("2025-03-21"), Text: "APPLE.COM/BILL", Amount: 15},   // refund
+		Transaction{Date: date("2025-03-05"), Text: "Netflix", Amount: -99},         // not an app store
+	)
+
+	clusters := ClusterAppStoreCharges(txs)
+	type cluster struct {
+		payee    string
+		min, max float64
+		charges  int
+		interval Interval
+	}
+	want := []cluster{
+		{"APPLE.COM/BILL", 15, 15, 1, 0},
+		{"APPLE.COM/BILL", 39, 39, 4, IntervalMonthly},
+		{"APPLE.COM/BILL", 119, 129, 2, 0},
+		{"GOOGLE *Google Play", 25, 25, 4, IntervalMonthly},
+	}
+	if len(clusters) != len(want) {
+		t.Fatalf("expected %d clusters, got %+v", len(want), clusters)
+	}
+	for i, c := range clusters {
+		got := cluster{c.Payee, c.MinAmount, c.MaxAmount, len(c.Transactions), c.Interval}
+		if got != want[i] {
+			t.Errorf("cluster %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	band := clusters[1].Band()
+	if band.Name != "APPLE.COM/BILL 39" || *band.MinAmount != 37.05 || *band.MaxAmount != 40.95 {
+		t.Errorf("unexpected band %q %v-%v", band.Name, *band.MinAmount, *band.MaxAmount)
+	}
+
+	var buf bytes.Buffer
+	PrintAppStoreClusters(&buf, clusters, GetCurrency("USD"))
+	output := buf.String()
+	for _, s := range []string{
+		"  $39 monthly, 4 charges (2025-01-03 to 2025-04-03)\n",
+		"  $15 once (2025-03-20)\n",
+		"  $119 - $129 irregular, 2 charges (2025-01-12 to 2025-03-12)\n",
+		"Found 2 probable subscription(s)",
+		"    \"APPLE.COM/BILL\":\n      - name: \"APPLE.COM/BILL 39\"\n        min_amount: 37.05\n        max_amount: 40.95\n",
+	} {
+		if !strings.Contains(output, s) {
+			t.Errorf("expected %q in output:\n%s", s, output)
+		}
+	}
+}
+
+func TestClusterAppStoreCharges_None(t *testing.T) {
+	var buf bytes.Buffer
+	PrintAppStoreClusters(&buf, ClusterAppStoreCharges([]Transaction{{Date: date("2025-01-01"), Text: "Netflix", Amount: -99}}), GetCurrency("USD"))
+	if buf.String() != "No app store charges found.\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
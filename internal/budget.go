@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Budgets sets monthly spending limits for active subscriptions
+type Budgets struct {
+	Total float64            `yaml:"total,omitempty"` // limit for all active subscriptions
+	Tags  map[string]float64 `yaml:"tags,omitempty"`  // limit per tag
+}
+
+func (b *Budgets) validate() error {
+	if b == nil {
+		return nil
+	}
+	if b.Total < 0 {
+		return fmt.Errorf("total must not be negative")
+	}
+	for tag, limit := range b.Tags {
+		if limit < 0 {
+			return fmt.Errorf("budget for tag %q must not be negative", tag)
+		}
+	}
+	return nil
+}
+
+// BudgetStatus compares the monthly spend of active subscriptions against a budget
+type BudgetStatus struct {
+	Tag           string // empty for the overall budget
+	Budget        float64
+	Spend         float64
+	Subscriptions []Subscription // active subscriptions counted against the budget
+	Cuts          []Subscription // subscriptions to cancel to get under budget (none if within budget)
+}
+
+// Headroom returns the remaining budget (negative when over budget)
+func (s BudgetStatus) Headroom() float64 {
+	return s.Budget - s.Spend
+}
+
+// CheckBudgets compares active subscription spend against the configured budgets:
+// the overall budget first, followed by tag budgets sorted by tag
+func CheckBudgets(subs []Subscription, cfg *Config) []BudgetStatus {
+	if cfg == nil || cfg.Budgets == nil {
+		return nil
+	}
+
+	var active []Subscription
+	for _, sub := range subs {
+		if sub.Status == StatusActive {
+			active = append(active, sub)
+		}
+	}
+
+	var result []BudgetStatus
+	if cfg.Budgets.Total > 0 {
+		result = append(result, newBudgetStatus("", cfg.Budgets.Total, active))
+	}
+
+	tags := make([]string, 0, len(cfg.Budgets.Tags))
+	for tag := range cfg.Budgets.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		result = append(result, newBudgetStatus(tag, cfg.Budgets.Tags[tag], FilterByTags(active, []string{tag}, cfg)))
+	}
+	return result
+}
+
+// newBudgetStatus computes the spend of subs against budget. When over budget, the most
+// expensive subscriptions are suggested as cuts until the spend is within budget.
+func newBudgetStatus(tag string, budget float64, subs []Subscription) BudgetStatus {
+	status := BudgetStatus{Tag: tag, Budget: budget, Subscriptions: subs, Spend: ActiveMonthlyTotal(subs)}
+	if status.Spend <= budget {
+		return status
+	}
+
+	byCost := make([]Subscription, len(subs))
+	copy(byCost, subs)
+	sort.SliceStable(byCost, func(i, j int) bool {
+		return byCost[i].MonthlyCost() > byCost[j].MonthlyCost()
+	})
+	spend := status.Spend
+	for _, sub := range byCost {
+		if spend <= budget {
+			break
+		}
+		status.Cuts = append(status.Cuts, sub)
+		spend -= sub.MonthlyCost()
+	}
+	return status
+}
+
+// PrintBudgetTable outputs budget statuses as a table, followed by the suggested cuts
+// for budgets that are exceeded
+func PrintBudgetTable(w io.Writer, statuses []BudgetStatus, opts OutputOptions) {
+	loc := opts.Locale
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, loc.T("No budgets configured (add a budgets section to the config file)."))
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Budget"), loc.T("Subscriptions"), loc.T("Monthly"), loc.T("Limit"), loc.T("Headroom"), ""})
+	for _, s := range statuses {
+		note := text.FgGreen.Sprint(loc.T("within budget"))
+		if s.Headroom() < 0 {
+			note = text.FgRed.Sprint(loc.T("over budget"))
+		}
+		t.AppendRow(table.Row{budgetLabel(s, loc), len(s.Subscriptions), opts.Currency.Format(s.Spend), opts.Currency.Format(s.Budget), opts.Currency.Format(s.Headroom()), note})
+	}
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 2, Align: text.AlignRight},
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight},
+		{Number: 5, Align: text.AlignRight},
+	})
+	t.Render()
+
+	for _, s := range statuses {
+		if len(s.Cuts) == 0 {
+			continue
+		}
+		names := make([]string, len(s.Cuts))
+		saved := 0.0
+		for i, sub := range s.Cuts {
+			names[i] = fmt.Sprintf("%s (%s)", sub.Name, opts.Currency.Format(sub.MonthlyCost()))
+			saved += sub.MonthlyCost()
+		}
+		fmt.Fprintln(w)
+		fmt.Fprint(w, loc.Sprintf("To get under the %s budget, cut %s (saves %s/month): %s\n",
+			budgetLabel(s, loc), loc.Sprintf("%d subscription(s)", len(s.Cuts)), opts.Currency.Format(saved), strings.Join(names, ", ")))
+	}
+}
+
+// budgetLabel returns the tag of a budget, or "Total" for the overall budget
+func budgetLabel(s BudgetStatus, loc Locale) string {
+	if s.Tag == "" {
+		return loc.T("Total")
+	}
+	return s.Tag
+}
+
+// JSONBudget is the JSON output format for the budget subcommand
+type JSONBudget struct {
+	SchemaVersion int                `json:"schema_version"`
+	Budgets       []JSONBudgetStatus `json:"budgets"`
+	Currency      string             `json:"currency"`
+}
+
+// JSONBudgetStatus is the JSON output format for a single budget
+type JSONBudgetStatus struct {
+	Tag           string   `json:"tag,omitempty"` // empty for the overall budget
+	Budget        float64  `json:"budget"`
+	Spend         float64  `json:"spend"`
+	Headroom      float64  `json:"headroom"`
+	OverBudget    bool     `json:"over_budget"`
+	Subscriptions []string `json:"subscriptions"`
+	Cuts          []string `json:"cuts"`
+}
+
+// PrintBudgetJSON outputs budget statuses in JSON format
+func PrintBudgetJSON(w io.Writer, statuses []BudgetStatus, currency Currency) {
+	output := JSONBudget{
+		SchemaVersion: JSONSchemaVersion,
+		Budgets:       []JSONBudgetStatus{},
+		Currency:      currency.Code,
+	}
+	for _, s := range statuses {
+		status := JSONBudgetStatus{
+			Tag:           s.Tag,
+			Budget:        currency.Round(s.Budget),
+			Spend:         currency.Round(s.Spend),
+			Headroom:      currency.Round(s.Headroom()),
+			OverBudget:    s.Headroom() < 0,
+			Subscriptions: []string{},
+			Cuts:          []string{},
+		}
+		for _, sub := range s.Subscriptions {
+			status.Subscriptions = append(status.Subscriptions, sub.Name)
+		}
+		for _, sub := range s.Cuts {
+			status.Cuts = append(status.Cuts, sub.Name)
+		}
+		output.Budgets = append(output.Budgets, status)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
@@ -0,0 +1,52 @@
+package internal
+
+import "testing"
+
+func TestCheckBudgets(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Netflix", LatestAmount: -99, Status: StatusActive},
+		{Name: "HBO", LatestAmount: -89, Status: StatusActive},
+		{Name: "Disney", LatestAmount: -79, Status: StatusActive},
+		{Name: "Gym", LatestAmount: -300, Status: StatusActive},
+		{Name: "Old Magazine", LatestAmount: -500, Status: StatusStopped},
+	}
+	cfg := &Config{
+		Tags: map[string][]string{"Netflix": {"video"}, "HBO": {"video"}, "Disney": {"video"}},
+		Budgets: &Budgets{
+			Total: 600,
+			Tags:  map[string]float64{"video": 100},
+		},
+	}
+
+	statuses := CheckBudgets(subs, cfg)
+	if len(statuses) != 2 {
+		t.Fatalf("expected the total and one tag budget, got %d", len(statuses))
+	}
+
+	total := statuses[0]
+	if total.Tag != "" || total.Spend != 567 || total.Headroom() != 33 || len(total.Cuts) != 0 {
+		t.Errorf("expected the total budget to have 33 headroom (stopped subscriptions not counted), got %+v", total)
+	}
+
+	video := statuses[1]
+	if video.Tag != "video" || video.Spend != 267 || len(video.Subscriptions) != 3 {
+		t.Errorf("unexpected video budget: %+v", video)
+	}
+	// 267 - 99 - 89 = 79 <= 100: the two most expensive are cut
+	if len(video.Cuts) != 2 || video.Cuts[0].Name != "Netflix" || video.Cuts[1].Name != "HBO" {
+		t.Errorf("expected Netflix and HBO as cuts, got %+v", video.Cuts)
+	}
+
+	if CheckBudgets(subs, &Config{}) != nil {
+		t.Error("expected no statuses without budgets")
+	}
+}
+
+func TestBudgets_Validate(t *testing.T) {
+	if err := (&Budgets{Tags: map[string]float64{"video": -1}}).validate(); err == nil {
+		t.Error("expected a negative tag budget to be rejected")
+	}
+	if err := (*Budgets)(nil).validate(); err != nil {
+		t.Errorf("expected no budgets to be valid, got %v", err)
+	}
+}
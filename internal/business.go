@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// BusinessPeriod is the spend on business expenses in a period, split into the VAT included in
+// the payments and the deductible amount excluding VAT
+type BusinessPeriod struct {
+	SpendPeriod
+	VAT float64 // VAT included in Total
+}
+
+// Deductible returns the spend excluding VAT
+func (p BusinessPeriod) Deductible() float64 {
+	return p.Total - p.VAT
+}
+
+// FilterBusiness returns the subscriptions that the config marks as business expenses
+func FilterBusiness(subs []Subscription, cfg *Config) []Subscription {
+	var result []Subscription
+	for _, sub := range subs {
+		if _, ok := cfg.GetVATRate(sub.Name); ok {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// BusinessByPeriod sums the payments of business expenses per period (see SpendByPeriod), with
+// the VAT included in them at each subscription's rate from the config
+func BusinessByPeriod(subs []Subscription, by string, cfg *Config) []BusinessPeriod {
+	var periods []BusinessPeriod
+	for _, p := range SpendByPeriod(FilterBusiness(subs, cfg), by) {
+		bp := BusinessPeriod{SpendPeriod: p}
+		for name, amount := range p.BySubscription {
+			rate, _ := cfg.GetVATRate(name)
+			bp.VAT += amount * rate / (1 + rate)
+		}
+		periods = append(periods, bp)
+	}
+	return periods
+}
+
+// PrintBusinessReportTable outputs the spend on business expenses per period as a table with the
+// included VAT and the deductible amount, followed by the totals
+func PrintBusinessReportTable(w io.Writer, periods []BusinessPeriod, by string, opts OutputOptions) {
+	loc := opts.Locale
+	if len(periods) == 0 {
+		fmt.Fprintln(w, loc.T("No business expenses detected."))
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Period"), loc.T("Subscriptions"), loc.T("Payments"), loc.T("Spend"), loc.T("VAT"), loc.T("Deductible")})
+	var total, vat float64
+	for _, p := range periods {
+		t.AppendRow(table.Row{p.Label(by), len(p.BySubscription), p.Payments, opts.Currency.Format(p.Total), opts.Currency.Format(p.VAT), opts.Currency.Format(p.Deductible())})
+		total += p.Total
+		vat += p.VAT
+	}
+	t.AppendFooter(table.Row{loc.T("Total"), "", "", opts.Currency.Format(total), opts.Currency.Format(vat), opts.Currency.Format(total - vat)})
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 2, Align: text.AlignRight},
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight, AlignFooter: text.AlignRight},
+		{Number: 5, Align: text.AlignRight, AlignFooter: text.AlignRight},
+		{Number: 6, Align: text.AlignRight, AlignFooter: text.AlignRight},
+	})
+	t.Render()
+}
+
+// JSONBusinessReport is the JSON output format for the report subcommand with --business
+type JSONBusinessReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	By            string               `json:"by"`
+	Periods       []JSONBusinessPeriod `json:"periods"`
+	Total         float64              `json:"total"`
+	VAT           float64              `json:"vat"`
+	Deductible    float64              `json:"deductible"`
+	Currency      string               `json:"currency"`
+}
+
+// JSONBusinessPeriod is the JSON output format for the business expenses in one period
+type JSONBusinessPeriod struct {
+	Period         string             `json:"period"` // YYYY-MM, YYYY-QN or YYYY
+	Total          float64            `json:"total"`  // including VAT
+	VAT            float64            `json:"vat"`
+	Deductible     float64            `json:"deductible"` // excluding VAT
+	Payments       int                `json:"payments"`
+	BySubscription map[string]float64 `json:"by_subscription"`
+}
+
+// PrintBusinessReportJSON outputs the business expenses per period in JSON format
+func PrintBusinessReportJSON(w io.Writer, periods []BusinessPeriod, by string, currency Currency) {
+	output := JSONBusinessReport{
+		SchemaVersion: JSONSchemaVersion,
+		By:            by,
+		Periods:       []JSONBusinessPeriod{},
+		Currency:      currency.Code,
+	}
+	for _, p := range periods {
+		output.Periods = append(output.Periods, JSONBusinessPeriod{
+			Period:         p.Label(by),
+			Total:          currency.Round(p.Total),
+			VAT:            currency.Round(p.VAT),
+			Deductible:     currency.Round(p.Deductible()),
+			Payments:       p.Payments,
+			BySubscription: p.BySubscription,
+		})
+		output.Total += p.Total
+		output.VAT += p.VAT
+	}
+	output.Deductible = currency.Round(output.Total - output.VAT)
+	output.Total = currency.Round(output.Total)
+	output.VAT = currency.Round(output.VAT)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
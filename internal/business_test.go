@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBusinessByPeriod(t *testing.T) {
+	subs := []Subscription{
+		{Name: "GitHub", Transactions: []Transaction{
+			{Date: date("2025-01-15"), Amount: -125},
+			{Date: date("2025-02-15"), Amount: -125},
+			{Date: date("2025-04-15"), Amount: -125},
+		}},
+		{Name: "Domain", Transactions: []Transaction{
+			{Date: date("2025-03-01"), Amount: -100},
+		}},
+		{Name: "Netflix", Transactions: []Transaction{
+			{Date: date("2025-01-10"), Amount: -99},
+		}},
+	}
+	cfg, err := parseConfig([]byte("business:\n  GitHub: 0.25\n  Domain: 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	periods := BusinessByPeriod(subs, "quarter", cfg)
+	if len(periods) != 2 {
+		t.Fatalf("expected two quarters, got %+v", periods)
+	}
+	if q1 := periods[0]; q1.Label("quarter") != "2025-Q1" || q1.Total != 350 || q1.VAT != 50 || q1.Deductible() != 300 {
+		t.Errorf("unexpected first quarter: %+v", q1)
+	}
+	if q2 := periods[1]; q2.Label("quarter") != "2025-Q2" || q2.Total != 125 || math.Abs(q2.VAT-25) > 1e-9 {
+		t.Errorf("unexpected second quarter: %+v", q2)
+	}
+
+	if got := FilterBusiness(subs, nil); got != nil {
+		t.Errorf("expected no business expenses without a config, got %+v", got)
+	}
+	if _, err := parseConfig([]byte("business:\n  GitHub: 25\n")); err == nil {
+		t.Error("expected a VAT rate of 25 (not 0.25) to be rejected")
+	}
+}
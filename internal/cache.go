@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseCacheVersion is part of every cache key. Bump it when a built-in parser's output for
+// the same file changes, so stale entries are no longer used.
+const parseCacheVersion = 5
+
+// ParseCache keeps parsed transactions on disk, keyed by the format, the parse options and a hash of
+// the file content, so that parsing the same export again (e.g., with different display flags) only has
+// to hash it. The cache is best-effort: entries that can't be read or written are ignored.
+type ParseCache struct {
+	dir string
+}
+
+// NewParseCache creates a cache storing its entries in dir (created when needed)
+func NewParseCache(dir string) *ParseCache {
+	return &ParseCache{dir: dir}
+}
+
+// DefaultCacheDir returns the default cache directory (~/.subscription-detector/cache)
+func DefaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".subscription-detector", "cache")
+}
+
+// ParseFile is like the package-level ParseFile, but returns the cached transactions if the
+// file was parsed with the same format before
+func (c *ParseCache) ParseFile(ctx context.Context, source, path string) ([]Transaction, error) {
+	if _, err := GetParser(source); err != nil {
+		return nil, err
+	}
+	key, err := c.key(ctx, source, path)
+	if err != nil {
+		// Let the parser report the file's problem
+		return ParseFile(ctx, source, path)
+	}
+	if txs, ok := c.load(key); ok {
+		if progress := progressFrom(ctx); progress != nil {
+			progress.fileDone(path, len(txs))
+		}
+		return txs, nil
+	}
+
+	txs, err := ParseFile(ctx, source, path)
+	if err != nil {
+		return nil, err
+	}
+	// Files with skipped rows aren't cached, so their warnings are reported on every run
+	if w := parseWarningsFrom(ctx); w == nil || !w.has(path) {
+		c.store(key, txs)
+	}
+	return txs, nil
+}
+
+// key returns the cache key of the file at path parsed with source and the parse options in ctx
+// (the timezone of dates and the selected sheets)
+func (c *ParseCache) key(ctx context.Context, source, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\x00%s\x00%s\x00%s\x00", parseCacheVersion, source, timezoneFrom(ctx), strings.Join(sheetsFrom(ctx), "\x01"))
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *ParseCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".gob")
+}
+
+// load returns the cached transactions for key, if there is a readable entry
+func (c *ParseCache) load(key string) ([]Transaction, bool) {
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var txs []Transaction
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&txs); err != nil {
+		return nil, false
+	}
+	return txs, true
+}
+
+// store writes the entry for key. The entry is written to a temporary file first and renamed,
+// so concurrent runs never see a partial entry.
+func (c *ParseCache) store(key string, txs []Transaction) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(txs); err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.entryPath(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+type parseCacheKey struct{}
+
+// WithParseCache returns a context in which ParseFiles uses c
+func WithParseCache(ctx context.Context, c *ParseCache) context.Context {
+	return context.WithValue(ctx, parseCacheKey{}, c)
+}
+
+// parseCacheFrom returns the ParseCache in ctx, or nil
+func parseCacheFrom(ctx context.Context) *ParseCache {
+	c, _ := ctx.Value(parseCacheKey{}).(*ParseCache)
+	return c
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	path := filepath.Join(dir, "tx.json")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := func() []string {
+		t.Helper()
+		matches, _ := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+		return matches
+	}
+
+	ctx := context.Background()
+	cache := NewParseCache(cacheDir)
+	write(`{"transactions": [{"date": "2025-01-15", "text": "Netflix", "amount": -99, "balance": 1000}]}`)
+
+	txs, err := cache.ParseFile(ctx, "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || len(entries()) != 1 {
+		t.Fatalf("expected 1 transaction and 1 cache entry, got %v and %v", txs, entries())
+	}
+
+	// A hit returns the stored transactions, including optional fields
+	cached, err := cache.ParseFile(ctx, "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 1 || !cached[0].Date.Equal(txs[0].Date) || cached[0].Text != "Netflix" ||
+		cached[0].Amount != -99 || cached[0].Balance == nil || *cached[0].Balance != 1000 {
+		t.Errorf("cached transactions = %+v, want %+v", cached, txs)
+	}
+
+	// Changed content is a different entry
+	write(`{"transactions": [{"date": "2025-01-15", "text": "Spotify", "amount": -119}]}`)
+	txs, err = cache.ParseFile(ctx, "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].Text != "Spotify" || len(entries()) != 2 {
+		t.Errorf("expected the changed file to be parsed again, got %+v and %v", txs, entries())
+	}
+
+	// Unreadable entries are parsed again and replaced
+	for _, entry := range entries() {
+		if err := os.WriteFile(entry, []byte("garbage"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := ParseFiles(WithParseCache(ctx, cache), []SourceFile{{Source: "simple-json", Path: path}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results[0]) != 1 || results[0][0].Text != "Spotify" {
+		t.Errorf("expected a corrupt entry to be ignored, got %+v", results)
+	}
+	if txs, ok := cache.load(mustKey(t, cache, "simple-json", path)); !ok || len(txs) != 1 {
+		t.Error("expected the corrupt entry to be replaced")
+	}
+
+	// Errors are the parser's, and nothing is cached for them
+	if _, err := cache.ParseFile(ctx, "simple-json", filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, err := cache.ParseFile(ctx, "unknown-format", path); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	write(`not json`)
+	if _, err := cache.ParseFile(ctx, "simple-json", path); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+	if len(entries()) != 2 {
+		t.Errorf("expected no entries for failed parses, got %v", entries())
+	}
+}
+
+func mustKey(t *testing.T, c *ParseCache, source, path string) string {
+	t.Helper()
+	key, err := c.key(context.Background(), source, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
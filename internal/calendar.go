@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCalendarMonths is how far ahead the calendar feed lists expected payments
+const defaultCalendarMonths = 3
+
+// ExpectedPayment is an upcoming payment of an active subscription
+type ExpectedPayment struct {
+	Name   string
+	Date   time.Time
+	Amount float64 // latest amount (absolute)
+}
+
+// ExpectedPayments returns the expected payments of active subscriptions from the day of from
+// up to months months later, sorted by date then name. Payments are expected monthly on the
+// subscription's typical day (clamped to the end of shorter months), starting the month after the last payment.
+func ExpectedPayments(subs []Subscription, from time.Time, months int) []ExpectedPayment {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, months, 0)
+
+	var payments []ExpectedPayment
+	for _, sub := range subs {
+		if sub.Status != StatusActive || sub.TypicalDay == 0 {
+			continue
+		}
+		month := time.Date(sub.LastDate.Year(), sub.LastDate.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+			day := sub.TypicalDay
+			if last := month.AddDate(0, 1, -1).Day(); day > last {
+				day = last
+			}
+			date := time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.UTC)
+			if date.Before(start) || !date.Before(end) {
+				continue
+			}
+			payments = append(payments, ExpectedPayment{Name: sub.Name, Date: date, Amount: math.Abs(sub.LatestAmount)})
+		}
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		if !payments[i].Date.Equal(payments[j].Date) {
+			return payments[i].Date.Before(payments[j].Date)
+		}
+		return strings.ToLower(payments[i].Name) < strings.ToLower(payments[j].Name)
+	})
+	return payments
+}
+
+// WriteICal writes expected payments as an iCalendar (RFC 5545) feed of all-day events
+func WriteICal(w io.Writer, payments []ExpectedPayment, currency Currency, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//subscription-detector//expected payments//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Subscription payments",
+		"REFRESH-INTERVAL;VALUE=DURATION:P1D",
+	}
+	for _, p := range payments {
+		uid := fmt.Sprintf("%s-%s@subscription-detector", p.Date.Format("20060102"), strings.ToLower(strings.Join(strings.Fields(p.Name), "-")))
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeICalText(uid),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+p.Date.Format("20060102"),
+			"DTEND;VALUE=DATE:"+p.Date.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+escapeICalText(fmt.Sprintf("%s %s", p.Name, currency.Format(p.Amount))),
+			"DESCRIPTION:"+escapeICalText(fmt.Sprintf("Expected subscription payment: %s, %s", p.Name, currency.Format(p.Amount))),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		io.WriteString(w, foldICalLine(line)+"\r\n")
+	}
+}
+
+// escapeICalText escapes a TEXT value (backslash, semicolon, comma and newline)
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldICalLine folds lines longer than 75 octets, without splitting UTF-8 characters
+func foldICalLine(line string) string {
+	const maxOctets = 75
+	if len(line) <= maxOctets {
+		return line
+	}
+	var sb strings.Builder
+	octets := 0
+	for _, r := range line {
+		size := len(string(r))
+		if octets+size > maxOctets {
+			sb.WriteString("\r\n ")
+			octets = 1 // the leading space counts
+		}
+		sb.WriteRune(r)
+		octets += size
+	}
+	return sb.String()
+}
+
+// handleCalendar serves upcoming expected payments as an iCalendar feed.
+// The months query parameter controls how far ahead payments are listed.
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	months := defaultCalendarMonths
+	if v := r.URL.Query().Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 24 {
+			http.Error(w, "months must be between 1 and 24", http.StatusBadRequest)
+			return
+		}
+		months = n
+	}
+
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	WriteICal(w, ExpectedPayments(subs, now, months), s.Currency, now)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectedPayments(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Netflix", Status: StatusActive, TypicalDay: 15, LastDate: date("2025-12-15"), LatestAmount: -99},
+		{Name: "Gym", Status: StatusActive, TypicalDay: 31, LastDate: date("2025-12-31"), LatestAmount: -300},
+		{Name: "Old", Status: StatusStopped, TypicalDay: 5, LastDate: date("2025-06-05"), LatestAmount: -50},
+	}
+
+	payments := ExpectedPayments(subs, date("2026-01-10"), 2)
+
+	expected := []struct {
+		name string
+		date string
+	}{
+		{"Netflix", "2026-01-15"},
+		{"Gym", "2026-01-31"},
+		{"Netflix", "2026-02-15"},
+		{"Gym", "2026-02-28"}, // clamped to the end of February
+	}
+	if len(payments) != len(expected) {
+		t.Fatalf("expected %d payments, got %d: %+v", len(expected), len(payments), payments)
+	}
+	for i, e := range expected {
+		if payments[i].Name != e.name || payments[i].Date.Format("2006-01-02") != e.date {
+			t.Errorf("payment %d: expected %s on %s, got %s on %s", i, e.name, e.date,
+				payments[i].Name, payments[i].Date.Format("2006-01-02"))
+		}
+	}
+	if payments[0].Amount != 99 {
+		t.Errorf("expected absolute amount 99, got %.2f", payments[0].Amount)
+	}
+}
+
+func TestWriteICal(t *testing.T) {
+	payments := []ExpectedPayment{
+		{Name: "Disney+, Family", Date: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), Amount: 119},
+	}
+	var buf bytes.Buffer
+	WriteICal(&buf, payments, GetCurrency("SEK"), time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	ical := buf.String()
+
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART;VALUE=DATE:20260115\r\n",
+		"DTEND;VALUE=DATE:20260116\r\n",
+		"SUMMARY:Disney+\\, Family 119 kr\r\n",
+		"DTSTAMP:20260101T120000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ical, expected) {
+			t.Errorf("expected iCal to contain %q, got:\n%s", expected, ical)
+		}
+	}
+	for _, line := range strings.Split(ical, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+import "regexp"
+
+// Categories of subscriptions. Unlike tags, which are freeform, every subscription has at most one
+// category from this fixed list, so that reports of different users compare.
+const (
+	CategoryStreaming = "streaming" // video streaming and TV
+	CategoryMusic     = "music"     // music, podcasts and audiobooks
+	CategoryGaming    = "gaming"
+	CategorySoftware  = "software" // apps, cloud storage, password managers and developer tools
+	CategorySecurity  = "security" // VPNs and secure mail
+	CategoryNews      = "news"     // news, magazines and e-books
+	CategoryFitness   = "fitness"  // fitness, health and meditation
+	CategoryTelecom   = "telecom"  // phone, internet and TV subscriptions of operators
+	CategoryUtilities = "utilities"
+	CategoryInsurance = "insurance"
+	CategoryOther     = "other"
+)
+
+// Categories lists the categories a subscription can have
+var Categories = []string{
+	CategoryStreaming, CategoryMusic, CategoryGaming, CategorySoftware, CategorySecurity,
+	CategoryNews, CategoryFitness, CategoryTelecom, CategoryUtilities, CategoryInsurance,
+	CategoryOther,
+}
+
+// categoryPatterns classify subscriptions by name, for those without a category in the config.
+// They cover the built-in known patterns and bundles, plus common words of other payees.
+var categoryPatterns = []struct {
+	category string
+	regex    *regexp.Regexp
+}{
+	{CategoryStreaming, categoryRegex(`NETFLIX|DISNEY\s*(\+|PLUS)|HBO\s*MAX|PRIME\s*VIDEO|AMAZON\s*PRIME|APPLE\s*TV|PARAMOUNT\s*(\+|PLUS)|PEACOCK|HULU|CRUNCHYROLL|VIAPLAY|DISCOVERY\+|TV4\s*PLAY|C\s*MORE|SKYSHOWTIME|ALLENTE|YOUTUBE\s*TV|SLING\s*TV|FUBO|PHILO|ESPN|STARZ|SHOWTIME|AMC\s*\+|BRITBOX|MUBI|CURIOSITYSTREAM`)},
+	{CategoryMusic, categoryRegex(`SPOTIFY|APPLE\s*MUSIC|TIDAL|DEEZER|YOUTUBE\s*(MUSIC|PREMIUM)|SOUNDCLOUD|AUDIBLE|STORYTEL|BOOKBEAT|NEXTORY|SIRIUS\s*XM|PANDORA`)},
+	{CategoryGaming, categoryRegex(`XBOX|PLAYSTATION|PS\s*PLUS|NINTENDO|EA\s*PLAY|UBISOFT|GEFORCE\s*NOW`)},
+	{CategorySecurity, categoryRegex(`NORDVPN|EXPRESSVPN|PROTON|SURFSHARK|MULLVAD|VPN`)},
+	{CategorySoftware, categoryRegex(`DROPBOX|GOOGLE\s*(ONE|WORKSPACE|GSUITE)|ICLOUD|ONEDRIVE|MICROSOFT\s*365|OFFICE\s*365|ADOBE|CANVA|NOTION|EVERNOTE|1PASSWORD|LASTPASS|BITWARDEN|DASHLANE|ZOOM|SLACK|DISCORD|GITHUB|GITLAB|JETBRAINS|DIGITALOCEAN|HEROKU|NETLIFY|VERCEL|OPENAI|CHATGPT`)},
+	{CategoryNews, categoryRegex(`NEW\s*YORK\s*TIMES|WASHINGTON\s*POST|WALL\s*STREET\s*JOURNAL|MEDIUM|SUBSTACK|KINDLE|SCRIBD|DAGENS\s*NYHETER|SVENSKA\s*DAGBLADET|AFTONBLADET|EXPRESSEN|TIDNING`)},
+	{CategoryFitness, categoryRegex(`PELOTON|STRAVA|HEADSPACE|CALM|MYFITNESSPAL|FITBIT|GYM|FITNESS|SATS|FRISKIS`)},
+	{CategoryTelecom, categoryRegex(`TELIA|TELE2|TELENOR|HI3G|COMVIQ|HALEBOP|VIMLA|BAHNHOF|BREDBANDSBOLAGET|COM\s*HEM|VERIZON|AT&T|T-MOBILE|XFINITY|COMCAST|SPECTRUM`)},
+	{CategoryUtilities, categoryRegex(`VATTENFALL|ELLEVIO|E\.?ON|FORTUM|TIBBER|GREENELY|ENERGI|ELECTRIC`)},
+	{CategoryInsurance, categoryRegex(`FÖRSÄKRING|FORSAKRING|FOLKSAM|TRYGG[\s-]*HANSA|INSURANCE|GEICO|ALLSTATE`)},
+}
+
+// categoryRegex compiles a category pattern, matched case-insensitively at word starts
+func categoryRegex(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN])(` + pattern + `)`)
+}
+
+// ClassifyCategory returns the category of a subscription name from the built-in patterns, or ""
+// if none matches
+func ClassifyCategory(name string) string {
+	for _, p := range categoryPatterns {
+		if p.regex.MatchString(name) {
+			return p.category
+		}
+	}
+	return ""
+}
+
+// GetCategory returns the category of a subscription: the one in the config, else the one its
+// name is classified as (see ClassifyCategory), or "" if neither
+func (c *Config) GetCategory(name string) string {
+	if c != nil {
+		if category, ok := c.Categories[name]; ok {
+			return category
+		}
+	}
+	return ClassifyCategory(name)
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyCategory(t *testing.T) {
+	tests := map[string]string{
+		"Netflix":              CategoryStreaming,
+		"SPOTIFY P3E460":       CategoryMusic,
+		"Xbox Game Pass":       CategoryGaming,
+		"GitHub":               CategorySoftware,
+		"NordVPN":              CategorySecurity,
+		"Dagens Nyheter":       CategoryNews,
+		"SATS Sweden":          CategoryFitness,
+		"TELIA SVERIGE":        CategoryTelecom,
+		"Göteborg Energi":      CategoryUtilities,
+		"Folksam Försäkring":   CategoryInsurance,
+		"Grocery Store":        "",
+		"Supernetflixfan shop": "", // only at the start of a word
+	}
+	for name, want := range tests {
+		if got := ClassifyCategory(name); got != want {
+			t.Errorf("ClassifyCategory(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConfig_Categories(t *testing.T) {
+	cfg, err := parseConfig([]byte("categories:\n  Netflix: other\n  Gym Membership: fitness\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.GetCategory("Netflix"); got != CategoryOther {
+		t.Errorf("expected the config to override the classification, got %q", got)
+	}
+	if got := cfg.GetCategory("Spotify"); got != CategoryMusic {
+		t.Errorf("expected Spotify classified by name, got %q", got)
+	}
+
+	if _, err := parseConfig([]byte("categories:\n  Netflix: movies\n")); err == nil || !strings.Contains(err.Error(), "must be one of streaming, music") {
+		t.Errorf("expected an error for an unknown category, got %v", err)
+	}
+}
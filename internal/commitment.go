@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Kinds of recurring commitments: payments that recur like subscriptions but aren't consumer
+// services, so they are listed in their own section instead of with the subscriptions
+const (
+	CommitmentTransfer  = "transfer"  // transfers to own accounts, e.g. monthly savings
+	CommitmentLoan      = "loan"      // loan and mortgage payments, including student loans (CSN)
+	CommitmentRent      = "rent"      // rent and housing association fees
+	CommitmentInsurance = "insurance" // insurance premiums
+
+	// CommitmentNone is the kind of commitment rules that keep matching payees subscriptions,
+	// e.g. a savings app that a default rule would take for a transfer
+	CommitmentNone = "subscription"
+)
+
+// CommitmentKinds lists the kinds a commitment rule can have
+var CommitmentKinds = []string{CommitmentTransfer, CommitmentLoan, CommitmentRent, CommitmentInsurance, CommitmentNone}
+
+// CommitmentRule classifies the subscriptions whose name matches a pattern as recurring
+// commitments of a kind
+type CommitmentRule struct {
+	Pattern string `yaml:"pattern"` // regex matched against subscription names (case-insensitive)
+	Kind    string `yaml:"kind"`    // one of CommitmentKinds
+
+	regex *regexp.Regexp `yaml:"-"`
+}
+
+// DefaultCommitmentRules classify transfers, loan payments, rent and insurance premiums by words
+// in their names (English and Swedish), as these would dominate the subscriptions otherwise. They
+// apply after the rules of the config, unless use_default_commitments is false, and
+// disable_default_commitments leaves out those of some kinds.
+var DefaultCommitmentRules = []CommitmentRule{
+	{Pattern: `(^|[^\pL])(transfer|överföring|överf|savings?|sparande|sparkonto|own account|eget konto|isk|standing order)([^\pL]|$)`, Kind: CommitmentTransfer},
+	{Pattern: `(^|[^\pL])(loan|lån|bolån|mortgage|amortering|amortization|interest|ränta|csn|studielån)([^\pL]|$)`, Kind: CommitmentLoan},
+	{Pattern: `(^|[^\pL])(rent|hyra|hyran|hyresavi|brf|bostadsrättsförening|hoa)([^\pL]|$)`, Kind: CommitmentRent},
+	{Pattern: `försäkr|forsakr|(^|[^\pL])(insurance|folksam|trygg[\s-]*hansa|länsförsäkringar)([^\pL]|$)`, Kind: CommitmentInsurance},
+}
+
+// defaultCommitmentRules are the compiled DefaultCommitmentRules
+var defaultCommitmentRules = func() []CommitmentRule {
+	rules := slices.Clone(DefaultCommitmentRules)
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			panic(err)
+		}
+	}
+	return rules
+}()
+
+// compile validates the kind and compiles the pattern of the rule
+func (r *CommitmentRule) compile() error {
+	if !slices.Contains(CommitmentKinds, r.Kind) {
+		return fmt.Errorf("invalid commitment kind %q for %q (must be one of %s)", r.Kind, r.Pattern, strings.Join(CommitmentKinds, ", "))
+	}
+	re, err := regexp.Compile("(?i)" + r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid commitment pattern %q: %w", r.Pattern, err)
+	}
+	r.regex = re
+	return nil
+}
+
+// CommitmentKind returns the kind of recurring commitment a subscription is, by the first rule
+// whose pattern matches its name (those of the config first, then the default ones), or "" if it
+// is a subscription
+func (c *Config) CommitmentKind(sub Subscription) string {
+	var rules []CommitmentRule
+	if c != nil {
+		rules = c.Commitments
+	}
+	if c == nil || c.UseDefaultCommitments == nil || *c.UseDefaultCommitments {
+		for _, rule := range defaultCommitmentRules {
+			if c == nil || !slices.Contains(c.DisableDefaultCommitments, rule.Kind) {
+				rules = append(slices.Clip(rules), rule)
+			}
+		}
+	}
+	for _, rule := range rules {
+		if rule.regex != nil && rule.regex.MatchString(sub.Name) {
+			if rule.Kind == CommitmentNone {
+				return ""
+			}
+			return rule.Kind
+		}
+	}
+	return ""
+}
+
+// SplitCommitments separates the recurring commitments (see CommitmentKind) from the
+// subscriptions, setting their Commitment kind. Both keep their order.
+func SplitCommitments(subs []Subscription, cfg *Config) (subscriptions, commitments []Subscription) {
+	for _, sub := range subs {
+		if kind := cfg.CommitmentKind(sub); kind != "" {
+			sub.Commitment = kind
+			commitments = append(commitments, sub)
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, commitments
+}
+
+// PrintCommitments lists recurring commitments with their own total, which isn't part of the
+// subscription totals
+func PrintCommitments(w io.Writer, commitments []Subscription, opts OutputOptions, cfg *Config) {
+	loc := opts.Locale
+	fmt.Fprint(w, loc.T("Recurring commitments (not in totals):\n"))
+	SortSubscriptions(commitments, opts.SortField, opts.SortDir, cfg)
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Name"), loc.T("Kind"), loc.T("Status"), loc.T("Day"), loc.T("Last Seen"), loc.T("Monthly"), loc.T("Yearly")})
+	for _, sub := range commitments {
+		status := text.FgGreen.Sprint(loc.T("ACTIVE"))
+		yearly := opts.Currency.Format(sub.MonthlyCost() * 12)
+		if sub.Status == StatusStopped {
+			status = text.FgRed.Sprint(loc.T("STOPPED"))
+			yearly = text.FgHiBlack.Sprint("-")
+		}
+		t.AppendRow(table.Row{sub.Name, sub.Commitment, status, loc.FormatDay(sub.TypicalDay), loc.FormatDate(sub.LastDate), opts.Currency.Format(math.Abs(sub.LatestAmount)), yearly})
+	}
+	t.AppendSeparator()
+	total := ActiveMonthlyTotal(commitments)
+	t.AppendFooter(table.Row{"", "", "", "", text.Bold.Sprint(loc.T("Total (active)")), text.Bold.Sprint(opts.Currency.Format(total)), text.Bold.Sprint(opts.Currency.Format(total * 12))})
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 6, Align: text.AlignRight}, {Number: 7, Align: text.AlignRight}})
+	t.Render()
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestSplitCommitments(t *testing.T) {
+	cfg, err := parseConfig([]byte(`commitments:
+  - pattern: "^Avanza"
+    kind: transfer
+  - pattern: "Savings Club"
+    kind: subscription
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs := []Subscription{
+		{Name: "Netflix"},
+		{Name: "Överföring sparkonto"},
+		{Name: "Bolån SBAB"},
+		{Name: "AVANZA BANK"},
+		{Name: "Savings Club"},    // kept by the config despite the default rule
+		{Name: "Interesting Mag"}, // "interest" only as a whole word
+		{Name: "HYRA BOSTADS AB"},
+		{Name: "CSN"},
+		{Name: "Hemförsäkring Folksam"},
+	}
+
+	subscriptions, commitments := SplitCommitments(subs, cfg)
+	var got []string
+	for _, c := range commitments {
+		got = append(got, c.Name+"="+c.Commitment)
+	}
+	if want := "Överföring sparkonto=transfer Bolån SBAB=loan AVANZA BANK=transfer HYRA BOSTADS AB=rent CSN=loan Hemförsäkring Folksam=insurance"; strings.Join(got, " ") != want {
+		t.Errorf("commitments = %s, want %s", strings.Join(got, " "), want)
+	}
+	if len(subscriptions) != 3 || subscriptions[1].Name != "Savings Club" {
+		t.Errorf("expected the other subscriptions kept in order, got %+v", subscriptions)
+	}
+
+	noInsurance, err := parseConfig([]byte("disable_default_commitments: [insurance]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscriptions, _ := SplitCommitments(subs, noInsurance); subscriptions[len(subscriptions)-1].Name != "Hemförsäkring Folksam" {
+		t.Errorf("expected insurance kept a subscription with its default rule disabled, got %+v", subscriptions)
+	}
+	if _, err := parseConfig([]byte("disable_default_commitments: [subscription]\n")); err == nil {
+		t.Error("expected an error for disabling an unknown kind")
+	}
+
+	noDefaults, err := parseConfig([]byte("use_default_commitments: false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, commitments := SplitCommitments(subs, noDefaults); len(commitments) != 0 {
+		t.Errorf("expected no commitments without the default rules, got %+v", commitments)
+	}
+
+	if _, err := parseConfig([]byte("commitments:\n  - pattern: Avanza\n    kind: savings\n")); err == nil || !strings.Contains(err.Error(), "invalid commitment kind") {
+		t.Errorf("expected an error for an unknown kind, got %v", err)
+	}
+}
+
+func TestAnalyze_LeavesOutCommitments(t *testing.T) {
+	var txs []Transaction
+	for _, month := range []string{"01", "02", "03", "04"} {
+		txs = append(txs,
+			Transaction{Date: date("2025-" + month + "-01"), Text: "HYRA BRF EKEN", Amount: -8500},
+			Transaction{Date: date("2025-" + month + "-20"), Text: "CSN", Amount: -1600},
+			Transaction{Date: date("2025-" + month + "-15"), Text: "Netflix", Amount: -99})
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Name != "Netflix" || ActiveMonthlyTotal(subs) != 99 {
+		t.Errorf("expected only Netflix in the subscriptions and totals, got %+v", subs)
+	}
+
+	keepRent, err := parseConfig([]byte("disable_default_commitments: [rent]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subs, _, _ := NewDetector().Analyze(context.Background(), txs, keepRent); len(subs) != 2 {
+		t.Errorf("expected the rent kept a subscription with its default rule disabled, got %+v", subs)
+	}
+}
+
+func TestPrintCommitments(t *testing.T) {
+	commitments := []Subscription{
+		{Name: "Bolån SBAB", Commitment: CommitmentLoan, Status: StatusActive, LatestAmount: -5400, LastDate: date("2025-04-28")},
+		{Name: "Överföring", Commitment: CommitmentTransfer, Status: StatusActive, LatestAmount: -2000, LastDate: date("2025-04-25")},
+	}
+	var buf bytes.Buffer
+	PrintCommitments(&buf, commitments, OutputOptions{Currency: GetCurrency("USD"), Locale: GetLocale(language.AmericanEnglish)}, nil)
+	out := buf.String()
+	if !strings.Contains(out, "Recurring commitments (not in totals):") || !strings.Contains(out, "loan") || !strings.Contains(out, "7,400") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
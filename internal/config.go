@@ -1,11 +1,18 @@
 package internal
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
@@ -40,105 +47,122 @@ type KnownSubscription struct {
 	MaxAmount *float64 `yaml:"max_amount,omitempty"` // Optional maximum amount (absolute value)
 	Before    string   `yaml:"before,omitempty"`     // Only match transactions before this date
 	After     string   `yaml:"after,omitempty"`      // Only match transactions after this date
+	Interval  string   `yaml:"interval,omitempty"`   // Billing interval (monthly, quarterly or yearly); detected from the payments if not set
+
+	// maxUSD is the default upper bound of a built-in pattern, in US dollars (see ScaleKnownBounds)
+	maxUSD float64 `yaml:"-"`
 
 	// compiled fields
 	regex      *regexp.Regexp `yaml:"-"`
 	beforeDate time.Time      `yaml:"-"`
 	afterDate  time.Time      `yaml:"-"`
+	interval   Interval       `yaml:"-"`
+}
+
+// disabledBy reports whether a disable_known entry refers to the pattern: the pattern itself
+// (case-insensitive), or a name the pattern matches in full
+func (k KnownSubscription) disabledBy(entry string) bool {
+	if strings.EqualFold(k.Pattern, entry) {
+		return true
+	}
+	re, err := regexp.Compile("(?i)^(?:" + k.Pattern + ")$")
+	return err == nil && re.MatchString(entry)
 }
 
 // DefaultKnownSubscriptions contains patterns for common subscription services.
-// These are automatically included unless disabled via use_default_known: false
+// These are automatically included unless disabled via use_default_known: false.
+// Each has a generous upper bound on the amount, enough for annual plans (see ScaleKnownBounds),
+// so that hardware or gift cards bought at the same merchants aren't detected as subscriptions.
 var DefaultKnownSubscriptions = []KnownSubscription{
 	// Video streaming
-	{Pattern: "NETFLIX"},
-	{Pattern: "DISNEY\\+"},
-	{Pattern: "DISNEYPLUS"},
-	{Pattern: "HBO\\s*MAX"},
-	{Pattern: "HBOMAX"},
-	{Pattern: "AMAZON\\s*PRIME"},
-	{Pattern: "PRIME\\s*VIDEO"},
-	{Pattern: "APPLE\\s*TV"},
-	{Pattern: "PARAMOUNT\\+"},
-	{Pattern: "PARAMOUNTPLUS"},
-	{Pattern: "PEACOCK"},
-	{Pattern: "HULU"},
-	{Pattern: "CRUNCHYROLL"},
-	{Pattern: "VIAPLAY"},
-	{Pattern: "DISCOVERY\\+"},
+	{Pattern: "NETFLIX", maxUSD: 300},
+	{Pattern: "DISNEY\\+", maxUSD: 300},
+	{Pattern: "DISNEYPLUS", maxUSD: 300},
+	{Pattern: "HBO\\s*MAX", maxUSD: 300},
+	{Pattern: "HBOMAX", maxUSD: 300},
+	{Pattern: "AMAZON\\s*PRIME", maxUSD: 300},
+	{Pattern: "PRIME\\s*VIDEO", maxUSD: 300},
+	{Pattern: "APPLE\\s*TV", maxUSD: 300},
+	{Pattern: "PARAMOUNT\\+", maxUSD: 300},
+	{Pattern: "PARAMOUNTPLUS", maxUSD: 300},
+	{Pattern: "PEACOCK", maxUSD: 300},
+	{Pattern: "HULU", maxUSD: 300},
+	{Pattern: "CRUNCHYROLL", maxUSD: 300},
+	{Pattern: "VIAPLAY", maxUSD: 300},
+	{Pattern: "DISCOVERY\\+", maxUSD: 300},
 
 	// Music streaming
-	{Pattern: "SPOTIFY"},
-	{Pattern: "APPLE\\s*MUSIC"},
-	{Pattern: "TIDAL"},
-	{Pattern: "DEEZER"},
-	{Pattern: "YOUTUBE\\s*(MUSIC|PREMIUM)"},
-	{Pattern: "SOUNDCLOUD"},
-	{Pattern: "AUDIBLE"},
+	{Pattern: "SPOTIFY", maxUSD: 250},
+	{Pattern: "APPLE\\s*MUSIC", maxUSD: 250},
+	{Pattern: "TIDAL", maxUSD: 250},
+	{Pattern: "DEEZER", maxUSD: 250},
+	{Pattern: "YOUTUBE\\s*(MUSIC|PREMIUM)", maxUSD: 250},
+	{Pattern: "SOUNDCLOUD", maxUSD: 250},
+	{Pattern: "AUDIBLE", maxUSD: 250},
 
 	// Gaming
-	{Pattern: "XBOX\\s*(GAME\\s*PASS|LIVE)"},
-	{Pattern: "PLAYSTATION\\s*(PLUS|NOW)"},
-	{Pattern: "PS\\s*PLUS"},
-	{Pattern: "NINTENDO\\s*ONLINE"},
-	{Pattern: "EA\\s*PLAY"},
-	{Pattern: "UBISOFT\\+"},
-	{Pattern: "GEFORCE\\s*NOW"},
+	{Pattern: "XBOX\\s*(GAME\\s*PASS|LIVE)", maxUSD: 250},
+	{Pattern: "PLAYSTATION\\s*(PLUS|NOW)", maxUSD: 250},
+	{Pattern: "PS\\s*PLUS", maxUSD: 250},
+	{Pattern: "NINTENDO\\s*ONLINE", maxUSD: 250},
+	{Pattern: "EA\\s*PLAY", maxUSD: 250},
+	{Pattern: "UBISOFT\\+", maxUSD: 250},
+	{Pattern: "GEFORCE\\s*NOW", maxUSD: 250},
 
 	// Cloud storage & productivity
-	{Pattern: "DROPBOX"},
-	{Pattern: "GOOGLE\\s*(ONE|WORKSPACE|GSUITE)"},
-	{Pattern: "ICLOUD"},
-	{Pattern: "ONEDRIVE"},
-	{Pattern: "MICROSOFT\\s*365"},
-	{Pattern: "OFFICE\\s*365"},
-	{Pattern: "ADOBE"},
-	{Pattern: "CANVA"},
-	{Pattern: "NOTION"},
-	{Pattern: "EVERNOTE"},
-	{Pattern: "1PASSWORD"},
-	{Pattern: "LASTPASS"},
-	{Pattern: "BITWARDEN"},
-	{Pattern: "DASHLANE"},
+	{Pattern: "DROPBOX", maxUSD: 1000},
+	{Pattern: "GOOGLE\\s*(ONE|WORKSPACE|GSUITE)", maxUSD: 1000},
+	{Pattern: "ICLOUD", maxUSD: 1000},
+	{Pattern: "ONEDRIVE", maxUSD: 1000},
+	{Pattern: "MICROSOFT\\s*365", maxUSD: 1000},
+	{Pattern: "OFFICE\\s*365", maxUSD: 1000},
+	{Pattern: "ADOBE", maxUSD: 1000},
+	{Pattern: "CANVA", maxUSD: 1000},
+	{Pattern: "NOTION", maxUSD: 1000},
+	{Pattern: "EVERNOTE", maxUSD: 1000},
+	{Pattern: "1PASSWORD", maxUSD: 1000},
+	{Pattern: "LASTPASS", maxUSD: 1000},
+	{Pattern: "BITWARDEN", maxUSD: 1000},
+	{Pattern: "DASHLANE", maxUSD: 1000},
 
 	// Communication
-	{Pattern: "ZOOM"},
-	{Pattern: "SLACK"},
-	{Pattern: "DISCORD\\s*NITRO"},
+	{Pattern: "ZOOM", maxUSD: 500},
+	{Pattern: "SLACK", maxUSD: 500},
+	{Pattern: "DISCORD\\s*NITRO", maxUSD: 500},
 
 	// VPN & security
-	{Pattern: "NORDVPN"},
-	{Pattern: "EXPRESSVPN"},
-	{Pattern: "SURFSHARK"},
-	{Pattern: "MULLVAD"},
-	{Pattern: "PROTONVPN"},
-	{Pattern: "PROTON\\s*(MAIL|DRIVE)"},
+	{Pattern: "NORDVPN", maxUSD: 400},
+	{Pattern: "EXPRESSVPN", maxUSD: 400},
+	{Pattern: "SURFSHARK", maxUSD: 400},
+	{Pattern: "MULLVAD", maxUSD: 400},
+	{Pattern: "PROTONVPN", maxUSD: 400},
+	{Pattern: "PROTON\\s*(MAIL|DRIVE)", maxUSD: 400},
 
 	// News & reading
-	{Pattern: "NEW\\s*YORK\\s*TIMES"},
-	{Pattern: "WASHINGTON\\s*POST"},
-	{Pattern: "WALL\\s*STREET\\s*JOURNAL"},
-	{Pattern: "MEDIUM"},
-	{Pattern: "SUBSTACK"},
-	{Pattern: "KINDLE\\s*UNLIMITED"},
-	{Pattern: "SCRIBD"},
+	{Pattern: "NEW\\s*YORK\\s*TIMES", maxUSD: 600},
+	{Pattern: "WASHINGTON\\s*POST", maxUSD: 600},
+	{Pattern: "WALL\\s*STREET\\s*JOURNAL", maxUSD: 600},
+	{Pattern: "MEDIUM", maxUSD: 600},
+	{Pattern: "SUBSTACK", maxUSD: 600},
+	{Pattern: "KINDLE\\s*UNLIMITED", maxUSD: 600},
+	{Pattern: "SCRIBD", maxUSD: 600},
 
 	// Fitness & health
-	{Pattern: "PELOTON"},
-	{Pattern: "STRAVA"},
-	{Pattern: "HEADSPACE"},
-	{Pattern: "CALM"},
-	{Pattern: "MYFITNESSPAL"},
-	{Pattern: "FITBIT\\s*PREMIUM"},
+	{Pattern: "PELOTON", maxUSD: 600},
+	{Pattern: "STRAVA", maxUSD: 600},
+	{Pattern: "HEADSPACE", maxUSD: 600},
+	{Pattern: "CALM", maxUSD: 600},
+	{Pattern: "MYFITNESSPAL", maxUSD: 600},
+	{Pattern: "FITBIT\\s*PREMIUM", maxUSD: 600},
 
 	// Developer tools
-	{Pattern: "GITHUB"},
-	{Pattern: "GITLAB"},
-	{Pattern: "JETBRAINS"},
-	{Pattern: "DIGITALOCEAN"},
-	{Pattern: "HEROKU"},
-	{Pattern: "NETLIFY"},
-	{Pattern: "VERCEL"},
+	{Pattern: "GITHUB", maxUSD: 1000},
+	{Pattern: "GITLAB", maxUSD: 1000},
+	{Pattern: "JETBRAINS", maxUSD: 1000},
+	{Pattern: "DIGITALOCEAN", maxUSD: 1000},
+	{Pattern: "HEROKU", maxUSD: 1000},
+	{Pattern: "NETLIFY", maxUSD: 1000},
+	{Pattern: "VERCEL", maxUSD: 1000},
 }
 
 type Config struct {
@@ -148,24 +172,116 @@ type Config struct {
 	// Tags maps subscription names to a list of tags (e.g., "entertainment", "utilities")
 	Tags map[string][]string `yaml:"tags,omitempty"`
 
+	// Categories maps subscription names to their category (one of Categories), overriding the
+	// one they are classified as from their name (see ClassifyCategory)
+	Categories map[string]string `yaml:"categories,omitempty"`
+
 	// Groups allows combining multiple transaction patterns into one subscription
 	Groups []Group `yaml:"groups,omitempty"`
 
+	// Rename maps payee names to the name to use instead. A rule applies to every transaction text
+	// that equals its name ignoring case, whitespace and trailing digits (see renameKey), e.g.
+	// "SPOTIFY: Spotify" renames "SPOTIFY 1234" and "spotify  5678". Renames apply before groups.
+	Rename map[string]string `yaml:"rename,omitempty"`
+
+	// SplitByAmount splits payees that bill several subscriptions at different price points (e.g.,
+	// APPLE.COM/BILL) into one subscription per amount band. Keys are payee names after renames and
+	// groups; payments within no band keep the payee name.
+	SplitByAmount map[string][]AmountBand `yaml:"split_by_amount,omitempty"`
+
+	// Split maps names of shared subscriptions to your share of the cost (e.g., 0.5 for half)
+	Split map[string]float64 `yaml:"split,omitempty"`
+
+	// SharedWith maps names of shared subscriptions to the number of people paying equal shares
+	SharedWith map[string]int `yaml:"shared_with,omitempty"`
+
+	// Business maps names of subscriptions that are business expenses to the VAT rate included in
+	// their amounts (e.g., 0.25 for 25%, 0 without VAT)
+	Business map[string]float64 `yaml:"business,omitempty"`
+
 	// UseDefaultKnown controls whether to include built-in known subscription patterns.
 	// Defaults to true. Set to false to disable all default patterns.
 	UseDefaultKnown *bool `yaml:"use_default_known,omitempty"`
 
+	// DisableKnown lists built-in or bundle known patterns to leave out, by pattern (e.g., GITHUB)
+	// or by a name the pattern matches in full (e.g., "HBO Max")
+	DisableKnown []string `yaml:"disable_known,omitempty"`
+
+	// KnownBundles lists bundles of known subscription patterns to include (see KnownBundles),
+	// e.g. swedish-utilities
+	KnownBundles []string `yaml:"known_bundles,omitempty"`
+
 	// Known lists subscriptions that should be detected immediately (even with 1 occurrence)
 	Known []KnownSubscription `yaml:"known,omitempty"`
 
+	// KnownRequireRecurrence marks known subscriptions with a single payment as possible
+	// subscriptions (StatusPossible), which aren't counted in totals, since one charge may be a
+	// one-off purchase
+	KnownRequireRecurrence bool `yaml:"known_require_recurrence,omitempty"`
+
+	// Commitments classifies subscriptions as recurring commitments, such as transfers to savings
+	// and loan payments, which are listed in their own section (see CommitmentKind)
+	Commitments []CommitmentRule `yaml:"commitments,omitempty"`
+
+	// UseDefaultCommitments controls whether to apply the built-in commitment rules
+	// (DefaultCommitmentRules) after those of the config. Defaults to true.
+	UseDefaultCommitments *bool `yaml:"use_default_commitments,omitempty"`
+
+	// DisableDefaultCommitments lists kinds of recurring commitments (e.g., insurance) whose
+	// built-in rules to leave out, so that matching payees stay subscriptions
+	DisableDefaultCommitments []string `yaml:"disable_default_commitments,omitempty"`
+
 	// Exclude is a list of exclusion rules (can be strings or objects with time bounds)
 	Exclude []yaml.Node `yaml:"exclude,omitempty"`
 
 	// Currency is the currency code for formatting (e.g., "SEK", "USD", "EUR")
 	Currency string `yaml:"currency,omitempty"`
 
+	// Country is the country code (ISO 3166, e.g. "SE") of the list prices that --compare-prices
+	// compares against; by default the country of the currency, if it has a single one
+	Country string `yaml:"country,omitempty"`
+
+	// PriceDatabase is the path of a price database (JSON, see PriceDatabase) to use instead of
+	// the bundled one, e.g. with newer prices
+	PriceDatabase string `yaml:"price_database,omitempty"`
+
+	// Precision is the number of decimals in displayed amounts (default: 0, whole units)
+	Precision int `yaml:"precision,omitempty"`
+
+	// Notify lists webhooks that receive a summary of changes since the last snapshot
+	Notify []NotifierConfig `yaml:"notify,omitempty"`
+
+	// Plugins lists external programs that add notifier types and output formats
+	Plugins []Plugin `yaml:"plugins,omitempty"`
+
+	// Budgets sets monthly spending limits for active subscriptions, overall and per tag
+	Budgets *Budgets `yaml:"budgets,omitempty"`
+
+	// CPI is a consumer price index table (year: index) for inflation-adjusted amounts (--real),
+	// instead of the built-in one for USD
+	CPI CPI `yaml:"cpi,omitempty"`
+
+	// Strategies lists the detection strategies to run, in order (default: known-patterns, monthly)
+	Strategies []string `yaml:"strategies,omitempty"`
+
+	// Templates holds YAML anchors to reuse in other sections (e.g., shared group settings with
+	// "<<: *streaming"); it isn't used otherwise
+	Templates yaml.Node `yaml:"templates,omitempty"`
+
 	// compiled exclusion rules (not serialized)
 	excludeRules []ExcludeRule `yaml:"-"`
+
+	// rename targets by renameKey of the rule names (not serialized)
+	renames map[string]string `yaml:"-"`
+
+	// amount bands of split_by_amount by folded payee name (not serialized)
+	splitByAmount map[string][]AmountBand `yaml:"-"`
+
+	// resolved detection strategies (not serialized)
+	strategies []Strategy `yaml:"-"`
+
+	// matcher for the compiled known patterns (not serialized)
+	knownMatcher *knownMatcher `yaml:"-"`
 }
 
 // DefaultConfigPath returns the default config file path (~/.subscription-detector/config.yaml)
@@ -193,18 +309,39 @@ func NewDefaultConfig() (*Config, error) {
 		}
 		cfg.Known[i].regex = re
 	}
+	cfg.knownMatcher = newKnownMatcher(cfg.Known)
 
 	return cfg, nil
 }
 
+// LoadConfig loads and validates the config file at path. Parse and validation failures
+// wrap ErrInvalidConfig.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
 
+// parseConfig parses, validates and compiles a config
+func parseConfig(data []byte) (*Config, error) {
+	// Unknown keys are errors, as a mistyped key would otherwise be silently ignored
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+	if err := checkKeys(&doc, reflect.TypeOf(Config{})); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
 
@@ -219,6 +356,31 @@ func LoadConfig(path string) (*Config, error) {
 		}
 	}
 
+	// Compile rename rules
+	for name, to := range cfg.Rename {
+		key := renameKey(name)
+		if key == "" || strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("invalid rename %q: %q (both names must have more than digits)", name, to)
+		}
+		if cfg.renames == nil {
+			cfg.renames = make(map[string]string)
+		}
+		cfg.renames[key] = to
+	}
+
+	// Validate amount bands
+	for name, bands := range cfg.SplitByAmount {
+		for _, band := range bands {
+			if err := band.validate(); err != nil {
+				return nil, fmt.Errorf("invalid split_by_amount band of %q: %w", name, err)
+			}
+		}
+		if cfg.splitByAmount == nil {
+			cfg.splitByAmount = make(map[string][]AmountBand)
+		}
+		cfg.splitByAmount[foldPayee(name)] = bands
+	}
+
 	// Parse exclude rules (supports both strings and objects)
 	for _, node := range cfg.Exclude {
 		var rule ExcludeRule
@@ -228,6 +390,9 @@ func LoadConfig(path string) (*Config, error) {
 			rule.Pattern = node.Value
 		} else if node.Kind == yaml.MappingNode {
 			// Object with pattern and optional time bounds
+			if err := checkKeys(&node, reflect.TypeOf(rule)); err != nil {
+				return nil, fmt.Errorf("parsing exclude rule: %w", err)
+			}
 			if err := node.Decode(&rule); err != nil {
 				return nil, fmt.Errorf("parsing exclude rule: %w", err)
 			}
@@ -235,42 +400,126 @@ func LoadConfig(path string) (*Config, error) {
 			return nil, fmt.Errorf("invalid exclude rule format")
 		}
 
-		// Compile regex
-		re, err := regexp.Compile(rule.Pattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid exclude pattern %q: %w", rule.Pattern, err)
+		if err := rule.compile(); err != nil {
+			return nil, err
 		}
-		rule.regex = re
+		cfg.excludeRules = append(cfg.excludeRules, rule)
+	}
 
-		// Parse time bounds
-		if rule.Before != "" {
-			t, err := time.Parse("2006-01-02", rule.Before)
-			if err != nil {
-				return nil, fmt.Errorf("invalid 'before' date %q: %w", rule.Before, err)
-			}
-			rule.beforeDate = t
+	// Compile commitment rules
+	for i := range cfg.Commitments {
+		if err := cfg.Commitments[i].compile(); err != nil {
+			return nil, err
 		}
-		if rule.After != "" {
-			t, err := time.Parse("2006-01-02", rule.After)
-			if err != nil {
-				return nil, fmt.Errorf("invalid 'after' date %q: %w", rule.After, err)
-			}
-			rule.afterDate = t
+	}
+	for _, kind := range cfg.DisableDefaultCommitments {
+		if kind == CommitmentNone || !slices.Contains(CommitmentKinds, kind) {
+			return nil, fmt.Errorf("invalid commitment kind %q in disable_default_commitments", kind)
 		}
+	}
 
-		cfg.excludeRules = append(cfg.excludeRules, rule)
+	// Validate categories
+	for name, category := range cfg.Categories {
+		if !slices.Contains(Categories, category) {
+			return nil, fmt.Errorf("invalid category %q for %q (must be one of %s)", category, name, strings.Join(Categories, ", "))
+		}
+	}
+
+	// Validate shares
+	for name, share := range cfg.Split {
+		if share <= 0 || share > 1 {
+			return nil, fmt.Errorf("invalid split %v for %q (must be more than 0 and at most 1)", share, name)
+		}
+		if _, ok := cfg.SharedWith[name]; ok {
+			return nil, fmt.Errorf("%q has both a split and shared_with", name)
+		}
+	}
+	for name, people := range cfg.SharedWith {
+		if people < 1 {
+			return nil, fmt.Errorf("invalid shared_with %d for %q (must be at least 1)", people, name)
+		}
+	}
+
+	// Validate VAT rates
+	for name, rate := range cfg.Business {
+		if rate < 0 || rate >= 1 {
+			return nil, fmt.Errorf("invalid VAT rate %v for business expense %q (must be at least 0 and less than 1)", rate, name)
+		}
+	}
+
+	// Validate plugins and notifiers
+	pluginNames := make(map[string]bool)
+	for i, p := range cfg.Plugins {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid plugin %d: %w", i+1, err)
+		}
+		if pluginNames[p.Type+"\x00"+p.Name] {
+			return nil, fmt.Errorf("%s plugin %q defined twice", p.Type, p.Name)
+		}
+		pluginNames[p.Type+"\x00"+p.Name] = true
+	}
+	for i, n := range cfg.Notify {
+		_, registered := GetNotifier(n.Type)
+		if err := n.validate(registered || cfg.hasPlugin(n.Type, PluginNotifier)); err != nil {
+			return nil, fmt.Errorf("invalid notifier %d: %w", i+1, err)
+		}
+	}
+
+	if err := cfg.Budgets.validate(); err != nil {
+		return nil, fmt.Errorf("invalid budgets: %w", err)
+	}
+	if err := cfg.CPI.validate(); err != nil {
+		return nil, fmt.Errorf("invalid cpi: %w", err)
+	}
+
+	// Resolve detection strategies
+	seen := make(map[string]bool)
+	for _, name := range cfg.Strategies {
+		if seen[name] {
+			return nil, fmt.Errorf("strategy %q listed twice", name)
+		}
+		seen[name] = true
+		strategy, err := StrategyByName(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.strategies = append(cfg.strategies, strategy)
 	}
 
-	// Merge default known subscriptions with user-defined ones (defaults come first)
-	// UseDefaultKnown defaults to true if not specified
-	useDefaults := cfg.UseDefaultKnown == nil || *cfg.UseDefaultKnown
-	if useDefaults {
-		// Prepend defaults so user patterns take precedence (matched first)
-		allKnown := make([]KnownSubscription, 0, len(DefaultKnownSubscriptions)+len(cfg.Known))
-		allKnown = append(allKnown, DefaultKnownSubscriptions...)
-		allKnown = append(allKnown, cfg.Known...)
-		cfg.Known = allKnown
+	// Merge default known subscriptions and bundles with user-defined ones (defaults come first,
+	// then bundles in the listed order). UseDefaultKnown defaults to true if not specified.
+	var included []KnownSubscription
+	if cfg.UseDefaultKnown == nil || *cfg.UseDefaultKnown {
+		included = append(included, DefaultKnownSubscriptions...)
+	}
+	seenBundles := make(map[string]bool)
+	for _, name := range cfg.KnownBundles {
+		bundle, ok := KnownBundles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown known_bundles entry %q (available: %s)", name, strings.Join(KnownBundleNames(), ", "))
+		}
+		if seenBundles[name] {
+			return nil, fmt.Errorf("known bundle %q listed twice", name)
+		}
+		seenBundles[name] = true
+		included = append(included, bundle...)
 	}
+	for _, entry := range cfg.DisableKnown {
+		n := len(included)
+		included = slices.DeleteFunc(included, func(k KnownSubscription) bool { return k.disabledBy(entry) })
+		if len(included) == n {
+			return nil, fmt.Errorf("disable_known entry %q doesn't match any built-in or bundle known pattern", entry)
+		}
+	}
+	// A user pattern that is the same as an included one replaces it, e.g. to change its bounds
+	included = slices.DeleteFunc(included, func(k KnownSubscription) bool {
+		return slices.ContainsFunc(cfg.Known, func(u KnownSubscription) bool { return strings.EqualFold(u.Pattern, k.Pattern) })
+	})
+	if len(included) > 0 {
+		// Prepend them so user patterns take precedence (matched first)
+		cfg.Known = append(included, cfg.Known...)
+	}
+	cfg.ScaleKnownBounds(cfg.Currency)
 
 	// Compile known subscription patterns
 	for i := range cfg.Known {
@@ -295,7 +544,15 @@ func LoadConfig(path string) (*Config, error) {
 			}
 			cfg.Known[i].afterDate = t
 		}
+		if cfg.Known[i].Interval != "" {
+			interval, err := parseInterval(cfg.Known[i].Interval)
+			if err != nil {
+				return nil, fmt.Errorf("known subscription %q: %w", cfg.Known[i].Pattern, err)
+			}
+			cfg.Known[i].interval = interval
+		}
 	}
+	cfg.knownMatcher = newKnownMatcher(cfg.Known)
 
 	return &cfg, nil
 }
@@ -324,27 +581,73 @@ func (c *Config) Save(path string) error {
 // ShouldExclude returns true if the subscription matches any exclude rule
 // considering time bounds against the subscription's date range
 func (c *Config) ShouldExclude(sub Subscription) bool {
+	return c.ExcludedBy(sub) != nil
+}
+
+// ExcludedBy returns the first exclusion rule that matches a subscription, or nil
+func (c *Config) ExcludedBy(sub Subscription) *ExcludeRule {
 	if c == nil {
-		return false
+		return nil
 	}
-	for _, rule := range c.excludeRules {
-		if !rule.regex.MatchString(sub.Name) {
-			continue
+	for i := range c.excludeRules {
+		if c.excludeRules[i].Matches(sub) {
+			return &c.excludeRules[i]
 		}
+	}
+	return nil
+}
+
+// NewExcludeRule creates a validated exclusion rule. before and after are optional (YYYY-MM-DD).
+func NewExcludeRule(pattern, before, after string) (ExcludeRule, error) {
+	rule := ExcludeRule{Pattern: pattern, Before: before, After: after}
+	if err := rule.compile(); err != nil {
+		return ExcludeRule{}, err
+	}
+	return rule, nil
+}
+
+// compile compiles the pattern and parses the time bounds of the rule
+func (r *ExcludeRule) compile() error {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid exclude pattern %q: %w", r.Pattern, err)
+	}
+	r.regex = re
 
-		// Check time bounds - exclude if subscription falls within the rule's time window
-		// before: exclude subscriptions that ended before this date
-		// after: exclude subscriptions that started after this date
-		if !rule.beforeDate.IsZero() && !sub.LastDate.Before(rule.beforeDate) {
-			continue // Subscription extends past the "before" date, don't exclude
+	if r.Before != "" {
+		t, err := time.Parse("2006-01-02", r.Before)
+		if err != nil {
+			return fmt.Errorf("invalid 'before' date %q: %w", r.Before, err)
 		}
-		if !rule.afterDate.IsZero() && sub.StartDate.Before(rule.afterDate) {
-			continue // Subscription started before the "after" date, don't exclude
+		r.beforeDate = t
+	}
+	if r.After != "" {
+		t, err := time.Parse("2006-01-02", r.After)
+		if err != nil {
+			return fmt.Errorf("invalid 'after' date %q: %w", r.After, err)
 		}
+		r.afterDate = t
+	}
+	return nil
+}
 
-		return true
+// Matches returns true if the rule excludes the subscription, considering time bounds
+// against the subscription's date range
+func (r ExcludeRule) Matches(sub Subscription) bool {
+	if !r.regex.MatchString(sub.Name) {
+		return false
 	}
-	return false
+
+	// Check time bounds - exclude if subscription falls within the rule's time window
+	// before: exclude subscriptions that ended before this date
+	// after: exclude subscriptions that started after this date
+	if !r.beforeDate.IsZero() && !sub.LastDate.Before(r.beforeDate) {
+		return false // Subscription extends past the "before" date, don't exclude
+	}
+	if !r.afterDate.IsZero() && sub.StartDate.Before(r.afterDate) {
+		return false // Subscription started before the "after" date, don't exclude
+	}
+	return true
 }
 
 // GetDescription returns the custom description for a subscription, or empty string
@@ -363,12 +666,70 @@ func (c *Config) GetTags(name string) []string {
 	return c.Tags[name]
 }
 
+// GetShare returns your share of the cost of a subscription: its split, or one over the number
+// of people it is shared with, else 1 (not shared)
+func (c *Config) GetShare(name string) float64 {
+	if c == nil {
+		return 1
+	}
+	if share, ok := c.Split[name]; ok {
+		return share
+	}
+	if people, ok := c.SharedWith[name]; ok {
+		return 1 / float64(people)
+	}
+	return 1
+}
+
+// GetVATRate returns the VAT rate of a subscription that is a business expense, and whether it is
+// one
+func (c *Config) GetVATRate(name string) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	rate, ok := c.Business[name]
+	return rate, ok
+}
+
+// unitsPerUSD are rough exchange rates for the default bounds of built-in known patterns. The
+// bounds are generous, so the rates don't need to be current.
+var unitsPerUSD = map[string]float64{
+	"USD": 1, "EUR": 1, "GBP": 1, "CHF": 1, "CAD": 1.4, "AUD": 1.5, "NZD": 1.7, "SGD": 1.4,
+	"SEK": 11, "NOK": 11, "DKK": 7, "ISK": 140, "PLN": 4, "CZK": 23, "HUF": 370, "TRY": 40,
+	"JPY": 150, "CNY": 7.2, "HKD": 7.8, "KRW": 1400, "INR": 85, "THB": 35,
+	"BRL": 5.5, "MXN": 19, "ZAR": 18,
+}
+
+// ScaleKnownBounds sets the max amount of the built-in known patterns to their default bound in
+// the given currency. Patterns from the config keep their own bounds; with a currency that has no
+// rough exchange rate (or none at all), built-in patterns have no bounds.
+func (c *Config) ScaleKnownBounds(currency string) {
+	if c == nil {
+		return
+	}
+	rate, ok := unitsPerUSD[strings.ToUpper(currency)]
+	for i := range c.Known {
+		k := &c.Known[i]
+		if k.maxUSD == 0 {
+			continue
+		}
+		k.MaxAmount = nil
+		if ok {
+			maxAmount := k.maxUSD * rate
+			k.MaxAmount = &maxAmount
+		}
+	}
+}
+
 // MatchesKnown checks if a transaction matches a known subscription pattern.
 // Returns the matching KnownSubscription or nil if no match.
 func (c *Config) MatchesKnown(tx Transaction) *KnownSubscription {
 	if c == nil {
 		return nil
 	}
+	if c.knownMatcher != nil && c.knownMatcher.builtFor(c.Known) {
+		return c.knownMatcher.match(tx)
+	}
 	for i := range c.Known {
 		if c.Known[i].Matches(tx) {
 			return &c.Known[i]
@@ -387,7 +748,11 @@ func (k *KnownSubscription) Matches(tx Transaction) bool {
 	if !k.regex.MatchString(tx.Text) {
 		return false
 	}
+	return k.matchesBounds(tx)
+}
 
+// matchesBounds checks the amount and date bounds of the rule
+func (k *KnownSubscription) matchesBounds(tx Transaction) bool {
 	// Check amount bounds (use absolute value since subscriptions are expenses)
 	amt := tx.Amount
 	if amt < 0 {
@@ -411,20 +776,31 @@ func (k *KnownSubscription) Matches(tx Transaction) bool {
 	return true
 }
 
-// ApplyGroups transforms transactions by replacing names that match group patterns
-// with the group name. Returns the transformed transactions and a map of group tolerances.
+// ApplyGroups transforms transactions by replacing names that match rename rules with their new
+// name, then names that match group patterns with the group name, and then names with amount
+// bands (see SplitByAmount) with the name of the band of the amount. Returns the transformed
+// transactions and a map of group tolerances.
 func (c *Config) ApplyGroups(txs []Transaction) ([]Transaction, map[string]float64) {
 	tolerances := make(map[string]float64)
-	if c == nil || len(c.Groups) == 0 {
+	if c == nil || (len(c.Groups) == 0 && len(c.renames) == 0 && len(c.splitByAmount) == 0) {
 		return txs, tolerances
 	}
 
 	result := make([]Transaction, len(txs))
 	for i, tx := range txs {
 		result[i] = tx
+		if name := c.renamed(tx.Text); name != tx.Text {
+			if result[i].RawText == "" {
+				result[i].RawText = tx.Text
+			}
+			result[i].Text = name
+		}
 		for _, group := range c.Groups {
 			for _, re := range group.regexes {
-				if re.MatchString(tx.Text) {
+				if re.MatchString(result[i].Text) {
+					if result[i].RawText == "" {
+						result[i].RawText = tx.Text
+					}
 					result[i].Text = group.Name
 					if group.Tolerance != nil {
 						tolerances[group.Name] = *group.Tolerance
@@ -433,10 +809,36 @@ func (c *Config) ApplyGroups(txs []Transaction) ([]Transaction, map[string]float
 				}
 			}
 		}
+		if band := c.amountBand(result[i].Text, tx.Amount); band != nil {
+			if result[i].RawText == "" {
+				result[i].RawText = tx.Text
+			}
+			result[i].Text = band.Name
+		}
 	}
 	return result, tolerances
 }
 
+// renamed returns the name that a rename rule gives a transaction text, or the text itself
+func (c *Config) renamed(text string) string {
+	if name, ok := c.renames[renameKey(text)]; ok {
+		return name
+	}
+	return text
+}
+
+// renameKey is the form of a payee name that rename rules compare: upper case, with whitespace
+// collapsed and trailing digits stripped, e.g. "SPOTIFY" for "Spotify  1234"
+func renameKey(text string) string {
+	return strings.ToUpper(trimTrailingDigits(text))
+}
+
+// trimTrailingDigits collapses the whitespace of a payee name and strips trailing digits
+func trimTrailingDigits(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimRightFunc(text, func(r rune) bool { return unicode.IsDigit(r) || r == ' ' })
+}
+
 // GenerateFromSubscriptions creates a config template from detected subscriptions
 func GenerateConfigTemplate(subscriptions []Subscription) *Config {
 	cfg := &Config{
@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The functions in this file edit the config file in place at the YAML node level, so comments
+// and entries that are not touched are kept. Config.Save can't be used for this since a loaded
+// config also contains the built-in known subscriptions.
+
+// editConfigFile applies edit to the top-level mapping of the config file at path and writes
+// the result back. A missing file is created. The edited config must still load. The file keeps
+// its permissions (it may hold notifier tokens), and a symlinked config is written through the
+// link instead of being replaced by a regular file.
+func editConfigFile(path string, edit func(root *yaml.Node) error) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parsing config file: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a YAML mapping", path)
+	}
+
+	if err := edit(root); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	enc.Close()
+
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), mode); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	// WriteFile only sets the mode of new files, and the umask applies
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if _, err := LoadConfig(tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("edited config is invalid: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// mappingEntry returns the value node for key in mapping m, adding an empty node of kind if missing
+func mappingEntry(m *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value := m.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				// "key:" without a value
+				*value = yaml.Node{Kind: kind}
+			}
+			if value.Kind != kind {
+				return nil, fmt.Errorf("config entry %q has an unexpected type", key)
+			}
+			return value, nil
+		}
+	}
+	value := &yaml.Node{Kind: kind}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value, nil
+}
+
+// AddConfigTag adds tag to the tags of subscription name in the config file at path.
+// Adding a tag that is already there is a no-op.
+func AddConfigTag(path, name, tag string) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		tags, err := mappingEntry(root, "tags", yaml.MappingNode)
+		if err != nil {
+			return err
+		}
+		list, err := mappingEntry(tags, name, yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		for _, n := range list.Content {
+			if n.Value == tag {
+				return nil
+			}
+		}
+		list.Style = yaml.FlowStyle
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: tag})
+		return nil
+	})
+}
+
+// RemoveConfigTag removes tag from the tags of subscription name in the config file at path.
+// The subscription's entry is removed when it has no tags left. Returns whether the tag was found.
+func RemoveConfigTag(path, name, tag string) (bool, error) {
+	found := false
+	err := editConfigFile(path, func(root *yaml.Node) error {
+		tags, err := mappingEntry(root, "tags", yaml.MappingNode)
+		if err != nil {
+			return err
+		}
+		list, err := mappingEntry(tags, name, yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		var kept []*yaml.Node
+		for _, n := range list.Content {
+			if n.Value == tag {
+				found = true
+				continue
+			}
+			kept = append(kept, n)
+		}
+		list.Content = kept
+		if len(kept) == 0 {
+			removeMappingEntry(tags, name)
+		}
+		if len(tags.Content) == 0 {
+			removeMappingEntry(root, "tags")
+		}
+		return nil
+	})
+	return found, err
+}
+
+// SetConfigDescription sets the description of subscription name in the config file at path.
+// An empty description removes it.
+func SetConfigDescription(path, name, description string) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		descriptions, err := mappingEntry(root, "descriptions", yaml.MappingNode)
+		if err != nil {
+			return err
+		}
+		if description == "" {
+			removeMappingEntry(descriptions, name)
+			if len(descriptions.Content) == 0 {
+				removeMappingEntry(root, "descriptions")
+			}
+			return nil
+		}
+		value, err := mappingEntry(descriptions, name, yaml.ScalarNode)
+		if err != nil {
+			return err
+		}
+		value.Value, value.Tag, value.Style = description, "!!str", 0
+		return nil
+	})
+}
+
+// removeMappingEntry removes key (and its value) from mapping m
+func removeMappingEntry(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// AddConfigExclude adds an exclude rule to the config file at path. Rules without time bounds
+// are written as plain patterns. Adding a rule that is already there is a no-op.
+func AddConfigExclude(path string, rule ExcludeRule) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		exclude, err := mappingEntry(root, "exclude", yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		for _, n := range exclude.Content {
+			var existing ExcludeRule
+			if n.Kind == yaml.ScalarNode {
+				existing.Pattern = n.Value
+			} else if err := n.Decode(&existing); err != nil {
+				continue
+			}
+			if existing.Pattern == rule.Pattern && existing.Before == rule.Before && existing.After == rule.After {
+				return nil
+			}
+		}
+
+		var node yaml.Node
+		if rule.Before == "" && rule.After == "" {
+			node = yaml.Node{Kind: yaml.ScalarNode, Value: rule.Pattern}
+		} else if err := node.Encode(rule); err != nil {
+			return fmt.Errorf("encoding exclude rule: %w", err)
+		}
+		exclude.Content = append(exclude.Content, &node)
+		return nil
+	})
+}
+
+// ExcludePatternFor returns an exclude pattern that matches exactly the subscription name
+func ExcludePatternFor(name string) string {
+	return "^" + regexp.QuoteMeta(name) + "$"
+}
+
+// AddConfigGroup adds a group to the config file at path. If a group with the same name exists,
+// the patterns it doesn't have yet are added to it instead.
+func AddConfigGroup(path string, group Group) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		groups, err := mappingEntry(root, "groups", yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		for _, n := range groups.Content {
+			var existing Group
+			if n.Kind != yaml.MappingNode || n.Decode(&existing) != nil || existing.Name != group.Name {
+				continue
+			}
+			patterns, err := mappingEntry(n, "patterns", yaml.SequenceNode)
+			if err != nil {
+				return err
+			}
+			for _, pattern := range group.Patterns {
+				if !slices.Contains(existing.Patterns, pattern) {
+					patterns.Content = append(patterns.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: pattern})
+				}
+			}
+			return nil
+		}
+
+		var node yaml.Node
+		if err := node.Encode(group); err != nil {
+			return fmt.Errorf("encoding group: %w", err)
+		}
+		groups.Content = append(groups.Content, &node)
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigEdit_TagsAndDescriptions(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("# my config\ncurrency: SEK\ntags:\n  Spotify: [music]\n"), 0644)
+
+	if err := AddConfigTag(configPath, "Netflix", "video"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigDescription(configPath, "Netflix", "Streaming: movies & series"); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := RemoveConfigTag(configPath, "Spotify", "music"); err != nil || !found {
+		t.Fatalf("expected music to be removed, found=%v err=%v", found, err)
+	}
+	if found, _ := RemoveConfigTag(configPath, "Netflix", "missing"); found {
+		t.Error("expected a missing tag not to be found")
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load edited config: %v", err)
+	}
+	if tags := cfg.GetTags("Netflix"); len(tags) != 1 || tags[0] != "video" {
+		t.Errorf("expected Netflix tags [video], got %v", tags)
+	}
+	if tags := cfg.GetTags("Spotify"); len(tags) != 0 {
+		t.Errorf("expected Spotify to have no tags, got %v", tags)
+	}
+	if desc := cfg.GetDescription("Netflix"); desc != "Streaming: movies & series" {
+		t.Errorf("unexpected description %q", desc)
+	}
+
+	if err := SetConfigDescription(configPath, "Netflix", ""); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(configPath)
+	if strings.Contains(string(data), "descriptions") || strings.Contains(string(data), "Spotify") {
+		t.Errorf("expected empty entries to be removed, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "# my config") {
+		t.Errorf("expected comments to be kept, got:\n%s", data)
+	}
+}
+
+func TestConfigEdit_KeepsModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.yaml")
+	os.MkdirAll(filepath.Dir(target), 0755)
+	os.WriteFile(target, []byte("currency: SEK\n"), 0600)
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := AddConfigTag(link, "Netflix", "video"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the config to stay a symlink, got %v (%v)", info, err)
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the config to keep mode 0600, got %v (%v)", info, err)
+	}
+	if cfg, err := LoadConfig(target); err != nil || len(cfg.GetTags("Netflix")) != 1 {
+		t.Errorf("expected the tag written to the link's target, got %v", err)
+	}
+}
+
+func TestConfigEdit_Exclude(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	bounded, err := NewExcludeRule("Gym", "2025-06-01", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []ExcludeRule{{Pattern: "^Netflix$"}, bounded, bounded} {
+		if err := AddConfigExclude(configPath, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewExcludeRule("(", "", ""); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load edited config: %v", err)
+	}
+	if len(cfg.Exclude) != 2 {
+		t.Errorf("expected 2 exclude rules (no duplicate), got %d", len(cfg.Exclude))
+	}
+	if !cfg.ShouldExclude(Subscription{Name: "Netflix"}) {
+		t.Error("expected Netflix to be excluded")
+	}
+	early := Subscription{Name: "Gym", LastDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	late := Subscription{Name: "Gym", LastDate: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)}
+	if !cfg.ShouldExclude(early) || cfg.ShouldExclude(late) {
+		t.Error("expected the before bound to be kept")
+	}
+}
+
+func TestConfigEdit_Group(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("# My groups\ngroups:\n  - name: Spotify\n    patterns:\n      - \"^Spotify P\"\n"), 0644)
+
+	for _, group := range []Group{
+		{Name: "Spotify", Patterns: []string{"^Spotify P", "^SPOTIFY"}},
+		{Name: "Google Workspace", Patterns: []string{"^GOOGLE\\*GSUITE"}},
+	} {
+		if err := AddConfigGroup(configPath, group); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load edited config: %v", err)
+	}
+	if len(cfg.Groups) != 2 || strings.Join(cfg.Groups[0].Patterns, ",") != "^Spotify P,^SPOTIFY" {
+		t.Errorf("expected a new pattern for Spotify and a new group, got %+v", cfg.Groups)
+	}
+	if grouped, _ := cfg.ApplyGroups([]Transaction{{Text: "GOOGLE*GSUITE 123"}}); grouped[0].Text != "Google Workspace" {
+		t.Errorf("expected the new group to match, got %q", grouped[0].Text)
+	}
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), "# My groups") {
+		t.Errorf("expected comments to be kept:\n%s", data)
+	}
+}
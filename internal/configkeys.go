@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var yamlNodeType = reflect.TypeOf(yaml.Node{})
+
+// checkKeys reports the keys of a YAML node that aren't fields of type t (nested structs, maps and
+// slices included), each with its line and the closest known key for likely typos, e.g.
+// "descrptions". Aliases are followed and merge keys (<<) are checked against the type they are
+// merged into. Fields of type yaml.Node are decoded later and not checked.
+func checkKeys(node *yaml.Node, t reflect.Type) error {
+	var unknown []string
+	walkKeys(node, t, "", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unknown keys:\n  %s", strings.Join(unknown, "\n  "))
+}
+
+func walkKeys(node *yaml.Node, t reflect.Type, path string, unknown *[]string) {
+	if node == nil {
+		return
+	}
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, n := range node.Content {
+			walkKeys(n, t, path, unknown)
+		}
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == yamlNodeType {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" || key.Value == "<<" {
+				walkMerge(value, t, path, unknown)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := fmt.Sprintf("line %d: %s", key.Line, joinKeyPath(path, key.Value))
+				if suggestion := closestKey(key.Value, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+				}
+				*unknown = append(*unknown, msg)
+				continue
+			}
+			walkKeys(value, field, joinKeyPath(path, key.Value), unknown)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkKeys(node.Content[i+1], t.Elem(), joinKeyPath(path, node.Content[i].Value), unknown)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			walkKeys(item, t.Elem(), path, unknown)
+		}
+	}
+}
+
+// walkMerge checks the mapping (or sequence of mappings) merged with a merge key
+func walkMerge(node *yaml.Node, t reflect.Type, path string, unknown *[]string) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			walkKeys(item, t, path, unknown)
+		}
+		return
+	}
+	walkKeys(node, t, path, unknown)
+}
+
+// yamlFields returns the types of the fields of a struct by their YAML key
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestKey returns the known key closest to a mistyped one, or "" if none is close enough to be
+// a likely typo
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", max(len(key)/3, 2)+1
+	for name := range fields {
+		d := editDistance(strings.ToLower(key), name)
+		if d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
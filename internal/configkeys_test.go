@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig_UnknownKeys(t *testing.T) {
+	_, err := parseConfig([]byte("descrptions:\n  Netflix: Movies\ngroups:\n  - name: Music\n    patern: \"^Spotify\"\nbudgets:\n  totl: 500\n  tags:\n    music: 100\nfoo: 1\n"))
+	if err == nil {
+		t.Fatal("expected an error for unknown keys")
+	}
+	for _, want := range []string{
+		"line 1: descrptions (did you mean descriptions?)",
+		"line 5: groups.patern (did you mean patterns?)",
+		"line 7: budgets.totl (did you mean total?)",
+		"line 10: foo\n",
+	} {
+		if !strings.Contains(err.Error()+"\n", want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+
+	if _, err := parseConfig([]byte("exclude:\n  - pattern: Gym\n    befor: 2025-01-01\n")); err == nil || !strings.Contains(err.Error(), "befor (did you mean before?)") {
+		t.Errorf("expected an error for an unknown exclude rule key, got %v", err)
+	}
+}
+
+func TestParseConfig_Anchors(t *testing.T) {
+	cfg, err := parseConfig([]byte(`templates:
+  streaming: &streaming
+    tolerance: 0.5
+  music: &music [music]
+groups:
+  - <<: *streaming
+    name: Video
+    patterns: ["^Netflix", "^HBO"]
+  - <<: *streaming
+    name: Music
+    patterns: ["^Spotify"]
+tags:
+  Music: *music
+  Podcasts: *music
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Groups) != 2 || cfg.Groups[1].Name != "Music" || cfg.Groups[1].Tolerance == nil || *cfg.Groups[1].Tolerance != 0.5 {
+		t.Errorf("expected the template merged into both groups, got %+v", cfg.Groups)
+	}
+	if got := cfg.GetTags("Podcasts"); len(got) != 1 || got[0] != "music" {
+		t.Errorf("expected tags from an alias, got %v", got)
+	}
+
+	// Keys merged from a template are checked too
+	if _, err := parseConfig([]byte("templates:\n  t: &t\n    tolerence: 0.5\ngroups:\n  - <<: *t\n    name: Video\n")); err == nil || !strings.Contains(err.Error(), "groups.tolerence (did you mean tolerance?)") {
+		t.Errorf("expected an error for a mistyped key in a template, got %v", err)
+	}
+}
+
+func TestParseConfig_Empty(t *testing.T) {
+	if _, err := parseConfig(nil); err != nil {
+		t.Errorf("expected an empty config to parse, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sortedByDate returns a copy of txs sorted by date (stable, so same-day order is kept)
+func sortedByDate(txs []Transaction) []Transaction {
+	sorted := make([]Transaction, len(txs))
+	copy(sorted, txs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return sorted
+}
+
+// WriteSimpleJSON writes transactions in the simple JSON format (see SimpleJSONFormat), sorted by date
+func WriteSimpleJSON(w io.Writer, txs []Transaction) error {
+	output := SimpleJSONFormat{Transactions: []SimpleJSONTransaction{}}
+	for _, tx := range sortedByDate(txs) {
+		output.Transactions = append(output.Transactions, newSimpleJSONTransaction(tx))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(output)
+}
+
+// WriteTransactionsCSV writes transactions as CSV with a date,text,amount header, sorted by date
+func WriteTransactionsCSV(w io.Writer, txs []Transaction) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "text", "amount"})
+	for _, tx := range sortedByDate(txs) {
+		cw.Write([]string{tx.Date.Format("2006-01-02"), tx.Text, strconv.FormatFloat(tx.Amount, 'f', -1, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePaymentsCSV writes the payments of subscriptions as CSV, one row per payment with a
+// subscription,date,text,amount header (and person, for labeled exports), by subscription and date.
+// Amounts are your share of shared subscriptions.
+func WritePaymentsCSV(w io.Writer, subs []Subscription) error {
+	subs = slices.Clone(subs)
+	sort.SliceStable(subs, func(i, j int) bool { return strings.ToLower(subs[i].Name) < strings.ToLower(subs[j].Name) })
+	hasPersons := HasPersons(subs)
+
+	cw := csv.NewWriter(w)
+	header := []string{"subscription", "date", "text", "amount"}
+	if hasPersons {
+		header = append(header, "person")
+	}
+	cw.Write(header)
+	for _, sub := range subs {
+		for _, tx := range sortedByDate(sub.Transactions) {
+			row := []string{sub.Name, tx.Date.Format("2006-01-02"), tx.Text, strconv.FormatFloat(math.Round(tx.Amount*100)/100, 'f', -1, 64)}
+			if hasPersons {
+				row = append(row, tx.Person)
+			}
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
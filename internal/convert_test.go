@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteTransactions(t *testing.T) {
+	txs := []Transaction{
+		{Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Text: `Store "Main", Inc`, Amount: -12.5},
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Text: "Netflix", Amount: -99},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTransactionsCSV(&buf, txs); err != nil {
+		t.Fatal(err)
+	}
+	expected := "date,text,amount\n2025-01-15,Netflix,-99\n2025-02-01,\"Store \"\"Main\"\", Inc\",-12.5\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+
+	// simple-json output can be read back by the simple-json parser
+	path := filepath.Join(t.TempDir(), "out.json")
+	buf.Reset()
+	if err := WriteSimpleJSON(&buf, txs); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, buf.Bytes(), 0644)
+	parsed, err := ParseSimpleJSON(context.Background(), path)
+	if err != nil {
+		t.Fatalf("failed to parse converted JSON: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Text != "Netflix" || parsed[1].Amount != -12.5 {
+		t.Errorf("unexpected round trip: %+v", parsed)
+	}
+}
+
+func TestWritePaymentsCSV(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Spotify", Transactions: []Transaction{
+			{Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Text: "Spotify AB", Amount: -129},
+			{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Text: "Spotify", Amount: -119},
+		}},
+		{Name: "Netflix", Transactions: []Transaction{
+			{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Text: "Netflix", Amount: -99.0 / 3},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePaymentsCSV(&buf, subs); err != nil {
+		t.Fatal(err)
+	}
+	expected := "subscription,date,text,amount\nNetflix,2025-01-15,Netflix,-33\nSpotify,2025-01-01,Spotify,-119\nSpotify,2025-02-01,Spotify AB,-129\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+
+	// Labeled exports get a person column
+	subs[1].Person = "alice"
+	subs[1].Transactions[0].Person = "alice"
+	buf.Reset()
+	WritePaymentsCSV(&buf, subs[1:])
+	if expected := "subscription,date,text,amount,person\nNetflix,2025-01-15,Netflix,-33,alice\n"; buf.String() != expected {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestTransaction_OptionalFields(t *testing.T) {
+	balance := 1234.5
+	txs := []Transaction{{
+		Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Text: "Netflix", Amount: -99,
+		ID: "tx-1", Account: "Checking", Currency: "SEK", Category: "Entertainment", Balance: &balance, RawText: "Prel Netflix",
+	}}
+
+	// simple-json keeps the optional fields
+	var buf bytes.Buffer
+	WriteSimpleJSON(&buf, txs)
+	path := filepath.Join(t.TempDir(), "out.json")
+	os.WriteFile(path, buf.Bytes(), 0644)
+	parsed, err := ParseSimpleJSON(context.Background(), path)
+	if err != nil {
+		t.Fatalf("failed to parse converted JSON: %v", err)
+	}
+	if len(parsed) != 1 || parsed[0].ID != "tx-1" || parsed[0].Account != "Checking" || parsed[0].Currency != "SEK" ||
+		parsed[0].Category != "Entertainment" || parsed[0].Balance == nil || *parsed[0].Balance != balance || parsed[0].RawText != "Prel Netflix" {
+		t.Errorf("optional fields lost in simple-json round trip: %+v", parsed)
+	}
+
+	// So does the state file
+	state := &State{}
+	state.ImportTransactions(txs)
+	stored, err := state.StoredTransactions()
+	if err != nil || len(stored) != 1 || stored[0].ID != "tx-1" || stored[0].Balance == nil {
+		t.Errorf("optional fields lost in the state file: %+v, %v", stored, err)
+	}
+
+	// Groups keep the exported text
+	cfg, _ := parseConfig([]byte("groups:\n  - name: Streaming\n    patterns: [Netflix]\n"))
+	grouped, _ := cfg.ApplyGroups([]Transaction{{Text: "Netflix"}})
+	if grouped[0].Text != "Streaming" || grouped[0].RawText != "Netflix" {
+		t.Errorf("expected the original text in RawText, got %+v", grouped[0])
+	}
+
+	// Identifying fields are dropped when anonymizing
+	anon := Anonymize(txs, []byte("secret"))[0]
+	if anon.ID != "" || anon.Account != "" || anon.Balance != nil || anon.RawText != "" || anon.Currency != "SEK" {
+		t.Errorf("unexpected anonymized optional fields: %+v", anon)
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"math"
 	"strings"
 
 	"golang.org/x/text/currency"
@@ -11,10 +12,11 @@ import (
 
 // Currency represents a currency with its formatting rules
 type Currency struct {
-	Code    string // "SEK", "USD", "EUR"
-	unit    currency.Unit
-	tag     language.Tag
-	printer *message.Printer
+	Code      string // "SEK", "USD", "EUR"
+	unit      currency.Unit
+	tag       language.Tag
+	printer   *message.Printer
+	precision int // decimals shown in formatted amounts
 }
 
 // symbolOverrides provides custom symbols where x/text defaults aren't ideal
@@ -143,20 +145,7 @@ func DetectSystemCurrency() string {
 // parseCurrencyFromLocale extracts currency code and language tag from a locale string.
 // Examples: "sv_SE.UTF-8" -> ("SEK", sv-SE), "pt_BR.UTF-8" -> ("BRL", pt-BR)
 func parseCurrencyFromLocale(locale string) (string, language.Tag) {
-	// Remove encoding suffix (everything after .)
-	base := locale
-	if idx := strings.Index(base, "."); idx != -1 {
-		base = base[:idx]
-	}
-
-	// Remove modifier suffix (everything after @)
-	if idx := strings.Index(base, "@"); idx != -1 {
-		base = base[:idx]
-	}
-
-	// Convert to BCP 47 format: "sv_SE" -> "sv-SE"
-	tagStr := strings.Replace(base, "_", "-", 1)
-	tag, err := language.Parse(tagStr)
+	tag, err := parseLocaleTag(locale)
 	if err != nil {
 		return "", language.Und
 	}
@@ -197,10 +186,34 @@ func (c Currency) isPrefix() bool {
 	}
 }
 
+// WithPrecision returns the currency formatting amounts with the given number of decimals
+// (e.g., 2 for "$9.99" rather than "$10"). The default is whole units.
+func (c Currency) WithPrecision(decimals int) Currency {
+	c.precision = max(decimals, 0)
+	return c
+}
+
+// Precision returns the number of decimals in formatted amounts
+func (c Currency) Precision() int {
+	return c.precision
+}
+
+// Round rounds an amount for JSON output: to the decimals shown in formatted amounts, but never
+// to less than cents, so JSON totals match the displayed ones without losing what was paid
+func (c Currency) Round(amount float64) float64 {
+	scale := math.Pow10(max(c.precision, 2))
+	return math.Round(amount*scale) / scale
+}
+
+// formatNumber formats an amount without symbol, with the currency's decimals
+func (c Currency) formatNumber(amount float64) string {
+	// Use x/text/number for proper locale-aware formatting
+	return c.printer.Sprint(number.Decimal(amount, number.MinFractionDigits(c.precision), number.MaxFractionDigits(c.precision)))
+}
+
 // Format formats a single amount with the currency symbol
 func (c Currency) Format(amount float64) string {
-	// Use x/text/number for proper locale-aware formatting
-	formatted := c.printer.Sprint(number.Decimal(amount, number.MaxFractionDigits(0)))
+	formatted := c.formatNumber(amount)
 	symbol := c.getSymbol()
 
 	if c.isPrefix() {
@@ -211,8 +224,8 @@ func (c Currency) Format(amount float64) string {
 
 // FormatRange formats a range of amounts (min-max) with the currency symbol
 func (c Currency) FormatRange(min, max float64) string {
-	minStr := c.printer.Sprint(number.Decimal(min, number.MaxFractionDigits(0)))
-	maxStr := c.printer.Sprint(number.Decimal(max, number.MaxFractionDigits(0)))
+	minStr := c.formatNumber(min)
+	maxStr := c.formatNumber(max)
 	symbol := c.getSymbol()
 
 	if c.isPrefix() {
@@ -302,3 +302,37 @@ func TestDetectSystemCurrency_SetsLocaleForFormatting(t *testing.T) {
 		t.Errorf("Format(1234) = %q, want %q", formatted, "1.234 R$")
 	}
 }
+
+func TestCurrency_WithPrecision(t *testing.T) {
+	usd := GetCurrencyWithLocale("USD", language.AmericanEnglish)
+	sek := GetCurrencyWithLocale("SEK", language.Swedish)
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"whole units by default", usd.Format(9.99), "$10"},
+		{"two decimals", usd.WithPrecision(2).Format(9.99), "$9.99"},
+		{"pads decimals", usd.WithPrecision(2).Format(1234), "$1,234.00"},
+		{"locale decimal separator", sek.WithPrecision(2).Format(1234.5), "1 234,50 kr"},
+		{"range", usd.WithPrecision(2).FormatRange(9.99, 12.5), "$9.99-$12.50"},
+		{"negative precision is whole units", usd.WithPrecision(-1).Format(9.99), "$10"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.expected)
+		}
+	}
+
+	// JSON amounts are rounded to the displayed decimals, but never to less than cents
+	if got := usd.Round(119.88000000000001); got != 119.88 {
+		t.Errorf("Round() = %v, want 119.88", got)
+	}
+	if got := usd.Round(9.994); got != 9.99 {
+		t.Errorf("Round() = %v, want 9.99", got)
+	}
+	if got := usd.WithPrecision(3).Round(9.9944); got != 9.994 {
+		t.Errorf("Round() with precision 3 = %v, want 9.994", got)
+	}
+}
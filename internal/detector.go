@@ -1,106 +1,234 @@
 package internal
 
 import (
+	"context"
+	"fmt"
 	"math"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 )
 
-// DetectSubscriptions analyzes transactions to find recurring monthly subscriptions.
-// It uses filteredTxs (from complete months) for pattern detection,
-// and allTxs to determine the full lifecycle including current month.
-// tolerance is the max allowed price change between consecutive months (e.g., 0.35 = 35%).
-func DetectSubscriptions(filteredTxs []Transaction, allTxs []Transaction, dateRange DateRange, tolerance float64) []Subscription {
-	// Group filtered transactions by payee name (case-insensitive)
-	byName := make(map[string][]Transaction)
-	displayNames := make(map[string]string) // lowercase -> display name (most recent)
-	for _, tx := range filteredTxs {
-		key := strings.ToLower(tx.Text)
-		byName[key] = append(byName[key], tx)
-		displayNames[key] = tx.Text // keeps updating to most recent
+// Interval is the number of months between the payments of a subscription
+type Interval int
+
+const (
+	IntervalMonthly   Interval = 1
+	IntervalQuarterly Interval = 3
+	IntervalYearly    Interval = 12
+)
+
+const (
+	defaultTolerance      = 0.35
+	defaultMinOccurrences = 2
+	defaultGraceDays      = 5
+)
+
+// parseInterval parses the name of a billing interval (monthly, quarterly, yearly or annual)
+func parseInterval(name string) (Interval, error) {
+	switch strings.ToLower(name) {
+	case "monthly":
+		return IntervalMonthly, nil
+	case "quarterly":
+		return IntervalQuarterly, nil
+	case "yearly", "annual":
+		return IntervalYearly, nil
 	}
+	return 0, fmt.Errorf("unknown interval %q (expected monthly, quarterly or yearly)", name)
+}
 
-	// Also group all transactions to check latest month
-	allByName := make(map[string][]Transaction)
-	for _, tx := range allTxs {
-		key := strings.ToLower(tx.Text)
-		allByName[key] = append(allByName[key], tx)
-		displayNames[key] = tx.Text
+// String returns the name of the interval, as accepted by parseInterval
+func (i Interval) String() string {
+	switch i {
+	case IntervalMonthly:
+		return "monthly"
+	case IntervalQuarterly:
+		return "quarterly"
+	case IntervalYearly:
+		return "yearly"
 	}
+	return fmt.Sprintf("every %d months", int(i))
+}
 
-	var subscriptions []Subscription
+// Detector finds recurring subscriptions in transactions. Create it with NewDetector and options;
+// the zero value is not usable.
+type Detector struct {
+	tolerance      float64
+	minOccurrences int
+	graceDays      int
+	intervals      []Interval
+	strategies     []Strategy
+	clock          Clock
+	observer       Observer
+	partialMonths  bool
+	ignoreGaps     bool
+}
 
-	for key, txs := range byName {
-		name := displayNames[key]
-		// Need at least 2 occurrences to be a subscription
-		if len(txs) < 2 {
-			continue
-		}
+// DetectorOption configures a Detector
+type DetectorOption func(*Detector)
+
+// NewDetector creates a detector. Without options it detects monthly subscriptions with at least
+// 2 payments, a max price change of 35% between payments and a 5-day grace period, and evaluates
+// status relative to the end of the data.
+func NewDetector(opts ...DetectorOption) *Detector {
+	d := &Detector{
+		tolerance:      defaultTolerance,
+		minOccurrences: defaultMinOccurrences,
+		graceDays:      defaultGraceDays,
+		intervals:      []Interval{IntervalMonthly},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
 
-		// Only consider expenses (negative amounts)
-		expenses := FilterExpenses(txs)
-		if len(expenses) < 2 {
-			continue
-		}
+// WithTolerance sets the max allowed price change between consecutive payments (e.g., 0.35 = 35%)
+func WithTolerance(tolerance float64) DetectorOption {
+	return func(d *Detector) { d.tolerance = tolerance }
+}
 
-		// Sort by date
-		sort.Slice(expenses, func(i, j int) bool {
-			return expenses[i].Date.Before(expenses[j].Date)
-		})
+// WithMinOccurrences sets the number of payments required for pattern detection (at least 2).
+// Known subscriptions from the config are detected from a single payment regardless.
+func WithMinOccurrences(n int) DetectorOption {
+	return func(d *Detector) { d.minOccurrences = max(n, 2) }
+}
 
-		// Get all transactions for this subscription (including current month)
-		allExpenses := FilterExpenses(allByName[key])
-		sort.Slice(allExpenses, func(i, j int) bool {
-			return allExpenses[i].Date.Before(allExpenses[j].Date)
-		})
+// WithGracePeriod sets the number of days after an expected payment date before a subscription
+// without that payment is considered stopped
+func WithGracePeriod(days int) DetectorOption {
+	return func(d *Detector) { d.graceDays = max(days, 0) }
+}
 
-		// Check for monthly pattern using ALL transactions
-		// If there are ever 2+ payments in any month, it's not a subscription
-		if !IsMonthlyPattern(allExpenses) {
-			continue
+// WithIntervals sets the billing intervals pattern detection accepts (default monthly only), when
+// neither WithStrategies nor the config's strategies are given. Payees whose typical gap between
+// payments matches none of them are not detected, except that irregular payees are still detected
+// as monthly when IntervalMonthly is included.
+func WithIntervals(intervals ...Interval) DetectorOption {
+	return func(d *Detector) {
+		d.intervals = nil
+		for _, interval := range intervals {
+			if interval > 0 && !slices.Contains(d.intervals, interval) {
+				d.intervals = append(d.intervals, interval)
+			}
 		}
+		slices.Sort(d.intervals)
+	}
+}
 
-		// Check if amounts are within tolerance of each other (using complete months data)
-		if !AmountsWithinTolerance(expenses, tolerance) {
-			continue
-		}
+// WithStrategies sets the strategy chain, overriding the config's strategies and WithIntervals.
+// Strategies run in the given order, so put specific ones before general ones (e.g., annual
+// before monthly).
+func WithStrategies(strategies ...Strategy) DetectorOption {
+	return func(d *Detector) { d.strategies = strategies }
+}
 
-		// Calculate statistics
-		avgAmount := CalculateAverageAmount(expenses)
-		minAmount, maxAmount := CalculateAmountRange(expenses)
-		typicalDay := CalculateTypicalDay(expenses)
-
-		startDate := allExpenses[0].Date
-		lastDate := allExpenses[len(allExpenses)-1].Date
-		latestAmount := allExpenses[len(allExpenses)-1].Amount
-
-		// Determine status
-		status := DetermineStatus(lastDate, typicalDay, dateRange.End)
-
-		subscriptions = append(subscriptions, Subscription{
-			Name:         name,
-			AvgAmount:    avgAmount,
-			LatestAmount: latestAmount,
-			MinAmount:    minAmount,
-			MaxAmount:    maxAmount,
-			Transactions: allExpenses,
-			StartDate:    startDate,
-			LastDate:     lastDate,
-			TypicalDay:   typicalDay,
-			Status:       status,
-		})
-	}
-
-	// Sort: active first, then by amount (highest first)
-	sort.Slice(subscriptions, func(i, j int) bool {
-		if subscriptions[i].Status != subscriptions[j].Status {
-			return subscriptions[i].Status == StatusActive
-		}
-		return math.Abs(subscriptions[i].AvgAmount) > math.Abs(subscriptions[j].AvgAmount)
-	})
+// Clock returns the time that subscription status is evaluated at
+type Clock func() time.Time
 
-	return subscriptions
+// FixedClock returns a Clock that always returns t, e.g. to see which subscriptions were active
+// at the end of a year, or for deterministic tests
+func FixedClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+// WithClock evaluates subscription status at the clock's date instead of the end of the data
+// (e.g., WithClock(time.Now)). Analyze and Stream ignore transactions after that date, which
+// weren't known yet at it (see TransactionsAsOf).
+func WithClock(clock Clock) DetectorOption {
+	return func(d *Detector) { d.clock = clock }
+}
+
+// WithPartialMonths also uses the payments of incomplete months (the month the data ends in,
+// before its last day) for pattern detection, for data covering only a few months. A payment
+// that isn't due yet in such a month isn't held against a payee, as months without a payment
+// never are, but one that is already made counts towards the required payments.
+func WithPartialMonths(enabled bool) DetectorOption {
+	return func(d *Detector) { d.partialMonths = enabled }
+}
+
+// WithIgnoreDataGaps doesn't count months without any transactions (see MissingMonths) when
+// deciding whether a subscription has stopped, so a missing export doesn't make the subscriptions
+// paid in its months look stopped
+func WithIgnoreDataGaps(enabled bool) DetectorOption {
+	return func(d *Detector) { d.ignoreGaps = enabled }
+}
+
+// patternMonths returns the months whose payments pattern detection uses: the complete months,
+// or with WithPartialMonths every month of the data (and the month after it, which card payments
+// after the last statement cut-off are billed in)
+func (d *Detector) patternMonths(completeMonths []string, dateRange DateRange) []string {
+	if !d.partialMonths || dateRange.Start.IsZero() {
+		return completeMonths
+	}
+	next := time.Date(dateRange.End.Year(), dateRange.End.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	var months []string
+	for m := time.Date(dateRange.Start.Year(), dateRange.Start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(next); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	return months
+}
+
+// statusDate returns the date that subscription status is evaluated against
+func (d *Detector) statusDate(dateRange DateRange) time.Time {
+	if d.clock != nil {
+		return d.clock()
+	}
+	return dateRange.End
+}
+
+// Analyze runs the full pipeline on raw transactions: config grouping, data coverage
+// analysis and DetectAll. Recurring commitments are left out, as they aren't subscriptions and
+// aren't part of any total (use DetectAll and SplitCommitments to list them). It returns
+// ctx.Err() if the context is cancelled.
+func (d *Detector) Analyze(ctx context.Context, transactions []Transaction, cfg *Config) ([]Subscription, DateRange, error) {
+	subscriptions, _, dateRange, err := d.analyze(ctx, transactions, cfg)
+	return subscriptions, dateRange, err
+}
+
+// analyze is Analyze, also returning the recurring commitments it leaves out
+func (d *Detector) analyze(ctx context.Context, transactions []Transaction, cfg *Config) (subscriptions, commitments []Subscription, dateRange DateRange, err error) {
+	transactions, _ = cfg.ApplyGroups(transactions)
+	var completeMonths []string
+	if d.clock != nil {
+		transactions, completeMonths, dateRange = TransactionsAsOf(transactions, day(d.clock()))
+	} else {
+		completeMonths, dateRange = AnalyzeDataCoverage(transactions)
+	}
+	subscriptions, err = d.DetectAll(ctx, transactions, completeMonths, dateRange, cfg)
+	if err != nil {
+		return nil, nil, DateRange{}, err
+	}
+	subscriptions, commitments = SplitCommitments(subscriptions, cfg)
+	return subscriptions, commitments, dateRange, nil
+}
+
+// Detect runs the pattern strategies of the chain (known patterns need a config) on transactions.
+// It uses filteredTxs (from complete months) for pattern detection,
+// and allTxs to determine the full lifecycle including current month.
+// It returns ctx.Err() if the context is cancelled.
+func (d *Detector) Detect(ctx context.Context, filteredTxs []Transaction, allTxs []Transaction, dateRange DateRange) ([]Subscription, error) {
+	var completeMonths []string
+	for _, tx := range filteredTxs {
+		completeMonths = append(completeMonths, tx.Date.Format("2006-01"))
+	}
+	return d.runChain(ctx, d.chain(nil), d.group(allTxs, completeMonths, dateRange, nil))
+}
+
+// monthIndex numbers calendar months consecutively
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// billingMonth returns the first day of the month a payment is billed in: its calendar month, or
+// for credit card transactions, the month of the statement it's on. Payments after the statement
+// cut-off day are on the next month's statement.
+func billingMonth(tx Transaction) time.Time {
+	month := tx.Date.Month()
+	if tx.StatementDay > 0 && tx.Date.Day() > tx.StatementDay {
+		month++
+	}
+	return time.Date(tx.Date.Year(), month, 1, 0, 0, 0, 0, time.UTC)
 }
 
 // FilterExpenses returns only transactions with negative amounts (expenses).
@@ -114,12 +242,13 @@ func FilterExpenses(txs []Transaction) []Transaction {
 	return expenses
 }
 
-// IsMonthlyPattern checks if transactions occur exactly once per calendar month.
+// IsMonthlyPattern checks if transactions occur exactly once per calendar month (or billing
+// cycle, for credit card transactions).
 func IsMonthlyPattern(txs []Transaction) bool {
 	// Group by year-month
 	byMonth := make(map[string]int)
 	for _, tx := range txs {
-		key := tx.Date.Format("2006-01")
+		key := billingMonth(tx).Format("2006-01")
 		byMonth[key]++
 	}
 
@@ -163,6 +292,15 @@ func CalculateAverageAmount(txs []Transaction) float64 {
 	return sum / float64(len(txs))
 }
 
+// CalculateTotalPaid returns the sum of absolute amounts across all transactions.
+func CalculateTotalPaid(txs []Transaction) float64 {
+	total := 0.0
+	for _, tx := range txs {
+		total += math.Abs(tx.Amount)
+	}
+	return total
+}
+
 // CalculateAmountRange returns the min and max absolute amounts.
 func CalculateAmountRange(txs []Transaction) (min, max float64) {
 	if len(txs) == 0 {
@@ -194,36 +332,38 @@ func CalculateTypicalDay(txs []Transaction) int {
 	return sum / len(txs)
 }
 
-// DetermineStatus checks if a subscription is active or stopped based on payment history.
+// DetermineStatus checks if a monthly subscription is active or stopped based on payment history.
 func DetermineStatus(lastPayment time.Time, typicalDay int, dataEndDate time.Time) SubscriptionStatus {
+	return determineStatus(lastPayment, typicalDay, IntervalMonthly, defaultGraceDays, dataEndDate)
+}
+
+// determineStatus checks if a subscription with the given interval is active or stopped: it is stopped
+// once the next expected payment is more than graceDays overdue at asOf.
+func determineStatus(lastPayment time.Time, typicalDay int, interval Interval, graceDays int, asOf time.Time) SubscriptionStatus {
 	// Calculate how many months since last payment
-	lastPaymentStart := time.Date(lastPayment.Year(), lastPayment.Month(), 1, 0, 0, 0, 0, time.UTC)
-	currentMonthStart := time.Date(dataEndDate.Year(), dataEndDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthsDiff := monthIndex(asOf) - monthIndex(lastPayment)
 
-	// If last payment is in current month - active
-	if lastPaymentStart.Equal(currentMonthStart) {
+	// If the next payment isn't due yet - active
+	if monthsDiff < int(interval) {
 		return StatusActive
 	}
 
-	// Calculate months difference
-	monthsDiff := (currentMonthStart.Year()-lastPaymentStart.Year())*12 + int(currentMonthStart.Month()-lastPaymentStart.Month())
-
-	// If more than 1 month has passed completely, it's stopped
-	if monthsDiff > 1 {
+	// If the month of the next payment has passed completely, it's stopped
+	if monthsDiff > int(interval) {
 		return StatusStopped
 	}
 
-	// Last payment was last month - check if we're past expected date + 5 days
+	// Next payment is due this month - check if we're past expected date + grace period
 	expectedDay := typicalDay
-	lastDayOfMonth := time.Date(dataEndDate.Year(), dataEndDate.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	lastDayOfMonth := time.Date(asOf.Year(), asOf.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
 	if expectedDay > lastDayOfMonth {
 		expectedDay = lastDayOfMonth
 	}
 
-	expectedDate := time.Date(dataEndDate.Year(), dataEndDate.Month(), expectedDay, 0, 0, 0, 0, time.UTC)
-	gracePeriodEnd := expectedDate.AddDate(0, 0, 5)
+	expectedDate := time.Date(asOf.Year(), asOf.Month(), expectedDay, 0, 0, 0, 0, time.UTC)
+	gracePeriodEnd := expectedDate.AddDate(0, 0, graceDays)
 
-	if dataEndDate.After(gracePeriodEnd) {
+	if asOf.After(gracePeriodEnd) {
 		return StatusStopped
 	}
 
@@ -249,6 +389,61 @@ func AnalyzeDataCoverage(transactions []Transaction) ([]string, DateRange) {
 		}
 	}
 
+	return completeMonthsBetween(minDate, maxDate), DateRange{Start: minDate, End: maxDate}
+}
+
+// TransactionsAsOf returns the transactions up to and including date, with their complete months
+// and date range, to evaluate subscriptions as they were at that date. For data that continues
+// after date, the range ends at date rather than at the last transaction before it.
+func TransactionsAsOf(transactions []Transaction, date time.Time) ([]Transaction, []string, DateRange) {
+	var kept []Transaction
+	later := false
+	for _, tx := range transactions {
+		if tx.Date.After(date) {
+			later = true
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	completeMonths, dateRange := AnalyzeDataCoverage(kept)
+	if later && len(kept) > 0 {
+		dateRange.End = date
+		completeMonths = completeMonthsBetween(dateRange.Start, date)
+	}
+	return kept, completeMonths, dateRange
+}
+
+// MissingMonths returns the months between the first and the last transaction without any
+// transactions at all, which usually means that an export is missing
+func MissingMonths(transactions []Transaction) []string {
+	months := make(map[int]bool)
+	for _, tx := range transactions {
+		months[monthIndex(tx.Date)] = true
+	}
+	return missingMonths(months)
+}
+
+// missingMonths returns the months between the first and the last of months (by monthIndex) that
+// aren't in it
+func missingMonths(months map[int]bool) []string {
+	if len(months) == 0 {
+		return nil
+	}
+	first, last := math.MaxInt, math.MinInt
+	for m := range months {
+		first, last = min(first, m), max(last, m)
+	}
+	var missing []string
+	for m := first + 1; m < last; m++ {
+		if !months[m] {
+			missing = append(missing, time.Date(m/12, time.Month(m%12+1), 1, 0, 0, 0, 0, time.UTC).Format("2006-01"))
+		}
+	}
+	return missing
+}
+
+// completeMonthsBetween returns the complete months of data from minDate to maxDate
+func completeMonthsBetween(minDate, maxDate time.Time) []string {
 	// Determine complete months
 	// A month is complete if:
 	// - It's a past month (not the month of maxDate), OR
@@ -274,7 +469,7 @@ func AnalyzeDataCoverage(transactions []Transaction) ([]string, DateRange) {
 		current = current.AddDate(0, 1, 0)
 	}
 
-	return completeMonths, DateRange{Start: minDate, End: maxDate}
+	return completeMonths
 }
 
 // FilterToCompleteMonths returns only transactions from complete months.
@@ -293,7 +488,8 @@ func FilterToCompleteMonths(transactions []Transaction, completeMonths []string)
 	return filtered
 }
 
-// FilterOutMatched returns transactions whose text (case-insensitive) is not in the matched set.
+// FilterOutMatched returns transactions whose payee (text, case-insensitive, per person) is not in
+// the matched set.
 func FilterOutMatched(transactions []Transaction, matchedTexts map[string]bool) []Transaction {
 	if len(matchedTexts) == 0 {
 		return transactions
@@ -301,98 +497,146 @@ func FilterOutMatched(transactions []Transaction, matchedTexts map[string]bool)
 
 	var filtered []Transaction
 	for _, tx := range transactions {
-		if !matchedTexts[strings.ToLower(tx.Text)] {
+		if !matchedTexts[payeeKey(tx)] {
 			filtered = append(filtered, tx)
 		}
 	}
 	return filtered
 }
 
-// DetectKnownSubscriptions finds subscriptions based on configured known patterns.
+// DetectKnown finds subscriptions based on configured known patterns (the known-patterns strategy).
 // Unlike regular detection, these can match even with a single occurrence and
 // include transactions from the current (incomplete) month.
 // Returns known subscriptions and the set of transaction texts that matched (to exclude from regular detection).
-func DetectKnownSubscriptions(allTxs []Transaction, dateRange DateRange, cfg *Config) ([]Subscription, map[string]bool) {
-	matchedTexts := make(map[string]bool) // tracks which transaction texts matched known patterns
+func (d *Detector) DetectKnown(allTxs []Transaction, dateRange DateRange, cfg *Config) ([]Subscription, map[string]bool) {
+	subscriptions := KnownPatternsStrategy{}.Match(d.group(allTxs, nil, dateRange, cfg))
+	return subscriptions, claimedPayees(subscriptions)
+}
 
-	if cfg == nil || len(cfg.Known) == 0 {
-		return nil, matchedTexts
+// DetectAll runs the strategy chain (known patterns first by default) on (grouped) transactions
+// and applies the config's exclusions
+func (d *Detector) DetectAll(ctx context.Context, transactions []Transaction, completeMonths []string, dateRange DateRange, cfg *Config) ([]Subscription, error) {
+	subscriptions, err := d.runChain(ctx, d.chain(cfg), d.group(transactions, completeMonths, dateRange, cfg))
+	if err != nil {
+		return nil, err
 	}
+	return applyShares(d.exclude(subscriptions, cfg), cfg), nil
+}
 
-	// Group matching transactions by the known subscription pattern
-	type matchGroup struct {
-		pattern string
-		txs     []Transaction
+// exclude applies the exclusion filters from the config
+func (d *Detector) exclude(subscriptions []Subscription, cfg *Config) []Subscription {
+	if d.observer != nil && cfg != nil {
+		for _, sub := range subscriptions {
+			if rule := cfg.ExcludedBy(sub); rule != nil {
+				d.observer.Observe(DetectionEvent{Kind: DetectionRejected, Payee: sub.Name, Code: RejectionExcluded, Reason: "excluded by the config", Rule: rule.Pattern})
+			}
+		}
 	}
-	byPattern := make(map[string]*matchGroup)
+	return FilterByExclusions(subscriptions, cfg)
+}
 
-	for _, tx := range allTxs {
-		// Only consider expenses
-		if tx.Amount >= 0 {
+// applyShares scales the amounts of shared subscriptions (see Config.GetShare) to your share, so
+// that displayed costs and totals are what you actually pay
+func applyShares(subscriptions []Subscription, cfg *Config) []Subscription {
+	for i, sub := range subscriptions {
+		share := cfg.GetShare(sub.Name)
+		if share == 1 {
 			continue
 		}
-
-		known := cfg.MatchesKnown(tx)
-		if known == nil {
-			continue
+		sub.Share = share
+		sub.AvgAmount *= share
+		sub.LatestAmount *= share
+		sub.MinAmount *= share
+		sub.MaxAmount *= share
+		sub.TotalPaid *= share
+		sub.Transactions = slices.Clone(sub.Transactions)
+		for j := range sub.Transactions {
+			sub.Transactions[j].Amount *= share
+		}
+		sub.AnnualCharges = slices.Clone(sub.AnnualCharges)
+		for j := range sub.AnnualCharges {
+			sub.AnnualCharges[j].Amount *= share
 		}
+		subscriptions[i] = sub
+	}
+	return subscriptions
+}
 
-		// Mark this text as matched (case-insensitive key)
-		matchedTexts[strings.ToLower(tx.Text)] = true
+// chain returns the strategies to run: those given with WithStrategies, else those enabled in the
+// config, else known patterns followed by one strategy per interval (longest first)
+func (d *Detector) chain(cfg *Config) []Strategy {
+	if d.strategies != nil {
+		return d.strategies
+	}
+	if cfg != nil && len(cfg.strategies) > 0 {
+		return cfg.strategies
+	}
+	chain := []Strategy{KnownPatternsStrategy{}}
+	for _, interval := range slices.Backward(d.intervals) {
+		chain = append(chain, IntervalStrategy{Interval: interval})
+	}
+	return chain
+}
+
+// group builds the input of the strategies
+func (d *Detector) group(transactions []Transaction, completeMonths []string, dateRange DateRange, cfg *Config) *GroupedTransactions {
+	in := d.input(groupByPayee(transactions, d.patternMonths(completeMonths, dateRange)), dateRange, cfg)
+	if d.ignoreGaps {
+		in.setDataGaps(MissingMonths(transactions))
+	}
+	return in
+}
 
-		if byPattern[known.Pattern] == nil {
-			byPattern[known.Pattern] = &matchGroup{pattern: known.Pattern}
+// input builds the input of the strategies from grouped payees
+func (d *Detector) input(payees []PayeeGroup, dateRange DateRange, cfg *Config) *GroupedTransactions {
+	if d.observer != nil {
+		for _, payee := range payees {
+			for i := range payee.Transactions {
+				d.observer.Observe(DetectionEvent{Kind: DetectionGrouped, Payee: payee.Name, Transaction: &payee.Transactions[i]})
+			}
 		}
-		byPattern[known.Pattern].txs = append(byPattern[known.Pattern].txs, tx)
 	}
+	return &GroupedTransactions{
+		Payees:         payees,
+		DateRange:      dateRange,
+		AsOf:           d.statusDate(dateRange),
+		Config:         cfg,
+		Tolerance:      d.tolerance,
+		MinOccurrences: d.minOccurrences,
+		GraceDays:      d.graceDays,
+		observer:       d.observer,
+	}
+}
 
+// runChain runs the strategies in order. Each strategy only sees the payees that earlier ones
+// didn't claim. Duplicates of known subscriptions are merged into them (see mergeKnownDuplicates).
+func (d *Detector) runChain(ctx context.Context, chain []Strategy, in *GroupedTransactions) ([]Subscription, error) {
 	var subscriptions []Subscription
-	for _, group := range byPattern {
-		if len(group.txs) == 0 {
-			continue
+	for _, strategy := range chain {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		// Sort by date
-		sort.Slice(group.txs, func(i, j int) bool {
-			return group.txs[i].Date.Before(group.txs[j].Date)
-		})
-
-		// Use the most recent transaction text as the display name
-		name := group.txs[len(group.txs)-1].Text
-
-		// Calculate statistics
-		avgAmount := CalculateAverageAmount(group.txs)
-		minAmount, maxAmount := CalculateAmountRange(group.txs)
-		typicalDay := CalculateTypicalDay(group.txs)
-
-		startDate := group.txs[0].Date
-		lastDate := group.txs[len(group.txs)-1].Date
-		latestAmount := group.txs[len(group.txs)-1].Amount
-
-		// Determine status
-		status := DetermineStatus(lastDate, typicalDay, dateRange.End)
-
-		subscriptions = append(subscriptions, Subscription{
-			Name:         name,
-			AvgAmount:    avgAmount,
-			LatestAmount: latestAmount,
-			MinAmount:    minAmount,
-			MaxAmount:    maxAmount,
-			Transactions: group.txs,
-			StartDate:    startDate,
-			LastDate:     lastDate,
-			TypicalDay:   typicalDay,
-			Status:       status,
-		})
-	}
-
-	// Sort: active first, then by amount
-	sort.Slice(subscriptions, func(i, j int) bool {
-		if subscriptions[i].Status != subscriptions[j].Status {
-			return subscriptions[i].Status == StatusActive
+		in.strategy = strategy.Name()
+		matched := strategy.Match(in)
+		for i := range matched {
+			matched[i].Strategy = in.strategy
+		}
+		subscriptions = append(subscriptions, matched...)
+		if d.observer != nil {
+			for i := range matched {
+				d.observer.Observe(DetectionEvent{Kind: DetectionAccepted, Payee: matched[i].Name, Strategy: in.strategy, Subscription: &matched[i]})
+			}
 		}
-		return math.Abs(subscriptions[i].AvgAmount) > math.Abs(subscriptions[j].AvgAmount)
-	})
 
-	return subscriptions, matchedTexts
+		claimed := claimedPayees(matched)
+		remaining := *in
+		remaining.Payees = nil
+		for _, payee := range in.Payees {
+			if !claimed[payee.Key] {
+				remaining.Payees = append(remaining.Payees, payee)
+			}
+		}
+		in = &remaining
+	}
+	return mergeKnownDuplicates(subscriptions, in), nil
 }
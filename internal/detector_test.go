@@ -1,6 +1,10 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -14,6 +18,16 @@ func date(s string) time.Time {
 	return t
 }
 
+// detect runs d.Detect with a background context and fails the test on errors
+func detect(t *testing.T, d *Detector, filteredTxs, allTxs []Transaction, dateRange DateRange) []Subscription {
+	t.Helper()
+	subs, err := d.Detect(context.Background(), filteredTxs, allTxs, dateRange)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	return subs
+}
+
 func TestFilterExpenses(t *testing.T) {
 	txs := []Transaction{
 		{Date: date("2025-01-15"), Text: "Expense", Amount: -100},
@@ -166,6 +180,19 @@ func TestCalculateAverageAmount(t *testing.T) {
 	}
 }
 
+func TestCalculateTotalPaid(t *testing.T) {
+	txs := []Transaction{
+		{Amount: -100},
+		{Amount: -200},
+		{Amount: -300},
+	}
+
+	total := CalculateTotalPaid(txs)
+	if total != 600 {
+		t.Errorf("expected 600, got %f", total)
+	}
+}
+
 func TestCalculateAmountRange(t *testing.T) {
 	txs := []Transaction{
 		{Amount: -150},
@@ -337,7 +364,7 @@ func TestDetectSubscriptions(t *testing.T) {
 	filteredTxs := FilterToCompleteMonths(allTxs, []string{"2025-01", "2025-02", "2025-03"})
 	dateRange := DateRange{Start: date("2025-01-10"), End: date("2025-04-10")}
 
-	subs := DetectSubscriptions(filteredTxs, allTxs, dateRange, 0.10)
+	subs := detect(t, NewDetector(WithTolerance(0.10)), filteredTxs, allTxs, dateRange)
 
 	if len(subs) != 1 {
 		t.Fatalf("expected 1 subscription, got %d", len(subs))
@@ -370,7 +397,7 @@ func TestDetectSubscriptions_Stopped(t *testing.T) {
 	filteredTxs := FilterToCompleteMonths(allTxs, []string{"2025-01", "2025-02", "2025-03"})
 	dateRange := DateRange{Start: date("2025-01-15"), End: date("2025-04-20")}
 
-	subs := DetectSubscriptions(filteredTxs, allTxs, dateRange, 0.10)
+	subs := detect(t, NewDetector(WithTolerance(0.10)), filteredTxs, allTxs, dateRange)
 
 	if len(subs) != 1 {
 		t.Fatalf("expected 1 subscription, got %d", len(subs))
@@ -385,6 +412,80 @@ func TestDetectSubscriptions_Stopped(t *testing.T) {
 	}
 }
 
+func TestDetector_Options(t *testing.T) {
+	allTxs := []Transaction{
+		{Date: date("2024-03-05"), Text: "Domain", Amount: -150},
+		{Date: date("2025-03-05"), Text: "Domain", Amount: -150},
+		{Date: date("2025-01-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-02-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-03-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-04-15"), Text: "Other", Amount: -10}, // just to set date range
+	}
+	completeMonths, dateRange := AnalyzeDataCoverage(allTxs)
+	filteredTxs := FilterToCompleteMonths(allTxs, completeMonths)
+	byName := func(subs []Subscription) map[string]Subscription {
+		m := make(map[string]Subscription)
+		for _, sub := range subs {
+			m[sub.Name] = sub
+		}
+		return m
+	}
+
+	// Defaults: the yearly payee is treated as a (stopped) monthly subscription
+	subs := byName(detect(t, NewDetector(), filteredTxs, allTxs, dateRange))
+	if subs["Domain"].Interval != IntervalMonthly || subs["Domain"].Status != StatusStopped {
+		t.Errorf("expected Domain as a stopped monthly subscription by default, got %+v", subs["Domain"])
+	}
+	if subs["Gym"].Status != StatusActive {
+		t.Errorf("expected Gym to be active within the grace period, got %s", subs["Gym"].Status)
+	}
+
+	subs = byName(detect(t, NewDetector(WithIntervals(IntervalMonthly, IntervalYearly)), filteredTxs, allTxs, dateRange))
+	domain := subs["Domain"]
+	if domain.Interval != IntervalYearly || domain.Status != StatusActive || domain.MonthlyCost() != 12.5 {
+		t.Errorf("expected Domain as an active yearly subscription costing 12.5/month, got %+v", domain)
+	}
+
+	subs = byName(detect(t, NewDetector(WithIntervals(IntervalYearly)), filteredTxs, allTxs, dateRange))
+	if _, ok := subs["Gym"]; ok || len(subs) != 1 {
+		t.Errorf("expected only the yearly subscription, got %+v", subs)
+	}
+
+	subs = byName(detect(t, NewDetector(WithMinOccurrences(3)), filteredTxs, allTxs, dateRange))
+	if _, ok := subs["Domain"]; ok || len(subs) != 1 {
+		t.Errorf("expected only Gym with 3 payments, got %+v", subs)
+	}
+
+	// Gym's April payment (expected on the 20th) becomes overdue after the grace period
+	asOf := func(s string) DetectorOption { return WithClock(func() time.Time { return date(s) }) }
+	subs = byName(detect(t, NewDetector(asOf("2025-04-24")), filteredTxs, allTxs, dateRange))
+	if subs["Gym"].Status != StatusActive {
+		t.Errorf("expected Gym to be active on 2025-04-24, got %s", subs["Gym"].Status)
+	}
+	subs = byName(detect(t, NewDetector(asOf("2025-04-24"), WithGracePeriod(2)), filteredTxs, allTxs, dateRange))
+	if subs["Gym"].Status != StatusStopped {
+		t.Errorf("expected Gym to be stopped on 2025-04-24 with a 2-day grace period, got %s", subs["Gym"].Status)
+	}
+}
+
+func TestDetector_Cancelled(t *testing.T) {
+	allTxs := []Transaction{
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-02-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-03-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-04-20"), Text: "Other", Amount: -10},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := NewDetector().Analyze(ctx, allTxs, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from Analyze, got %v", err)
+	}
+	if _, _, err := NewDetector().Analyze(context.Background(), allTxs, nil); err != nil {
+		t.Errorf("expected no error with a live context, got %v", err)
+	}
+}
+
 func TestDetectKnownSubscriptions(t *testing.T) {
 	// Create transactions - some matching known patterns, some not
 	allTxs := []Transaction{
@@ -417,7 +518,7 @@ func TestDetectKnownSubscriptions(t *testing.T) {
 		cfg.Known[i].regex = re
 	}
 
-	subs, matchedTexts := DetectKnownSubscriptions(allTxs, dateRange, cfg)
+	subs, matchedTexts := NewDetector().DetectKnown(allTxs, dateRange, cfg)
 
 	// Should detect 2 known subscriptions
 	if len(subs) != 2 {
@@ -436,6 +537,71 @@ func TestDetectKnownSubscriptions(t *testing.T) {
 	}
 }
 
+func TestDetectKnownSubscriptions_RequireRecurrence(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-20"), Text: "Adobe Stock", Amount: -299},
+		{Date: date("2025-01-05"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-02-05"), Text: "Netflix", Amount: -99},
+	}
+	dateRange := DateRange{Start: date("2025-01-05"), End: date("2025-02-28")}
+	cfg, err := parseConfig([]byte("use_default_known: false\nknown_require_recurrence: true\nknown:\n  - pattern: ADOBE\n  - pattern: NETFLIX\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+
+	subs, _ := NewDetector().DetectKnown(txs, dateRange, cfg)
+	if len(subs) != 2 || subs[0].Name != "Netflix" || subs[0].Status != StatusActive || subs[1].Name != "Adobe Stock" || subs[1].Status != StatusPossible {
+		t.Fatalf("expected Netflix active and Adobe Stock possible, got %+v", subs)
+	}
+	if total := ActiveMonthlyTotal(subs); total != 99 {
+		t.Errorf("expected only Netflix in the monthly total, got %v", total)
+	}
+
+	cfg.KnownRequireRecurrence = false
+	subs, _ = NewDetector().DetectKnown(txs, dateRange, cfg)
+	for _, sub := range subs {
+		if sub.Status == StatusPossible {
+			t.Errorf("expected no possible subscriptions by default, got %s", sub.Name)
+		}
+	}
+}
+
+func TestDetectKnownSubscriptions_Interval(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2024-02-10"), Text: "Amazon Prime", Amount: -1490},
+		{Date: date("2025-02-10"), Text: "Amazon Prime", Amount: -1490},
+		{Date: date("2025-03-03"), Text: "Dropbox", Amount: -1200},
+		{Date: date("2025-01-20"), Text: "Netflix", Amount: -99},
+	}
+	dateRange := DateRange{Start: date("2024-02-10"), End: date("2025-06-30")}
+	cfg, err := parseConfig([]byte("use_default_known: false\nknown:\n  - pattern: AMAZON\\s*PRIME\n  - pattern: DROPBOX\n    interval: yearly\n  - pattern: NETFLIX\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+
+	subs, _ := NewDetector().DetectKnown(txs, dateRange, cfg)
+	got := make(map[string]Subscription)
+	for _, sub := range subs {
+		got[sub.Name] = sub
+	}
+	// Detected from the gap between payments
+	if prime := got["Amazon Prime"]; prime.Interval != IntervalYearly || prime.Status != StatusActive {
+		t.Errorf("expected Amazon Prime yearly and active, got interval %d, %s", prime.Interval, prime.Status)
+	}
+	// Configured
+	if dropbox := got["Dropbox"]; dropbox.Interval != IntervalYearly || dropbox.Status != StatusActive {
+		t.Errorf("expected Dropbox yearly and active, got interval %d, %s", dropbox.Interval, dropbox.Status)
+	}
+	// Monthly by default
+	if netflix := got["Netflix"]; netflix.Interval != IntervalMonthly || netflix.Status != StatusStopped {
+		t.Errorf("expected Netflix monthly and stopped, got interval %d, %s", netflix.Interval, netflix.Status)
+	}
+
+	if _, err := parseConfig([]byte("known:\n  - pattern: DROPBOX\n    interval: weekly\n")); err == nil {
+		t.Error("expected an error for an unknown interval")
+	}
+}
+
 func TestDetectKnownSubscriptions_AmountFilter(t *testing.T) {
 	allTxs := []Transaction{
 		{Date: date("2025-01-15"), Text: "Service", Amount: -49},  // within range
@@ -461,7 +627,7 @@ func TestDetectKnownSubscriptions_AmountFilter(t *testing.T) {
 		cfg.Known[i].regex = re
 	}
 
-	subs, _ := DetectKnownSubscriptions(allTxs, dateRange, cfg)
+	subs, _ := NewDetector().DetectKnown(allTxs, dateRange, cfg)
 
 	if len(subs) != 1 {
 		t.Fatalf("expected 1 subscription, got %d", len(subs))
@@ -498,7 +664,7 @@ func TestDetectKnownSubscriptions_DateFilter(t *testing.T) {
 		}
 	}
 
-	subs, _ := DetectKnownSubscriptions(allTxs, dateRange, cfg)
+	subs, _ := NewDetector().DetectKnown(allTxs, dateRange, cfg)
 
 	if len(subs) != 1 {
 		t.Fatalf("expected 1 subscription, got %d", len(subs))
@@ -593,7 +759,7 @@ func TestDetectKnownSubscriptions_WithDefaults(t *testing.T) {
 		t.Fatalf("NewDefaultConfig() failed: %v", err)
 	}
 
-	subs, matchedTexts := DetectKnownSubscriptions(allTxs, dateRange, cfg)
+	subs, matchedTexts := NewDetector().DetectKnown(allTxs, dateRange, cfg)
 
 	// Should detect Netflix and Spotify as known subscriptions
 	if len(subs) != 2 {
@@ -648,3 +814,304 @@ func TestDefaultKnownSubscriptions_Patterns(t *testing.T) {
 		}
 	}
 }
+
+func TestConfig_DisableKnown(t *testing.T) {
+	cfg, err := parseConfig([]byte("disable_known: [github, HBO Max]\nknown:\n  - pattern: GITHUB SPONSORS\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if len(cfg.Known) != len(DefaultKnownSubscriptions)-2+1 { // GITHUB and HBO\s*MAX
+		t.Errorf("expected 2 default patterns disabled, got %d patterns", len(cfg.Known))
+	}
+	for text, matches := range map[string]bool{
+		"GitHub Marketplace":     false,
+		"GitHub Sponsors":        true, // the user's own pattern is kept
+		"HBO MAX":                false,
+		"HBOMAX":                 true, // a separate pattern
+		"Netflix":                true,
+		"Spotify Premium Family": true,
+	} {
+		if matched := cfg.MatchesKnown(Transaction{Text: text, Amount: -50}); (matched != nil) != matches {
+			t.Errorf("MatchesKnown(%q) = %v, want match %v", text, matched, matches)
+		}
+	}
+
+	if _, err := parseConfig([]byte("disable_known: [NOT A SERVICE]\n")); err == nil {
+		t.Error("expected an error for an entry matching no default pattern")
+	}
+}
+
+func TestConfig_KnownBounds(t *testing.T) {
+	matches := func(cfg *Config, text string, amount float64) bool {
+		return cfg.MatchesKnown(Transaction{Text: text, Amount: -amount}) != nil
+	}
+
+	cfg, err := parseConfig([]byte("currency: SEK\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if !matches(cfg, "NETFLIX.COM", 199) || !matches(cfg, "NETFLIX.COM", 1500) {
+		t.Error("expected monthly and annual Netflix payments to match")
+	}
+	if matches(cfg, "PELOTON INTERACTIVE", 25000) {
+		t.Error("expected a bike from Peloton not to match")
+	}
+
+	// The bounds follow the currency
+	cfg.ScaleKnownBounds("USD")
+	if matches(cfg, "NETFLIX.COM", 1500) {
+		t.Error("expected 1500 USD not to match Netflix")
+	}
+	cfg.ScaleKnownBounds("XYZ")
+	if !matches(cfg, "NETFLIX.COM", 1500) {
+		t.Error("expected no bounds for a currency without an exchange rate")
+	}
+
+	// A config pattern replaces the built-in one with the same pattern
+	cfg, err = parseConfig([]byte("currency: SEK\nknown:\n  - pattern: peloton\n    max_amount: 30000\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if len(cfg.Known) != len(DefaultKnownSubscriptions) {
+		t.Errorf("expected the built-in pattern to be replaced, got %d patterns", len(cfg.Known))
+	}
+	if !matches(cfg, "PELOTON INTERACTIVE", 25000) {
+		t.Error("expected the config's bound to apply")
+	}
+}
+
+func TestDetector_StatementDay(t *testing.T) {
+	// A card charge posted around the turn of the month, twice in March by calendar
+	var txs []Transaction
+	for _, d := range []string{"2025-01-30", "2025-03-01", "2025-03-30", "2025-05-02", "2025-05-30"} {
+		txs = append(txs, Transaction{Date: date(d), Text: "Spotify", Amount: -119})
+	}
+	txs = append(txs,
+		Transaction{Date: date("2025-01-01"), Text: "Salary", Amount: 30000},
+		Transaction{Date: date("2025-06-30"), Text: "Salary", Amount: 30000},
+	)
+	detector := NewDetector(WithClock(func() time.Time { return date("2025-06-30") }))
+
+	subs, _, err := detector.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected no subscription by calendar month, got %+v", subs)
+	}
+
+	// With a statement cut-off on the 15th, each charge is on its own statement
+	for i := range txs {
+		txs[i].StatementDay = 15
+	}
+	subs, _, err = detector.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Name != "Spotify" || len(subs[0].Transactions) != 5 {
+		t.Fatalf("expected Spotify detected by billing cycle, got %+v", subs)
+	}
+
+	stream := detector.Stream(nil)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	streamed, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != 1 || streamed[0].Name != "Spotify" {
+		t.Errorf("expected the same result streaming, got %+v", streamed)
+	}
+
+	if got := billingMonth(Transaction{Date: date("2025-12-20"), StatementDay: 15}); !got.Equal(date("2026-01-01")) {
+		t.Errorf("billingMonth() = %v, want 2026-01-01", got)
+	}
+}
+
+func TestDetector_PartialMonths(t *testing.T) {
+	// Six weeks of data: only January is complete
+	txs := []Transaction{
+		{Date: date("2025-01-01"), Text: "Salary", Amount: 30000},
+		{Date: date("2025-01-05"), Text: "Streamio", Amount: -99},
+		{Date: date("2025-02-05"), Text: "Streamio", Amount: -99},
+		{Date: date("2025-02-12"), Text: "Groceries", Amount: -450},
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected no subscription from complete months only, got %+v", subs)
+	}
+
+	detector := NewDetector(WithPartialMonths(true))
+	subs, _, err = detector.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Name != "Streamio" || subs[0].Status != StatusActive {
+		t.Fatalf("expected Streamio detected with partial months, got %+v", subs)
+	}
+
+	stream := detector.Stream(nil)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	streamed, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != 1 || streamed[0].Name != "Streamio" {
+		t.Errorf("expected the same result streaming, got %+v", streamed)
+	}
+}
+
+func TestDetector_IgnoreDataGaps(t *testing.T) {
+	// The March and April exports are missing
+	txs := []Transaction{
+		{Date: date("2025-01-01"), Text: "Salary", Amount: 30000},
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-02-01"), Text: "Salary", Amount: 30000},
+		{Date: date("2025-02-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-05-01"), Text: "Salary", Amount: 30000},
+		{Date: date("2025-05-10"), Text: "Groceries", Amount: -450},
+	}
+	if got := MissingMonths(txs); !reflect.DeepEqual(got, []string{"2025-03", "2025-04"}) {
+		t.Errorf("MissingMonths() = %v, want [2025-03 2025-04]", got)
+	}
+	if got := MissingMonths(txs[:4]); got != nil {
+		t.Errorf("MissingMonths() = %v, want none for continuous data", got)
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Status != StatusStopped {
+		t.Fatalf("expected Netflix stopped across the gap, got %+v", subs)
+	}
+
+	detector := NewDetector(WithIgnoreDataGaps(true))
+	subs, _, err = detector.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Status != StatusActive || !subs[0].LastDate.Equal(date("2025-02-15")) {
+		t.Fatalf("expected Netflix active with the gap ignored, got %+v", subs)
+	}
+
+	stream := detector.Stream(nil)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	if got := stream.MissingMonths(); !reflect.DeepEqual(got, []string{"2025-03", "2025-04"}) {
+		t.Errorf("stream.MissingMonths() = %v, want [2025-03 2025-04]", got)
+	}
+	streamed, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != 1 || streamed[0].Status != StatusActive {
+		t.Errorf("expected the same result streaming, got %+v", streamed)
+	}
+}
+
+func TestDetector_AsOf(t *testing.T) {
+	// Netflix is cancelled after June, Gym starts in September
+	var txs []Transaction
+	for m := 1; m <= 12; m++ {
+		month := fmt.Sprintf("2025-%02d", m)
+		txs = append(txs, Transaction{Date: date(month + "-01"), Text: "Salary", Amount: 30000})
+		if m <= 6 {
+			txs = append(txs, Transaction{Date: date(month + "-15"), Text: "Netflix", Amount: -99})
+		}
+		if m >= 9 {
+			txs = append(txs, Transaction{Date: date(month + "-20"), Text: "Gym", Amount: -299})
+		}
+	}
+
+	kept, completeMonths, dateRange := TransactionsAsOf(txs, date("2025-06-30"))
+	if len(kept) != 12 || !dateRange.End.Equal(date("2025-06-30")) || len(completeMonths) != 6 {
+		t.Errorf("TransactionsAsOf() = %d transactions, %v, %+v; want 12, 6 months, ending 2025-06-30", len(kept), completeMonths, dateRange)
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := statusByName(subs); status["Netflix"] != StatusStopped || status["Gym"] != StatusActive {
+		t.Fatalf("expected Netflix stopped and Gym active at the end of the data, got %v", status)
+	}
+
+	detector := NewDetector(WithClock(FixedClock(date("2025-06-30"))))
+	subs, _, err = detector.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := statusByName(subs); len(status) != 1 || status["Netflix"] != StatusActive {
+		t.Errorf("expected only Netflix, active, as of 2025-06-30, got %v", status)
+	}
+
+	stream := detector.Stream(nil)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	if _, r := stream.Coverage(); !r.End.Equal(date("2025-06-30")) {
+		t.Errorf("expected the streamed range to end at 2025-06-30, got %+v", r)
+	}
+	streamed, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := statusByName(streamed); len(status) != 1 || status["Netflix"] != StatusActive {
+		t.Errorf("expected the same result streaming, got %v", status)
+	}
+}
+
+func statusByName(subs []Subscription) map[string]SubscriptionStatus {
+	status := make(map[string]SubscriptionStatus)
+	for _, s := range subs {
+		status[s.Name] = s.Status
+	}
+	return status
+}
+
+func TestDetector_Shares(t *testing.T) {
+	var txs []Transaction
+	for m := 1; m <= 6; m++ {
+		month := fmt.Sprintf("2025-%02d", m)
+		txs = append(txs, Transaction{Date: date(month + "-01"), Text: "Salary", Amount: 30000})
+		txs = append(txs, Transaction{Date: date(month + "-15"), Text: "Spotify Family", Amount: -200})
+		txs = append(txs, Transaction{Date: date(month + "-20"), Text: "Netflix", Amount: -120})
+	}
+	cfg, err := parseConfig([]byte("split:\n  Spotify Family: 0.5\nshared_with:\n  Netflix: 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts := map[string]float64{}
+	for _, sub := range subs {
+		amounts[sub.Name] = sub.LatestAmount
+		if sub.Transactions[0].Amount != sub.LatestAmount {
+			t.Errorf("%s: expected transactions scaled to the share, got %v", sub.Name, sub.Transactions[0].Amount)
+		}
+	}
+	if amounts["Spotify Family"] != -100 || amounts["Netflix"] != -40 {
+		t.Errorf("expected shares of -100 and -40, got %v", amounts)
+	}
+	if txs[1].Amount != -200 {
+		t.Errorf("input transactions were modified: %v", txs[1].Amount)
+	}
+
+	for _, bad := range []string{"split:\n  Netflix: 1.5\n", "shared_with:\n  Netflix: 0\n", "split:\n  Netflix: 0.5\nshared_with:\n  Netflix: 2\n"} {
+		if _, err := parseConfig([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
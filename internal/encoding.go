@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Many European bank exports aren't UTF-8 but ISO-8859-1 or Windows-1252, and tools on Windows
+// often write UTF-16 with a byte order mark. Text-based parsers read their input through
+// OpenText or DecodeText, so payee names like "Café" come out the same whatever the encoding
+// of the export (and group together).
+
+// textDecoder returns a transformer converting text to UTF-8: UTF-8 and UTF-16 are recognized by
+// their byte order mark (which is removed); otherwise valid UTF-8 passes through and any other
+// byte is decoded as Windows-1252, a superset of ISO-8859-1's printable characters
+func textDecoder() transform.Transformer {
+	return unicode.BOMOverride(utf8Fallback{})
+}
+
+// OpenText opens a text file for parsing, converting its content to UTF-8 while it's read
+func OpenText(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{transform.NewReader(f, textDecoder()), f}, nil
+}
+
+// DecodeText converts text to UTF-8 like OpenText. Data that already is UTF-8 without a byte
+// order mark is returned as is.
+func DecodeText(data []byte) []byte {
+	if utf8.Valid(data) && !bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		return data
+	}
+	decoded, _, err := transform.Bytes(textDecoder(), data)
+	if err != nil {
+		return data
+	}
+	return decoded
+}
+
+// utf8Fallback passes valid UTF-8 through and decodes the bytes that aren't as Windows-1252.
+// Deciding byte by byte (rather than for the whole file from its start) also handles exports that
+// are ASCII for megabytes before the first accented name.
+type utf8Fallback struct{ transform.NopResetter }
+
+func (utf8Fallback) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if c := src[nSrc]; c < utf8.RuneSelf {
+			if nDst >= len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = c
+			nDst++
+			nSrc++
+			continue
+		}
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 {
+			r = charmap.Windows1252.DecodeByte(src[nSrc])
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDecodeText(t *testing.T) {
+	utf16le, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String("Café Ölund")
+	utf16be, _ := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().String("Café Ölund")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"ascii", "Netflix", "Netflix"},
+		{"utf-8", "Café Ölund", "Café Ölund"},
+		{"utf-8 with BOM", "\xef\xbb\xbfCafé", "Café"},
+		{"iso-8859-1", "Caf\xe9 \xd6lund", "Café Ölund"},
+		{"windows-1252", "\x80 99 \x96 Caf\xe9", "€ 99 – Café"},
+		{"mixed", "Café Caf\xe9", "Café Café"},
+		{"truncated utf-8 sequence at the end", "Caf\xc3", "CafÃ"},
+		{"utf-16le with BOM", utf16le, "Café Ölund"},
+		{"utf-16be with BOM", utf16be, "Café Ölund"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(DecodeText([]byte(tt.input))); got != tt.expected {
+				t.Errorf("DecodeText(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpenText(t *testing.T) {
+	// Long enough that multi-byte sequences straddle the transformer's buffers
+	content := strings.Repeat("Café \xd6lund ", 10000)
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenText(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(bufio.NewReaderSize(f, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Repeat("Café Ölund ", 10000); string(data) != expected {
+		t.Errorf("unexpected content: %q...", data[:40])
+	}
+}
+
+func TestParseSimpleJSON_Encodings(t *testing.T) {
+	json := `{"transactions": [{"date": "2025-01-15", "text": "Café Ölund", "amount": -99}]}`
+	utf16le, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(json)
+	latin1 := strings.NewReplacer("é", "\xe9", "Ö", "\xd6").Replace(json)
+
+	for name, content := range map[string]string{"utf-16le": utf16le, "iso-8859-1": latin1, "utf-8 with BOM": "\xef\xbb\xbf" + json} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tx.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if format, err := DetectFormat(path); err != nil || format != "simple-json" {
+				t.Errorf("DetectFormat = %q, %v", format, err)
+			}
+
+			txs, err := ParseSimpleJSON(context.Background(), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var streamed []Transaction
+			if err := StreamSimpleJSON(context.Background(), path, func(tx Transaction) error {
+				streamed = append(streamed, tx)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if len(txs) != 1 || txs[0].Text != "Café Ölund" || len(streamed) != 1 || streamed[0].Text != "Café Ölund" {
+				t.Errorf("expected Café Ölund, got %+v and %+v", txs, streamed)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownSource is returned (wrapped) when a file's source type is missing or not registered
+var ErrUnknownSource = errors.New("unknown source type")
+
+// ErrInvalidConfig is returned (wrapped) when a config file can't be parsed or fails validation
+var ErrInvalidConfig = errors.New("invalid config")
+
+// ParseError is returned when a transaction file can't be parsed
+type ParseError struct {
+	File string // path of the file
+	Line int    // 1-based line (or spreadsheet row) of the problem, 0 if unknown
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("parsing %s line %d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("parsing %s: %v", e.File, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
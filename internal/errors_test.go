@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ParseFile(context.Background(), "no-such-format", "data.csv"); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("expected ErrUnknownSource, got %v", err)
+	}
+
+	badDate := filepath.Join(dir, "bad-date.json")
+	os.WriteFile(badDate, []byte("{\n  \"transactions\": [\n    {\"date\": \"2025-01-01\", \"text\": \"A\", \"amount\": -1},\n    {\"date\": \"2025-13-01\", \"text\": \"B\", \"amount\": -1}\n  ]\n}\n"), 0644)
+	badType := filepath.Join(dir, "bad-type.json")
+	os.WriteFile(badType, []byte("{\n  \"transactions\": [\n    {\"date\": \"2025-01-01\", \"text\": \"A\", \"amount\": \"-1\"}\n  ]\n}\n"), 0644)
+
+	for _, tt := range []struct {
+		path string
+		line int
+	}{
+		{badDate, 4},
+		{badType, 3},
+	} {
+		_, err := ParseFile(context.Background(), "simple-json", tt.path)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected a ParseError for %s, got %v", tt.path, err)
+		}
+		if parseErr.File != tt.path || parseErr.Line != tt.line {
+			t.Errorf("expected %s line %d, got %s line %d (%v)", tt.path, tt.line, parseErr.File, parseErr.Line, err)
+		}
+	}
+
+	badConfig := filepath.Join(dir, "config.yaml")
+	os.WriteFile(badConfig, []byte("exclude:\n  - \"(\"\n"), 0644)
+	if _, err := LoadConfig(badConfig); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil || errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a missing config file not to be reported as invalid, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// EventKind is the type of a subscription lifecycle event
+type EventKind string
+
+const (
+	EventStarted      EventKind = "started"
+	EventStopped      EventKind = "stopped"
+	EventResumed      EventKind = "resumed"
+	EventPriceChanged EventKind = "price_changed"
+)
+
+// Event is a dated subscription lifecycle event
+type Event struct {
+	Date         string    `json:"date"` // YYYY-MM-DD
+	Subscription string    `json:"subscription"`
+	Kind         EventKind `json:"kind"`
+	OldAmount    float64   `json:"old_amount,omitempty"` // previous payment amount (price changes)
+	NewAmount    float64   `json:"new_amount,omitempty"` // payment amount at the event
+	OldPlan      string    `json:"old_plan,omitempty"`   // plan before a price change that is likely a plan change (see AnnotateEventPlans)
+	NewPlan      string    `json:"new_plan,omitempty"`   // plan after it
+}
+
+// DetectEvents derives lifecycle events from the payment history of each subscription:
+// the first payment (started), amount changes between consecutive payments (price_changed),
+// gaps of at least one full calendar month without payment (stopped, then resumed),
+// and the last payment of stopped subscriptions (stopped).
+// Events are sorted by date, then subscription name.
+func DetectEvents(subs []Subscription) []Event {
+	var events []Event
+	for _, sub := range subs {
+		txs := make([]Transaction, len(sub.Transactions))
+		copy(txs, sub.Transactions)
+		sort.Slice(txs, func(i, j int) bool { return txs[i].Date.Before(txs[j].Date) })
+		if len(txs) == 0 {
+			continue
+		}
+
+		event := func(tx Transaction, kind EventKind) Event {
+			return Event{Date: tx.Date.Format("2006-01-02"), Subscription: sub.Name, Kind: kind, NewAmount: math.Abs(tx.Amount)}
+		}
+
+		events = append(events, event(txs[0], EventStarted))
+		for i := 1; i < len(txs); i++ {
+			prev, curr := txs[i-1], txs[i]
+			monthsDiff := (curr.Date.Year()-prev.Date.Year())*12 + int(curr.Date.Month()-prev.Date.Month())
+			if monthsDiff > 1 {
+				events = append(events, event(prev, EventStopped), event(curr, EventResumed))
+			}
+			// Compare at cent precision to avoid float noise
+			if math.Round(math.Abs(prev.Amount)*100) != math.Round(math.Abs(curr.Amount)*100) {
+				e := event(curr, EventPriceChanged)
+				e.OldAmount = math.Abs(prev.Amount)
+				events = append(events, e)
+			}
+		}
+		if sub.Status == StatusStopped {
+			events = append(events, event(txs[len(txs)-1], EventStopped))
+		}
+	}
+
+	SortEvents(events)
+	return events
+}
+
+// SortEvents sorts events by date, then subscription name
+func SortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		return strings.ToLower(events[i].Subscription) < strings.ToLower(events[j].Subscription)
+	})
+}
+
+// RecordEvents adds events not already in the state (same date, subscription and kind)
+// and returns the number of events added
+func (s *State) RecordEvents(events []Event) int {
+	key := func(e Event) string {
+		return fmt.Sprintf("%s|%s|%s", e.Date, strings.ToLower(e.Subscription), e.Kind)
+	}
+	existing := make(map[string]bool)
+	for _, e := range s.Events {
+		existing[key(e)] = true
+	}
+
+	added := 0
+	for _, e := range events {
+		if existing[key(e)] {
+			continue
+		}
+		existing[key(e)] = true
+		s.Events = append(s.Events, e)
+		added++
+	}
+	SortEvents(s.Events)
+	return added
+}
+
+// EventFilter selects events by subscription name, kind and date
+type EventFilter struct {
+	Name  string   // case-insensitive substring of the subscription name
+	Kinds []string // event kinds to include (empty = all)
+	Since string   // include events on or after this date (YYYY-MM-DD)
+	Until string   // include events on or before this date (YYYY-MM-DD)
+}
+
+// FilterEvents returns the events matching the filter
+func FilterEvents(events []Event, f EventFilter) []Event {
+	var result []Event
+	for _, e := range events {
+		if f.Name != "" && !strings.Contains(strings.ToLower(e.Subscription), strings.ToLower(f.Name)) {
+			continue
+		}
+		if len(f.Kinds) > 0 && !containsKind(f.Kinds, e.Kind) {
+			continue
+		}
+		if f.Since != "" && e.Date < f.Since {
+			continue
+		}
+		if f.Until != "" && e.Date > f.Until {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+func containsKind(kinds []string, kind EventKind) bool {
+	for _, k := range kinds {
+		if strings.EqualFold(k, string(kind)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintEventsTable outputs lifecycle events as a table
+func PrintEventsTable(w io.Writer, events []Event, opts OutputOptions) {
+	loc := opts.Locale
+	if len(events) == 0 {
+		fmt.Fprintln(w, loc.T("No events recorded (use --save-snapshot)."))
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Date"), loc.T("Name"), loc.T("Event"), loc.T("Amount")})
+	for _, e := range events {
+		date := e.Date
+		if d, err := time.Parse("2006-01-02", e.Date); err == nil {
+			date = loc.FormatDate(d)
+		}
+		amount := opts.Currency.Format(e.NewAmount)
+		kind := formatEventKind(e.Kind, loc)
+		if e.Kind == EventPriceChanged {
+			amount = fmt.Sprintf("%s → %s", opts.Currency.Format(e.OldAmount), opts.Currency.Format(e.NewAmount))
+		}
+		if e.NewPlan != "" {
+			kind = text.FgYellow.Sprint(loc.T("PLAN CHANGED"))
+			amount = fmt.Sprintf("%s → %s (%s)", e.OldPlan, e.NewPlan, amount)
+		}
+		t.AppendRow(table.Row{date, e.Subscription, kind, amount})
+	}
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 4, Align: text.AlignRight},
+	})
+	t.Render()
+}
+
+// formatEventKind returns a colored, translated label for an event kind
+func formatEventKind(kind EventKind, loc Locale) string {
+	switch kind {
+	case EventStarted:
+		return text.FgGreen.Sprint(loc.T("STARTED"))
+	case EventStopped:
+		return text.FgRed.Sprint(loc.T("STOPPED"))
+	case EventResumed:
+		return text.FgCyan.Sprint(loc.T("RESUMED"))
+	case EventPriceChanged:
+		return text.FgYellow.Sprint(loc.T("PRICE CHANGED"))
+	default:
+		return string(kind)
+	}
+}
+
+// JSONEvents is the JSON output format for the events subcommand
+type JSONEvents struct {
+	SchemaVersion int     `json:"schema_version"`
+	Events        []Event `json:"events"`
+	Currency      string  `json:"currency"`
+}
+
+// PrintEventsJSON outputs lifecycle events in JSON format
+func PrintEventsJSON(w io.Writer, events []Event, currency Currency) {
+	output := JSONEvents{
+		SchemaVersion: JSONSchemaVersion,
+		Events:        events,
+		Currency:      currency.Code,
+	}
+	if output.Events == nil {
+		output.Events = []Event{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectEvents(t *testing.T) {
+	day := func(d string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", d)
+		return parsed
+	}
+	subs := []Subscription{
+		{
+			Name:   "Spotify",
+			Status: StatusActive,
+			Transactions: []Transaction{
+				{Date: day("2025-03-01"), Amount: -119},
+				{Date: day("2025-01-01"), Amount: -119},
+				{Date: day("2025-02-01"), Amount: -119},
+				{Date: day("2025-05-01"), Amount: -129}, // April skipped, new price
+			},
+		},
+		{
+			Name:   "Gym",
+			Status: StatusStopped,
+			Transactions: []Transaction{
+				{Date: day("2025-01-20"), Amount: -300},
+				{Date: day("2025-02-20"), Amount: -300},
+			},
+		},
+	}
+
+	events := DetectEvents(subs)
+
+	expected := []struct {
+		date string
+		name string
+		kind EventKind
+	}{
+		{"2025-01-01", "Spotify", EventStarted},
+		{"2025-01-20", "Gym", EventStarted},
+		{"2025-02-20", "Gym", EventStopped},
+		{"2025-03-01", "Spotify", EventStopped},
+		{"2025-05-01", "Spotify", EventResumed},
+		{"2025-05-01", "Spotify", EventPriceChanged},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, e := range expected {
+		if events[i].Date != e.date || events[i].Subscription != e.name || events[i].Kind != e.kind {
+			t.Errorf("event %d: expected %s %s %s, got %s %s %s", i, e.date, e.name, e.kind,
+				events[i].Date, events[i].Subscription, events[i].Kind)
+		}
+	}
+	if events[5].OldAmount != 119 || events[5].NewAmount != 129 {
+		t.Errorf("expected price change 119 -> 129, got %.0f -> %.0f", events[5].OldAmount, events[5].NewAmount)
+	}
+}
+
+func TestState_RecordEvents(t *testing.T) {
+	var state State
+	events := []Event{
+		{Date: "2025-01-01", Subscription: "Netflix", Kind: EventStarted},
+		{Date: "2025-02-01", Subscription: "Netflix", Kind: EventPriceChanged},
+	}
+	if added := state.RecordEvents(events); added != 2 {
+		t.Errorf("expected 2 events added, got %d", added)
+	}
+
+	// Re-recording the same events (different case) only adds the new one
+	events = append(events, Event{Date: "2025-03-01", Subscription: "NETFLIX", Kind: EventStopped})
+	events[0].Subscription = "NETFLIX"
+	if added := state.RecordEvents(events); added != 1 {
+		t.Errorf("expected 1 event added, got %d", added)
+	}
+	if len(state.Events) != 3 {
+		t.Errorf("expected 3 stored events, got %d", len(state.Events))
+	}
+
+	filtered := FilterEvents(state.Events, EventFilter{Kinds: []string{"stopped"}, Since: "2025-02-15"})
+	if len(filtered) != 1 || filtered[0].Kind != EventStopped {
+		t.Errorf("expected the stopped event, got %+v", filtered)
+	}
+}
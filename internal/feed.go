@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxFeedEntries limits the number of events in the Atom feed (newest first)
+const maxFeedEntries = 100
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+// FormatEvent returns a one-line description of a lifecycle event
+func FormatEvent(e Event, currency Currency) string {
+	switch e.Kind {
+	case EventStarted:
+		return fmt.Sprintf("Subscription started: %s %s/month", e.Subscription, currency.Format(e.NewAmount))
+	case EventStopped:
+		return fmt.Sprintf("Subscription stopped: %s", e.Subscription)
+	case EventResumed:
+		return fmt.Sprintf("Subscription resumed: %s %s/month", e.Subscription, currency.Format(e.NewAmount))
+	case EventPriceChanged:
+		if e.NewPlan != "" {
+			return fmt.Sprintf("Plan changed: %s %s → %s, %s → %s/month", e.Subscription, e.OldPlan, e.NewPlan, currency.Format(e.OldAmount), currency.Format(e.NewAmount))
+		}
+		return fmt.Sprintf("Price changed: %s %s → %s/month", e.Subscription, currency.Format(e.OldAmount), currency.Format(e.NewAmount))
+	default:
+		return fmt.Sprintf("%s: %s", e.Kind, e.Subscription)
+	}
+}
+
+// WriteAtomFeed writes lifecycle events as an Atom feed, newest first.
+// baseURL is the absolute URL of the web UI, used for the feed and entry links.
+func WriteAtomFeed(w io.Writer, events []Event, currency Currency, baseURL string, now time.Time) error {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	feed := atomFeed{
+		Title:   "Subscription changes",
+		ID:      baseURL + "/feed.atom",
+		Updated: now.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: baseURL + "/feed.atom", Rel: "self"},
+			{Href: baseURL + "/"},
+		},
+		Author: atomAuthor{Name: "subscription-detector"},
+	}
+
+	for i := len(events) - 1; i >= 0 && len(feed.Entries) < maxFeedEntries; i-- {
+		e := events[i]
+		updated := now.UTC().Format(time.RFC3339)
+		if d, err := time.Parse("2006-01-02", e.Date); err == nil {
+			updated = d.Format(time.RFC3339)
+			if len(feed.Entries) == 0 {
+				feed.Updated = updated
+			}
+		}
+		title := FormatEvent(e, currency)
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title: title,
+			// Stable across requests so feed readers don't show events twice
+			ID:      fmt.Sprintf("%s/events/%s/%s/%s", baseURL, e.Date, url.PathEscape(strings.ToLower(e.Subscription)), e.Kind),
+			Updated: updated,
+			Link:    atomLink{Href: baseURL + "/subscriptions/" + url.PathEscape(e.Subscription)},
+			Summary: fmt.Sprintf("%s (%s)", title, e.Date),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// handleFeed serves lifecycle events as an Atom feed: events recorded in the state file
+// plus events derived from the imported transactions. The name and kind query parameters
+// filter the events like the events subcommand.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	state, err := LoadState(s.StatePath)
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state.RecordEvents(DetectEvents(subs)) // in memory only, nothing is saved
+
+	q := r.URL.Query()
+	events := FilterEvents(state.Events, EventFilter{Name: q.Get("name"), Kinds: q["kind"]})
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	baseURL := scheme + "://" + r.Host
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	WriteAtomFeed(w, events, s.Currency, baseURL, time.Now())
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer_Feed(t *testing.T) {
+	server := &Server{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Tolerance: 0.35,
+		Currency:  GetCurrency("USD"),
+	}
+	handler := server.Handler()
+	handler.ServeHTTP(httptest.NewRecorder(), uploadRequest(t, "/api/import", "../testdata/sample.json"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/feed.atom", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("expected Atom feed, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid feed XML: %v\n%s", err, rec.Body.String())
+	}
+	// Netflix and Spotify started, Spotify price change - newest first
+	if len(feed.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(feed.Entries), feed.Entries)
+	}
+	first := feed.Entries[0]
+	if !strings.Contains(first.Title, "Price changed: Spotify") || first.Updated != "2025-07-01T00:00:00Z" {
+		t.Errorf("unexpected newest entry: %+v", first)
+	}
+	if first.Link.Href != "http://example.com/subscriptions/Spotify" {
+		t.Errorf("unexpected entry link: %s", first.Link.Href)
+	}
+	if feed.Updated != first.Updated {
+		t.Errorf("expected feed updated %s, got %s", first.Updated, feed.Updated)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed.atom?kind=started&name=netflix", nil))
+	feed = atomFeed{}
+	xml.Unmarshal(rec.Body.Bytes(), &feed)
+	if len(feed.Entries) != 1 || !strings.Contains(feed.Entries[0].Title, "Subscription started: Netflix") {
+		t.Errorf("expected only Netflix started, got %+v", feed.Entries)
+	}
+}
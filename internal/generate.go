@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Price change behaviors of generated subscriptions
+const (
+	PriceChangeNone      = "none"      // constant prices
+	PriceChangeIncreases = "increases" // occasional price increases, like most subscription services
+	PriceChangeFluctuate = "fluctuate" // small changes every payment, like currency conversion
+)
+
+// GenerateOptions configures GenerateTransactions
+type GenerateOptions struct {
+	Months        int       // months of data, ending with the month of End
+	End           time.Time // last day of the data
+	Subscriptions int       // number of monthly subscriptions
+	Stopped       int       // how many of the subscriptions stop halfway through the data (at most all)
+	Noise         int       // non-recurring purchases per month
+	PriceChange   string    // PriceChangeNone, PriceChangeIncreases or PriceChangeFluctuate
+	Seed          uint64    // the same seed and options generate the same transactions
+}
+
+// generatedServices are the names of generated subscriptions, before numbered ones
+var generatedServices = []string{
+	"Netflix", "Spotify", "Disney Plus", "HBO Max", "YouTube Premium", "Apple iCloud",
+	"Dropbox", "Adobe Creative Cloud", "GitHub", "Microsoft 365", "Audible", "Gym Membership",
+	"Mobile Plan", "Cloud Backup", "Newspaper", "Storytel", "Patreon", "ChatGPT Plus",
+}
+
+// generatedMerchants are the payees of generated non-recurring purchases
+var generatedMerchants = []string{
+	"Grocery Store", "Coffee Shop", "Restaurant", "Gas Station", "Pharmacy", "Hardware Store",
+	"Bookstore", "Clothing Store", "Taxi", "Cinema", "Online Shop", "Bakery",
+}
+
+// GenerateTransactions generates a synthetic dataset for benchmarks and experiments: a monthly
+// salary, monthly subscriptions on fixed days and non-recurring purchases on random days
+func GenerateTransactions(opts GenerateOptions) ([]Transaction, error) {
+	if opts.Months < 1 {
+		return nil, fmt.Errorf("months must be at least 1")
+	}
+	if opts.Subscriptions < 0 || opts.Noise < 0 || opts.Stopped < 0 {
+		return nil, fmt.Errorf("subscriptions, stopped and noise must not be negative")
+	}
+	switch opts.PriceChange {
+	case "", PriceChangeNone, PriceChangeIncreases, PriceChangeFluctuate:
+	default:
+		return nil, fmt.Errorf("unknown price change behavior %q (available: none, increases, fluctuate)", opts.PriceChange)
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	end := time.Date(opts.End.Year(), opts.End.Month(), opts.End.Day(), 0, 0, 0, 0, time.UTC)
+	first := time.Date(end.Year(), end.Month()-time.Month(opts.Months-1), 1, 0, 0, 0, 0, time.UTC)
+	var txs []Transaction
+	add := func(date time.Time, text string, amount float64) {
+		if !date.After(end) {
+			txs = append(txs, Transaction{Date: date, Text: text, Amount: math.Round(amount*100) / 100})
+		}
+	}
+	// dayIn returns the day of a month, clamped to its last day
+	dayIn := func(month time.Time, day int) time.Time {
+		return time.Date(month.Year(), month.Month(), min(day, month.AddDate(0, 1, -1).Day()), 0, 0, 0, 0, time.UTC)
+	}
+
+	for m := range opts.Months {
+		add(first.AddDate(0, m, 24), "Salary", 30000)
+	}
+
+	for i := range opts.Subscriptions {
+		name := fmt.Sprintf("Service %d", i+1-len(generatedServices))
+		if i < len(generatedServices) {
+			name = generatedServices[i]
+		}
+		day := 1 + rng.IntN(28)
+		price := float64(49 + 10*rng.IntN(30))
+		months := opts.Months
+		if i < opts.Stopped {
+			months = max(opts.Months/2, 2)
+		}
+		for m := range months {
+			amount := price
+			switch opts.PriceChange {
+			case PriceChangeIncreases:
+				// About one increase of 5-20% a year
+				if m > 0 && rng.IntN(12) == 0 {
+					price = math.Round(price * (1.05 + 0.15*rng.Float64()))
+				}
+				amount = price
+			case PriceChangeFluctuate:
+				amount = price * (0.97 + 0.06*rng.Float64())
+			}
+			add(dayIn(first.AddDate(0, m, 0), day), name, -amount)
+		}
+	}
+
+	for m := range opts.Months {
+		month := first.AddDate(0, m, 0)
+		for range opts.Noise {
+			merchant := generatedMerchants[rng.IntN(len(generatedMerchants))]
+			add(dayIn(month, 1+rng.IntN(31)), merchant, -(20 + 980*rng.Float64()))
+		}
+	}
+
+	return sortedByDate(txs), nil
+}
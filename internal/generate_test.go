@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateTransactions(t *testing.T) {
+	opts := GenerateOptions{Months: 24, End: date("2025-12-31"), Subscriptions: 20, Stopped: 3, Noise: 20, PriceChange: PriceChangeIncreases, Seed: 42}
+	txs, err := GenerateTransactions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := GenerateTransactions(opts)
+	if !reflect.DeepEqual(txs, again) {
+		t.Error("expected the same transactions for the same seed")
+	}
+	if first, last := txs[0].Date, txs[len(txs)-1].Date; first.Before(date("2024-01-01")) || last.After(date("2025-12-31")) {
+		t.Errorf("expected data from 2024-01 to 2025-12, got %v to %v", first, last)
+	}
+
+	for _, priceChange := range []string{PriceChangeNone, PriceChangeIncreases, PriceChangeFluctuate} {
+		opts.PriceChange = priceChange
+		txs, _ := GenerateTransactions(opts)
+		subs, _, err := NewDetector().Analyze(context.Background(), txs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active, stopped := countByStatus(subs); active != 17 || stopped != 3 {
+			t.Errorf("%s: expected 17 active and 3 stopped subscriptions, got %d and %d", priceChange, active, stopped)
+		}
+	}
+
+	if _, err := GenerateTransactions(GenerateOptions{Months: 0}); err == nil {
+		t.Error("expected an error for no months")
+	}
+	if _, err := GenerateTransactions(GenerateOptions{Months: 12, PriceChange: "sometimes"}); err == nil {
+		t.Error("expected an error for an unknown price change behavior")
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	txs, err := GenerateTransactions(GenerateOptions{Months: 60, End: date("2025-12-31"), Subscriptions: 50, Stopped: 10, Noise: 300, PriceChange: PriceChangeIncreases, Seed: 1})
+	if err != nil {
+		b.Fatal(err)
+	}
+	cfg, err := NewDefaultConfig()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, _, err := NewDetector().Analyze(context.Background(), txs, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Grafana JSON datasource targets
+const (
+	grafanaTargetMonthlyCost   = "monthly_cost"
+	grafanaTargetSubscriptions = "subscriptions"
+)
+
+// MonthlyCost is the total paid for subscriptions in a calendar month
+type MonthlyCost struct {
+	Month time.Time // first day of the month (UTC)
+	Total float64   // sum of absolute payment amounts
+}
+
+// MonthlyCostSeries sums the payments of all subscriptions per calendar month, oldest first
+func MonthlyCostSeries(subs []Subscription) []MonthlyCost {
+	totals := make(map[time.Time]float64)
+	for _, sub := range subs {
+		for _, tx := range sub.Transactions {
+			month := time.Date(tx.Date.Year(), tx.Date.Month(), 1, 0, 0, 0, 0, time.UTC)
+			totals[month] += math.Abs(tx.Amount)
+		}
+	}
+
+	series := make([]MonthlyCost, 0, len(totals))
+	for month, total := range totals {
+		series = append(series, MonthlyCost{Month: month, Total: total})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Month.Before(series[j].Month) })
+	return series
+}
+
+// JSONMonthlyCost is the JSON format for a month of subscription spend
+type JSONMonthlyCost struct {
+	Month string  `json:"month"` // YYYY-MM
+	Total float64 `json:"total"`
+}
+
+// handleAPIMonthlyCost returns the monthly subscription spend as a flat JSON array
+// (e.g., for the Grafana Infinity datasource)
+func (s *Server) handleAPIMonthlyCost(w http.ResponseWriter, r *http.Request) {
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := []JSONMonthlyCost{}
+	for _, mc := range MonthlyCostSeries(subs) {
+		response = append(response, JSONMonthlyCost{Month: mc.Month.Format("2006-01"), Total: mc.Total})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// grafanaQueryRequest is the subset of a Grafana JSON datasource /query request that is used
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+		RefID  string `json:"refId"`
+	} `json:"targets"`
+}
+
+type grafanaTimeseries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"` // [value, unix ms]
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+type grafanaMetric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// registerGrafana adds the Grafana JSON datasource endpoints under /grafana
+func (s *Server) registerGrafana(mux *http.ServeMux) {
+	// Connection test
+	mux.HandleFunc("GET /grafana/{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /grafana/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, []grafanaMetric{
+			{Label: "Monthly cost", Value: grafanaTargetMonthlyCost},
+			{Label: "Subscriptions", Value: grafanaTargetSubscriptions},
+		})
+	})
+	// Legacy SimpleJSON datasource
+	mux.HandleFunc("POST /grafana/search", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, []string{grafanaTargetMonthlyCost, grafanaTargetSubscriptions})
+	})
+	mux.HandleFunc("POST /grafana/query", s.handleGrafanaQuery)
+}
+
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := []any{}
+	for _, target := range req.Targets {
+		switch target.Target {
+		case grafanaTargetMonthlyCost:
+			series := grafanaTimeseries{Target: grafanaTargetMonthlyCost, Datapoints: [][2]float64{}}
+			for _, mc := range MonthlyCostSeries(subs) {
+				if !req.Range.From.IsZero() && !mc.Month.AddDate(0, 1, 0).After(req.Range.From) {
+					continue
+				}
+				if !req.Range.To.IsZero() && mc.Month.After(req.Range.To) {
+					continue
+				}
+				series.Datapoints = append(series.Datapoints, [2]float64{mc.Total, float64(mc.Month.UnixMilli())})
+			}
+			response = append(response, series)
+		case grafanaTargetSubscriptions:
+			response = append(response, s.grafanaSubscriptionsTable(subs))
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) grafanaSubscriptionsTable(subs []Subscription) grafanaTable {
+	sorted := make([]Subscription, len(subs))
+	copy(sorted, subs)
+	SortSubscriptions(sorted, "name", "asc", s.Config)
+
+	table := grafanaTable{
+		Type: "table",
+		Columns: []grafanaColumn{
+			{Text: "Name", Type: "string"},
+			{Text: "Description", Type: "string"},
+			{Text: "Status", Type: "string"},
+			{Text: "Monthly", Type: "number"},
+			{Text: "Yearly", Type: "number"},
+			{Text: "Total Paid", Type: "number"},
+			{Text: "Started", Type: "time"},
+			{Text: "Last Seen", Type: "time"},
+		},
+		Rows: [][]any{},
+	}
+	for _, sub := range sorted {
+		latest := math.Abs(sub.LatestAmount)
+		table.Rows = append(table.Rows, []any{
+			sub.Name,
+			s.Config.GetDescription(sub.Name),
+			string(sub.Status),
+			latest,
+			latest * 12,
+			sub.TotalPaid,
+			sub.StartDate.UnixMilli(),
+			sub.LastDate.UnixMilli(),
+		})
+	}
+	return table
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+)
+
+// Fields that the subscription table can be grouped by (see GroupSubscriptions)
+const (
+	GroupByTag      = "tag"
+	GroupByCategory = "category"
+	GroupByStatus   = "status"
+	GroupByAccount  = "account"
+	GroupByInterval = "interval"
+)
+
+// SubscriptionGroup is a section of the subscription table
+type SubscriptionGroup struct {
+	Key           string // tag, category, status, account or interval name; "" for untagged, uncategorized or without account
+	Subscriptions []Subscription
+}
+
+// GroupSubscriptions splits subs into sections by a field (GroupByTag etc.), keeping their order
+// within each section. Sections by status are active before stopped, by interval shortest first,
+// and by tag, category or account alphabetical with the untagged, uncategorized or those without
+// account last. A subscription with several tags is in the section of each of them.
+func GroupSubscriptions(subs []Subscription, by string, cfg *Config) []SubscriptionGroup {
+	byKey := make(map[string][]Subscription)
+	var keys []string
+	add := func(key string, sub Subscription) {
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], sub)
+	}
+	for _, sub := range subs {
+		switch by {
+		case GroupByTag:
+			var tags []string
+			if cfg != nil {
+				tags = cfg.GetTags(sub.Name)
+			}
+			if len(tags) == 0 {
+				add("", sub)
+			}
+			for _, tag := range tags {
+				add(tag, sub)
+			}
+		case GroupByCategory:
+			add(cfg.GetCategory(sub.Name), sub)
+		case GroupByStatus:
+			add(string(sub.Status), sub)
+		case GroupByAccount:
+			add(subscriptionAccount(sub), sub)
+		case GroupByInterval:
+			add(max(sub.Interval, IntervalMonthly).String(), sub)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		switch by {
+		case GroupByStatus:
+			return statusOrder[SubscriptionStatus(a)] - statusOrder[SubscriptionStatus(b)]
+		case GroupByInterval:
+			ia, _ := parseInterval(a)
+			ib, _ := parseInterval(b)
+			return int(ia) - int(ib)
+		}
+		if (a == "") != (b == "") {
+			if a == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	groups := make([]SubscriptionGroup, len(keys))
+	for i, key := range keys {
+		groups[i] = SubscriptionGroup{Key: key, Subscriptions: byKey[key]}
+	}
+	return groups
+}
+
+// subscriptionAccount returns the account of the latest payment of a subscription that has one
+func subscriptionAccount(sub Subscription) string {
+	var account string
+	var latest Transaction
+	for _, tx := range sub.Transactions {
+		if tx.Account != "" && (account == "" || tx.Date.After(latest.Date)) {
+			account, latest = tx.Account, tx
+		}
+	}
+	return account
+}
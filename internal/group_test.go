@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGroupSubscriptions(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Spotify", Status: StatusActive, Transactions: []Transaction{
+			{Date: date("2025-01-01"), Account: "Card"},
+			{Date: date("2025-02-01"), Account: "Checking"},
+		}},
+		{Name: "Magazine", Status: StatusActive, Interval: IntervalYearly},
+		{Name: "HBO", Status: StatusStopped, Interval: IntervalQuarterly, Transactions: []Transaction{{Date: date("2025-01-05"), Account: "Card"}}},
+		{Name: "Netflix", Status: StatusActive},
+	}
+	cfg := &Config{
+		Tags:       map[string][]string{"Spotify": {"music", "entertainment"}, "Netflix": {"entertainment"}},
+		Categories: map[string]string{"Magazine": CategoryNews},
+	}
+
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{GroupByTag, "entertainment=Spotify,Netflix music=Spotify =Magazine,HBO"},
+		{GroupByCategory, "music=Spotify news=Magazine streaming=Netflix =HBO"},
+		{GroupByStatus, "active=Spotify,Magazine,Netflix stopped=HBO"},
+		{GroupByAccount, "Card=HBO Checking=Spotify =Magazine,Netflix"},
+		{GroupByInterval, "monthly=Spotify,Netflix quarterly=HBO yearly=Magazine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			var got []string
+			for _, g := range GroupSubscriptions(subs, tt.by, cfg) {
+				var names []string
+				for _, sub := range g.Subscriptions {
+					names = append(names, sub.Name)
+				}
+				got = append(got, fmt.Sprintf("%s=%s", g.Key, strings.Join(names, ",")))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("GroupSubscriptions(%s) = %s, want %s", tt.by, strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sheet tabs written by ExportToSheet
+const (
+	sheetTabSubscriptions = "Subscriptions"
+	sheetTabHistory       = "History"
+)
+
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// sheetsAPIURL is the Google Sheets API base URL (a variable so tests can point it to a local server)
+var sheetsAPIURL = "https://sheets.googleapis.com"
+
+// ServiceAccount is a Google service account key (the JSON file downloaded from the Cloud console)
+type ServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// LoadServiceAccount reads a service account key file
+func LoadServiceAccount(path string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sa ServiceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("parsing service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("service account key %s has no client_email or private_key", path)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &sa, nil
+}
+
+// accessToken exchanges a signed JWT for an OAuth2 access token (service account flow)
+func (sa *ServiceAccount) accessToken(ctx context.Context, client *http.Client, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("parsing service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("service account private key is not an RSA key")
+	}
+
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encode(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sa.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decoding token response: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// sheetsClient calls the Google Sheets API for one spreadsheet
+type sheetsClient struct {
+	http    *http.Client
+	token   string
+	sheetID string
+}
+
+// call sends a request to the spreadsheet's endpoint at path and decodes the response into result (if non-nil)
+func (c *sheetsClient) call(ctx context.Context, method, path string, payload, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/v4/spreadsheets/%s%s", sheetsAPIURL, url.PathEscape(c.sheetID), path), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("sheets API: %s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("sheets API: %s", resp.Status)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
+
+// ensureTabs adds the tabs that don't exist yet and returns which ones were created
+func (c *sheetsClient) ensureTabs(ctx context.Context, titles ...string) (map[string]bool, error) {
+	var meta struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.call(ctx, http.MethodGet, "?fields=sheets.properties.title", nil, &meta); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, s := range meta.Sheets {
+		existing[s.Properties.Title] = true
+	}
+
+	created := make(map[string]bool)
+	var requests []any
+	for _, title := range titles {
+		if !existing[title] {
+			created[title] = true
+			requests = append(requests, map[string]any{"addSheet": map[string]any{"properties": map[string]string{"title": title}}})
+		}
+	}
+	if len(requests) == 0 {
+		return created, nil
+	}
+	return created, c.call(ctx, http.MethodPost, ":batchUpdate", map[string]any{"requests": requests}, nil)
+}
+
+// SheetSubscriptionRows returns the Subscriptions tab contents: a header row and one row per subscription
+func SheetSubscriptionRows(subs []Subscription, cfg *Config, currency Currency) [][]any {
+	rows := [][]any{{"Name", "Description", "Tags", "Status", "Typical Day", "Started", "Last Seen", "Monthly", "Yearly", "Total Paid", "Currency"}}
+	for _, sub := range subs {
+		desc, tags := "", ""
+		if cfg != nil {
+			desc = cfg.GetDescription(sub.Name)
+			tags = strings.Join(cfg.GetTags(sub.Name), ", ")
+		}
+		latest := math.Abs(sub.LatestAmount)
+		rows = append(rows, []any{
+			sub.Name,
+			desc,
+			tags,
+			string(sub.Status),
+			sub.TypicalDay,
+			sub.StartDate.Format("2006-01-02"),
+			sub.LastDate.Format("2006-01-02"),
+			latest,
+			latest * 12,
+			sub.TotalPaid,
+			currency.Code,
+		})
+	}
+	return rows
+}
+
+// ExportToSheet writes the subscriptions to the Subscriptions tab of a Google spreadsheet
+// (replacing its contents) and appends a row with the totals to the History tab.
+// Missing tabs are created. The spreadsheet must be shared with the service account.
+func ExportToSheet(ctx context.Context, client *http.Client, sa *ServiceAccount, sheetID string, subs []Subscription, cfg *Config, currency Currency, now time.Time) error {
+	token, err := sa.accessToken(ctx, client, now)
+	if err != nil {
+		return err
+	}
+	c := &sheetsClient{http: client, token: token, sheetID: sheetID}
+
+	created, err := c.ensureTabs(ctx, sheetTabSubscriptions, sheetTabHistory)
+	if err != nil {
+		return err
+	}
+
+	// Values are written RAW so payee names starting with "=" are never evaluated as formulas
+	subsRange := url.PathEscape(sheetTabSubscriptions)
+	if err := c.call(ctx, http.MethodPost, "/values/"+subsRange+":clear", map[string]any{}, nil); err != nil {
+		return err
+	}
+	rows := SheetSubscriptionRows(subs, cfg, currency)
+	if err := c.call(ctx, http.MethodPut, "/values/"+subsRange+"!A1?valueInputOption=RAW",
+		map[string]any{"values": rows}, nil); err != nil {
+		return err
+	}
+
+	activeCount, _ := countByStatus(subs)
+	monthly := ActiveMonthlyTotal(subs)
+	history := [][]any{{now.Format("2006-01-02 15:04"), activeCount, monthly, monthly * 12, currency.Code}}
+	if created[sheetTabHistory] {
+		history = append([][]any{{"Date", "Active", "Monthly Total", "Yearly Total", "Currency"}}, history...)
+	}
+	return c.call(ctx, http.MethodPost, "/values/"+url.PathEscape(sheetTabHistory)+"!A1:append?valueInputOption=RAW&insertDataOption=INSERT_ROWS",
+		map[string]any{"values": history}, nil)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExportToSheet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	var mu sync.Mutex
+	var calls []string
+	values := make(map[string][][]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			if parts := strings.Split(r.Form.Get("assertion"), "."); len(parts) != 3 {
+				t.Errorf("expected a signed JWT assertion, got %q", r.Form.Get("assertion"))
+			}
+			w.Write([]byte(`{"access_token": "test-token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("missing access token on %s", r.URL.Path)
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"sheets": [{"properties": {"title": "Subscriptions"}}]}`))
+		case strings.Contains(r.URL.Path, "!A1"):
+			var body struct {
+				Values [][]any `json:"values"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if r.URL.Query().Get("valueInputOption") != "RAW" {
+				t.Errorf("expected RAW values, got %s", r.URL.RawQuery)
+			}
+			values[r.URL.Path] = body.Values
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	orig := sheetsAPIURL
+	sheetsAPIURL = server.URL
+	defer func() { sheetsAPIURL = orig }()
+
+	sa := &ServiceAccount{ClientEmail: "exporter@example.iam.gserviceaccount.com", PrivateKey: privateKey, TokenURI: server.URL + "/token"}
+	subs := []Subscription{
+		{Name: "Netflix", Status: StatusActive, TypicalDay: 15, LatestAmount: -99, TotalPaid: 1188},
+		{Name: "Gym", Status: StatusStopped, TypicalDay: 1, LatestAmount: -300, TotalPaid: 900},
+	}
+	now := time.Date(2026, 1, 2, 8, 30, 0, 0, time.UTC)
+	if err := ExportToSheet(context.Background(), server.Client(), sa, "sheet123", subs, nil, GetCurrency("SEK"), now); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	expectedCalls := []string{
+		"GET /v4/spreadsheets/sheet123",
+		"POST /v4/spreadsheets/sheet123:batchUpdate", // History tab is missing
+		"POST /v4/spreadsheets/sheet123/values/Subscriptions:clear",
+		"PUT /v4/spreadsheets/sheet123/values/Subscriptions!A1",
+		"POST /v4/spreadsheets/sheet123/values/History!A1:append",
+	}
+	if strings.Join(calls, "\n") != strings.Join(expectedCalls, "\n") {
+		t.Errorf("unexpected API calls:\n%s", strings.Join(calls, "\n"))
+	}
+
+	rows := values["/v4/spreadsheets/sheet123/values/Subscriptions!A1"]
+	if len(rows) != 3 || rows[1][0] != "Netflix" || rows[1][7] != float64(99) {
+		t.Errorf("unexpected subscription rows: %v", rows)
+	}
+	// New History tab gets a header row before the totals
+	history := values["/v4/spreadsheets/sheet123/values/History!A1:append"]
+	if len(history) != 2 || history[0][0] != "Date" || history[1][0] != "2026-01-02 08:30" || history[1][2] != float64(99) {
+		t.Errorf("unexpected history rows: %v", history)
+	}
+}
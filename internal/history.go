@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// HistoryEntry summarizes a single saved snapshot
+type HistoryEntry struct {
+	Timestamp    time.Time
+	DataEnd      string
+	ActiveCount  int
+	MonthlyTotal float64 // sum of latest amounts of active subscriptions
+
+	// RealMonthlyTotal is MonthlyTotal in the prices of the latest snapshot (see AdjustHistory)
+	RealMonthlyTotal float64
+}
+
+// PriceTrend describes how a subscription's price evolved across snapshots
+type PriceTrend struct {
+	Name      string
+	Amounts   []float64 // latest amount in each snapshot where the subscription was active
+	First     float64
+	Latest    float64
+	ChangePct float64 // relative change from First to Latest (0.1 = +10%)
+	Active    bool    // active in the most recent snapshot
+
+	// RealChangePct is ChangePct adjusted for inflation (see AdjustHistory)
+	RealChangePct float64
+}
+
+// AnalyzeHistory computes total cost per snapshot and per-subscription price trends
+func AnalyzeHistory(snapshots []Snapshot) ([]HistoryEntry, []PriceTrend) {
+	var entries []HistoryEntry
+	trendsByName := make(map[string]*PriceTrend)
+	var names []string
+
+	for i, snap := range snapshots {
+		entry := HistoryEntry{Timestamp: snap.Timestamp, DataEnd: snap.DataEnd}
+		for _, sub := range snap.Subscriptions {
+			key := strings.ToLower(sub.Name)
+			trend, ok := trendsByName[key]
+			if !ok {
+				trend = &PriceTrend{Name: sub.Name}
+				trendsByName[key] = trend
+				names = append(names, key)
+			}
+			if sub.Status != string(StatusActive) {
+				continue
+			}
+			entry.ActiveCount++
+			entry.MonthlyTotal += sub.LatestAmount
+			trend.Amounts = append(trend.Amounts, sub.LatestAmount)
+			trend.Name = sub.Name // most recent display name
+			if i == len(snapshots)-1 {
+				trend.Active = true
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	var trends []PriceTrend
+	for _, key := range names {
+		trend := trendsByName[key]
+		if len(trend.Amounts) == 0 {
+			continue // never seen active
+		}
+		trend.First = trend.Amounts[0]
+		trend.Latest = trend.Amounts[len(trend.Amounts)-1]
+		if trend.First != 0 {
+			trend.ChangePct = (trend.Latest - trend.First) / trend.First
+		}
+		trends = append(trends, *trend)
+	}
+
+	// Biggest relative increases first, then by name
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].ChangePct != trends[j].ChangePct {
+			return trends[i].ChangePct > trends[j].ChangePct
+		}
+		return strings.ToLower(trends[i].Name) < strings.ToLower(trends[j].Name)
+	})
+
+	return entries, trends
+}
+
+// PrintHistoryTable outputs the snapshot history and price trends as tables
+func PrintHistoryTable(w io.Writer, entries []HistoryEntry, trends []PriceTrend, opts OutputOptions) {
+	loc := opts.Locale
+	if len(entries) == 0 {
+		fmt.Fprintln(w, loc.T("No snapshots saved yet (use --save-snapshot)."))
+		return
+	}
+
+	totals := make([]float64, len(entries))
+	for i, e := range entries {
+		totals[i] = e.MonthlyTotal
+	}
+	fmt.Fprint(w, loc.Sprintf("Monthly cost over %d snapshot(s): %s\n\n", len(entries), Sparkline(totals)))
+	if opts.RealTerms {
+		last := entries[len(entries)-1]
+		fmt.Fprint(w, loc.Sprintf("Real amounts are adjusted for inflation to %d prices.\n\n", snapshotDate(Snapshot{Timestamp: last.Timestamp, DataEnd: last.DataEnd}).Year()))
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	header := table.Row{loc.T("Snapshot"), loc.T("Data Until"), loc.T("Active"), loc.T("Monthly"), loc.T("Change")}
+	if opts.RealTerms {
+		header = append(header, loc.T("Real"), loc.T("Real Change"))
+	}
+	t.AppendHeader(header)
+	for i, e := range entries {
+		change, realChange := "", ""
+		if i > 0 {
+			change = formatDelta(e.MonthlyTotal-entries[i-1].MonthlyTotal, opts.Currency)
+			realChange = formatDelta(e.RealMonthlyTotal-entries[i-1].RealMonthlyTotal, opts.Currency)
+		}
+		dataEnd := e.DataEnd
+		if d, err := time.Parse("2006-01-02", e.DataEnd); err == nil {
+			dataEnd = loc.FormatDate(d)
+		}
+		row := table.Row{loc.FormatDate(e.Timestamp), dataEnd, e.ActiveCount, opts.Currency.Format(e.MonthlyTotal), change}
+		if opts.RealTerms {
+			row = append(row, opts.Currency.Format(e.RealMonthlyTotal), realChange)
+		}
+		t.AppendRow(row)
+	}
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight},
+		{Number: 5, Align: text.AlignRight},
+		{Number: 6, Align: text.AlignRight},
+		{Number: 7, Align: text.AlignRight},
+	})
+	t.Render()
+
+	if len(trends) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	pt := table.NewWriter()
+	pt.SetOutputMirror(w)
+	trendHeader := table.Row{loc.T("Name"), loc.T("Trend"), loc.T("First"), loc.T("Latest"), loc.T("Change")}
+	if opts.RealTerms {
+		trendHeader = append(trendHeader, loc.T("Real Change"))
+	}
+	pt.AppendHeader(trendHeader)
+	for _, trend := range trends {
+		name := trend.Name
+		if !trend.Active {
+			name = text.FgHiBlack.Sprint(name)
+		}
+		row := table.Row{
+			name,
+			Sparkline(trend.Amounts),
+			opts.Currency.Format(trend.First),
+			opts.Currency.Format(trend.Latest),
+			formatPercentChange(trend.ChangePct),
+		}
+		if opts.RealTerms {
+			row = append(row, formatPercentChange(trend.RealChangePct))
+		}
+		pt.AppendRow(row)
+	}
+	styleTable(pt, opts)
+	pt.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight},
+		{Number: 5, Align: text.AlignRight},
+		{Number: 6, Align: text.AlignRight},
+	})
+	pt.Render()
+}
+
+// JSONHistory is the JSON output format for the history subcommand
+type JSONHistory struct {
+	SchemaVersion int                `json:"schema_version"`
+	Snapshots     []JSONHistoryEntry `json:"snapshots"`
+	Trends        []JSONPriceTrend   `json:"trends"`
+	Currency      string             `json:"currency"`
+}
+
+// JSONHistoryEntry is the JSON output format for a snapshot summary
+type JSONHistoryEntry struct {
+	Timestamp    string  `json:"timestamp"`
+	DataEnd      string  `json:"data_end"`
+	ActiveCount  int     `json:"active_count"`
+	MonthlyTotal float64 `json:"monthly_total"`
+	RealTotal    float64 `json:"real_monthly_total,omitempty"` // with --real, in the prices of the latest snapshot
+}
+
+// JSONPriceTrend is the JSON output format for a subscription's price trend
+type JSONPriceTrend struct {
+	Name      string    `json:"name"`
+	Amounts   []float64 `json:"amounts"`
+	First     float64   `json:"first"`
+	Latest    float64   `json:"latest"`
+	ChangePct float64   `json:"change_pct"`
+	Active    bool      `json:"active"`
+	RealPct   float64   `json:"real_change_pct,omitempty"` // with --real
+}
+
+// PrintHistoryJSON outputs the snapshot history and price trends in JSON format
+func PrintHistoryJSON(w io.Writer, entries []HistoryEntry, trends []PriceTrend, currency Currency) {
+	output := JSONHistory{
+		SchemaVersion: JSONSchemaVersion,
+		Snapshots:     []JSONHistoryEntry{},
+		Trends:        []JSONPriceTrend{},
+		Currency:      currency.Code,
+	}
+	for _, e := range entries {
+		output.Snapshots = append(output.Snapshots, JSONHistoryEntry{
+			Timestamp:    e.Timestamp.Format(time.RFC3339),
+			DataEnd:      e.DataEnd,
+			ActiveCount:  e.ActiveCount,
+			MonthlyTotal: currency.Round(e.MonthlyTotal),
+			RealTotal:    currency.Round(e.RealMonthlyTotal),
+		})
+	}
+	for _, trend := range trends {
+		output.Trends = append(output.Trends, JSONPriceTrend{
+			Name:      trend.Name,
+			Amounts:   trend.Amounts,
+			First:     trend.First,
+			Latest:    trend.Latest,
+			ChangePct: trend.ChangePct,
+			Active:    trend.Active,
+			RealPct:   trend.RealChangePct,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
+
+// styleTable applies the common table style used across all table outputs
+func styleTable(t table.Writer, opts OutputOptions) {
+	t.SetStyle(table.StyleRounded)
+	t.Style().Format.Header = text.FormatDefault
+	t.Style().Format.Footer = text.FormatDefault
+	t.Style().Size.WidthMax = opts.MaxWidth
+}
+
+// formatDelta formats a signed amount difference, colored red for increases and green for decreases
+func formatDelta(delta float64, currency Currency) string {
+	switch {
+	case math.Round(delta*100) > 0:
+		return text.FgRed.Sprint("+" + currency.Format(delta))
+	case math.Round(delta*100) < 0:
+		return text.FgGreen.Sprint("-" + currency.Format(-delta))
+	default:
+		return text.FgHiBlack.Sprint("±0")
+	}
+}
+
+// formatPercentChange formats a relative change (0.1 = +10%), colored like formatDelta
+func formatPercentChange(pct float64) string {
+	switch {
+	case math.Round(pct*1000) > 0:
+		return text.FgRed.Sprintf("+%.1f%%", pct*100)
+	case math.Round(pct*1000) < 0:
+		return text.FgGreen.Sprintf("%.1f%%", pct*100)
+	default:
+		return text.FgHiBlack.Sprint("±0%")
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzeHistory(t *testing.T) {
+	snapshots := []Snapshot{
+		{
+			Timestamp: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			Subscriptions: []SnapshotSubscription{
+				{Name: "Netflix", Status: "active", LatestAmount: 100},
+				{Name: "Spotify", Status: "active", LatestAmount: 119},
+			},
+		},
+		{
+			Timestamp: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			Subscriptions: []SnapshotSubscription{
+				{Name: "Netflix", Status: "active", LatestAmount: 110},
+				{Name: "Spotify", Status: "stopped", LatestAmount: 119},
+			},
+		},
+	}
+
+	entries, trends := AnalyzeHistory(snapshots)
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].ActiveCount != 2 || entries[0].MonthlyTotal != 219 {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].ActiveCount != 1 || entries[1].MonthlyTotal != 110 {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+
+	if len(trends) != 2 {
+		t.Fatalf("expected 2 trends, got %d", len(trends))
+	}
+	// Biggest increase first
+	netflix := trends[0]
+	if netflix.Name != "Netflix" || netflix.First != 100 || netflix.Latest != 110 {
+		t.Errorf("unexpected Netflix trend: %+v", netflix)
+	}
+	if diff := netflix.ChangePct - 0.1; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected +10%% change, got %f", netflix.ChangePct)
+	}
+	if !netflix.Active {
+		t.Error("expected Netflix to be active in latest snapshot")
+	}
+	if trends[1].Name != "Spotify" || trends[1].Active {
+		t.Errorf("expected inactive Spotify trend, got %+v", trends[1])
+	}
+}
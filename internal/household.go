@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PersonSubtotal is what one household member pays for active subscriptions
+type PersonSubtotal struct {
+	Person       string
+	Active       int
+	MonthlyTotal float64
+}
+
+// DuplicateAccount is a service that more than one household member pays for, e.g. two
+// separate family plans of the same streaming service
+type DuplicateAccount struct {
+	Name         string   // description from the config, else the name of the first subscription
+	Persons      []string // sorted
+	MonthlyTotal float64  // what the household pays for it in total
+}
+
+// HasPersons reports whether any subscription is labeled with a person (see Transaction.Person)
+func HasPersons(subs []Subscription) bool {
+	return slices.ContainsFunc(subs, func(sub Subscription) bool { return sub.Person != "" })
+}
+
+// SubtotalsByPerson sums the active subscriptions of each person, sorted by person. Subscriptions
+// without a person (e.g., imported transactions) are left out.
+func SubtotalsByPerson(subs []Subscription) []PersonSubtotal {
+	byPerson := make(map[string]*PersonSubtotal)
+	var persons []string
+	for _, sub := range subs {
+		if sub.Person == "" {
+			continue
+		}
+		s := byPerson[sub.Person]
+		if s == nil {
+			s = &PersonSubtotal{Person: sub.Person}
+			byPerson[sub.Person] = s
+			persons = append(persons, sub.Person)
+		}
+		if sub.Status == StatusActive {
+			s.Active++
+			s.MonthlyTotal += sub.MonthlyCost()
+		}
+	}
+	sort.Strings(persons)
+	subtotals := make([]PersonSubtotal, len(persons))
+	for i, person := range persons {
+		subtotals[i] = *byPerson[person]
+	}
+	return subtotals
+}
+
+// DuplicateAccounts returns the active services paid by more than one person, sorted by name.
+// Subscriptions are the same service if they have the same description in the config, or else
+// the same name (case-insensitive).
+func DuplicateAccounts(subs []Subscription, cfg *Config) []DuplicateAccount {
+	byService := make(map[string]*DuplicateAccount)
+	var keys []string
+	for _, sub := range subs {
+		if sub.Person == "" || sub.Status != StatusActive {
+			continue
+		}
+		name := sub.Name
+		if desc := cfg.GetDescription(sub.Name); desc != "" {
+			name = desc
+		}
+		key := strings.ToLower(name)
+		d := byService[key]
+		if d == nil {
+			d = &DuplicateAccount{Name: name}
+			byService[key] = d
+			keys = append(keys, key)
+		}
+		if !slices.Contains(d.Persons, sub.Person) {
+			d.Persons = append(d.Persons, sub.Person)
+		}
+		d.MonthlyTotal += sub.MonthlyCost()
+	}
+	sort.Strings(keys)
+	var duplicates []DuplicateAccount
+	for _, key := range keys {
+		if d := byService[key]; len(d.Persons) > 1 {
+			sort.Strings(d.Persons)
+			duplicates = append(duplicates, *d)
+		}
+	}
+	return duplicates
+}
+
+// PrintHousehold outputs the monthly subtotal of each person and the services paid by more than
+// one of them
+func PrintHousehold(w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) {
+	loc := opts.Locale
+	fmt.Fprint(w, loc.T("Per person (active):\n"))
+	for _, s := range SubtotalsByPerson(subs) {
+		fmt.Fprint(w, loc.Sprintf("  %-12s %d subscriptions, %s/month\n", s.Person, s.Active, opts.Currency.Format(s.MonthlyTotal)))
+	}
+	duplicates := DuplicateAccounts(subs, cfg)
+	if len(duplicates) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprint(w, loc.T("Paid by more than one person:\n"))
+	for _, d := range duplicates {
+		fmt.Fprint(w, loc.Sprintf("  %s (%s): %s/month\n", d.Name, strings.Join(d.Persons, ", "), opts.Currency.Format(d.MonthlyTotal)))
+	}
+}
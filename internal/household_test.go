@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHousehold(t *testing.T) {
+	var txs []Transaction
+	for _, month := range []string{"2025-01", "2025-02", "2025-03", "2025-04"} {
+		txs = append(txs,
+			Transaction{Date: date(month + "-10"), Text: "Netflix", Amount: -99, Person: "alice"},
+			Transaction{Date: date(month + "-12"), Text: "NETFLIX.COM", Amount: -129, Person: "bob"},
+			Transaction{Date: date(month + "-15"), Text: "Spotify", Amount: -119, Person: "bob"},
+		)
+	}
+	cfg, err := parseConfig([]byte("descriptions:\n  Netflix: Netflix\n  NETFLIX.COM: Netflix\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasPersons(subs) || len(subs) != 3 {
+		t.Fatalf("expected 3 subscriptions labeled with persons, got %+v", subs)
+	}
+
+	want := []PersonSubtotal{{Person: "alice", Active: 1, MonthlyTotal: 99}, {Person: "bob", Active: 2, MonthlyTotal: 248}}
+	if got := SubtotalsByPerson(subs); !reflect.DeepEqual(got, want) {
+		t.Errorf("SubtotalsByPerson() = %+v, want %+v", got, want)
+	}
+	wantDuplicates := []DuplicateAccount{{Name: "Netflix", Persons: []string{"alice", "bob"}, MonthlyTotal: 228}}
+	if got := DuplicateAccounts(subs, cfg); !reflect.DeepEqual(got, wantDuplicates) {
+		t.Errorf("DuplicateAccounts() = %+v, want %+v", got, wantDuplicates)
+	}
+	if got := DuplicateAccounts(subs, nil); got != nil {
+		t.Errorf("expected no duplicates without descriptions, got %+v", got)
+	}
+}
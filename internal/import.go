@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoredTransaction is the persisted form of an imported transaction (the simple JSON format)
+type StoredTransaction = SimpleJSONTransaction
+
+// ImportResult summarizes an import into the state file
+type ImportResult struct {
+	Added      int // transactions not previously stored
+	Duplicates int // transactions already stored (skipped)
+}
+
+// transactionKey identifies a transaction by date, text and amount (at cent precision)
+func transactionKey(date, text string, amount float64) string {
+	return fmt.Sprintf("%s|%s|%.2f", date, strings.TrimSpace(text), amount)
+}
+
+// ImportTransactions adds transactions that are not already stored.
+// Identical transactions are counted per key, so two equal payments on the same day are kept
+// as long as the imported data contains more of them than are already stored. This makes
+// re-importing overlapping exports safe.
+func (s *State) ImportTransactions(txs []Transaction) ImportResult {
+	stored := make(map[string]int)
+	for _, tx := range s.Transactions {
+		stored[transactionKey(tx.Date, tx.Text, tx.Amount)]++
+	}
+
+	var result ImportResult
+	seen := make(map[string]int)
+	for _, tx := range txs {
+		date := tx.Date.Format("2006-01-02")
+		key := transactionKey(date, tx.Text, tx.Amount)
+		seen[key]++
+		if seen[key] <= stored[key] {
+			result.Duplicates++
+			continue
+		}
+		s.Transactions = append(s.Transactions, newSimpleJSONTransaction(tx))
+		result.Added++
+	}
+
+	sort.SliceStable(s.Transactions, func(i, j int) bool {
+		return s.Transactions[i].Date < s.Transactions[j].Date
+	})
+	return result
+}
+
+// StoredTransactions returns the imported transactions
+func (s *State) StoredTransactions() ([]Transaction, error) {
+	txs := make([]Transaction, 0, len(s.Transactions))
+	for _, st := range s.Transactions {
+		date, err := time.Parse("2006-01-02", st.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in stored transaction %q: %w", st.Date, st.Text, err)
+		}
+		txs = append(txs, st.transaction(date))
+	}
+	return txs, nil
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CPI is a consumer price index table: the yearly average index by year. Amounts are compared in
+// real terms by converting them to the prices of a base year.
+type CPI map[int]float64
+
+// USConsumerPrices is the built-in CPI table for USD: the annual averages of the US CPI-U
+// (1982-84 = 100), from the Bureau of Labor Statistics
+var USConsumerPrices = CPI{
+	2000: 172.2, 2001: 177.1, 2002: 179.9, 2003: 184.0, 2004: 188.9,
+	2005: 195.3, 2006: 201.6, 2007: 207.342, 2008: 215.303, 2009: 214.537,
+	2010: 218.056, 2011: 224.939, 2012: 229.594, 2013: 232.957, 2014: 236.736,
+	2015: 237.017, 2016: 240.007, 2017: 245.120, 2018: 251.107, 2019: 255.657,
+	2020: 258.811, 2021: 270.970, 2022: 292.655, 2023: 304.702, 2024: 313.689,
+}
+
+func (c CPI) validate() error {
+	for year, index := range c {
+		if index <= 0 {
+			return fmt.Errorf("index for %d must be positive", year)
+		}
+	}
+	return nil
+}
+
+// Index returns the index of a year. Years before or after the table use its first or last year,
+// so amounts from them aren't adjusted relative to that year.
+func (c CPI) Index(year int) float64 {
+	if index, ok := c[year]; ok {
+		return index
+	}
+	years := make([]int, 0, len(c))
+	for y := range c {
+		years = append(years, y)
+	}
+	slices.Sort(years)
+	if len(years) == 0 {
+		return 1
+	}
+	if year < years[0] {
+		return c[years[0]]
+	}
+	if year > years[len(years)-1] {
+		return c[years[len(years)-1]]
+	}
+	// A year missing inside the table: use the closest year before it
+	i, _ := slices.BinarySearch(years, year)
+	return c[years[i-1]]
+}
+
+// Real converts an amount paid at date to the prices of the base year
+func (c CPI) Real(amount float64, date time.Time, base int) float64 {
+	return amount * c.Index(base) / c.Index(date.Year())
+}
+
+// ResolveCPI returns the CPI table from the config, else the built-in one for the currency.
+// There is no built-in table for currencies other than USD.
+func ResolveCPI(cfg *Config, currency string) (CPI, error) {
+	if cfg != nil && len(cfg.CPI) > 0 {
+		return cfg.CPI, nil
+	}
+	if strings.EqualFold(currency, "USD") {
+		return USConsumerPrices, nil
+	}
+	return nil, fmt.Errorf("no built-in CPI table for %s; add a cpi table with yearly index values to the config", currency)
+}
+
+// AdjustHistory sets the inflation-adjusted monthly totals and price changes of entries and
+// trends from AnalyzeHistory on the same snapshots, in the prices of the year of the latest
+// snapshot's data
+func AdjustHistory(snapshots []Snapshot, entries []HistoryEntry, trends []PriceTrend, cpi CPI) {
+	if len(snapshots) == 0 {
+		return
+	}
+	base := snapshotDate(snapshots[len(snapshots)-1]).Year()
+	deflated := make([]Snapshot, len(snapshots))
+	for i, snap := range snapshots {
+		date := snapshotDate(snap)
+		deflated[i] = snap
+		deflated[i].Subscriptions = slices.Clone(snap.Subscriptions)
+		for j := range deflated[i].Subscriptions {
+			deflated[i].Subscriptions[j].LatestAmount = cpi.Real(snap.Subscriptions[j].LatestAmount, date, base)
+		}
+	}
+
+	realEntries, realTrends := AnalyzeHistory(deflated)
+	for i := range entries {
+		entries[i].RealMonthlyTotal = realEntries[i].MonthlyTotal
+	}
+	realChange := make(map[string]float64)
+	for _, trend := range realTrends {
+		realChange[strings.ToLower(trend.Name)] = trend.ChangePct
+	}
+	for i := range trends {
+		trends[i].RealChangePct = realChange[strings.ToLower(trends[i].Name)]
+	}
+}
+
+// snapshotDate returns the date that the prices of a snapshot are from: the end of its data, else
+// when it was saved
+func snapshotDate(snap Snapshot) time.Time {
+	if d, err := time.Parse("2006-01-02", snap.DataEnd); err == nil {
+		return d
+	}
+	return snap.Timestamp
+}
+
+// AdjustSpend sets the inflation-adjusted spend of periods from SpendByPeriod, in the prices of
+// the year of the last period
+func AdjustSpend(subs []Subscription, periods []SpendPeriod, by string, cpi CPI) {
+	if len(periods) == 0 {
+		return
+	}
+	base := periods[len(periods)-1].Start.Year()
+	index := make(map[string]int)
+	for i, p := range periods {
+		index[p.Label(by)] = i
+	}
+	for _, sub := range subs {
+		for _, tx := range sub.Transactions {
+			i, ok := index[(SpendPeriod{Start: tx.Date}).Label(by)]
+			if ok {
+				periods[i].Real += cpi.Real(math.Abs(tx.Amount), tx.Date, base)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCPI(t *testing.T) {
+	cpi := CPI{2020: 100, 2021: 110, 2023: 121}
+	if got := cpi.Index(2022); got != 110 {
+		t.Errorf("expected a missing year to use the year before, got %v", got)
+	}
+	if got := cpi.Index(2019); got != 100 {
+		t.Errorf("expected years before the table to use its first year, got %v", got)
+	}
+	if got := cpi.Real(100, date("2020-06-01"), 2030); math.Abs(got-121) > 1e-9 {
+		t.Errorf("expected 100 in 2020 to be 121 in prices of the last year, got %v", got)
+	}
+
+	if _, err := ResolveCPI(nil, "USD"); err != nil {
+		t.Errorf("expected the built-in table for USD, got %v", err)
+	}
+	if _, err := ResolveCPI(nil, "SEK"); err == nil {
+		t.Error("expected an error without a table for SEK")
+	}
+	cfg, err := parseConfig([]byte("cpi:\n  2020: 100\n  2021: 110\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ResolveCPI(cfg, "SEK"); err != nil || got[2021] != 110 {
+		t.Errorf("expected the table from the config, got %v, %v", got, err)
+	}
+	if _, err := parseConfig([]byte("cpi:\n  2020: 0\n")); err == nil {
+		t.Error("expected a zero index to be rejected")
+	}
+}
+
+func TestAdjustForInflation(t *testing.T) {
+	cpi := CPI{2020: 100, 2021: 110}
+
+	// A price increase from 100 to 105 is a decrease in real terms with 10% inflation
+	snapshots := []Snapshot{
+		{Timestamp: time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC), DataEnd: "2020-12-31", Subscriptions: []SnapshotSubscription{{Name: "Gym", Status: "active", LatestAmount: 100}}},
+		{Timestamp: time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC), DataEnd: "2021-12-31", Subscriptions: []SnapshotSubscription{{Name: "Gym", Status: "active", LatestAmount: 105}}},
+	}
+	entries, trends := AnalyzeHistory(snapshots)
+	AdjustHistory(snapshots, entries, trends, cpi)
+	if math.Abs(entries[0].RealMonthlyTotal-110) > 1e-9 || entries[1].RealMonthlyTotal != 105 {
+		t.Errorf("unexpected real totals: %+v", entries)
+	}
+	if trends[0].ChangePct <= 0 || trends[0].RealChangePct >= 0 {
+		t.Errorf("expected a nominal increase and a real decrease, got %+v", trends[0])
+	}
+	if snapshots[0].Subscriptions[0].LatestAmount != 100 {
+		t.Error("AdjustHistory modified the snapshots")
+	}
+
+	subs := []Subscription{{Name: "Gym", Transactions: []Transaction{
+		{Date: date("2020-03-01"), Amount: -100},
+		{Date: date("2021-03-01"), Amount: -105},
+	}}}
+	periods := SpendByPeriod(subs, "year")
+	AdjustSpend(subs, periods, "year", cpi)
+	if math.Abs(periods[0].Real-110) > 1e-9 || periods[1].Real != 105 {
+		t.Errorf("unexpected real spend: %+v", periods)
+	}
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// AnalyzeJSON detects subscriptions in simple JSON data with the default settings and returns
+// them in the --output json format, recurring commitments included, like the CLI. It takes and
+// returns bytes so that it can be called from JavaScript (see the wasm directory). An empty config
+// uses the built-in known subscriptions; an empty currency uses the config's currency, then USD.
+func AnalyzeJSON(ctx context.Context, data, config []byte, currency string) ([]byte, error) {
+	transactions, err := ParseSimpleJSONBytes(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := NewDefaultConfig()
+	if len(config) > 0 {
+		cfg, err = parseConfig(config)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if currency == "" {
+		currency = cfg.Currency
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+	cfg.ScaleKnownBounds(currency)
+
+	subscriptions, commitments, _, err := NewDetector().analyze(ctx, transactions, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	PrintSubscriptionsJSON(&buf, subscriptions, cfg, OutputOptions{Currency: GetCurrency(currency).WithPrecision(cfg.Precision), Commitments: commitments})
+	return buf.Bytes(), nil
+}
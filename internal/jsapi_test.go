@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestAnalyzeJSON(t *testing.T) {
+	data, err := os.ReadFile("../testdata/sample.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := AnalyzeJSON(context.Background(), data, nil, "")
+	if err != nil {
+		t.Fatalf("AnalyzeJSON: %v", err)
+	}
+	var result JSONOutput
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(result.Subscriptions) != 2 || result.Summary.MonthlyTotal != 228 || result.Summary.Currency != "USD" {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	// The config's currency and descriptions are used
+	out, err = AnalyzeJSON(context.Background(), data, []byte("currency: SEK\n"), "")
+	if err != nil || json.Unmarshal(out, &result) != nil || result.Summary.Currency != "SEK" {
+		t.Errorf("expected SEK from the config, got %s (%v)", out, err)
+	}
+
+	if _, err := AnalyzeJSON(context.Background(), data, []byte("exclude: [\n"), ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	// Like the CLI, the known pattern bounds follow the currency and commitments are listed apart
+	data = []byte(`{"transactions": [
+		{"date": "2025-03-05", "text": "NETFLIX.COM", "amount": -1500},
+		{"date": "2025-01-01", "text": "HYRA BRF EKEN", "amount": -8500},
+		{"date": "2025-02-01", "text": "HYRA BRF EKEN", "amount": -8500},
+		{"date": "2025-03-01", "text": "HYRA BRF EKEN", "amount": -8500},
+		{"date": "2025-04-01", "text": "HYRA BRF EKEN", "amount": -8500}
+	]}`)
+	out, err = AnalyzeJSON(context.Background(), data, []byte("currency: SEK\n"), "USD")
+	result = JSONOutput{}
+	if err != nil || json.Unmarshal(out, &result) != nil {
+		t.Fatalf("AnalyzeJSON: %v\n%s", err, out)
+	}
+	if len(result.Subscriptions) != 0 || len(result.Commitments) != 1 || result.Commitments[0].Commitment != "rent" {
+		t.Errorf("expected only the rent as a commitment (1500 USD is above the Netflix bound), got %s", out)
+	}
+
+	var parseErr *ParseError
+	if _, err := AnalyzeJSON(context.Background(), []byte("{bad"), nil, ""); !errors.As(err, &parseErr) {
+		t.Errorf("expected a ParseError, got %v", err)
+	}
+}
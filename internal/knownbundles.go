@@ -0,0 +1,110 @@
+package internal
+
+import "sort"
+
+// KnownBundles are curated sets of known subscription patterns for regions or categories,
+// which configs opt into with known_bundles. They are kept out of DefaultKnownSubscriptions
+// because their names (utilities, local services) are only meaningful to some users.
+var KnownBundles = map[string][]KnownSubscription{
+	"swedish-utilities": {
+		// Electricity
+		{Pattern: "VATTENFALL"},
+		{Pattern: "ELLEVIO"},
+		{Pattern: "E\\.?ON\\s*(ENERGI|SVERIGE)"},
+		{Pattern: "FORTUM"},
+		{Pattern: "TIBBER"},
+		{Pattern: "GREENELY"},
+		{Pattern: "GÖTEBORG\\s*ENERGI"},
+		// Phone & broadband
+		{Pattern: "TELIA"},
+		{Pattern: "TELE2"},
+		{Pattern: "TELENOR"},
+		{Pattern: "HI3G"},
+		{Pattern: "COMVIQ"},
+		{Pattern: "HALEBOP"},
+		{Pattern: "VIMLA"},
+		{Pattern: "BAHNHOF"},
+		{Pattern: "BREDBANDSBOLAGET"},
+		{Pattern: "COM\\s*HEM"},
+		// Insurance
+		{Pattern: "FOLKSAM"},
+		{Pattern: "LÄNSFÖRSÄKRINGAR"},
+		{Pattern: "TRYGG[\\s-]*HANSA"},
+		{Pattern: "IF\\s*SKADEFÖRS"},
+	},
+	"swedish-media": {
+		{Pattern: "STORYTEL"},
+		{Pattern: "BOOKBEAT"},
+		{Pattern: "NEXTORY"},
+		{Pattern: "TV4\\s*PLAY"},
+		{Pattern: "C\\s*MORE"},
+		{Pattern: "SKYSHOWTIME"},
+		{Pattern: "ALLENTE"},
+		{Pattern: "DAGENS\\s*NYHETER"},
+		{Pattern: "SVENSKA\\s*DAGBLADET"},
+		{Pattern: "AFTONBLADET\\s*PLUS"},
+		{Pattern: "EXPRESSEN\\s*PREMIUM"},
+	},
+	"us-streaming": {
+		{Pattern: "YOUTUBE\\s*TV"},
+		{Pattern: "SLING\\s*TV"},
+		{Pattern: "FUBO"},
+		{Pattern: "PHILO"},
+		{Pattern: "ESPN\\s*(\\+|PLUS)"},
+		{Pattern: "STARZ"},
+		{Pattern: "SHOWTIME"},
+		{Pattern: "AMC\\s*\\+"},
+		{Pattern: "BRITBOX"},
+		{Pattern: "MUBI"},
+		{Pattern: "CURIOSITYSTREAM"},
+		{Pattern: "SIRIUS\\s*XM"},
+		{Pattern: "PANDORA"},
+	},
+	"us-utilities": {
+		// Phone, TV & internet
+		{Pattern: "VERIZON"},
+		{Pattern: "AT&T"},
+		{Pattern: "T-MOBILE"},
+		{Pattern: "XFINITY"},
+		{Pattern: "COMCAST"},
+		{Pattern: "SPECTRUM"},
+		{Pattern: "COX\\s*COMM"},
+		{Pattern: "MINT\\s*MOBILE"},
+		{Pattern: "GOOGLE\\s*FI\\b"},
+		// Energy
+		{Pattern: "CON\\s*ED"},
+		{Pattern: "PG&E"},
+		{Pattern: "DUKE\\s*ENERGY"},
+		// Insurance
+		{Pattern: "GEICO"},
+		{Pattern: "STATE\\s*FARM"},
+		{Pattern: "ALLSTATE"},
+		{Pattern: "PROGRESSIVE\\s*INS"},
+	},
+	"uk-utilities": {
+		// Energy & water
+		{Pattern: "BRITISH\\s*GAS"},
+		{Pattern: "OCTOPUS\\s*ENERGY"},
+		{Pattern: "EDF\\s*ENERGY"},
+		{Pattern: "OVO\\s*ENERGY"},
+		{Pattern: "E\\.?ON\\s*NEXT"},
+		{Pattern: "THAMES\\s*WATER"},
+		// Phone, TV & broadband
+		{Pattern: "BRITISH\\s*TELECOM"},
+		{Pattern: "VIRGIN\\s*MEDIA"},
+		{Pattern: "SKY\\s*(DIGITAL|UK|BROADBAND)"},
+		{Pattern: "VODAFONE"},
+		{Pattern: "GIFFGAFF"},
+		{Pattern: "TV\\s*LICEN[CS]E"},
+	},
+}
+
+// KnownBundleNames returns the sorted names of the known pattern bundles
+func KnownBundleNames() []string {
+	names := make([]string, 0, len(KnownBundles))
+	for name := range KnownBundles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKnownBundles_Patterns(t *testing.T) {
+	for _, name := range KnownBundleNames() {
+		for _, k := range KnownBundles[name] {
+			if _, err := compileKnownPattern(k.Pattern); err != nil {
+				t.Errorf("bundle %s: invalid pattern %q: %v", name, k.Pattern, err)
+			}
+		}
+	}
+}
+
+func TestConfig_KnownBundles(t *testing.T) {
+	cfg, err := parseConfig([]byte("use_default_known: false\nknown_bundles: [swedish-utilities, us-streaming]\nknown:\n  - pattern: MyService\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if want := len(KnownBundles["swedish-utilities"]) + len(KnownBundles["us-streaming"]) + 1; len(cfg.Known) != want {
+		t.Errorf("expected %d known patterns, got %d", want, len(cfg.Known))
+	}
+	tests := []struct {
+		text    string
+		matches bool
+	}{
+		{"VATTENFALL AB", true},
+		{"Tibber AB", true},
+		{"YOUTUBE TV", true},
+		{"MyService", true},
+		{"NETFLIX.COM", false}, // defaults are disabled
+		{"British Gas", false}, // bundle not included
+	}
+	for _, tt := range tests {
+		if matched := cfg.MatchesKnown(Transaction{Text: tt.text, Amount: -50}); (matched != nil) != tt.matches {
+			t.Errorf("MatchesKnown(%q) = %v, want match %v", tt.text, matched, tt.matches)
+		}
+	}
+
+	// Bundles come on top of the defaults
+	cfg, err = parseConfig([]byte("known_bundles: [uk-utilities]\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if len(cfg.Known) != len(DefaultKnownSubscriptions)+len(KnownBundles["uk-utilities"]) {
+		t.Errorf("expected the defaults and the bundle, got %d patterns", len(cfg.Known))
+	}
+
+	if _, err := parseConfig([]byte("known_bundles: [atlantis-utilities]\n")); err == nil || !strings.Contains(err.Error(), "swedish-utilities") {
+		t.Errorf("expected an error listing the available bundles, got %v", err)
+	}
+	if _, err := parseConfig([]byte("known_bundles: [us-streaming, us-streaming]\n")); err == nil {
+		t.Error("expected an error for a bundle listed twice")
+	}
+}
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// knownMatcher finds the known subscriptions whose pattern matches a transaction text with a
+// single Aho-Corasick pass over the case-folded text, instead of running every regex. A pattern
+// that is a plain literal (most of the defaults, e.g. "NETFLIX") is decided by the automaton
+// alone; a pattern with a literal it requires (e.g. "HBO" in "HBO\s*MAX") only runs its regex when
+// the literal occurs; the rest always run their regex.
+type knownMatcher struct {
+	known    []KnownSubscription // the slice the matcher was built for
+	ac       *ahoCorasick
+	literal  []int  // per known subscription: index of its literal in ac, or -1
+	exact    []bool // per known subscription: the literal is the whole pattern
+	literals int
+}
+
+// newKnownMatcher builds a matcher for known subscriptions with compiled patterns
+func newKnownMatcher(known []KnownSubscription) *knownMatcher {
+	m := &knownMatcher{known: known, literal: make([]int, len(known)), exact: make([]bool, len(known))}
+	var literals []string
+	for i := range known {
+		m.literal[i] = -1
+		lit, exact := requiredLiteral(known[i].Pattern)
+		if lit == "" {
+			continue
+		}
+		m.literal[i] = len(literals)
+		m.exact[i] = exact
+		literals = append(literals, lit)
+	}
+	m.ac = newAhoCorasick(literals)
+	m.literals = len(literals)
+	return m
+}
+
+// builtFor reports whether the matcher was built for exactly this slice of known subscriptions
+func (m *knownMatcher) builtFor(known []KnownSubscription) bool {
+	return len(m.known) == len(known) && (len(known) == 0 || &m.known[0] == &known[0])
+}
+
+// match returns the first known subscription matching tx, like checking each in order
+func (m *knownMatcher) match(tx Transaction) *KnownSubscription {
+	found := make([]bool, m.literals)
+	m.ac.scan(foldCase(tx.Text), func(id int) { found[id] = true })
+
+	for i := range m.known {
+		k := &m.known[i]
+		if k.regex == nil {
+			continue
+		}
+		if lit := m.literal[i]; lit >= 0 {
+			if !found[lit] {
+				continue
+			}
+			if !m.exact[i] && !k.regex.MatchString(tx.Text) {
+				continue
+			}
+		} else if !k.regex.MatchString(tx.Text) {
+			continue
+		}
+		if k.matchesBounds(tx) {
+			return k
+		}
+	}
+	return nil
+}
+
+// requiredLiteral returns a case-insensitive literal that every match of the (case-insensitive)
+// pattern contains, and whether matching the literal is the same as matching the pattern.
+// It returns "" if the pattern has no such literal.
+func requiredLiteral(pattern string) (string, bool) {
+	re, err := syntax.Parse("(?i)"+pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	if re.Op == syntax.OpLiteral {
+		return foldCase(string(re.Rune)), re.Flags&syntax.FoldCase != 0
+	}
+	return foldCase(longestLiteral(re)), false
+}
+
+// longestLiteral returns the longest literal that every match of re contains
+func longestLiteral(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return string(re.Rune)
+	case syntax.OpCapture:
+		return longestLiteral(re.Sub[0])
+	case syntax.OpPlus:
+		return longestLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return longestLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		longest := ""
+		for _, sub := range re.Sub {
+			if lit := longestLiteral(sub); utf8.RuneCountInString(lit) > utf8.RuneCountInString(longest) {
+				longest = lit
+			}
+		}
+		return longest
+	}
+	return ""
+}
+
+// foldCase maps each rune to a canonical member of its case-folding orbit (the smallest), so that
+// two strings are equal under (?i) matching exactly when their folded forms are equal
+func foldCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			r -= 'a' - 'A'
+		case r >= utf8.RuneSelf:
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				if f < r {
+					r = f
+				}
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ahoCorasick is an Aho-Corasick automaton over bytes, finding all of a set of literals in a
+// text in one pass
+type ahoCorasick struct {
+	nodes []acNode
+	fail  []int32 // failure link of each node: the longest proper suffix that is also a node
+}
+
+type acNode struct {
+	next map[byte]int32 // trie edges
+	out  []int          // literals ending at this node, including those reached via failure links
+}
+
+// newAhoCorasick builds an automaton finding the given literals, identified by their index
+func newAhoCorasick(literals []string) *ahoCorasick {
+	ac := &ahoCorasick{nodes: []acNode{{next: map[byte]int32{}}}}
+	for id, lit := range literals {
+		node := int32(0)
+		for i := 0; i < len(lit); i++ {
+			child, ok := ac.nodes[node].next[lit[i]]
+			if !ok {
+				child = int32(len(ac.nodes))
+				ac.nodes = append(ac.nodes, acNode{next: map[byte]int32{}})
+				ac.nodes[node].next[lit[i]] = child
+			}
+			node = child
+		}
+		ac.nodes[node].out = append(ac.nodes[node].out, id)
+	}
+
+	// Breadth-first, so that failure links point to nodes that are already complete
+	fail := make([]int32, len(ac.nodes))
+	queue := make([]int32, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for c, child := range ac.nodes[node].next {
+			f := fail[node]
+			for f != 0 {
+				if _, ok := ac.nodes[f].next[c]; ok {
+					break
+				}
+				f = fail[f]
+			}
+			if target, ok := ac.nodes[f].next[c]; ok && target != child {
+				fail[child] = target
+			}
+			ac.nodes[child].out = append(ac.nodes[child].out, ac.nodes[fail[child]].out...)
+			queue = append(queue, child)
+		}
+	}
+	ac.fail = fail
+	return ac
+}
+
+// scan calls found with the index of each literal occurring in text (possibly more than once)
+func (ac *ahoCorasick) scan(text string, found func(id int)) {
+	node := int32(0)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		for {
+			if next, ok := ac.nodes[node].next[c]; ok {
+				node = next
+				break
+			}
+			if node == 0 {
+				break
+			}
+			node = ac.fail[node]
+		}
+		for _, id := range ac.nodes[node].out {
+			found(id)
+		}
+	}
+}
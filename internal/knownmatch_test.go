@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+)
+
+// matchKnownLinear is MatchesKnown without the matcher: every pattern's regex, in order
+func matchKnownLinear(cfg *Config, tx Transaction) *KnownSubscription {
+	for i := range cfg.Known {
+		if cfg.Known[i].Matches(tx) {
+			return &cfg.Known[i]
+		}
+	}
+	return nil
+}
+
+func TestKnownMatcher(t *testing.T) {
+	cfg, err := parseConfig([]byte(`known:
+  - pattern: "^Gym"
+  - pattern: "(?-i)CaseSensitive"
+  - pattern: "foo|bar"
+  - pattern: "Åsa Café"
+  - pattern: "Kelvin"
+  - pattern: "Spotify Family"
+    min_amount: 150
+  - pattern: ".*"
+    after: "2030-01-01"
+`))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if cfg.knownMatcher == nil || !cfg.knownMatcher.builtFor(cfg.Known) {
+		t.Fatal("expected the config to have a matcher for its known patterns")
+	}
+
+	texts := []string{
+		"NETFLIX.COM", "netflix", "Net flix", "HBO   Max", "hbomax", "HBO", "Disney+", "Disney",
+		"YouTube Premium", "YOUTUBE MUSIC", "youtube", "Xbox Game  Pass", "PlayStation Now",
+		"ſpotify", "Spotify Family", "Gym membership", "My Gym", "CaseSensitive", "casesensitive",
+		"barbecue", "ÅSA CAFÉ", "åsa café", "Kelvin", "Grocery Store", "", "Ka\xffboom",
+	}
+	for _, text := range texts {
+		for _, amount := range []float64{-99, -199} {
+			tx := Transaction{Date: date("2025-01-15"), Text: text, Amount: amount}
+			got, want := cfg.MatchesKnown(tx), matchKnownLinear(cfg, tx)
+			if got != want {
+				t.Errorf("%q (%v): matcher gave %v, regexes gave %v", text, amount, got, want)
+			}
+		}
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	tests := []struct {
+		pattern string
+		literal string
+		exact   bool
+	}{
+		{"NETFLIX", "NETFLIX", true},
+		{"Disney\\+", "DISNEY+", true},
+		{"(Spotify)", "SPOTIFY", true},
+		{"HBO\\s*MAX", "HBO", false},
+		{"YOUTUBE\\s*(MUSIC|PREMIUM)", "YOUTUBE", false},
+		{"^Gym", "GYM", false},
+		{"(?-i)CaseSensitive", "CASESENSITIVE", false},
+		{"foo|bar", "", false},
+		{".*", "", false},
+		{"(", "", false},
+	}
+	for _, tt := range tests {
+		literal, exact := requiredLiteral(tt.pattern)
+		if literal != tt.literal || exact != tt.exact {
+			t.Errorf("requiredLiteral(%q) = %q, %v; want %q, %v", tt.pattern, literal, exact, tt.literal, tt.exact)
+		}
+	}
+}
+
+func BenchmarkMatchesKnown(b *testing.B) {
+	cfg, err := NewDefaultConfig()
+	if err != nil {
+		b.Fatal(err)
+	}
+	var txs []Transaction
+	for i := range 1000 {
+		text := fmt.Sprintf("KORTKÖP 2501%02d ICA SUPERMARKET %d", i%28+1, i)
+		if i%20 == 0 {
+			text = "NETFLIX.COM"
+		}
+		txs = append(txs, Transaction{Date: date("2025-01-15"), Text: text, Amount: -99})
+	}
+
+	b.Run("matcher", func(b *testing.B) {
+		for b.Loop() {
+			for _, tx := range txs {
+				cfg.MatchesKnown(tx)
+			}
+		}
+	})
+	b.Run("regexes", func(b *testing.B) {
+		for b.Loop() {
+			for _, tx := range txs {
+				matchKnownLinear(cfg, tx)
+			}
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reasons a subscription cost is a savings opportunity
+const (
+	SavingResumed   = "resumed"   // billed again after a pause, e.g. a cancellation that didn't take
+	SavingDuplicate = "duplicate" // paid by more than one household member (see DuplicateAccounts)
+)
+
+// KPIs are key figures of the detected subscriptions, and what could likely be saved
+type KPIs struct {
+	Year            int           // the year that started and stopped subscriptions are counted in
+	Active          int           // number of active subscriptions
+	AverageMonthly  float64       // average monthly cost of an active subscription
+	MostExpensive   *Subscription // active subscription with the highest monthly cost (nil if none)
+	StartedThisYear int           // subscriptions with their first payment in Year
+	StoppedThisYear int           // stopped subscriptions with their last payment in Year
+	Savings         []Saving      // by monthly amount, highest first
+	YearlySavings   float64       // yearly cost of the savings opportunities
+}
+
+// Saving is a subscription cost that could likely be cut
+type Saving struct {
+	Name    string
+	Reason  string   // SavingResumed or SavingDuplicate
+	Persons []string // household members paying for a duplicate
+	Monthly float64
+}
+
+// ComputeKPIs computes the key figures of subs as of a date (e.g., the end of the data), which
+// sets the year started and stopped subscriptions are counted in. Savings opportunities are active
+// subscriptions billed again this year after a pause longer than their billing interval, and
+// services paid by more than one household member (all but one of the accounts).
+func ComputeKPIs(subs []Subscription, cfg *Config, asOf time.Time) KPIs {
+	k := KPIs{Year: asOf.Year()}
+	var monthlyTotal float64
+	for i, sub := range subs {
+		if sub.StartDate.Year() == k.Year {
+			k.StartedThisYear++
+		}
+		if sub.Status == StatusStopped && sub.LastDate.Year() == k.Year {
+			k.StoppedThisYear++
+		}
+		if sub.Status != StatusActive {
+			continue
+		}
+		k.Active++
+		monthlyTotal += sub.MonthlyCost()
+		if k.MostExpensive == nil || sub.MonthlyCost() > k.MostExpensive.MonthlyCost() {
+			k.MostExpensive = &subs[i]
+		}
+		if resumedIn(sub, k.Year) {
+			k.Savings = append(k.Savings, Saving{Name: sub.Name, Reason: SavingResumed, Monthly: sub.MonthlyCost()})
+		}
+	}
+	if k.Active > 0 {
+		k.AverageMonthly = monthlyTotal / float64(k.Active)
+	}
+	for _, d := range DuplicateAccounts(subs, cfg) {
+		k.Savings = append(k.Savings, Saving{
+			Name:    d.Name,
+			Reason:  SavingDuplicate,
+			Persons: d.Persons,
+			Monthly: d.MonthlyTotal * float64(len(d.Persons)-1) / float64(len(d.Persons)),
+		})
+	}
+	sort.SliceStable(k.Savings, func(i, j int) bool { return k.Savings[i].Monthly > k.Savings[j].Monthly })
+	for _, s := range k.Savings {
+		k.YearlySavings += s.Monthly * 12
+	}
+	return k
+}
+
+// resumedIn reports whether a subscription was billed again in the given year after a pause
+// longer than its billing interval
+func resumedIn(sub Subscription, year int) bool {
+	interval := max(int(sub.Interval), 1)
+	txs := sortedByDate(sub.Transactions)
+	for i := 1; i < len(txs); i++ {
+		gap := monthIndex(billingMonth(txs[i])) - monthIndex(billingMonth(txs[i-1]))
+		if gap > interval && txs[i].Date.Year() == year {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintKPIs outputs the key figures and savings opportunities
+func PrintKPIs(w io.Writer, k KPIs, opts OutputOptions) {
+	loc, currency := opts.Locale, opts.Currency
+	year := strconv.Itoa(k.Year) // not formatted as a number, which would group its digits
+	fmt.Fprint(w, loc.Sprintf("Key figures (%s):\n", year))
+	fmt.Fprint(w, loc.Sprintf("  Average active subscription: %s/month\n", currency.Format(k.AverageMonthly)))
+	if k.MostExpensive != nil {
+		fmt.Fprint(w, loc.Sprintf("  Most expensive: %s (%s/month)\n", k.MostExpensive.Name, currency.Format(k.MostExpensive.MonthlyCost())))
+	}
+	fmt.Fprint(w, loc.Sprintf("  Started in %s: %d, stopped in %s: %d\n", year, k.StartedThisYear, year, k.StoppedThisYear))
+	if len(k.Savings) == 0 {
+		return
+	}
+	fmt.Fprint(w, loc.T("Savings opportunities:\n"))
+	for _, s := range k.Savings {
+		switch s.Reason {
+		case SavingResumed:
+			fmt.Fprint(w, loc.Sprintf("  %s: billed again after a pause, %s/month\n", s.Name, currency.Format(s.Monthly)))
+		case SavingDuplicate:
+			fmt.Fprint(w, loc.Sprintf("  %s: paid by %s, %s/month for the extra accounts\n", s.Name, strings.Join(s.Persons, ", "), currency.Format(s.Monthly)))
+		}
+	}
+	fmt.Fprint(w, loc.Sprintf("  Estimated yearly savings: %s\n", currency.Format(k.YearlySavings)))
+}
+
+// JSONKPIs is the JSON output format of the key figures
+type JSONKPIs struct {
+	Year            int          `json:"year"`
+	AverageMonthly  float64      `json:"average_monthly"`
+	MostExpensive   string       `json:"most_expensive,omitempty"`
+	StartedThisYear int          `json:"started_this_year"`
+	StoppedThisYear int          `json:"stopped_this_year"`
+	Savings         []JSONSaving `json:"savings"`
+	YearlySavings   float64      `json:"yearly_savings"`
+}
+
+// JSONSaving is the JSON output format of a savings opportunity
+type JSONSaving struct {
+	Name    string   `json:"name"`
+	Reason  string   `json:"reason"`
+	Persons []string `json:"persons,omitempty"`
+	Monthly float64  `json:"monthly"`
+}
+
+func buildJSONKPIs(k KPIs, currency Currency) *JSONKPIs {
+	out := &JSONKPIs{
+		Year:            k.Year,
+		AverageMonthly:  currency.Round(k.AverageMonthly),
+		StartedThisYear: k.StartedThisYear,
+		StoppedThisYear: k.StoppedThisYear,
+		Savings:         []JSONSaving{},
+		YearlySavings:   currency.Round(k.YearlySavings),
+	}
+	if k.MostExpensive != nil {
+		out.MostExpensive = k.MostExpensive.Name
+	}
+	for _, s := range k.Savings {
+		out.Savings = append(out.Savings, JSONSaving{Name: s.Name, Reason: s.Reason, Persons: s.Persons, Monthly: currency.Round(s.Monthly)})
+	}
+	return out
+}
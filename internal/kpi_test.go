@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeKPIs(t *testing.T) {
+	monthly := func(text string, amount float64, months ...string) []Transaction {
+		var txs []Transaction
+		for _, m := range months {
+			txs = append(txs, Transaction{Date: date(m + "-10"), Text: text, Amount: amount})
+		}
+		return txs
+	}
+	subs := []Subscription{
+		// Paused from March to June 2025, billed again since
+		{Name: "Gym", LatestAmount: -400, Status: StatusActive, StartDate: date("2024-11-10"),
+			Transactions: monthly("Gym", -400, "2024-11", "2024-12", "2025-01", "2025-02", "2025-07", "2025-08")},
+		{Name: "Spotify", LatestAmount: -119, Status: StatusActive, StartDate: date("2025-03-10"),
+			Transactions: monthly("Spotify", -119, "2025-03", "2025-04", "2025-05", "2025-06", "2025-07", "2025-08")},
+		{Name: "Magazine", LatestAmount: -600, Interval: IntervalYearly, Status: StatusActive, StartDate: date("2024-02-10"),
+			Transactions: monthly("Magazine", -600, "2024-02", "2025-02")},
+		{Name: "HBO", LatestAmount: -89, Status: StatusStopped, StartDate: date("2024-10-10"), LastDate: date("2025-04-10"),
+			Transactions: monthly("HBO", -89, "2024-10", "2025-04")},
+	}
+
+	k := ComputeKPIs(subs, nil, date("2025-08-31"))
+	if k.Year != 2025 || k.Active != 3 || k.StartedThisYear != 1 || k.StoppedThisYear != 1 {
+		t.Errorf("unexpected counts: %+v", k)
+	}
+	if want := (400 + 119 + 50) / 3.0; k.AverageMonthly != want {
+		t.Errorf("AverageMonthly = %v, want %v", k.AverageMonthly, want)
+	}
+	if k.MostExpensive == nil || k.MostExpensive.Name != "Gym" {
+		t.Errorf("MostExpensive = %+v, want Gym", k.MostExpensive)
+	}
+	if len(k.Savings) != 1 || k.Savings[0].Name != "Gym" || k.Savings[0].Reason != SavingResumed {
+		t.Errorf("Savings = %+v, want only the resumed Gym", k.Savings)
+	}
+	if k.YearlySavings != 4800 {
+		t.Errorf("YearlySavings = %v, want 4800", k.YearlySavings)
+	}
+
+	// A pause in an earlier year isn't counted
+	if k := ComputeKPIs(subs, nil, time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)); len(k.Savings) != 0 {
+		t.Errorf("expected no savings in 2026, got %+v", k.Savings)
+	}
+}
+
+func TestComputeKPIs_Duplicates(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Netflix", LatestAmount: -99, Status: StatusActive, Person: "alice", StartDate: date("2025-01-10")},
+		{Name: "NETFLIX.COM", LatestAmount: -129, Status: StatusActive, Person: "bob", StartDate: date("2025-01-12")},
+	}
+	cfg, err := parseConfig([]byte("descriptions:\n  Netflix: Netflix\n  NETFLIX.COM: Netflix\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := ComputeKPIs(subs, cfg, date("2025-04-30"))
+	if len(k.Savings) != 1 || k.Savings[0].Reason != SavingDuplicate || k.Savings[0].Monthly != 114 {
+		t.Fatalf("Savings = %+v, want half of the Netflix accounts", k.Savings)
+	}
+	if k.YearlySavings != 114*12 {
+		t.Errorf("YearlySavings = %v, want %v", k.YearlySavings, 114*12)
+	}
+}
@@ -0,0 +1,301 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Locale controls language-dependent formatting of table output: labels, dates and the Day column.
+// The zero value formats like English with ISO dates.
+type Locale struct {
+	Tag     language.Tag
+	printer *message.Printer
+}
+
+// dateLayouts maps language tags to their conventional short date layout.
+// Tags not listed here fall back to their base language, then to ISO 8601.
+var dateLayouts = map[string]string{
+	"en-US": "01/02/2006",
+	"en-GB": "02/01/2006",
+	"en-AU": "02/01/2006",
+	"en-NZ": "02/01/2006",
+	"en-IN": "02/01/2006",
+	"en-CA": "2006-01-02",
+	"de":    "02.01.2006",
+	"nb":    "02.01.2006",
+	"no":    "02.01.2006",
+	"da":    "02.01.2006",
+	"fi":    "2.1.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"pt":    "02/01/2006",
+	"nl":    "02-01-2006",
+	"pl":    "02.01.2006",
+	"ja":    "2006/01/02",
+	"zh":    "2006/01/02",
+	"sv":    "2006-01-02",
+}
+
+// translations is a small message catalog for table labels, keyed by base language.
+// Keys are the English strings (including format verbs) used directly in output code.
+var translations = map[language.Tag]map[string]string{
+	language.Swedish: {
+		"Name":           "Namn",
+		"Description":    "Beskrivning",
+		"Tags":           "Taggar",
+		"Category":       "Kategori",
+		"Kind":           "Typ",
+		"  None\n":       "  Inga\n",
+		"Status":         "Status",
+		"Day":            "Dag",
+		"Started":        "Startad",
+		"Age":            "Ålder",
+		"%dm":            "%d mån",
+		"%dy":            "%d år",
+		"%dy %dm":        "%d år %d mån",
+		"Last Seen":      "Senast sedd",
+		"Trend":          "Trend",
+		"Monthly":        "Månadsvis",
+		"Yearly":         "Årsvis",
+		"Total (active)": "Totalt (aktiva)",
+		"Others (%d)":    "Övriga (%d)",
+		"Subtotal":       "Delsumma",
+		"Untagged":       "Utan tagg",
+		"Uncategorized":  "Utan kategori",
+		"No account":     "Inget konto",
+		"monthly":        "månadsvis",
+		"quarterly":      "kvartalsvis",
+		"yearly":         "årsvis",
+		"ACTIVE":         "AKTIV",
+		"STOPPED":        "AVSLUTAD",
+		"POSSIBLE":       "MÖJLIG",
+		"Amount":         "Belopp",
+		"Found %d subscriptions (%d active, %d stopped)\n": "Hittade %d prenumerationer (%d aktiva, %d avslutade)\n",
+		"Showing: %s\n\n":                               "Visar: %s\n\n",
+		"No subscriptions detected.":                    "Inga prenumerationer hittades.",
+		"Monthly total (active): %s\n":                  "Totalt per månad (aktiva): %s\n",
+		"Yearly total (active): %s\n":                   "Totalt per år (aktiva): %s\n",
+		"Lifetime spend on stopped subscriptions: %s\n": "Totalt betalt för avslutade prenumerationer: %s\n",
+		"Possible subscriptions (not in totals): %d\n":  "Möjliga prenumerationer (ej i totalen): %d\n",
+		"Possible subscriptions (not in totals):\n":     "Möjliga prenumerationer (ej i totalen):\n",
+		"Recurring commitments (not in totals):\n":      "Återkommande åtaganden (ej i totalen):\n",
+		"Excluded by the config:\n":                     "Exkluderade av konfigurationen:\n",
+		"Key figures (%s):\n":                           "Nyckeltal (%s):\n",
+		"  Average active subscription: %s/month\n":     "  Genomsnittlig aktiv prenumeration: %s/mån\n",
+		"  Most expensive: %s (%s/month)\n":             "  Dyrast: %s (%s/mån)\n",
+		"  Started in %s: %d, stopped in %s: %d\n":      "  Startade %s: %d, avslutade %s: %d\n",
+		"Savings opportunities:\n":                      "Möjliga besparingar:\n",
+		"  Estimated yearly savings: %s\n":              "  Uppskattad besparing per år: %s\n",
+		"Paying more than the standard plan:\n":         "Dyrare än standardabonnemanget:\n",
+		"  %s: %s/month, %s %s is %s/month (+%s)\n":     "  %s: %s/mån, %s %s kostar %s/mån (+%s)\n",
+		"Subscriptions paid for %d+ years:\n":           "Prenumerationer betalda i %d+ år:\n",
+		"  %s: since %s (%s), %s/month\n":               "  %s: sedan %s (%s), %s/mån\n",
+		"Yearly charges on monthly subscriptions:\n":    "Årsavgifter på månadsabonnemang:\n",
+		"  %s: %s/month + %s/year (last %s)\n":          "  %s: %s/mån + %s/år (senast %s)\n",
+		"Changes since last snapshot (%s):\n":           "Ändringar sedan senaste ögonblicksbild (%s):\n",
+		"No changes since last snapshot (%s).\n":        "Inga ändringar sedan senaste ögonblicksbild (%s).\n",
+		"Changes since last snapshot:\n":                "Ändringar sedan senaste ögonblicksbild:\n",
+		"No changes since last snapshot.\n":             "Inga ändringar sedan senaste ögonblicksbild.\n",
+		"NEW":                                           "NY",
+		"RESUMED":                                       "ÅTERUPPTAGEN",
+		"REMOVED":                                       "BORTTAGEN",
+		"PRICE UP":                                      "DYRARE",
+		"PRICE DOWN":                                    "BILLIGARE",
+		"NEW PLAN":                                      "NY NIVÅ",
+	},
+	language.German: {
+		"Name":           "Name",
+		"Description":    "Beschreibung",
+		"Tags":           "Tags",
+		"Category":       "Kategorie",
+		"Kind":           "Art",
+		"  None\n":       "  Keine\n",
+		"Status":         "Status",
+		"Day":            "Tag",
+		"Started":        "Beginn",
+		"Age":            "Alter",
+		"%dm":            "%d Mon.",
+		"%dy":            "%d J.",
+		"%dy %dm":        "%d J. %d Mon.",
+		"Last Seen":      "Zuletzt",
+		"Trend":          "Verlauf",
+		"Monthly":        "Monatlich",
+		"Yearly":         "Jährlich",
+		"Total (active)": "Summe (aktiv)",
+		"Others (%d)":    "Sonstige (%d)",
+		"Subtotal":       "Zwischensumme",
+		"Untagged":       "Ohne Tag",
+		"Uncategorized":  "Ohne Kategorie",
+		"No account":     "Kein Konto",
+		"monthly":        "monatlich",
+		"quarterly":      "vierteljährlich",
+		"yearly":         "jährlich",
+		"ACTIVE":         "AKTIV",
+		"STOPPED":        "BEENDET",
+		"POSSIBLE":       "MÖGLICH",
+		"Amount":         "Betrag",
+		"Found %d subscriptions (%d active, %d stopped)\n": "%d Abonnements gefunden (%d aktiv, %d beendet)\n",
+		"Showing: %s\n\n":                               "Anzeige: %s\n\n",
+		"No subscriptions detected.":                    "Keine Abonnements gefunden.",
+		"Monthly total (active): %s\n":                  "Summe pro Monat (aktiv): %s\n",
+		"Yearly total (active): %s\n":                   "Summe pro Jahr (aktiv): %s\n",
+		"Lifetime spend on stopped subscriptions: %s\n": "Gesamtausgaben für beendete Abonnements: %s\n",
+		"Possible subscriptions (not in totals): %d\n":  "Mögliche Abonnements (nicht in der Summe): %d\n",
+		"Possible subscriptions (not in totals):\n":     "Mögliche Abonnements (nicht in der Summe):\n",
+		"Recurring commitments (not in totals):\n":      "Wiederkehrende Verpflichtungen (nicht in der Summe):\n",
+		"Excluded by the config:\n":                     "Durch die Konfiguration ausgeschlossen:\n",
+		"Key figures (%s):\n":                           "Kennzahlen (%s):\n",
+		"  Average active subscription: %s/month\n":     "  Durchschnittliches aktives Abonnement: %s/Monat\n",
+		"  Most expensive: %s (%s/month)\n":             "  Am teuersten: %s (%s/Monat)\n",
+		"  Started in %s: %d, stopped in %s: %d\n":      "  Begonnen %s: %d, beendet %s: %d\n",
+		"Savings opportunities:\n":                      "Sparmöglichkeiten:\n",
+		"  Estimated yearly savings: %s\n":              "  Geschätzte Ersparnis pro Jahr: %s\n",
+		"Paying more than the standard plan:\n":         "Teurer als das Standard-Abo:\n",
+		"  %s: %s/month, %s %s is %s/month (+%s)\n":     "  %s: %s/Monat, %s %s kostet %s/Monat (+%s)\n",
+		"Subscriptions paid for %d+ years:\n":           "Seit %d+ Jahren bezahlte Abos:\n",
+		"  %s: since %s (%s), %s/month\n":               "  %s: seit %s (%s), %s/Monat\n",
+		"Yearly charges on monthly subscriptions:\n":    "Jahresgebühren auf Monatsabos:\n",
+		"  %s: %s/month + %s/year (last %s)\n":          "  %s: %s/Monat + %s/Jahr (zuletzt %s)\n",
+		"Changes since last snapshot (%s):\n":           "Änderungen seit dem letzten Snapshot (%s):\n",
+		"No changes since last snapshot (%s).\n":        "Keine Änderungen seit dem letzten Snapshot (%s).\n",
+		"Changes since last snapshot:\n":                "Änderungen seit dem letzten Snapshot:\n",
+		"No changes since last snapshot.\n":             "Keine Änderungen seit dem letzten Snapshot.\n",
+		"NEW":                                           "NEU",
+		"RESUMED":                                       "WIEDER",
+		"REMOVED":                                       "ENTFERNT",
+		"PRICE UP":                                      "TEURER",
+		"PRICE DOWN":                                    "GÜNSTIGER",
+		"NEW PLAN":                                      "NEUER TARIF",
+	},
+}
+
+func init() {
+	for tag, msgs := range translations {
+		for key, msg := range msgs {
+			if err := message.SetString(tag, key, msg); err != nil {
+				panic(fmt.Sprintf("invalid translation %q for %s: %v", key, tag, err))
+			}
+		}
+	}
+}
+
+// GetLocale returns a Locale for the given language tag.
+func GetLocale(tag language.Tag) Locale {
+	return Locale{Tag: tag, printer: message.NewPrinter(tag)}
+}
+
+// ResolveLocale returns the Locale for an explicit locale name (e.g., "sv-SE" or "sv_SE.UTF-8"),
+// or the system locale if name is empty. Falls back to English if nothing can be detected.
+func ResolveLocale(name string) (Locale, error) {
+	if name != "" {
+		tag, err := parseLocaleTag(name)
+		if err != nil {
+			return Locale{}, fmt.Errorf("invalid locale %q: %w", name, err)
+		}
+		return GetLocale(tag), nil
+	}
+	if system := detectSystemLocale(); system != "" {
+		if tag, err := parseLocaleTag(system); err == nil {
+			return GetLocale(tag), nil
+		}
+	}
+	return GetLocale(language.English), nil
+}
+
+// parseLocaleTag converts a POSIX or BCP 47 locale string to a language tag.
+// Examples: "sv_SE.UTF-8" -> sv-SE, "de_DE@euro" -> de-DE, "en-US" -> en-US
+func parseLocaleTag(locale string) (language.Tag, error) {
+	// Remove encoding suffix (everything after .)
+	base := locale
+	if idx := strings.Index(base, "."); idx != -1 {
+		base = base[:idx]
+	}
+
+	// Remove modifier suffix (everything after @)
+	if idx := strings.Index(base, "@"); idx != -1 {
+		base = base[:idx]
+	}
+
+	// Convert to BCP 47 format: "sv_SE" -> "sv-SE"
+	return language.Parse(strings.Replace(base, "_", "-", 1))
+}
+
+// Sprintf formats according to the locale, translating format if the catalog has an entry for it
+func (l Locale) Sprintf(format string, args ...any) string {
+	if l.printer == nil {
+		return fmt.Sprintf(format, args...)
+	}
+	return l.printer.Sprintf(format, args...)
+}
+
+// T translates a fixed label
+func (l Locale) T(label string) string {
+	if l.printer == nil {
+		return label
+	}
+	return l.printer.Sprintf(message.Key(label, label))
+}
+
+// FormatDate formats a date using the locale's conventional short date layout
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.dateLayout())
+}
+
+func (l Locale) dateLayout() string {
+	if layout, ok := dateLayouts[l.Tag.String()]; ok {
+		return layout
+	}
+	if layout, ok := dateLayouts[l.baseLanguage()]; ok {
+		return layout
+	}
+	return "2006-01-02"
+}
+
+// baseLanguage returns the base language code (e.g., "sv"), or "" for an undetermined tag
+func (l Locale) baseLanguage() string {
+	if l.Tag == language.Und {
+		return ""
+	}
+	base, _ := l.Tag.Base()
+	return base.String()
+}
+
+// FormatDay formats an approximate day of month using the locale's ordinal style
+// (e.g., "~15th" in English, "~15:e" in Swedish, "~15." in German)
+func (l Locale) FormatDay(day int) string {
+	switch l.baseLanguage() {
+	case "en":
+		return fmt.Sprintf("~%d%s", day, englishOrdinalSuffix(day))
+	case "sv":
+		suffix := ":e"
+		if d := day % 10; (d == 1 || d == 2) && day%100 != 11 && day%100 != 12 {
+			suffix = ":a"
+		}
+		return fmt.Sprintf("~%d%s", day, suffix)
+	case "de", "nb", "no", "da", "fi", "pl":
+		return fmt.Sprintf("~%d.", day)
+	default:
+		return fmt.Sprintf("~%d", day)
+	}
+}
+
+func englishOrdinalSuffix(day int) string {
+	if day%100 >= 11 && day%100 <= 13 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
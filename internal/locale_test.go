@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocale_FormatDate(t *testing.T) {
+	d := date("2025-03-07")
+	tests := []struct {
+		tag      language.Tag
+		expected string
+	}{
+		{language.Swedish, "2025-03-07"},
+		{language.AmericanEnglish, "03/07/2025"},
+		{language.BritishEnglish, "07/03/2025"},
+		{language.German, "07.03.2025"},
+		{language.MustParse("de-AT"), "07.03.2025"},
+		{language.English, "2025-03-07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag.String(), func(t *testing.T) {
+			if got := GetLocale(tt.tag).FormatDate(d); got != tt.expected {
+				t.Errorf("FormatDate() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocale_FormatDay(t *testing.T) {
+	tests := []struct {
+		tag      language.Tag
+		day      int
+		expected string
+	}{
+		{language.English, 1, "~1st"},
+		{language.English, 2, "~2nd"},
+		{language.English, 3, "~3rd"},
+		{language.English, 11, "~11th"},
+		{language.English, 22, "~22nd"},
+		{language.Swedish, 1, "~1:a"},
+		{language.Swedish, 12, "~12:e"},
+		{language.Swedish, 15, "~15:e"},
+		{language.German, 15, "~15."},
+		{language.French, 15, "~15"},
+	}
+
+	for _, tt := range tests {
+		if got := GetLocale(tt.tag).FormatDay(tt.day); got != tt.expected {
+			t.Errorf("%s FormatDay(%d) = %q, want %q", tt.tag, tt.day, got, tt.expected)
+		}
+	}
+}
+
+func TestLocale_Translations(t *testing.T) {
+	sv := GetLocale(language.MustParse("sv-SE"))
+	if got := sv.T("Monthly"); got != "Månadsvis" {
+		t.Errorf("T(Monthly) = %q, want %q", got, "Månadsvis")
+	}
+	if got := sv.Sprintf("Showing: %s\n\n", "all"); got != "Visar: all\n\n" {
+		t.Errorf("Sprintf() = %q, want %q", got, "Visar: all\n\n")
+	}
+
+	// Untranslated languages and the zero value fall back to English
+	if got := GetLocale(language.French).T("Monthly"); got != "Monthly" {
+		t.Errorf("T(Monthly) = %q, want fallback %q", got, "Monthly")
+	}
+	if got := (Locale{}).FormatDay(15); got != "~15" {
+		t.Errorf("zero Locale FormatDay(15) = %q, want %q", got, "~15")
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	loc, err := ResolveLocale("sv_SE.UTF-8")
+	if err != nil {
+		t.Fatalf("ResolveLocale() error = %v", err)
+	}
+	if loc.Tag != language.MustParse("sv-SE") {
+		t.Errorf("ResolveLocale() tag = %v, want sv-SE", loc.Tag)
+	}
+
+	if _, err := ResolveLocale("not a locale!"); err == nil {
+		t.Error("expected error for invalid locale")
+	}
+}
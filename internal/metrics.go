@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// WriteMetrics writes subscription gauges in the Prometheus text exposition format
+func WriteMetrics(w io.Writer, subs []Subscription, dateRange DateRange, txCount int, currency Currency) {
+	active, stopped := countByStatus(subs)
+	monthly := ActiveMonthlyTotal(subs)
+	cur := fmt.Sprintf(`currency="%s"`, escapeLabelValue(currency.Code))
+
+	gauge := func(name, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("subscription_detector_active_subscriptions", "Number of active subscriptions.")
+	fmt.Fprintf(w, "subscription_detector_active_subscriptions %d\n", active)
+	gauge("subscription_detector_stopped_subscriptions", "Number of stopped subscriptions.")
+	fmt.Fprintf(w, "subscription_detector_stopped_subscriptions %d\n", stopped)
+	gauge("subscription_detector_monthly_total", "Sum of the latest monthly amounts of active subscriptions.")
+	fmt.Fprintf(w, "subscription_detector_monthly_total{%s} %g\n", cur, monthly)
+	gauge("subscription_detector_yearly_total", "Monthly total of active subscriptions times 12.")
+	fmt.Fprintf(w, "subscription_detector_yearly_total{%s} %g\n", cur, monthly*12)
+
+	gauge("subscription_detector_subscription_monthly_cost", "Latest monthly amount per subscription.")
+	for _, sub := range subs {
+		fmt.Fprintf(w, "subscription_detector_subscription_monthly_cost{name=\"%s\",status=\"%s\",%s} %g\n",
+			escapeLabelValue(sub.Name), sub.Status, cur, math.Abs(sub.LatestAmount))
+	}
+	gauge("subscription_detector_subscription_total_paid", "Sum of all payments per subscription.")
+	for _, sub := range subs {
+		fmt.Fprintf(w, "subscription_detector_subscription_total_paid{name=\"%s\",status=\"%s\",%s} %g\n",
+			escapeLabelValue(sub.Name), sub.Status, cur, sub.TotalPaid)
+	}
+
+	gauge("subscription_detector_transactions", "Number of imported transactions.")
+	fmt.Fprintf(w, "subscription_detector_transactions %d\n", txCount)
+	if txCount > 0 {
+		gauge("subscription_detector_data_end_timestamp_seconds", "Date of the latest imported transaction (Unix time).")
+		fmt.Fprintf(w, "subscription_detector_data_end_timestamp_seconds %d\n", dateRange.End.Unix())
+	}
+}
+
+// escapeLabelValue escapes a Prometheus label value (backslash, double quote and newline)
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// handleMetrics serves Prometheus metrics for the transactions imported into the state file
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	subs, dateRange, txCount, err := s.detect(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	WriteMetrics(w, subs, dateRange, txCount, s.Currency)
+}
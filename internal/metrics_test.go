@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Netflix", Status: StatusActive, LatestAmount: -99, TotalPaid: 1188},
+		{Name: `Odd "Name"`, Status: StatusActive, LatestAmount: -10.5, TotalPaid: 21},
+		{Name: "Gym", Status: StatusStopped, LatestAmount: -300, TotalPaid: 900},
+	}
+	dateRange := DateRange{End: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)}
+
+	var buf bytes.Buffer
+	WriteMetrics(&buf, subs, dateRange, 42, GetCurrency("SEK"))
+	output := buf.String()
+
+	for _, expected := range []string{
+		"# TYPE subscription_detector_active_subscriptions gauge\n",
+		"subscription_detector_active_subscriptions 2\n",
+		"subscription_detector_stopped_subscriptions 1\n",
+		`subscription_detector_monthly_total{currency="SEK"} 109.5` + "\n",
+		`subscription_detector_yearly_total{currency="SEK"} 1314` + "\n",
+		`subscription_detector_subscription_monthly_cost{name="Netflix",status="active",currency="SEK"} 99` + "\n",
+		`subscription_detector_subscription_monthly_cost{name="Odd \"Name\"",status="active",currency="SEK"} 10.5` + "\n",
+		`subscription_detector_subscription_total_paid{name="Gym",status="stopped",currency="SEK"} 900` + "\n",
+		"subscription_detector_transactions 42\n",
+		"subscription_detector_data_end_timestamp_seconds 1765756800\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
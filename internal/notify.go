@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NotifierConfig configures a chat webhook, push service or notifier plugin (see RegisterNotifier)
+// that receives change summaries. Environment variables (${VAR}) are expanded in URLs, tokens and ids.
+type NotifierConfig struct {
+	Type       string            `yaml:"type" json:"type"`                                   // "slack", "discord", "ntfy", "pushover", "telegram" or a plugin name
+	WebhookURL string            `yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"` // slack, discord
+	URL        string            `yaml:"url,omitempty" json:"url,omitempty"`                 // ntfy topic URL (e.g., https://ntfy.sh/my-topic)
+	Token      string            `yaml:"token,omitempty" json:"token,omitempty"`             // pushover application token, ntfy access token (optional)
+	User       string            `yaml:"user,omitempty" json:"user,omitempty"`               // pushover user key
+	BotToken   string            `yaml:"bot_token,omitempty" json:"bot_token,omitempty"`     // telegram
+	ChatID     string            `yaml:"chat_id,omitempty" json:"chat_id,omitempty"`         // telegram
+	Kinds      []string          `yaml:"kinds,omitempty" json:"kinds,omitempty"`             // change kinds to notify about (empty = all)
+	Options    map[string]string `yaml:"options,omitempty" json:"options,omitempty"`         // settings for notifier plugins
+}
+
+// discordMaxLength is the maximum length of a Discord message
+const discordMaxLength = 2000
+
+// notificationTitle is used by push services that show a separate title
+const notificationTitle = "Subscription changes"
+
+// API endpoints of push services (variables so tests can point them to a local server)
+var (
+	pushoverAPIURL = "https://api.pushover.net/1/messages.json"
+	telegramAPIURL = "https://api.telegram.org"
+)
+
+// validate checks the notifier's settings. Plugin notifiers (see RegisterNotifier) check their
+// own settings when sending.
+func (n NotifierConfig) validate(plugin bool) error {
+	var required map[string]string
+	switch n.Type {
+	case "slack", "discord":
+		required = map[string]string{"webhook_url": n.WebhookURL}
+	case "ntfy":
+		required = map[string]string{"url": n.URL}
+	case "pushover":
+		required = map[string]string{"token": n.Token, "user": n.User}
+	case "telegram":
+		required = map[string]string{"bot_token": n.BotToken, "chat_id": n.ChatID}
+	default:
+		if !plugin {
+			return fmt.Errorf("unknown type %q (expected slack, discord, ntfy, pushover, telegram or a notifier plugin)", n.Type)
+		}
+	}
+	for _, field := range []string{"webhook_url", "url", "token", "user", "bot_token", "chat_id"} {
+		if value, ok := required[field]; ok && value == "" {
+			return fmt.Errorf("%s is required for %s", field, n.Type)
+		}
+	}
+	for _, kind := range n.Kinds {
+		switch normalizeChangeKind(kind) {
+		case ChangeNew, ChangeRemoved, ChangeStopped, ChangeResumed, ChangePriceIncrease, ChangePriceDecrease:
+		default:
+			return fmt.Errorf("unknown change kind %q", kind)
+		}
+	}
+	return nil
+}
+
+// wants reports whether the notifier is interested in a change kind
+func (n NotifierConfig) wants(kind ChangeKind) bool {
+	if len(n.Kinds) == 0 {
+		return true
+	}
+	for _, k := range n.Kinds {
+		if normalizeChangeKind(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeChangeKind accepts change kinds with dashes (e.g., "price-increase")
+func normalizeChangeKind(kind string) ChangeKind {
+	return ChangeKind(strings.ReplaceAll(strings.ToLower(kind), "-", "_"))
+}
+
+// FormatChange returns a one-line, human-readable description of a change
+// (e.g., "New subscription detected: Disney+ 119 kr/month")
+func FormatChange(c SubscriptionChange, currency Currency) string {
+	switch c.Kind {
+	case ChangeNew:
+		return fmt.Sprintf("New subscription detected: %s %s/month", c.Name, currency.Format(c.NewAmount))
+	case ChangeResumed:
+		return fmt.Sprintf("Subscription resumed: %s %s/month", c.Name, currency.Format(c.NewAmount))
+	case ChangeStopped:
+		return fmt.Sprintf("Subscription stopped: %s", c.Name)
+	case ChangeRemoved:
+		return fmt.Sprintf("Subscription no longer detected: %s", c.Name)
+	case ChangePriceIncrease, ChangePriceDecrease:
+		if c.NewPlan != "" {
+			return fmt.Sprintf("Plan change: %s %s → %s, %s → %s/month", c.Name, c.OldPlan, c.NewPlan, currency.Format(c.OldAmount), currency.Format(c.NewAmount))
+		}
+		if c.Kind == ChangePriceDecrease {
+			return fmt.Sprintf("Price decrease: %s %s → %s/month", c.Name, currency.Format(c.OldAmount), currency.Format(c.NewAmount))
+		}
+		return fmt.Sprintf("Price increase: %s %s → %s/month", c.Name, currency.Format(c.OldAmount), currency.Format(c.NewAmount))
+	default:
+		return fmt.Sprintf("%s: %s", c.Kind, c.Name)
+	}
+}
+
+// FormatChangeSummary returns a multi-line summary of changes, or "" if there are none
+func FormatChangeSummary(changes []SubscriptionChange, since time.Time, currency Currency) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Subscription changes since %s:\n", since.Format("2006-01-02"))
+	for _, c := range changes {
+		fmt.Fprintf(&sb, "• %s\n", FormatChange(c, currency))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// SendNotifications posts the change summary to each notifier that is interested in
+// at least one of the changes. All notifiers are tried; errors are joined.
+func SendNotifications(ctx context.Context, client *http.Client, notifiers []NotifierConfig, report *ChangeReport, currency Currency) error {
+	if report == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, n := range notifiers {
+		var changes []SubscriptionChange
+		for _, c := range report.Changes {
+			if n.wants(c.Kind) {
+				changes = append(changes, c)
+			}
+		}
+		message := FormatChangeSummary(changes, report.Since, currency)
+		if message == "" {
+			continue
+		}
+		if plugin, ok := GetNotifier(n.Type); ok && !slices.Contains(builtinNotifiers, n.Type) {
+			notification := Notification{Message: message, Since: report.Since, Changes: changes, Currency: currency}
+			if err := plugin.Notify(ctx, n, notification); err != nil {
+				errs = append(errs, fmt.Errorf("%s notification: %w", n.Type, err))
+			}
+			continue
+		}
+		if err := n.send(ctx, client, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s notification: %w", n.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts a message to the notifier's service
+func (n NotifierConfig) send(ctx context.Context, client *http.Client, message string) error {
+	var req *http.Request
+	var err error
+	switch n.Type {
+	case "discord":
+		if len([]rune(message)) > discordMaxLength {
+			message = string([]rune(message)[:discordMaxLength-1]) + "…"
+		}
+		req, err = jsonRequest(ctx, os.ExpandEnv(n.WebhookURL), map[string]string{"content": message})
+	case "ntfy":
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, os.ExpandEnv(n.URL), strings.NewReader(message))
+		if err == nil {
+			req.Header.Set("Title", notificationTitle)
+			if n.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+os.ExpandEnv(n.Token))
+			}
+		}
+	case "pushover":
+		form := url.Values{
+			"token":   {os.ExpandEnv(n.Token)},
+			"user":    {os.ExpandEnv(n.User)},
+			"title":   {notificationTitle},
+			"message": {message},
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPIURL, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	case "telegram":
+		endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIURL, os.ExpandEnv(n.BotToken))
+		req, err = jsonRequest(ctx, endpoint, map[string]string{"chat_id": os.ExpandEnv(n.ChatID), "text": message})
+	default: // "slack"
+		req, err = jsonRequest(ctx, os.ExpandEnv(n.WebhookURL), map[string]string{"text": message})
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Don't leak URLs (which may contain secrets) into error messages
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// jsonRequest builds a POST request with a JSON body
+func jsonRequest(ctx context.Context, endpoint string, payload any) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatChange(t *testing.T) {
+	sek := GetCurrency("SEK")
+	tests := []struct {
+		change   SubscriptionChange
+		expected string
+	}{
+		{SubscriptionChange{Name: "Disney+", Kind: ChangeNew, NewAmount: 119}, "New subscription detected: Disney+ 119 kr/month"},
+		{SubscriptionChange{Name: "Spotify", Kind: ChangePriceIncrease, OldAmount: 119, NewAmount: 129}, "Price increase: Spotify 119 kr → 129 kr/month"},
+		{SubscriptionChange{Name: "HBO Max", Kind: ChangeStopped}, "Subscription stopped: HBO Max"},
+	}
+	for _, tt := range tests {
+		if got := FormatChange(tt.change, sek); got != tt.expected {
+			t.Errorf("FormatChange(%+v) = %q, want %q", tt.change, got, tt.expected)
+		}
+	}
+}
+
+func TestSendNotifications(t *testing.T) {
+	received := make(map[string]map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		received[r.URL.Path] = payload
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_WEBHOOK_BASE", server.URL)
+	notifiers := []NotifierConfig{
+		{Type: "slack", WebhookURL: "${TEST_WEBHOOK_BASE}/slack"},
+		{Type: "discord", WebhookURL: server.URL + "/discord", Kinds: []string{"price_increase"}},
+		{Type: "discord", WebhookURL: server.URL + "/nothing", Kinds: []string{"removed"}},
+	}
+	report := &ChangeReport{
+		Since: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		Changes: []SubscriptionChange{
+			{Name: "Disney+", Kind: ChangeNew, NewAmount: 119},
+			{Name: "Spotify", Kind: ChangePriceIncrease, OldAmount: 119, NewAmount: 129},
+		},
+	}
+
+	if err := SendNotifications(context.Background(), server.Client(), notifiers, report, GetCurrency("SEK")); err != nil {
+		t.Fatalf("SendNotifications() error = %v", err)
+	}
+
+	slack := received["/slack"]["text"]
+	if !strings.HasPrefix(slack, "Subscription changes since 2025-11-01:") ||
+		!strings.Contains(slack, "New subscription detected: Disney+ 119 kr/month") ||
+		!strings.Contains(slack, "Price increase: Spotify") {
+		t.Errorf("unexpected Slack message: %q", slack)
+	}
+	discord := received["/discord"]["content"]
+	if !strings.Contains(discord, "Spotify") || strings.Contains(discord, "Disney+") {
+		t.Errorf("expected Discord message with only the price increase, got: %q", discord)
+	}
+	if _, ok := received["/nothing"]; ok {
+		t.Error("expected no message for a notifier without matching changes")
+	}
+
+	err := SendNotifications(context.Background(), server.Client(), []NotifierConfig{{Type: "slack", WebhookURL: server.URL + "/failing"}}, report, GetCurrency("SEK"))
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+}
+
+func TestLoadConfig_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	os.WriteFile(path, []byte("notify:\n  - type: slack\n    webhook_url: https://hooks.slack.com/services/x\n    kinds: [new]\n"), 0644)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Notify) != 1 || cfg.Notify[0].Type != "slack" || cfg.Notify[0].Kinds[0] != "new" {
+		t.Errorf("unexpected notifiers: %+v", cfg.Notify)
+	}
+
+	for _, invalid := range []string{
+		"notify:\n  - type: email\n    webhook_url: x\n",
+		"notify:\n  - type: discord\n",
+		"notify:\n  - type: slack\n    webhook_url: x\n    kinds: [bogus]\n",
+		"notify:\n  - type: pushover\n    token: x\n",
+		"notify:\n  - type: telegram\n    bot_token: x\n",
+		"notify:\n  - type: ntfy\n",
+	} {
+		os.WriteFile(path, []byte(invalid), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("expected error for config:\n%s", invalid)
+		}
+	}
+}
+
+func TestSendNotifications_PushServices(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   string
+	}
+	received := make(map[string]request)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(strings.Builder)
+		if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+			r.ParseForm()
+			body.WriteString(r.PostForm.Encode())
+		} else {
+			data, _ := io.ReadAll(r.Body)
+			body.Write(data)
+		}
+		received[r.URL.Path] = request{header: r.Header, body: body.String()}
+	}))
+	defer server.Close()
+
+	origPushover, origTelegram := pushoverAPIURL, telegramAPIURL
+	pushoverAPIURL, telegramAPIURL = server.URL+"/pushover", server.URL
+	defer func() { pushoverAPIURL, telegramAPIURL = origPushover, origTelegram }()
+
+	t.Setenv("TEST_BOT_TOKEN", "123:abc")
+	notifiers := []NotifierConfig{
+		{Type: "ntfy", URL: server.URL + "/my-topic", Token: "tk_secret", Kinds: []string{"new"}},
+		{Type: "pushover", Token: "app-token", User: "user-key", Kinds: []string{"price-increase"}},
+		{Type: "telegram", BotToken: "${TEST_BOT_TOKEN}", ChatID: "-1001", Kinds: []string{"stopped"}},
+	}
+	report := &ChangeReport{
+		Since: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		Changes: []SubscriptionChange{
+			{Name: "Disney+", Kind: ChangeNew, NewAmount: 119},
+			{Name: "Spotify", Kind: ChangePriceIncrease, OldAmount: 119, NewAmount: 129},
+			{Name: "HBO Max", Kind: ChangeStopped},
+		},
+	}
+
+	if err := SendNotifications(context.Background(), server.Client(), notifiers, report, GetCurrency("SEK")); err != nil {
+		t.Fatalf("SendNotifications() error = %v", err)
+	}
+
+	ntfy := received["/my-topic"]
+	if !strings.Contains(ntfy.body, "Disney+") || strings.Contains(ntfy.body, "Spotify") {
+		t.Errorf("expected ntfy message with only the new subscription, got: %q", ntfy.body)
+	}
+	if ntfy.header.Get("Authorization") != "Bearer tk_secret" || ntfy.header.Get("Title") == "" {
+		t.Errorf("expected ntfy auth and title headers, got: %v", ntfy.header)
+	}
+
+	pushover := received["/pushover"].body
+	if !strings.Contains(pushover, "token=app-token") || !strings.Contains(pushover, "user=user-key") || !strings.Contains(pushover, "Spotify") {
+		t.Errorf("unexpected Pushover request: %q", pushover)
+	}
+
+	var telegram map[string]string
+	json.Unmarshal([]byte(received["/bot123:abc/sendMessage"].body), &telegram)
+	if telegram["chat_id"] != "-1001" || !strings.Contains(telegram["text"], "Subscription stopped: HBO Max") {
+		t.Errorf("unexpected Telegram request: %+v (received: %v)", telegram, received)
+	}
+}
@@ -0,0 +1,70 @@
+package internal
+
+import "fmt"
+
+// DetectionEventKind is the type of a detection event
+type DetectionEventKind string
+
+const (
+	DetectionGrouped  DetectionEventKind = "grouped"  // an expense was grouped under a payee
+	DetectionRejected DetectionEventKind = "rejected" // a strategy or the config rejected a payee
+	DetectionAccepted DetectionEventKind = "accepted" // a strategy detected a subscription
+)
+
+// RejectionCode is a machine-readable reason why a payee was rejected
+type RejectionCode string
+
+const (
+	RejectionTooFewOccurrences RejectionCode = "too-few-occurrences" // not enough payments in complete months
+	RejectionMultipleInMonth   RejectionCode = "multiple-in-month"   // more than one payment in a month
+	RejectionToleranceExceeded RejectionCode = "tolerance-exceeded"  // amounts change more than the tolerance
+	RejectionIntervalMismatch  RejectionCode = "interval-mismatch"   // payments aren't the strategy's interval apart
+	RejectionMissingMonth      RejectionCode = "missing-month"       // a month without a payment (variable strategy)
+	RejectionExcluded          RejectionCode = "excluded"            // removed by an exclude rule of the config
+	RejectionOther             RejectionCode = "other"               // any other reason (custom strategies)
+)
+
+// DetectionEvent describes a step of the detection pipeline
+type DetectionEvent struct {
+	Kind         DetectionEventKind
+	Payee        string        // payee (display name) the event is about
+	Strategy     string        // strategy that rejected or accepted the payee ("" for grouping and exclusions)
+	Code         RejectionCode // why the payee was rejected, machine-readable
+	Reason       string        // why the payee was rejected
+	Rule         string        // pattern of the exclude rule that excluded the payee
+	Transaction  *Transaction  // the grouped transaction
+	Subscription *Subscription // the accepted subscription
+}
+
+// Observer receives detection events, e.g. to explain why a payee was or wasn't detected.
+// Events are delivered synchronously from the goroutine running the detector.
+type Observer interface {
+	Observe(event DetectionEvent)
+}
+
+// ObserverFunc is a function that implements Observer
+type ObserverFunc func(event DetectionEvent)
+
+func (f ObserverFunc) Observe(event DetectionEvent) {
+	f(event)
+}
+
+// WithObserver sends detection events to o
+func WithObserver(o Observer) DetectorOption {
+	return func(d *Detector) { d.observer = o }
+}
+
+// Reject reports that the current strategy rejected a payee, with a code (RejectionOther if none
+// fits) and a reason for people. The reason is only formatted when an observer is listening.
+func (g *GroupedTransactions) Reject(payee PayeeGroup, code RejectionCode, format string, args ...any) {
+	if g.observer == nil {
+		return
+	}
+	g.observer.Observe(DetectionEvent{
+		Kind:     DetectionRejected,
+		Payee:    payee.Name,
+		Strategy: g.strategy,
+		Code:     code,
+		Reason:   fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestObserver(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-02-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-03-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-01-10"), Text: "Grocery", Amount: -150},
+		{Date: date("2025-01-25"), Text: "Grocery", Amount: -300},
+		{Date: date("2025-02-12"), Text: "Grocery", Amount: -200},
+		{Date: date("2025-01-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-02-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-03-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-04-15"), Text: "Other", Amount: -10}, // just to set date range
+	}
+	cfg, err := parseConfig([]byte("use_default_known: false\nexclude: [Gym]\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+
+	var events []DetectionEvent
+	observer := ObserverFunc(func(e DetectionEvent) { events = append(events, e) })
+	if _, _, err := NewDetector(WithObserver(observer)).Analyze(context.Background(), txs, cfg); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	grouped := 0
+	reasons := make(map[string]string)
+	var accepted []string
+	for _, e := range events {
+		switch e.Kind {
+		case DetectionGrouped:
+			grouped++
+			if e.Transaction == nil || e.Transaction.Text != e.Payee {
+				t.Errorf("expected the grouped transaction of %s, got %+v", e.Payee, e.Transaction)
+			}
+		case DetectionRejected:
+			reasons[e.Payee] = e.Reason
+			if e.Code == RejectionExcluded && e.Rule != "Gym" {
+				t.Errorf("expected the exclude rule of %s, got %q", e.Payee, e.Rule)
+			}
+		case DetectionAccepted:
+			if e.Strategy != StrategyMonthly || e.Subscription == nil {
+				t.Errorf("unexpected accepted event: %+v", e)
+			}
+			accepted = append(accepted, e.Payee)
+		}
+	}
+
+	if grouped != len(txs) {
+		t.Errorf("expected %d grouped events, got %d", len(txs), grouped)
+	}
+	if len(accepted) != 2 {
+		t.Errorf("expected Netflix and Gym to be accepted, got %v", accepted)
+	}
+	expected := map[string]string{
+		"Grocery": "more than one payment in a month",
+		"Other":   "0 payments in complete months (need 2)",
+		"Gym":     "excluded by the config",
+	}
+	for payee, reason := range expected {
+		if reasons[payee] != reason {
+			t.Errorf("expected %s to be rejected with %q, got %q", payee, reason, reasons[payee])
+		}
+	}
+}
@@ -1,30 +1,129 @@
 package internal
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"os"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-isatty"
 )
 
 // OutputOptions controls how subscriptions are displayed
 type OutputOptions struct {
-	ShowFilter string
-	TagFilter  []string
-	SortField  string
-	SortDir    string
-	Currency   Currency
+	ShowFilter       string
+	TagFilter        []string
+	Filter           string // name/description pattern the displayed subscriptions were filtered by
+	SortField        string
+	SortDir          string
+	Currency         Currency
+	Sparkline        bool              // show a sparkline of payment amounts over time
+	MaxWidth         int               // max table width in characters (0 = unlimited)
+	Locale           Locale            // language for labels, dates and the Day column
+	Changes          *ChangeReport     // changes since the last snapshot (nil = not compared)
+	Events           []Event           // lifecycle events to include in JSON output (nil = not included)
+	Rejections       []JSONRejection   // rejected payees to include in JSON output (nil = not included)
+	KPIs             *KPIs             // key figures and savings opportunities to include (nil = not included)
+	Commitments      []Subscription    // recurring commitments to list apart from the subscriptions (see SplitCommitments)
+	PriceComparisons []PriceComparison // subscriptions costing more than their standard plan (nil = not compared)
+	OlderThan        int               // also list active subscriptions paid for at least this many years (0 = not listed)
+	RuleSource       bool              // show the mechanism and config rules behind each subscription (see ExplainRuleSource)
+	GroupBy          string            // split the table into sections with subtotals by GroupByTag etc. ("" = one list)
+	Top              int               // only list the N most expensive active subscriptions, plus an "others" row (0 = all)
+	Stable           bool              // omit fields that differ between runs on the same data (snapshot timestamps)
+	RealTerms        bool              // show inflation-adjusted amounts in history and reports (see AdjustHistory)
 }
 
+// ConfigureColors enables or disables colored output globally.
+// mode is "always", "never" or "auto"; in auto mode colors are only used when w is a
+// terminal and not disabled through the environment (NO_COLOR, TERM=dumb).
+func ConfigureColors(mode string, w io.Writer) {
+	switch mode {
+	case "always":
+		text.EnableColors()
+	case "never":
+		text.DisableColors()
+	default: // "auto"
+		if !IsTerminal(w) {
+			text.DisableColors()
+		}
+	}
+}
+
+// IsTerminal returns true if w is an interactive terminal (not, e.g., a pipe, a file or /dev/null)
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// JSONSchemaVersion is the version of the JSON output format.
+// Bump it on any breaking change (removed/renamed fields or changed semantics).
+const JSONSchemaVersion = 1
+
 // JSONOutput is the root JSON output object
 type JSONOutput struct {
-	Subscriptions []JSONSubscription `json:"subscriptions"`
-	Summary       JSONSummary        `json:"summary"`
+	SchemaVersion    int                   `json:"schema_version"`
+	Subscriptions    []JSONSubscription    `json:"subscriptions"`
+	Summary          JSONSummary           `json:"summary"`
+	ComparedWith     string                `json:"compared_with,omitempty"` // timestamp of the snapshot compared against
+	Changes          []JSONChange          `json:"changes,omitempty"`
+	Events           []Event               `json:"events,omitempty"`
+	Rejections       []JSONRejection       `json:"rejections,omitempty"`
+	Household        *JSONHousehold        `json:"household,omitempty"` // only for exports labeled with --person
+	KPIs             *JSONKPIs             `json:"kpis,omitempty"`      // only with --kpis
+	Others           *JSONOthers           `json:"others,omitempty"`    // only with --top, for the subscriptions not listed
+	Commitments      []JSONSubscription    `json:"commitments,omitempty"`
+	PriceComparisons []JSONPriceComparison `json:"price_comparisons,omitempty"` // only with --compare-prices
+	OldSubscriptions []string              `json:"old_subscriptions,omitempty"` // only with --older-than
+}
+
+// JSONOthers is the JSON output format of the active subscriptions left out by --top
+type JSONOthers struct {
+	Count        int     `json:"count"`
+	MonthlyTotal float64 `json:"monthly_total"`
+	YearlyTotal  float64 `json:"yearly_total"`
+}
+
+// JSONHousehold is the JSON output format of the per-person breakdown of a household
+type JSONHousehold struct {
+	Persons    []JSONPersonSubtotal `json:"persons"`
+	Duplicates []JSONDuplicate      `json:"duplicates,omitempty"`
+}
+
+// JSONPersonSubtotal is the JSON output format for what one person pays for active subscriptions
+type JSONPersonSubtotal struct {
+	Person       string  `json:"person"`
+	Count        int     `json:"count"`
+	MonthlyTotal float64 `json:"monthly_total"`
+}
+
+// JSONDuplicate is the JSON output format for a service paid by more than one person
+type JSONDuplicate struct {
+	Name         string   `json:"name"`
+	Persons      []string `json:"persons"`
+	MonthlyTotal float64  `json:"monthly_total"`
+}
+
+// JSONChange is the JSON output format for a change since the last snapshot
+type JSONChange struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	OldAmount float64 `json:"old_amount,omitempty"`
+	NewAmount float64 `json:"new_amount,omitempty"`
+	OldPlan   string  `json:"old_plan,omitempty"` // plans of a price change that is likely a plan change
+	NewPlan   string  `json:"new_plan,omitempty"`
 }
 
 // JSONSummary contains aggregate statistics
@@ -32,6 +131,7 @@ type JSONSummary struct {
 	Count        int     `json:"count"`
 	MonthlyTotal float64 `json:"monthly_total"`
 	YearlyTotal  float64 `json:"yearly_total"`
+	StoppedSpend float64 `json:"stopped_total_paid"` // lifetime spend on stopped subscriptions
 	Currency     string  `json:"currency"`
 }
 
@@ -40,57 +140,177 @@ type JSONSubscription struct {
 	Name         string   `json:"name"`
 	Description  string   `json:"description,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
+	Category     string   `json:"category,omitempty"`
 	Status       string   `json:"status"`
 	TypicalDay   int      `json:"typical_day"`
 	StartDate    string   `json:"start_date"`
 	LastDate     string   `json:"last_date"`
+	AgeMonths    int      `json:"age_months"` // months from the first to the last payment
 	LatestAmount float64  `json:"latest_amount"`
 	MinAmount    float64  `json:"min_amount"`
 	MaxAmount    float64  `json:"max_amount"`
 	YearlyCost   float64  `json:"yearly_cost"`
+	TotalPaid    float64  `json:"total_paid"`
+	Share        float64  `json:"share,omitempty"` // your share of a shared subscription; amounts are already your share
+	Person       string   `json:"person,omitempty"`
+	Commitment   string   `json:"commitment,omitempty"` // kind of recurring commitment, only in commitments
+
+	AnnualCharge     float64 `json:"annual_charge,omitempty"`      // latest yearly charge on top of the monthly payments, e.g. an annual fee
+	AnnualChargeDate string  `json:"annual_charge_date,omitempty"` // date of the latest yearly charge
+
+	Source *JSONRuleSource `json:"source,omitempty"` // only with --show-rule-source
+}
+
+// JSONRuleSource is the JSON output format of the mechanism and config rules behind a subscription
+type JSONRuleSource struct {
+	Mechanism string   `json:"mechanism"`
+	Rules     []string `json:"rules,omitempty"`
 }
 
 // PrintSubscriptionsJSON outputs subscriptions in JSON format
-func PrintSubscriptionsJSON(w io.Writer, subs []Subscription, cfg *Config, currency Currency) {
+func PrintSubscriptionsJSON(w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(BuildJSONOutput(subs, cfg, opts))
+}
+
+// BuildJSONOutput returns the JSON output for subscriptions, as printed by PrintSubscriptionsJSON
+func BuildJSONOutput(subs []Subscription, cfg *Config, opts OutputOptions) JSONOutput {
 	var subscriptions []JSONSubscription
-	var monthlyTotal float64
 
-	for _, sub := range subs {
-		desc := ""
-		var tags []string
-		if cfg != nil {
-			desc = cfg.GetDescription(sub.Name)
-			tags = cfg.GetTags(sub.Name)
-		}
+	listed, others := subs, []Subscription(nil)
+	if opts.Top > 0 {
+		listed, others = TopSubscriptions(subs, opts.Top)
+	}
+	for _, sub := range listed {
+		subscriptions = append(subscriptions, buildJSONSubscription(sub, cfg, opts))
+	}
 
-		latestAmount := math.Abs(sub.LatestAmount)
-		if sub.Status == StatusActive {
-			monthlyTotal += latestAmount
+	output := JSONOutput{
+		SchemaVersion: JSONSchemaVersion,
+		Subscriptions: subscriptions,
+		Summary:       buildJSONSummary(subs, opts.Currency),
+		Events:        opts.Events,
+		Rejections:    opts.Rejections,
+	}
+	if HasPersons(subs) {
+		output.Household = buildJSONHousehold(subs, cfg, opts.Currency)
+	}
+	if opts.KPIs != nil {
+		output.KPIs = buildJSONKPIs(*opts.KPIs, opts.Currency)
+	}
+	for _, sub := range opts.Commitments {
+		output.Commitments = append(output.Commitments, buildJSONSubscription(sub, cfg, opts))
+	}
+	if opts.PriceComparisons != nil {
+		output.PriceComparisons = buildJSONPriceComparisons(opts.PriceComparisons, opts.Currency)
+	}
+	if opts.OlderThan > 0 {
+		for _, sub := range OlderThan(subs, opts.OlderThan) {
+			output.OldSubscriptions = append(output.OldSubscriptions, sub.Name)
 		}
+	}
+	if len(others) > 0 {
+		monthly := ActiveMonthlyTotal(others)
+		output.Others = &JSONOthers{
+			Count:        len(others),
+			MonthlyTotal: opts.Currency.Round(monthly),
+			YearlyTotal:  opts.Currency.Round(monthly * 12),
+		}
+	}
+	if opts.Changes != nil {
+		if !opts.Stable {
+			output.ComparedWith = opts.Changes.Since.Format(time.RFC3339)
+		}
+		for _, c := range opts.Changes.Changes {
+			output.Changes = append(output.Changes, JSONChange{
+				Name:      c.Name,
+				Kind:      string(c.Kind),
+				OldAmount: opts.Currency.Round(c.OldAmount),
+				NewAmount: opts.Currency.Round(c.NewAmount),
+				OldPlan:   c.OldPlan,
+				NewPlan:   c.NewPlan,
+			})
+		}
+	}
+	return output
+}
+
+// buildJSONSubscription returns the JSON output format of a subscription
+func buildJSONSubscription(sub Subscription, cfg *Config, opts OutputOptions) JSONSubscription {
+	desc := ""
+	var tags []string
+	if cfg != nil {
+		desc = cfg.GetDescription(sub.Name)
+		tags = cfg.GetTags(sub.Name)
+	}
+
+	latestAmount := math.Abs(sub.LatestAmount)
+
+	var source *JSONRuleSource
+	if opts.RuleSource {
+		s := ExplainRuleSource(sub, cfg)
+		source = &JSONRuleSource{Mechanism: s.Mechanism, Rules: s.Rules}
+	}
+
+	out := JSONSubscription{
+		Name:         sub.Name,
+		Description:  desc,
+		Tags:         tags,
+		Category:     cfg.GetCategory(sub.Name),
+		Status:       string(sub.Status),
+		TypicalDay:   sub.TypicalDay,
+		StartDate:    sub.StartDate.Format("2006-01-02"),
+		LastDate:     sub.LastDate.Format("2006-01-02"),
+		AgeMonths:    sub.AgeMonths(),
+		LatestAmount: opts.Currency.Round(latestAmount),
+		MinAmount:    opts.Currency.Round(sub.MinAmount),
+		MaxAmount:    opts.Currency.Round(sub.MaxAmount),
+		YearlyCost:   opts.Currency.Round(sub.MonthlyCost() * 12),
+		TotalPaid:    opts.Currency.Round(sub.TotalPaid),
+		Share:        sub.Share,
+		Person:       sub.Person,
+		Commitment:   sub.Commitment,
+		Source:       source,
+	}
+	if len(sub.AnnualCharges) > 0 {
+		out.AnnualCharge = opts.Currency.Round(sub.AnnualCharge())
+		out.AnnualChargeDate = sub.AnnualCharges[len(sub.AnnualCharges)-1].Date.Format("2006-01-02")
+	}
+	return out
+}
 
-		subscriptions = append(subscriptions, JSONSubscription{
-			Name:         sub.Name,
-			Description:  desc,
-			Tags:         tags,
-			Status:       string(sub.Status),
-			TypicalDay:   sub.TypicalDay,
-			StartDate:    sub.StartDate.Format("2006-01-02"),
-			LastDate:     sub.LastDate.Format("2006-01-02"),
-			LatestAmount: latestAmount,
-			MinAmount:    sub.MinAmount,
-			MaxAmount:    sub.MaxAmount,
-			YearlyCost:   latestAmount * 12,
+func buildJSONHousehold(subs []Subscription, cfg *Config, currency Currency) *JSONHousehold {
+	household := &JSONHousehold{}
+	for _, s := range SubtotalsByPerson(subs) {
+		household.Persons = append(household.Persons, JSONPersonSubtotal{
+			Person:       s.Person,
+			Count:        s.Active,
+			MonthlyTotal: currency.Round(s.MonthlyTotal),
 		})
 	}
+	for _, d := range DuplicateAccounts(subs, cfg) {
+		household.Duplicates = append(household.Duplicates, JSONDuplicate{
+			Name:         d.Name,
+			Persons:      d.Persons,
+			MonthlyTotal: currency.Round(d.MonthlyTotal),
+		})
+	}
+	return household
+}
 
-	output := JSONOutput{
-		Subscriptions: subscriptions,
-		Summary: JSONSummary{
-			Count:        len(subscriptions),
-			MonthlyTotal: monthlyTotal,
-			YearlyTotal:  monthlyTotal * 12,
-			Currency:     currency.Code,
-		},
+// PrintSummaryJSON outputs only the summary section (and the key figures, if set) in JSON format
+func PrintSummaryJSON(w io.Writer, subs []Subscription, opts OutputOptions) {
+	output := struct {
+		SchemaVersion int         `json:"schema_version"`
+		Summary       JSONSummary `json:"summary"`
+		KPIs          *JSONKPIs   `json:"kpis,omitempty"`
+	}{
+		SchemaVersion: JSONSchemaVersion,
+		Summary:       buildJSONSummary(subs, opts.Currency),
+	}
+	if opts.KPIs != nil {
+		output.KPIs = buildJSONKPIs(*opts.KPIs, opts.Currency)
 	}
 
 	enc := json.NewEncoder(w)
@@ -98,71 +318,162 @@ func PrintSubscriptionsJSON(w io.Writer, subs []Subscription, cfg *Config, curre
 	enc.Encode(output)
 }
 
-// PrintSubscriptionsTable outputs subscriptions as a formatted table
-func PrintSubscriptionsTable(w io.Writer, allSubs []Subscription, displaySubs []Subscription, opts OutputOptions, cfg *Config) {
-	// Count from all subscriptions (for summary line)
-	activeCount := 0
-	stoppedCount := 0
-	for _, sub := range allSubs {
-		if sub.Status == StatusActive {
-			activeCount++
+// PrintJSONSchema outputs the JSON Schema document describing the JSON output
+func PrintJSONSchema(w io.Writer) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(GenerateJSONSchema())
+}
+
+func buildJSONSummary(subs []Subscription, currency Currency) JSONSummary {
+	monthlyTotal := ActiveMonthlyTotal(subs)
+	return JSONSummary{
+		Count:        len(subs),
+		MonthlyTotal: currency.Round(monthlyTotal),
+		YearlyTotal:  currency.Round(monthlyTotal * 12),
+		StoppedSpend: currency.Round(stoppedTotalPaid(subs)),
+		Currency:     currency.Code,
+	}
+}
+
+// PrintSummary outputs only subscription counts and totals, without the table
+func PrintSummary(w io.Writer, allSubs []Subscription, displaySubs []Subscription, opts OutputOptions) {
+	activeCount, stoppedCount := countByStatus(allSubs)
+	totalMonthlyCost := ActiveMonthlyTotal(displaySubs)
+
+	fmt.Fprint(w, opts.Locale.Sprintf("Found %d subscriptions (%d active, %d stopped)\n",
+		len(allSubs), activeCount, stoppedCount))
+	fmt.Fprint(w, opts.Locale.Sprintf("Monthly total (active): %s\n", opts.Currency.Format(totalMonthlyCost)))
+	fmt.Fprint(w, opts.Locale.Sprintf("Yearly total (active): %s\n", opts.Currency.Format(totalMonthlyCost*12)))
+	if stoppedSpend := stoppedTotalPaid(displaySubs); stoppedSpend > 0 {
+		fmt.Fprint(w, opts.Locale.Sprintf("Lifetime spend on stopped subscriptions: %s\n", opts.Currency.Format(stoppedSpend)))
+	}
+	if _, possible := splitPossible(displaySubs); len(possible) > 0 {
+		fmt.Fprint(w, opts.Locale.Sprintf("Possible subscriptions (not in totals): %d\n", len(possible)))
+	}
+	if opts.KPIs != nil {
+		fmt.Fprintln(w)
+		PrintKPIs(w, *opts.KPIs, opts)
+	}
+	if opts.PriceComparisons != nil {
+		fmt.Fprintln(w)
+		PrintPriceComparisons(w, opts.PriceComparisons, opts)
+	}
+	if opts.OlderThan > 0 {
+		fmt.Fprintln(w)
+		PrintOldSubscriptions(w, OlderThan(displaySubs, opts.OlderThan), opts)
+	}
+}
+
+// countByStatus returns the number of active and stopped subscriptions (possible ones are neither)
+func countByStatus(subs []Subscription) (active, stopped int) {
+	for _, sub := range subs {
+		switch sub.Status {
+		case StatusActive:
+			active++
+		case StatusStopped:
+			stopped++
+		}
+	}
+	return active, stopped
+}
+
+// splitPossible separates the possible subscriptions (see StatusPossible) from the others
+func splitPossible(subs []Subscription) (others, possible []Subscription) {
+	for _, sub := range subs {
+		if sub.Status == StatusPossible {
+			possible = append(possible, sub)
 		} else {
-			stoppedCount++
+			others = append(others, sub)
 		}
 	}
+	return others, possible
+}
 
-	// Calculate totals from displayed subscriptions only (using latest amount)
-	var totalMonthlyCost float64
-	for _, sub := range displaySubs {
+// TopSubscriptions returns the n active subscriptions with the highest monthly cost, most
+// expensive first, and the other active ones
+func TopSubscriptions(subs []Subscription, n int) (top, others []Subscription) {
+	for _, sub := range subs {
 		if sub.Status == StatusActive {
-			totalMonthlyCost += math.Abs(sub.LatestAmount)
+			top = append(top, sub)
 		}
 	}
+	sort.SliceStable(top, func(i, j int) bool {
+		if a, b := top[i].MonthlyCost(), top[j].MonthlyCost(); a != b {
+			return a > b
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) <= n {
+		return top, nil
+	}
+	return top[:n], top[n:]
+}
+
+// stoppedTotalPaid sums everything ever paid to stopped subscriptions
+func stoppedTotalPaid(subs []Subscription) float64 {
+	var total float64
+	for _, sub := range subs {
+		if sub.Status == StatusStopped {
+			total += sub.TotalPaid
+		}
+	}
+	return total
+}
+
+// ActiveMonthlyTotal sums the monthly cost of all active subscriptions
+func ActiveMonthlyTotal(subs []Subscription) float64 {
+	var total float64
+	for _, sub := range subs {
+		if sub.Status == StatusActive {
+			total += sub.MonthlyCost()
+		}
+	}
+	return total
+}
+
+// PrintSubscriptionsTable outputs subscriptions as a formatted table
+func PrintSubscriptionsTable(w io.Writer, allSubs []Subscription, displaySubs []Subscription, opts OutputOptions, cfg *Config) {
+	// Count from all subscriptions (for summary line)
+	activeCount, stoppedCount := countByStatus(allSubs)
+
+	// Calculate totals from displayed subscriptions only (using latest amount)
+	totalMonthlyCost := ActiveMonthlyTotal(displaySubs)
 	totalYearlyCost := totalMonthlyCost * 12
 
-	fmt.Fprintf(w, "Found %d subscriptions (%d active, %d stopped)\n",
-		len(allSubs), activeCount, stoppedCount)
+	fmt.Fprint(w, opts.Locale.Sprintf("Found %d subscriptions (%d active, %d stopped)\n",
+		len(allSubs), activeCount, stoppedCount))
 	showingStr := opts.ShowFilter
 	if len(opts.TagFilter) > 0 {
 		showingStr += fmt.Sprintf(", tags: %s", strings.Join(opts.TagFilter, ", "))
 	}
-	fmt.Fprintf(w, "Showing: %s\n\n", showingStr)
-
-	// Sort displayed subscriptions
-	sort.Slice(displaySubs, func(i, j int) bool {
-		var less bool
-		switch opts.SortField {
-		case "amount":
-			less = math.Abs(displaySubs[i].AvgAmount) < math.Abs(displaySubs[j].AvgAmount)
-		case "description":
-			iName := displaySubs[i].Name
-			jName := displaySubs[j].Name
-			if cfg != nil {
-				if desc := cfg.GetDescription(iName); desc != "" {
-					iName = desc
-				}
-				if desc := cfg.GetDescription(jName); desc != "" {
-					jName = desc
-				}
-			}
-			less = strings.ToLower(iName) < strings.ToLower(jName)
-		default: // "name"
-			less = strings.ToLower(displaySubs[i].Name) < strings.ToLower(displaySubs[j].Name)
-		}
-		if opts.SortDir == "desc" {
-			return !less
-		}
-		return less
-	})
+	if opts.Filter != "" {
+		showingStr += fmt.Sprintf(", filter: %s", opts.Filter)
+	}
+	if opts.Top > 0 {
+		showingStr += fmt.Sprintf(", top: %d", opts.Top)
+	}
+	fmt.Fprint(w, opts.Locale.Sprintf("Showing: %s\n\n", showingStr))
+
+	// Possible subscriptions are listed apart, after the table
+	displaySubs, possible := splitPossible(displaySubs)
+	rows, others := displaySubs, []Subscription(nil)
+	if opts.Top > 0 {
+		rows, others = TopSubscriptions(displaySubs, opts.Top)
+	} else {
+		SortSubscriptions(rows, opts.SortField, opts.SortDir, cfg)
+	}
 
 	t := table.NewWriter()
 	t.SetOutputMirror(w)
 
 	// Check which optional columns to show
+	hasPersons := HasPersons(rows)
 	hasDescriptions := false
 	hasTags := false
+	hasCategories := slices.ContainsFunc(rows, func(sub Subscription) bool { return cfg.GetCategory(sub.Name) != "" })
 	if cfg != nil {
-		for _, sub := range displaySubs {
+		for _, sub := range rows {
 			if cfg.GetDescription(sub.Name) != "" {
 				hasDescriptions = true
 			}
@@ -176,72 +487,121 @@ func PrintSubscriptionsTable(w io.Writer, allSubs []Subscription, displaySubs []
 	}
 
 	// Build header dynamically
-	header := table.Row{"Name"}
+	loc := opts.Locale
+	header := table.Row{loc.T("Name")}
+	if hasPersons {
+		header = append(header, loc.T("Person"))
+	}
 	if hasDescriptions {
-		header = append(header, "Description")
+		header = append(header, loc.T("Description"))
 	}
 	if hasTags {
-		header = append(header, "Tags")
+		header = append(header, loc.T("Tags"))
+	}
+	if hasCategories {
+		header = append(header, loc.T("Category"))
+	}
+	if opts.RuleSource {
+		header = append(header, loc.T("Source"))
+	}
+	header = append(header, loc.T("Status"), loc.T("Day"), loc.T("Started"), loc.T("Age"), loc.T("Last Seen"))
+	if opts.Sparkline {
+		header = append(header, loc.T("Trend"))
 	}
-	header = append(header, "Status", "Day", "Started", "Last Seen", "Monthly", "Yearly")
+	header = append(header, loc.T("Monthly"), loc.T("Yearly"))
 	t.AppendHeader(header)
 
-	for _, sub := range displaySubs {
-		status := text.FgGreen.Sprint("ACTIVE")
-		if sub.Status == StatusStopped {
-			status = text.FgRed.Sprint("STOPPED")
+	// Empty cells for the columns between the first one and the last three
+	padding := len(header) - 4
+	summaryRow := func(name, label string, monthly float64) table.Row {
+		row := table.Row{name}
+		for range padding {
+			row = append(row, "")
 		}
+		return append(row, label, opts.Currency.Format(monthly), opts.Currency.Format(monthly*12))
+	}
 
-		monthlyStr := opts.Currency.Format(math.Abs(sub.AvgAmount))
-		if sub.MinAmount != sub.MaxAmount {
-			monthlyStr = opts.Currency.FormatRange(sub.MinAmount, sub.MaxAmount)
+	groups := []SubscriptionGroup{{Subscriptions: rows}}
+	if opts.GroupBy != "" {
+		groups = GroupSubscriptions(rows, opts.GroupBy, cfg)
+	}
+	for _, group := range groups {
+		if opts.GroupBy != "" {
+			t.AppendRow(table.Row{text.Bold.Sprint(groupLabel(group.Key, opts.GroupBy, loc))})
 		}
+		for _, sub := range group.Subscriptions {
+			status := text.FgGreen.Sprint(loc.T("ACTIVE"))
+			if sub.Status == StatusStopped {
+				status = text.FgRed.Sprint(loc.T("STOPPED"))
+			}
 
-		yearlyAmount := math.Abs(sub.LatestAmount) * 12
-		yearlyStr := opts.Currency.Format(yearlyAmount)
-		if sub.Status == StatusStopped {
-			yearlyStr = text.FgHiBlack.Sprint("-")
-		}
+			monthlyStr := opts.Currency.Format(math.Abs(sub.AvgAmount))
+			if sub.MinAmount != sub.MaxAmount {
+				monthlyStr = opts.Currency.FormatRange(sub.MinAmount, sub.MaxAmount)
+			}
 
-		dayStr := fmt.Sprintf("~%d", sub.TypicalDay)
+			yearlyAmount := sub.MonthlyCost() * 12
+			yearlyStr := opts.Currency.Format(yearlyAmount)
+			if sub.Status == StatusStopped {
+				yearlyStr = text.FgHiBlack.Sprint("-")
+			}
 
-		// Build row dynamically
-		row := table.Row{sub.Name}
-		if hasDescriptions {
-			desc := ""
-			if cfg != nil {
-				desc = cfg.GetDescription(sub.Name)
+			dayStr := loc.FormatDay(sub.TypicalDay)
+
+			// Build row dynamically
+			row := table.Row{sub.Name}
+			if hasPersons {
+				row = append(row, sub.Person)
 			}
-			row = append(row, desc)
-		}
-		if hasTags {
-			tagsStr := ""
-			if cfg != nil {
-				tags := cfg.GetTags(sub.Name)
-				tagsStr = strings.Join(tags, ", ")
+			if hasDescriptions {
+				desc := ""
+				if cfg != nil {
+					desc = cfg.GetDescription(sub.Name)
+				}
+				row = append(row, desc)
+			}
+			if hasTags {
+				tagsStr := ""
+				if cfg != nil {
+					tags := cfg.GetTags(sub.Name)
+					tagsStr = strings.Join(tags, ", ")
+				}
+				row = append(row, tagsStr)
+			}
+			if hasCategories {
+				row = append(row, cfg.GetCategory(sub.Name))
+			}
+			if opts.RuleSource {
+				row = append(row, ExplainRuleSource(sub, cfg).String())
+			}
+			row = append(row, status, dayStr, loc.FormatDate(sub.StartDate), FormatAge(sub.AgeMonths(), loc), loc.FormatDate(sub.LastDate))
+			if opts.Sparkline {
+				row = append(row, Sparkline(paymentAmounts(sub.Transactions, sparklineMaxPoints)))
 			}
-			row = append(row, tagsStr)
+			row = append(row, monthlyStr, yearlyStr)
+			t.AppendRow(row)
 		}
-		row = append(row, status, dayStr, sub.StartDate.Format("2006-01-02"), sub.LastDate.Format("2006-01-02"), monthlyStr, yearlyStr)
-		t.AppendRow(row)
+		if opts.GroupBy != "" {
+			t.AppendRow(summaryRow("", loc.T("Subtotal"), ActiveMonthlyTotal(group.Subscriptions)))
+			t.AppendSeparator()
+		}
+	}
+
+	if len(others) > 0 {
+		t.AppendRow(summaryRow(loc.Sprintf("Others (%d)", len(others)), "", ActiveMonthlyTotal(others)))
 	}
 
 	t.AppendSeparator()
 
 	// Build footer dynamically (empty cells for optional columns)
 	footer := table.Row{""}
-	if hasDescriptions {
+	for range padding {
 		footer = append(footer, "")
 	}
-	if hasTags {
-		footer = append(footer, "")
-	}
-	footer = append(footer, "", "", "", text.Bold.Sprint("Total (active)"), text.Bold.Sprint(opts.Currency.Format(totalMonthlyCost)), text.Bold.Sprint(opts.Currency.Format(totalYearlyCost)))
+	footer = append(footer, text.Bold.Sprint(loc.T("Total (active)")), text.Bold.Sprint(opts.Currency.Format(totalMonthlyCost)), text.Bold.Sprint(opts.Currency.Format(totalYearlyCost)))
 	t.AppendFooter(footer)
 
-	t.SetStyle(table.StyleRounded)
-	t.Style().Format.Header = text.FormatDefault
-	t.Style().Format.Footer = text.FormatDefault
+	styleTable(t, opts)
 
 	// Right-align Monthly and Yearly columns (last two)
 	colCount := len(header)
@@ -251,16 +611,253 @@ func PrintSubscriptionsTable(w io.Writer, allSubs []Subscription, displaySubs []
 	})
 
 	t.Render()
+
+	if stoppedSpend := stoppedTotalPaid(displaySubs); stoppedSpend > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, loc.Sprintf("Lifetime spend on stopped subscriptions: %s\n", opts.Currency.Format(stoppedSpend)))
+	}
+
+	if len(possible) > 0 {
+		fmt.Fprintln(w)
+		PrintPossibleSubscriptions(w, possible, opts, cfg)
+	}
+
+	if annual := WithAnnualCharges(rows); len(annual) > 0 {
+		fmt.Fprintln(w)
+		PrintAnnualCharges(w, annual, opts)
+	}
+
+	if len(opts.Commitments) > 0 {
+		fmt.Fprintln(w)
+		PrintCommitments(w, opts.Commitments, opts, cfg)
+	}
+
+	if opts.RuleSource {
+		printExcluded(w, opts)
+	}
+
+	if opts.KPIs != nil {
+		fmt.Fprintln(w)
+		PrintKPIs(w, *opts.KPIs, opts)
+	}
+
+	if opts.PriceComparisons != nil {
+		fmt.Fprintln(w)
+		PrintPriceComparisons(w, opts.PriceComparisons, opts)
+	}
+
+	if opts.OlderThan > 0 {
+		fmt.Fprintln(w)
+		PrintOldSubscriptions(w, OlderThan(displaySubs, opts.OlderThan), opts)
+	}
+
+	if hasPersons {
+		fmt.Fprintln(w)
+		PrintHousehold(w, displaySubs, cfg, opts)
+	}
+
+	if opts.Changes != nil {
+		fmt.Fprintln(w)
+		PrintChanges(w, opts.Changes, opts)
+	}
+}
+
+// printExcluded lists the payees that exclude rules removed, with the rules (see --show-rule-source)
+func printExcluded(w io.Writer, opts OutputOptions) {
+	first := true
+	for _, r := range opts.Rejections {
+		for _, reason := range r.Reasons {
+			if reason.Code != RejectionExcluded {
+				continue
+			}
+			if first {
+				fmt.Fprintln(w)
+				fmt.Fprint(w, opts.Locale.T("Excluded by the config:\n"))
+				first = false
+			}
+			fmt.Fprintf(w, "  %s: exclude %q\n", r.Payee, reason.Rule)
+		}
+	}
+}
+
+// groupLabel returns the section title of a group of the subscription table
+func groupLabel(key, by string, loc Locale) string {
+	switch {
+	case by == GroupByStatus:
+		return loc.T(strings.ToUpper(key))
+	case by == GroupByTag && key == "":
+		return loc.T("Untagged")
+	case by == GroupByCategory && key == "":
+		return loc.T("Uncategorized")
+	case by == GroupByAccount && key == "":
+		return loc.T("No account")
+	case by == GroupByInterval:
+		return loc.T(key)
+	}
+	return key
+}
+
+// PrintPossibleSubscriptions lists possible subscriptions (single payments to known services,
+// see StatusPossible), which aren't counted in the totals
+func PrintPossibleSubscriptions(w io.Writer, possible []Subscription, opts OutputOptions, cfg *Config) {
+	loc := opts.Locale
+	fmt.Fprint(w, loc.T("Possible subscriptions (not in totals):\n"))
+	SortSubscriptions(possible, opts.SortField, opts.SortDir, cfg)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Name"), loc.T("Last Seen"), loc.T("Amount")})
+	for _, sub := range possible {
+		t.AppendRow(table.Row{sub.Name, loc.FormatDate(sub.LastDate), opts.Currency.Format(math.Abs(sub.LatestAmount))})
+	}
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 3, Align: text.AlignRight}})
+	t.Render()
+}
+
+// PrintChanges outputs the changes since the last snapshot as a highlighted list
+func PrintChanges(w io.Writer, report *ChangeReport, opts OutputOptions) {
+	loc := opts.Locale
+	since := loc.FormatDate(report.Since)
+	switch {
+	case len(report.Changes) == 0 && opts.Stable:
+		fmt.Fprint(w, loc.T("No changes since last snapshot.\n"))
+		return
+	case len(report.Changes) == 0:
+		fmt.Fprint(w, loc.Sprintf("No changes since last snapshot (%s).\n", since))
+		return
+	case opts.Stable:
+		fmt.Fprint(w, loc.T("Changes since last snapshot:\n"))
+	default:
+		fmt.Fprint(w, loc.Sprintf("Changes since last snapshot (%s):\n", since))
+	}
+	for _, c := range report.Changes {
+		var line string
+		switch c.Kind {
+		case ChangeNew:
+			line = text.FgGreen.Sprintf("  + %-10s %s (%s)", loc.T("NEW"), c.Name, opts.Currency.Format(c.NewAmount))
+		case ChangeResumed:
+			line = text.FgGreen.Sprintf("  + %-10s %s", loc.T("RESUMED"), c.Name)
+		case ChangeStopped:
+			line = text.FgRed.Sprintf("  - %-10s %s", loc.T("STOPPED"), c.Name)
+		case ChangeRemoved:
+			line = text.FgRed.Sprintf("  - %-10s %s", loc.T("REMOVED"), c.Name)
+		case ChangePriceIncrease:
+			line = text.FgYellow.Sprintf("  ↑ %-10s %s %s → %s%s", priceChangeLabel(c, "PRICE UP", loc), c.Name,
+				opts.Currency.Format(c.OldAmount), opts.Currency.Format(c.NewAmount), planSuffix(c))
+		case ChangePriceDecrease:
+			line = text.FgCyan.Sprintf("  ↓ %-10s %s %s → %s%s", priceChangeLabel(c, "PRICE DOWN", loc), c.Name,
+				opts.Currency.Format(c.OldAmount), opts.Currency.Format(c.NewAmount), planSuffix(c))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// priceChangeLabel returns the label of a price change: NEW PLAN for likely plan changes (see
+// AnnotatePlanChanges), else the given one
+func priceChangeLabel(c SubscriptionChange, label string, loc Locale) string {
+	if c.NewPlan != "" {
+		return loc.T("NEW PLAN")
+	}
+	return loc.T(label)
 }
 
-// FilterByStatus filters subscriptions by status (active/stopped/all)
+// planSuffix returns the plans of a likely plan change, e.g. " (Individual → Family)", or ""
+func planSuffix(c SubscriptionChange) string {
+	if c.NewPlan == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s → %s)", c.OldPlan, c.NewPlan)
+}
+
+// sparklineMaxPoints limits the sparkline to the most recent payments
+const sparklineMaxPoints = 12
+
+// sparklineTicks are the block characters used to draw sparklines, lowest first
+var sparklineTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a unicode sparkline scaled between their min and max.
+// A series where all values are equal renders as a flat line at the lowest tick.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	minVal, maxVal := values[0], values[0]
+	for _, v := range values[1:] {
+		minVal = math.Min(minVal, v)
+		maxVal = math.Max(maxVal, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if maxVal > minVal {
+			idx = int(math.Round((v - minVal) / (maxVal - minVal) * float64(len(sparklineTicks)-1)))
+		}
+		sb.WriteRune(sparklineTicks[idx])
+	}
+	return sb.String()
+}
+
+// paymentAmounts returns the absolute amounts of the last n transactions (in date order)
+func paymentAmounts(txs []Transaction, n int) []float64 {
+	if len(txs) > n {
+		txs = txs[len(txs)-n:]
+	}
+	amounts := make([]float64, len(txs))
+	for i, tx := range txs {
+		amounts[i] = math.Abs(tx.Amount)
+	}
+	return amounts
+}
+
+// SortSubscriptions sorts subscriptions in place by field ("name", "description" or "amount")
+// in direction dir ("asc" or "desc"). Descriptions come from cfg, falling back to the name.
+// Ties are broken by name, then start date, so the order doesn't depend on the input order.
+func SortSubscriptions(subs []Subscription, field, dir string, cfg *Config) {
+	sort.Slice(subs, func(i, j int) bool {
+		var c int
+		switch field {
+		case "amount":
+			c = cmp.Compare(math.Abs(subs[i].AvgAmount), math.Abs(subs[j].AvgAmount))
+		case "description":
+			iName := subs[i].Name
+			jName := subs[j].Name
+			if cfg != nil {
+				if desc := cfg.GetDescription(iName); desc != "" {
+					iName = desc
+				}
+				if desc := cfg.GetDescription(jName); desc != "" {
+					jName = desc
+				}
+			}
+			c = strings.Compare(strings.ToLower(iName), strings.ToLower(jName))
+		default: // "name"
+			c = strings.Compare(strings.ToLower(subs[i].Name), strings.ToLower(subs[j].Name))
+		}
+		if dir == "desc" {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Or(
+				strings.Compare(strings.ToLower(subs[i].Name), strings.ToLower(subs[j].Name)),
+				strings.Compare(subs[i].Name, subs[j].Name),
+				subs[i].StartDate.Compare(subs[j].StartDate),
+			)
+		}
+		return c < 0
+	})
+}
+
+// FilterByStatus filters subscriptions by status (active/stopped/all). Possible subscriptions
+// (see StatusPossible) are kept with the active ones.
 func FilterByStatus(subs []Subscription, show string) []Subscription {
 	if show == "all" {
 		return subs
 	}
 	var result []Subscription
 	for _, sub := range subs {
-		if show == "active" && sub.Status == StatusActive {
+		if show == "active" && (sub.Status == StatusActive || sub.Status == StatusPossible) {
 			result = append(result, sub)
 		} else if show == "stopped" && sub.Status == StatusStopped {
 			result = append(result, sub)
@@ -269,30 +866,61 @@ func FilterByStatus(subs []Subscription, show string) []Subscription {
 	return result
 }
 
-// FilterByTags filters subscriptions to only those with matching tags
+// FindSubscription returns the subscription with the given name (case-insensitive), or nil
+func FindSubscription(subs []Subscription, name string) *Subscription {
+	for i := range subs {
+		if strings.EqualFold(subs[i].Name, name) {
+			return &subs[i]
+		}
+	}
+	return nil
+}
+
+// FilterByTags filters subscriptions to only those with matching tags. tags is a tag filter
+// (e.g., "entertainment,!music", see ParseTagExpression); an invalid one matches nothing.
 func FilterByTags(subs []Subscription, tags []string, cfg *Config) []Subscription {
 	if cfg == nil || len(tags) == 0 {
 		return subs
 	}
+	expr, err := ParseTagExpression(tags)
+	if err != nil {
+		return nil
+	}
 	var result []Subscription
 	for _, sub := range subs {
-		subTags := cfg.GetTags(sub.Name)
-		if hasAnyTag(subTags, tags) {
+		if expr.Matches(cfg.GetTags(sub.Name)) {
 			result = append(result, sub)
 		}
 	}
 	return result
 }
 
-func hasAnyTag(subTags []string, filterTags []string) bool {
-	for _, ft := range filterTags {
-		for _, st := range subTags {
-			if strings.EqualFold(st, ft) {
-				return true
-			}
+// FilterByQuery returns subscriptions whose name or description contains query (case-insensitive).
+// An empty query matches all subscriptions.
+func FilterByQuery(subs []Subscription, query string, cfg *Config) []Subscription {
+	query = strings.ToLower(query)
+	if query == "" {
+		return subs
+	}
+	var result []Subscription
+	for _, sub := range subs {
+		text := sub.Name + " " + cfg.GetDescription(sub.Name)
+		if strings.Contains(strings.ToLower(text), query) {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// FilterByPattern returns subscriptions whose name or description matches re
+func FilterByPattern(subs []Subscription, re *regexp.Regexp, cfg *Config) []Subscription {
+	var result []Subscription
+	for _, sub := range subs {
+		if re.MatchString(sub.Name) || re.MatchString(cfg.GetDescription(sub.Name)) {
+			result = append(result, sub)
 		}
 	}
-	return false
+	return result
 }
 
 // FilterByExclusions removes subscriptions matching exclusion rules
@@ -308,3 +936,46 @@ func FilterByExclusions(subs []Subscription, cfg *Config) []Subscription {
 	}
 	return result
 }
+
+// PrintSourcesTable outputs the registered parsers as a table
+func PrintSourcesTable(w io.Writer, infos []ParserInfo, opts OutputOptions) {
+	loc := opts.Locale
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Source"), loc.T("Extensions"), loc.T("Auto-detect"), loc.T("Description"), loc.T("Example")})
+	for _, info := range infos {
+		autoDetect := ""
+		if info.Sniff != nil {
+			autoDetect = "✓"
+		}
+		t.AppendRow(table.Row{info.Name, strings.Join(info.Extensions, ", "), autoDetect, info.Description, info.Example})
+	}
+	styleTable(t, opts)
+	t.Render()
+}
+
+// JSONSource is the JSON output format for a registered parser
+type JSONSource struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Extensions  []string `json:"extensions,omitempty"`
+	AutoDetect  bool     `json:"auto_detect"` // the format is recognized from file contents
+	Example     string   `json:"example,omitempty"`
+}
+
+// PrintSourcesJSON outputs the registered parsers in JSON format
+func PrintSourcesJSON(w io.Writer, infos []ParserInfo) {
+	output := []JSONSource{}
+	for _, info := range infos {
+		output = append(output, JSONSource{
+			Name:        info.Name,
+			Description: info.Description,
+			Extensions:  info.Extensions,
+			AutoDetect:  info.Sniff != nil,
+			Example:     info.Example,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
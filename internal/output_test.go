@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected string
+	}{
+		{name: "empty", values: nil, expected: ""},
+		{name: "flat", values: []float64{99, 99, 99}, expected: "▁▁▁"},
+		{name: "rising", values: []float64{100, 150, 200}, expected: "▁▅█"},
+		{name: "price increase", values: []float64{119, 119, 129, 129}, expected: "▁▁██"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPaymentAmounts(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-15"), Amount: -100},
+		{Date: date("2025-02-15"), Amount: -110},
+		{Date: date("2025-03-15"), Amount: -120},
+	}
+
+	amounts := paymentAmounts(txs, 2)
+	if len(amounts) != 2 || amounts[0] != 110 || amounts[1] != 120 {
+		t.Errorf("expected last 2 absolute amounts [110 120], got %v", amounts)
+	}
+}
+
+func TestSortSubscriptions_Deterministic(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Spotify", AvgAmount: -119},
+		{Name: "netflix", AvgAmount: -99, StartDate: date("2025-02-01")},
+		{Name: "Netflix", AvgAmount: -99, StartDate: date("2025-01-01")},
+		{Name: "HBO", AvgAmount: -99},
+	}
+	tests := []struct {
+		field, dir string
+		expected   []string
+	}{
+		{"name", "asc", []string{"HBO", "Netflix", "netflix", "Spotify"}},
+		{"name", "desc", []string{"Spotify", "Netflix", "netflix", "HBO"}},
+		{"amount", "asc", []string{"HBO", "Netflix", "netflix", "Spotify"}},
+		{"amount", "desc", []string{"Spotify", "HBO", "Netflix", "netflix"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+" "+tt.dir, func(t *testing.T) {
+			// Every input order gives the same result
+			for shift := range subs {
+				sorted := append(append([]Subscription{}, subs[shift:]...), subs[:shift]...)
+				SortSubscriptions(sorted, tt.field, tt.dir, nil)
+				var names []string
+				for _, sub := range sorted {
+					names = append(names, sub.Name)
+				}
+				if !reflect.DeepEqual(names, tt.expected) {
+					t.Errorf("input shifted by %d: expected %v, got %v", shift, tt.expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintChanges_Stable(t *testing.T) {
+	report := &ChangeReport{
+		Since:   date("2025-06-01"),
+		Changes: []SubscriptionChange{{Name: "Netflix", Kind: ChangeNew, NewAmount: 99}},
+	}
+	opts := OutputOptions{Currency: GetCurrency("USD"), Locale: GetLocale(language.AmericanEnglish)}
+
+	var buf bytes.Buffer
+	PrintChanges(&buf, report, opts)
+	if !strings.Contains(buf.String(), "Changes since last snapshot (") {
+		t.Errorf("expected the snapshot date, got %q", buf.String())
+	}
+
+	opts.Stable = true
+	buf.Reset()
+	PrintChanges(&buf, report, opts)
+	if !strings.HasPrefix(buf.String(), "Changes since last snapshot:\n") || !strings.Contains(buf.String(), "Netflix") {
+		t.Errorf("expected the changes without the snapshot date, got %q", buf.String())
+	}
+
+	buf.Reset()
+	PrintChanges(&buf, &ChangeReport{Since: report.Since}, opts)
+	if buf.String() != "No changes since last snapshot.\n" {
+		t.Errorf("expected no changes without the snapshot date, got %q", buf.String())
+	}
+
+	buf.Reset()
+	PrintSubscriptionsJSON(&buf, nil, nil, OutputOptions{Currency: opts.Currency, Changes: report, Stable: true})
+	if strings.Contains(buf.String(), "compared_with") || !strings.Contains(buf.String(), "Netflix") {
+		t.Errorf("expected changes without compared_with in stable JSON, got %s", buf.String())
+	}
+}
+
+func TestFilterByPattern(t *testing.T) {
+	subs := []Subscription{{Name: "Spotify P3A8AC"}, {Name: "NETFLIX.COM"}, {Name: "K*svd.se"}, {Name: "Gym"}}
+	cfg := &Config{Descriptions: map[string]string{"K*svd.se": "Newspaper", "Gym": "Fitness"}}
+
+	var names []string
+	for _, sub := range FilterByPattern(subs, regexp.MustCompile("(?i)spotify|netflix|news"), cfg) {
+		names = append(names, sub.Name)
+	}
+	if got := strings.Join(names, ","); got != "Spotify P3A8AC,NETFLIX.COM,K*svd.se" {
+		t.Errorf("expected matches by name and description, got %s", got)
+	}
+	if got := FilterByPattern(subs, regexp.MustCompile("fitness"), nil); got != nil {
+		t.Errorf("expected no description matches without a config, got %+v", got)
+	}
+}
+
+func TestTopSubscriptions(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Spotify", LatestAmount: -119, Status: StatusActive},
+		{Name: "Gym", LatestAmount: -400, Status: StatusActive},
+		{Name: "HBO", LatestAmount: -999, Status: StatusStopped},
+		{Name: "Magazine", LatestAmount: -1200, Interval: IntervalYearly, Status: StatusActive},
+		{Name: "Netflix", LatestAmount: -119, Status: StatusActive},
+	}
+
+	top, others := TopSubscriptions(subs, 2)
+	var names []string
+	for _, sub := range append(top, others...) {
+		names = append(names, sub.Name)
+	}
+	if got := strings.Join(names, ","); got != "Gym,Netflix,Spotify,Magazine" {
+		t.Errorf("expected active subscriptions by monthly cost, then name, got %s", got)
+	}
+	if len(top) != 2 || len(others) != 2 {
+		t.Errorf("expected 2 top and 2 others, got %d and %d", len(top), len(others))
+	}
+	if top, others := TopSubscriptions(subs, 10); len(top) != 4 || others != nil {
+		t.Errorf("expected all active subscriptions and no others, got %d and %+v", len(top), others)
+	}
+}
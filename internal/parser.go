@@ -1,51 +1,319 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// Parser parses transaction files into a list of transactions
+// Parser parses transaction files into a list of transactions.
+// Parsers should stop and return ctx.Err() when the context is cancelled.
 type Parser interface {
-	Parse(path string) ([]Transaction, error)
+	Parse(ctx context.Context, path string) ([]Transaction, error)
 }
 
 // ParserFunc is a function that implements Parser
-type ParserFunc func(path string) ([]Transaction, error)
+type ParserFunc func(ctx context.Context, path string) ([]Transaction, error)
 
-func (f ParserFunc) Parse(path string) ([]Transaction, error) {
-	return f(path)
+func (f ParserFunc) Parse(ctx context.Context, path string) ([]Transaction, error) {
+	return f(ctx, path)
 }
 
-// parsers is the registry of available parsers
-var parsers = map[string]Parser{}
+// ParserInfo describes a registered parser for listings, help output and format auto-detection
+type ParserInfo struct {
+	Name        string   // source type, as used with --source and format:path
+	Description string   // one-line description
+	Extensions  []string // expected file extensions, e.g. ".xlsx"
+	Example     string   // example invocation
+
+	// Sniff reports whether the start of a file (up to sniffLen bytes) looks like this format.
+	// Optional; used to detect the format of files without a format prefix.
+	Sniff func(head []byte) bool
+}
+
+// sniffLen is the number of bytes passed to ParserInfo.Sniff
+const sniffLen = 4096
+
+type registeredParser struct {
+	parser Parser
+	info   ParserInfo
+}
+
+// registry holds the available parsers. Parsers may be registered from several packages'
+// init functions or at runtime in library mode, so access is guarded by a lock.
+var registry = struct {
+	sync.RWMutex
+	parsers map[string]registeredParser
+}{parsers: map[string]registeredParser{}}
 
 // RegisterParser registers a parser with the given name
 func RegisterParser(name string, p Parser) {
-	parsers[name] = p
+	RegisterParserWithInfo(ParserInfo{Name: name}, p)
+}
+
+// RegisterParserWithInfo registers a parser with metadata describing it. Registering a name
+// again replaces the previous parser.
+func RegisterParserWithInfo(info ParserInfo, p Parser) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.parsers[info.Name] = registeredParser{parser: p, info: info}
 }
 
 // GetParser returns the parser for the given source type
 func GetParser(source string) (Parser, error) {
-	p, ok := parsers[source]
+	registry.RLock()
+	p, ok := registry.parsers[source]
+	registry.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("unknown source type: %s (available: %v)", source, AvailableSources())
+		if source == "" {
+			return nil, fmt.Errorf("%w: none given (available: %v)", ErrUnknownSource, AvailableSources())
+		}
+		return nil, fmt.Errorf("%w: %s (available: %v)", ErrUnknownSource, source, AvailableSources())
+	}
+	return p.parser, nil
+}
+
+// ParseFile parses the file at path with the parser for source. Parse failures are returned
+// as a *ParseError; cancellation is returned as the context's error. Dates are normalized to
+// calendar days in the timezone in ctx (see WithTimezone).
+func ParseFile(ctx context.Context, source, path string) ([]Transaction, error) {
+	p, err := GetParser(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	txs, err := parseRecovered(ctx, p, path)
+	if err != nil {
+		return nil, parseFileError(ctx, path, err)
 	}
-	return p, nil
+	normalizeDates(txs, timezoneFrom(ctx))
+	if progress := progressFrom(ctx); progress != nil {
+		progress.fileDone(path, len(txs))
+	}
+	return txs, nil
+}
+
+// SourceFile is a file and the format to parse it with
+type SourceFile struct {
+	Source        string
+	Path          string
+	InvertAmounts bool   // the export lists charges as positive amounts; negate them (see InvertAmounts)
+	StatementDay  int    // statement cut-off day of a credit card export (see Transaction.StatementDay)
+	Person        string // household member the export belongs to (see Transaction.Person)
 }
 
-// AvailableSources returns a list of registered source types
+// Apply returns a transaction parsed from the file with the file's options applied
+func (f SourceFile) Apply(tx Transaction) Transaction {
+	if f.InvertAmounts {
+		tx.Amount = -tx.Amount
+	}
+	if f.StatementDay > 0 {
+		tx.StatementDay = f.StatementDay
+	}
+	if f.Person != "" {
+		tx.Person = f.Person
+	}
+	return tx
+}
+
+// InvertAmounts negates the amounts of txs in place, for exports that list charges as positive
+// numbers and refunds as negative ones. Balances are kept as exported.
+func InvertAmounts(txs []Transaction) {
+	for i := range txs {
+		txs[i].Amount = -txs[i].Amount
+	}
+}
+
+// ParseFiles parses files concurrently, with up to workers files at a time (GOMAXPROCS if
+// workers <= 0). The results are in the order of files, regardless of which file finished first.
+// All files are parsed even if one fails, and the error returned is that of the first failed
+// file in order, like ParseFile's. Files are read from the ParseCache in ctx, if any.
+func ParseFiles(ctx context.Context, files []SourceFile, workers int) ([][]Transaction, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	parse := ParseFile
+	if cache := parseCacheFrom(ctx); cache != nil {
+		parse = cache.ParseFile
+	}
+
+	results := make([][]Transaction, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(files)) {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i], errs[i] = parse(ctx, files[i].Source, files[i].Path)
+				for j, tx := range results[i] {
+					results[i][j] = files[i].Apply(tx)
+				}
+			}
+		})
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// StreamParser is implemented by parsers that can emit transactions one at a time instead of
+// returning them all, for streaming detection on very large files (see Detector.Stream)
+type StreamParser interface {
+	Parser
+	ParseStream(ctx context.Context, path string, fn func(Transaction) error) error
+}
+
+// StreamFile calls fn for each transaction in the file at path, parsed with the parser for
+// source. Parsers that don't implement StreamParser parse the whole file first. Errors are
+// returned like ParseFile's, except that errors from fn are returned unchanged.
+func StreamFile(ctx context.Context, source, path string, fn func(Transaction) error) error {
+	p, err := GetParser(source)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	loc := timezoneFrom(ctx)
+	sp, ok := p.(StreamParser)
+	if !ok {
+		txs, err := parseRecovered(ctx, p, path)
+		if err != nil {
+			return parseFileError(ctx, path, err)
+		}
+		for _, tx := range txs {
+			tx.Date = normalizeDate(tx.Date, loc)
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		if progress := progressFrom(ctx); progress != nil {
+			progress.fileDone(path, len(txs))
+		}
+		return nil
+	}
+
+	var fnErr error
+	count := 0
+	err = sp.ParseStream(ctx, path, func(tx Transaction) error {
+		count++
+		tx.Date = normalizeDate(tx.Date, loc)
+		fnErr = fn(tx)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return parseFileError(ctx, path, err)
+	}
+	if progress := progressFrom(ctx); progress != nil {
+		progress.fileDone(path, count)
+	}
+	return nil
+}
+
+// MaxParseSize is the largest input that is read into memory for parsing (1 GiB). Larger files
+// can still be streamed (see StreamFile).
+const MaxParseSize = 1 << 30
+
+// errTooLarge is returned for inputs larger than MaxParseSize
+var errTooLarge = fmt.Errorf("input is larger than %d MiB; use streaming (--stream) for files this large", MaxParseSize>>20)
+
+// parseRecovered parses a file with p, returning a panic in the parser as an error
+func parseRecovered(ctx context.Context, p Parser, path string) (txs []Transaction, err error) {
+	defer recoverParse(path, &err)
+	return p.Parse(ctx, path)
+}
+
+// recoverParse turns a panic while parsing malformed input (e.g., in a library reading a
+// truncated workbook) into a *ParseError for path. Defer it in parse functions with a named
+// error result.
+func recoverParse(path string, err *error) {
+	if r := recover(); r != nil {
+		*err = &ParseError{File: path, Err: fmt.Errorf("malformed input: %v", r)}
+	}
+}
+
+// readLimited reads all of r, failing for input larger than MaxParseSize
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxParseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxParseSize {
+		return nil, errTooLarge
+	}
+	return data, nil
+}
+
+// parseFileError returns a parser's error as a *ParseError for path, or unchanged for cancellation
+func parseFileError(ctx context.Context, path string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		if parseErr.File == "" {
+			parseErr.File = path
+		}
+		return parseErr
+	}
+	return &ParseError{File: path, Err: err}
+}
+
+// AvailableSources returns a sorted list of registered source types
 func AvailableSources() []string {
+	registry.RLock()
+	defer registry.RUnlock()
 	var sources []string
-	for name := range parsers {
+	for name := range registry.parsers {
 		sources = append(sources, name)
 	}
+	sort.Strings(sources)
 	return sources
 }
 
+// SourceInfos returns the metadata of all registered parsers, sorted by name
+func SourceInfos() []ParserInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	var infos []ParserInfo
+	for _, p := range registry.parsers {
+		infos = append(infos, p.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
 // IsKnownParser returns true if the name is a registered parser
 func IsKnownParser(name string) bool {
-	_, ok := parsers[name]
+	registry.RLock()
+	defer registry.RUnlock()
+	_, ok := registry.parsers[name]
 	return ok
 }
 
@@ -66,7 +334,69 @@ func ParseFileArg(arg string) (format, path string) {
 	return "", arg // Not a known parser, treat whole thing as path
 }
 
+// FormatForFile guesses the format of a file from its extension, using the extensions of the
+// registered parsers. Returns "" if no parser, or more than one, claims the extension.
+func FormatForFile(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return ""
+	}
+	var matches []string
+	for _, info := range SourceInfos() {
+		for _, e := range info.Extensions {
+			if strings.ToLower(e) == ext {
+				matches = append(matches, info.Name)
+				break
+			}
+		}
+	}
+	if len(matches) != 1 {
+		return ""
+	}
+	return matches[0]
+}
+
+// DetectFormat determines the format of a file without a format prefix. Parsers whose sniffer
+// accepts the start of the file win (ties are broken by extension); otherwise the format is
+// guessed from the extension. Returns "" if the format can't be determined.
+func DetectFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+
+	byExtension := FormatForFile(path)
+	var sniffed []string
+	for _, info := range SourceInfos() {
+		if info.Sniff != nil && info.Sniff(head) {
+			sniffed = append(sniffed, info.Name)
+		}
+	}
+	switch {
+	case len(sniffed) == 1:
+		return sniffed[0], nil
+	case len(sniffed) > 1 && slices.Contains(sniffed, byExtension):
+		return byExtension, nil
+	case len(sniffed) > 1:
+		return "", nil
+	}
+	return byExtension, nil
+}
+
 func init() {
 	// Register built-in parsers
-	RegisterParser("handelsbanken-xlsx", ParserFunc(ParseHandelsbankenXLSX))
+	RegisterParserWithInfo(ParserInfo{
+		Name:        "handelsbanken-xlsx",
+		Description: "Handelsbanken (Sweden) Excel export of an account or credit card",
+		Extensions:  []string{".xlsx"},
+		Example:     "subscription-detector --source handelsbanken-xlsx export.xlsx",
+		Sniff:       sniffXLSX,
+	}, handelsbankenParser{})
 }
@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -13,57 +15,137 @@ import (
 // Supports two layouts:
 // - Regular account: Reskontradatum, Transaktionsdatum, Text, Belopp, Saldo
 // - Credit card: Reskontradatum, Transaktionsdatum, Text, Belopp (no Saldo, may have empty first column)
-func ParseHandelsbankenXLSX(path string) ([]Transaction, error) {
-	f, err := excelize.OpenFile(path)
+func ParseHandelsbankenXLSX(ctx context.Context, path string) ([]Transaction, error) {
+	return collectTransactions(func(fn func(Transaction) error) error {
+		return StreamHandelsbankenXLSX(ctx, path, fn)
+	})
+}
+
+// ParseHandelsbankenXLSXReader reads transactions from a Handelsbanken Excel export in r, of at
+// most MaxParseSize bytes
+func ParseHandelsbankenXLSXReader(ctx context.Context, r io.Reader) ([]Transaction, error) {
+	data, err := readLimited(r)
 	if err != nil {
-		return nil, fmt.Errorf("opening file: %w", err)
+		return nil, fmt.Errorf("reading input: %w", err)
 	}
-	defer f.Close()
+	return ParseHandelsbankenXLSXBytes(ctx, data)
+}
+
+// ParseHandelsbankenXLSXBytes reads transactions from a Handelsbanken Excel export that isn't
+// read from a file (e.g. in fuzz tests)
+func ParseHandelsbankenXLSXBytes(ctx context.Context, data []byte) ([]Transaction, error) {
+	return collectTransactions(func(fn func(Transaction) error) (err error) {
+		defer recoverParse("input", &err)
+		f, err := excelize.OpenReader(bytes.NewReader(data), xlsxOptions())
+		if err != nil {
+			return fmt.Errorf("opening workbook: %w", err)
+		}
+		defer f.Close()
+		return streamHandelsbankenWorkbook(ctx, f, "input", fn)
+	})
+}
+
+// collectTransactions returns the transactions that stream passes to its callback
+func collectTransactions(stream func(fn func(Transaction) error) error) ([]Transaction, error) {
+	var transactions []Transaction
+	err := stream(func(tx Transaction) error {
+		transactions = append(transactions, tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// xlsxOptions limits how much of a workbook excelize unzips, so that a malicious or corrupt file
+// (e.g., a zip bomb) fails instead of exhausting memory
+func xlsxOptions() excelize.Options {
+	return excelize.Options{UnzipSizeLimit: MaxParseSize}
+}
 
-	sheets := f.GetSheetList()
-	if len(sheets) == 0 {
-		return nil, fmt.Errorf("no sheets found in file")
+// StreamHandelsbankenXLSX calls fn for each transaction in a Handelsbanken Excel export. All
+// sheets with the expected header are read in order (or those selected with WithSheets); sheets
+// without it, such as summaries, are skipped. Rows are read one at a time with excelize's
+// streaming reader rather than loading the whole sheet.
+func StreamHandelsbankenXLSX(ctx context.Context, path string, fn func(Transaction) error) (err error) {
+	defer recoverParse(path, &err)
+	f, err := excelize.OpenFile(path, xlsxOptions())
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
 	}
+	defer f.Close()
+	return streamHandelsbankenWorkbook(ctx, f, path, fn)
+}
 
-	rows, err := f.GetRows(sheets[0])
+// streamHandelsbankenWorkbook calls fn for each transaction in the sheets of a Handelsbanken
+// Excel export, naming path in progress reports
+func streamHandelsbankenWorkbook(ctx context.Context, f *excelize.File, path string, fn func(Transaction) error) error {
+	sheets, err := selectSheets(ctx, f.GetSheetList())
 	if err != nil {
-		return nil, fmt.Errorf("reading sheet: %w", err)
+		return err
 	}
 
-	// Find header row and column indices
-	var dateCol, textCol, amountCol int = -1, -1, -1
-	var dataStartRow int = -1
-
-	for i, row := range rows {
-		for j, cell := range row {
-			cell = strings.TrimSpace(cell)
-			switch cell {
-			case "Reskontradatum":
-				dateCol = j
-				dataStartRow = i + 1
-			case "Transaktionsdatum":
-				// Use transaction date if available, prefer over Reskontradatum
-				if dateCol == -1 || j > dateCol {
-					// Keep Reskontradatum as date column
-				}
-			case "Text":
-				textCol = j
-			case "Belopp":
-				amountCol = j
-			}
-		}
-		if dateCol >= 0 && textCol >= 0 && amountCol >= 0 {
-			break
+	count := 0
+	headerFound := false
+	for _, sheet := range sheets {
+		found, err := streamHandelsbankenSheet(ctx, f, sheet, path, &count, fn)
+		if err != nil {
+			return err
 		}
+		headerFound = headerFound || found
 	}
 
-	if dateCol < 0 || textCol < 0 || amountCol < 0 {
-		return nil, fmt.Errorf("could not find required columns (Reskontradatum, Text, Belopp)")
+	if !headerFound {
+		return fmt.Errorf("could not find required columns (Reskontradatum, Text, Belopp)")
 	}
+	return nil
+}
 
-	var transactions []Transaction
-	for i := dataStartRow; i < len(rows); i++ {
-		row := rows[i]
+// streamHandelsbankenSheet calls fn for each transaction in one sheet of a Handelsbanken Excel
+// export, counting them in count, and reports whether the sheet has the expected header
+func streamHandelsbankenSheet(ctx context.Context, f *excelize.File, sheet, path string, count *int, fn func(Transaction) error) (bool, error) {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return false, fmt.Errorf("reading sheet %q: %w", sheet, err)
+	}
+	defer rows.Close()
+
+	// Column indices, found in the header row
+	var dateCol, textCol, amountCol, balanceCol int = -1, -1, -1, -1
+	headerFound := false
+
+	for i := 0; rows.Next(); i++ {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			ReportProgress(ctx, path, *count, -1) // the row count isn't known up front
+		}
+		row, err := rows.Columns()
+		if err != nil {
+			return false, &ParseError{Line: i + 1, Err: fmt.Errorf("sheet %q: reading row: %w", sheet, err)}
+		}
+
+		if !headerFound {
+			for j, cell := range row {
+				cell = strings.TrimSpace(cell)
+				switch cell {
+				case "Reskontradatum":
+					dateCol = j
+				case "Transaktionsdatum":
+					// Keep Reskontradatum as date column
+				case "Text":
+					textCol = j
+				case "Belopp":
+					amountCol = j
+				case "Saldo":
+					balanceCol = j
+				}
+			}
+			headerFound = dateCol >= 0 && textCol >= 0 && amountCol >= 0
+			continue
+		}
 
 		// Ensure row has enough columns
 		maxCol := max(dateCol, textCol, amountCol)
@@ -80,28 +162,68 @@ func ParseHandelsbankenXLSX(path string) ([]Transaction, error) {
 			continue
 		}
 
-		// Parse date
-		date, err := time.Parse("2006-01-02", dateStr)
+		// Parse date (a formatted date, or the raw serial number of an unformatted date cell)
+		date, err := ParseDate(ctx, dateStr)
 		if err != nil {
-			continue
+			serial, serialErr := strconv.ParseFloat(dateStr, 64)
+			if serialErr == nil {
+				date, serialErr = excelize.ExcelDateToTime(serial, false)
+			}
+			if serialErr != nil {
+				if err := SkipRow(ctx, path, i+1, fmt.Sprintf("sheet %q: invalid date %q", sheet, dateStr)); err != nil {
+					return false, err
+				}
+				continue
+			}
+			date = day(date)
 		}
 
 		// Parse amount
-		amountStr = strings.ReplaceAll(amountStr, ",", ".")
-		amount, err := strconv.ParseFloat(amountStr, 64)
+		amount, err := ParseAmount(amountStr, ',')
 		if err != nil {
+			if err := SkipRow(ctx, path, i+1, fmt.Sprintf("sheet %q: invalid amount %q", sheet, amountStr)); err != nil {
+				return false, err
+			}
 			continue
 		}
 
+		tx := Transaction{Date: date, Text: text, Amount: amount}
+
 		// Strip "Prel " prefix from pending transactions
-		text = strings.TrimPrefix(text, "Prel ")
+		if cleaned := strings.TrimPrefix(text, "Prel "); cleaned != text {
+			tx.Text = cleaned
+			tx.RawText = text
+		}
 
-		transactions = append(transactions, Transaction{
-			Date:   date,
-			Text:   text,
-			Amount: amount,
-		})
+		if balanceCol >= 0 && balanceCol < len(row) {
+			if balance, err := ParseAmount(row[balanceCol], ','); err == nil {
+				tx.Balance = &balance
+			}
+		}
+
+		if err := fn(tx); err != nil {
+			return false, err
+		}
+		*count++
+	}
+	if err := rows.Error(); err != nil {
+		return false, fmt.Errorf("reading sheet %q: %w", sheet, err)
 	}
+	return headerFound, nil
+}
 
-	return transactions, nil
+// handelsbankenParser parses Handelsbanken Excel exports, in full or streaming
+type handelsbankenParser struct{}
+
+func (handelsbankenParser) Parse(ctx context.Context, path string) ([]Transaction, error) {
+	return ParseHandelsbankenXLSX(ctx, path)
+}
+
+func (handelsbankenParser) ParseStream(ctx context.Context, path string, fn func(Transaction) error) error {
+	return StreamHandelsbankenXLSX(ctx, path, fn)
+}
+
+// sniffXLSX reports whether head starts an Office Open XML (zip) file such as an Excel workbook
+func sniffXLSX(head []byte) bool {
+	return bytes.HasPrefix(head, []byte("PK\x03\x04")) && bytes.Contains(head, []byte("[Content_Types].xml"))
 }
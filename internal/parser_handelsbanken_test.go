@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseHandelsbankenXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp", "Saldo"})
+	f.SetSheetRow(sheet, "A2", &[]string{"2025-01-15", "2025-01-14", "Netflix", "-99,00", "1 000,50"})
+	f.SetSheetRow(sheet, "A3", &[]string{"2025-02-15", "2025-02-14", "Prel Netflix", "-99,00", "901,50"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	txs, err := ParseHandelsbankenXLSX(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ParseHandelsbankenXLSX: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 transactions, got %+v", txs)
+	}
+	if txs[0].Amount != -99 || txs[0].RawText != "" || txs[0].Balance == nil || *txs[0].Balance != 1000.5 {
+		t.Errorf("unexpected first transaction: %+v", txs[0])
+	}
+	if txs[1].Text != "Netflix" || txs[1].RawText != "Prel Netflix" || txs[1].Balance == nil || *txs[1].Balance != 901.5 {
+		t.Errorf("unexpected pending transaction: %+v", txs[1])
+	}
+}
+
+func TestParseHandelsbankenXLSX_DateCells(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+	// An unformatted date cell (serial number of 2025-01-31 23:30) and a datetime as text
+	f.SetSheetRow(sheet, "A2", &[]any{45688.979, "", "Netflix", "-99,00"})
+	f.SetSheetRow(sheet, "A3", &[]string{"2025-02-28 23:30", "", "Netflix", "-99,00"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	txs, err := ParseHandelsbankenXLSX(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ParseHandelsbankenXLSX: %v", err)
+	}
+	if len(txs) != 2 || !txs[0].Date.Equal(date("2025-01-31")) || !txs[1].Date.Equal(date("2025-02-28")) {
+		t.Errorf("expected dates 2025-01-31 and 2025-02-28, got %+v", txs)
+	}
+}
+
+func TestStreamHandelsbankenXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]string{"Kontoutdrag"})
+	f.SetSheetRow(sheet, "B3", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+	for i, month := range []string{"01", "02", "03"} {
+		f.SetSheetRow(sheet, fmt.Sprintf("B%d", i+4), &[]string{"2025-" + month + "-15", "2025-" + month + "-14", "Spotify", "-119,00"})
+	}
+	f.SetSheetRow(sheet, "B7", &[]string{"", "", "Summa", "-357,00"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var streamed []Transaction
+	if err := StreamFile(context.Background(), "handelsbanken-xlsx", path, func(tx Transaction) error {
+		streamed = append(streamed, tx)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamFile: %v", err)
+	}
+	parsed, err := ParseHandelsbankenXLSX(context.Background(), path)
+	if err != nil || len(streamed) != 3 || !reflect.DeepEqual(streamed, parsed) {
+		t.Errorf("expected 3 transactions both ways, streamed %+v, parsed %+v (%v)", streamed, parsed, err)
+	}
+
+	// Without the header row, the file isn't a Handelsbanken export
+	f = excelize.NewFile()
+	f.SetSheetRow(f.GetSheetName(0), "A1", &[]string{"Date", "Text", "Amount"})
+	f.SaveAs(path)
+	if _, err := ParseFile(context.Background(), "handelsbanken-xlsx", path); err == nil {
+		t.Error("expected an error for a sheet without the Handelsbanken columns")
+	}
+}
+
+func TestParseHandelsbankenXLSX_Sheets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), "Summary")
+	f.SetSheetRow("Summary", "A1", &[]string{"Konto", "Saldo"})
+	for i, year := range []string{"2024", "2025"} {
+		f.NewSheet(year)
+		f.SetSheetRow(year, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+		f.SetSheetRow(year, "A2", &[]string{year + "-01-15", "", "Netflix", fmt.Sprintf("-%d,00", 99+i)})
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// All sheets are read in order; sheets without transactions are skipped
+	txs, err := ParseHandelsbankenXLSX(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ParseHandelsbankenXLSX: %v", err)
+	}
+	if len(txs) != 2 || !txs[0].Date.Equal(date("2024-01-15")) || !txs[1].Date.Equal(date("2025-01-15")) {
+		t.Errorf("expected a transaction from each yearly sheet, got %+v", txs)
+	}
+
+	// WithSheets restricts them by name
+	txs, err = ParseHandelsbankenXLSX(WithSheets(context.Background(), []string{"2025"}), path)
+	if err != nil {
+		t.Fatalf("ParseHandelsbankenXLSX: %v", err)
+	}
+	if len(txs) != 1 || txs[0].Amount != -100 {
+		t.Errorf("expected only the 2025 sheet, got %+v", txs)
+	}
+	if _, err := ParseHandelsbankenXLSX(WithSheets(context.Background(), []string{"2023"}), path); err == nil {
+		t.Error("expected an error for a missing sheet")
+	}
+	if _, err := ParseHandelsbankenXLSX(WithSheets(context.Background(), []string{"summary"}), path); err == nil {
+		t.Error("expected an error when no selected sheet has transactions")
+	}
+}
+
+func FuzzParseHandelsbankenXLSXBytes(f *testing.F) {
+	wb := excelize.NewFile()
+	sheet := wb.GetSheetName(0)
+	wb.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp", "Saldo"})
+	wb.SetSheetRow(sheet, "A2", &[]string{"2025-01-15", "2025-01-14", "Netflix", "-99,00", "1 000,50"})
+	buf, err := wb.WriteToBuffer()
+	if err != nil {
+		f.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	txs, err := ParseHandelsbankenXLSXBytes(context.Background(), valid)
+	if err != nil || len(txs) != 1 {
+		f.Fatalf("expected 1 transaction from the seed workbook, got %+v, %v", txs, err)
+	}
+
+	f.Add(valid)
+	for _, n := range []int{0, 4, 30, len(valid) / 2, len(valid) - 1} {
+		f.Add(valid[:n]) // truncated workbooks
+	}
+	f.Add([]byte("PK\x03\x04[Content_Types].xml"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Malformed workbooks must return errors, not panic
+		ParseHandelsbankenXLSXBytes(context.Background(), data)
+	})
+}
+
+func TestParseHandelsbankenXLSX_SkippedRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	f.SetSheetRow(sheet, "A1", &[]string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"})
+	f.SetSheetRow(sheet, "A2", &[]string{"2025-01-15", "", "Netflix", "-99,00"})
+	f.SetSheetRow(sheet, "A3", &[]string{"someday", "", "Spotify", "-119,00"})
+	f.SetSheetRow(sheet, "A4", &[]string{"2025-01-20", "", "Gym", "ninety"})
+	f.SetSheetRow(sheet, "A5", &[]string{"", "", "", ""}) // blank rows are skipped silently
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := NewParseWarnings(false)
+	txs, err := ParseHandelsbankenXLSX(WithParseWarnings(context.Background(), warnings), path)
+	if err != nil || len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got %+v, %v", txs, err)
+	}
+	want := []ParseWarning{
+		{File: path, Line: 3, Reason: `sheet "Sheet1": invalid date "someday"`},
+		{File: path, Line: 4, Reason: `sheet "Sheet1": invalid amount "ninety"`},
+	}
+	if got := warnings.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected warnings %+v, got %+v", want, got)
+	}
+
+	_, err = ParseFile(WithParseWarnings(context.Background(), NewParseWarnings(true)), "handelsbanken-xlsx", path)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != path || parseErr.Line != 3 {
+		t.Errorf("expected a ParseError for line 3 in strict mode, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package internal
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
@@ -26,36 +31,264 @@ type SimpleJSONTransaction struct {
 	Date   string  `json:"date"`   // YYYY-MM-DD format
 	Text   string  `json:"text"`   // Payee/description
 	Amount float64 `json:"amount"` // Negative for expenses
+
+	// Optional fields (see Transaction)
+	ID           string   `json:"id,omitempty"`
+	Account      string   `json:"account,omitempty"`
+	Currency     string   `json:"currency,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Balance      *float64 `json:"balance,omitempty"`
+	RawText      string   `json:"raw_text,omitempty"`
+	StatementDay int      `json:"statement_day,omitempty"` // credit card statement cut-off day
+}
+
+// transaction converts to a Transaction with the given date
+func (tx SimpleJSONTransaction) transaction(date time.Time) Transaction {
+	return Transaction{
+		Date:         date,
+		Text:         tx.Text,
+		Amount:       tx.Amount,
+		ID:           tx.ID,
+		Account:      tx.Account,
+		Currency:     tx.Currency,
+		Category:     tx.Category,
+		Balance:      tx.Balance,
+		RawText:      tx.RawText,
+		StatementDay: tx.StatementDay,
+	}
 }
 
-// ParseSimpleJSON parses a JSON file in the simple JSON format
-func ParseSimpleJSON(path string) ([]Transaction, error) {
-	data, err := os.ReadFile(path)
+// newSimpleJSONTransaction converts a Transaction to the simple JSON format
+func newSimpleJSONTransaction(tx Transaction) SimpleJSONTransaction {
+	return SimpleJSONTransaction{
+		Date:         tx.Date.Format("2006-01-02"),
+		Text:         tx.Text,
+		Amount:       tx.Amount,
+		ID:           tx.ID,
+		Account:      tx.Account,
+		Currency:     tx.Currency,
+		Category:     tx.Category,
+		Balance:      tx.Balance,
+		RawText:      tx.RawText,
+		StatementDay: tx.StatementDay,
+	}
+}
+
+// ParseSimpleJSON parses a JSON file in the simple JSON format. Files larger than MaxParseSize
+// are rejected; stream them with StreamSimpleJSON instead.
+func ParseSimpleJSON(ctx context.Context, path string) ([]Transaction, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
+	defer f.Close()
+	data, err := readLimited(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return parseSimpleJSON(ctx, path, data)
+}
+
+// ParseSimpleJSONReader parses simple JSON data from r, of at most MaxParseSize bytes
+func ParseSimpleJSONReader(ctx context.Context, r io.Reader) ([]Transaction, error) {
+	data, err := readLimited(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return parseSimpleJSON(ctx, "input", data)
+}
 
+// ParseSimpleJSONBytes parses simple JSON data that isn't read from a file (e.g. in the browser
+// or in fuzz tests)
+func ParseSimpleJSONBytes(ctx context.Context, data []byte) ([]Transaction, error) {
+	return parseSimpleJSON(ctx, "input", data)
+}
+
+// parseSimpleJSON parses simple JSON data, naming path in errors
+func parseSimpleJSON(ctx context.Context, path string, data []byte) (_ []Transaction, err error) {
+	defer recoverParse(path, &err)
+	data = DecodeText(data)
 	var jsonData SimpleJSONFormat
 	if err := json.Unmarshal(data, &jsonData); err != nil {
-		return nil, fmt.Errorf("parsing JSON: %w", err)
+		return nil, &ParseError{File: path, Line: jsonErrorLine(data, err), Err: fmt.Errorf("parsing JSON: %w", err)}
 	}
 
 	var transactions []Transaction
-	for _, tx := range jsonData.Transactions {
-		date, err := time.Parse("2006-01-02", tx.Date)
+	for i, tx := range jsonData.Transactions {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ReportProgress(ctx, path, i, float64(i)/float64(len(jsonData.Transactions)))
+		}
+		date, err := ParseDate(ctx, tx.Date)
 		if err != nil {
-			return nil, fmt.Errorf("parsing date %q: %w", tx.Date, err)
+			return nil, &ParseError{File: path, Line: transactionLine(data, i), Err: fmt.Errorf("parsing date %q: %w", tx.Date, err)}
 		}
-		transactions = append(transactions, Transaction{
-			Date:   date,
-			Text:   tx.Text,
-			Amount: tx.Amount,
-		})
+		transactions = append(transactions, tx.transaction(date))
 	}
 
 	return transactions, nil
 }
 
+// StreamSimpleJSON calls fn for each transaction in a simple JSON file, decoding one transaction
+// at a time instead of reading the whole file. Errors don't include line numbers.
+func StreamSimpleJSON(ctx context.Context, path string, fn func(Transaction) error) (err error) {
+	defer recoverParse(path, &err)
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	f, err := OpenText(path)
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return &ParseError{File: path, Err: errors.New("parsing JSON: expected an object")}
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return &ParseError{File: path, Err: fmt.Errorf("parsing JSON: %w", err)}
+		}
+		if key != "transactions" {
+			if err := skipJSONValue(dec); err != nil {
+				return &ParseError{File: path, Err: fmt.Errorf("parsing JSON: %w", err)}
+			}
+			continue
+		}
+		if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+			return &ParseError{File: path, Err: errors.New(`parsing JSON: "transactions" is not an array`)}
+		}
+		for i := 0; dec.More(); i++ {
+			if i%1000 == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if size > 0 {
+					ReportProgress(ctx, path, i, float64(dec.InputOffset())/float64(size))
+				}
+			}
+			var tx SimpleJSONTransaction
+			if err := dec.Decode(&tx); err != nil {
+				return &ParseError{File: path, Err: fmt.Errorf("parsing JSON: transaction %d: %w", i+1, err)}
+			}
+			date, err := ParseDate(ctx, tx.Date)
+			if err != nil {
+				return &ParseError{File: path, Err: fmt.Errorf("transaction %d: parsing date %q: %w", i+1, tx.Date, err)}
+			}
+			if err := fn(tx.transaction(date)); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return &ParseError{File: path, Err: fmt.Errorf("parsing JSON: %w", err)}
+		}
+	}
+	return nil
+}
+
+// skipJSONValue skips the next value in dec token by token, so that skipping a huge value doesn't
+// need memory for all of it
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// simpleJSONParser parses simple JSON files, in full or streaming
+type simpleJSONParser struct{}
+
+func (simpleJSONParser) Parse(ctx context.Context, path string) ([]Transaction, error) {
+	return ParseSimpleJSON(ctx, path)
+}
+
+func (simpleJSONParser) ParseStream(ctx context.Context, path string, fn func(Transaction) error) error {
+	return StreamSimpleJSON(ctx, path, fn)
+}
+
+// jsonErrorLine returns the line of a JSON decoding error, or 0 if the error has no offset
+func jsonErrorLine(data []byte, err error) int {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return lineAt(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return lineAt(data, typeErr.Offset)
+	}
+	return 0
+}
+
+// transactionLine returns the line where element index of the "transactions" array starts, or 0
+func transactionLine(data []byte, index int) int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		if key != "transactions" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return 0
+			}
+			continue
+		}
+		if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+			return 0
+		}
+		for i := 0; dec.More(); i++ {
+			var elem json.RawMessage
+			if err := dec.Decode(&elem); err != nil {
+				return 0
+			}
+			if i == index {
+				return lineAt(data, dec.InputOffset()-int64(len(elem)))
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// lineAt returns the 1-based line of a byte offset in data
+func lineAt(data []byte, offset int64) int {
+	offset = min(max(offset, 0), int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// sniffSimpleJSON reports whether head starts a JSON object with a "transactions" key
+func sniffSimpleJSON(head []byte) bool {
+	head = bytes.TrimLeft(DecodeText(head), " \t\r\n")
+	return bytes.HasPrefix(head, []byte("{")) && bytes.Contains(head, []byte(`"transactions"`))
+}
+
 func init() {
-	RegisterParser("simple-json", ParserFunc(ParseSimpleJSON))
+	RegisterParserWithInfo(ParserInfo{
+		Name:        "simple-json",
+		Description: "Minimal JSON format ({\"transactions\": [{date, text, amount}]}) to convert any export to",
+		Extensions:  []string{".json"},
+		Example:     "subscription-detector --source simple-json transactions.json",
+		Sniff:       sniffSimpleJSON,
+	}, simpleJSONParser{})
 }
@@ -1,10 +1,20 @@
 package internal
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestIsKnownParser(t *testing.T) {
 	// Register a test parser
-	RegisterParser("test-format", ParserFunc(func(path string) ([]Transaction, error) {
+	RegisterParser("test-format", ParserFunc(func(_ context.Context, path string) ([]Transaction, error) {
 		return nil, nil
 	}))
 
@@ -31,7 +41,7 @@ func TestIsKnownParser(t *testing.T) {
 
 func TestParseFileArg(t *testing.T) {
 	// Register a test parser for these tests
-	RegisterParser("test-format", ParserFunc(func(path string) ([]Transaction, error) {
+	RegisterParser("test-format", ParserFunc(func(_ context.Context, path string) ([]Transaction, error) {
 		return nil, nil
 	}))
 
@@ -103,3 +113,211 @@ func TestParseFileArg(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatForFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"export.xlsx", "handelsbanken-xlsx"},
+		{"/dir/Export.XLSX", "handelsbanken-xlsx"},
+		{"data.json", "simple-json"},
+		{"notes.txt", ""},
+		{"noextension", ""},
+	}
+	for _, tt := range tests {
+		if got := FormatForFile(tt.path); got != tt.expected {
+			t.Errorf("FormatForFile(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestSourceInfos(t *testing.T) {
+	infos := SourceInfos()
+	for i := 1; i < len(infos); i++ {
+		if infos[i-1].Name >= infos[i].Name {
+			t.Errorf("expected sources sorted by name, got %s before %s", infos[i-1].Name, infos[i].Name)
+		}
+	}
+	for _, info := range infos {
+		if info.Name != "handelsbanken-xlsx" && info.Name != "simple-json" {
+			continue // registered by other tests without metadata
+		}
+		if info.Description == "" || len(info.Extensions) == 0 || info.Example == "" || info.Sniff == nil {
+			t.Errorf("expected built-in parser %s to have metadata, got %+v", info.Name, info)
+		}
+		if FormatForFile("file"+info.Extensions[0]) != info.Name {
+			t.Errorf("expected extension %s to default to %s", info.Extensions[0], info.Name)
+		}
+	}
+}
+
+func TestParseFile_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParseFile(ctx, "simple-json", "../testdata/sample.json")
+	var parseErr *ParseError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &parseErr) {
+		t.Errorf("expected context.Canceled (not a ParseError), got %v", err)
+	}
+	if _, err := ParseFile(context.Background(), "simple-json", "../testdata/sample.json"); err != nil {
+		t.Errorf("expected no error with a live context, got %v", err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		return path
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"../testdata/sample.json", "simple-json"},
+		{write("export.txt", "\ufeff  {\n  \"transactions\": []\n}"), "simple-json"}, // sniffed despite the extension
+		{write("list.json", "[1, 2, 3]"), "simple-json"},                             // falls back to the extension
+		{write("notes.txt", "hello"), ""},
+		{write("download", "PK\x03\x04\x14\x00[Content_Types].xml"), "handelsbanken-xlsx"},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.path)
+		if err != nil || got != tt.expected {
+			t.Errorf("DetectFormat(%q) = %q, %v, want %q", tt.path, got, err, tt.expected)
+		}
+	}
+	if _, err := DetectFormat(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("concurrent-%d", i)
+			RegisterParserWithInfo(ParserInfo{Name: name, Extensions: []string{".concurrent"}}, ParserFunc(func(context.Context, string) ([]Transaction, error) {
+				return nil, nil
+			}))
+			if !IsKnownParser(name) || len(SourceInfos()) == 0 {
+				t.Errorf("expected %s to be registered", name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Several parsers claim .concurrent, so the extension alone is ambiguous
+	if got := FormatForFile("x.concurrent"); got != "" {
+		t.Errorf("expected an ambiguous extension to give no format, got %q", got)
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	// The parser waits until all three files are being parsed at once, and the first file
+	// finishes last
+	var mu sync.Mutex
+	started := 0
+	allStarted := make(chan struct{})
+	RegisterParser("parallel-test", ParserFunc(func(ctx context.Context, path string) ([]Transaction, error) {
+		mu.Lock()
+		if started++; started == 3 {
+			close(allStarted)
+		}
+		mu.Unlock()
+		select {
+		case <-allStarted:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("files were not parsed concurrently")
+		}
+		if path == "a" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if path == "bad" {
+			return nil, errors.New("broken")
+		}
+		return []Transaction{{Text: path}}, nil
+	}))
+
+	files := []SourceFile{{Source: "parallel-test", Path: "a"}, {Source: "parallel-test", Path: "b"}, {Source: "parallel-test", Path: "c"}}
+	results, err := ParseFiles(context.Background(), files, 3)
+	if err != nil {
+		t.Fatalf("ParseFiles: %v", err)
+	}
+	for i, txs := range results {
+		if len(txs) != 1 || txs[0].Text != files[i].Path {
+			t.Errorf("expected the transactions of %s at index %d, got %+v", files[i].Path, i, txs)
+		}
+	}
+
+	started, allStarted = 0, make(chan struct{})
+	files = []SourceFile{{Source: "parallel-test", Path: "a"}, {Source: "parallel-test", Path: "bad"}, {Source: "parallel-test", Path: "c"}}
+	_, err = ParseFiles(context.Background(), files, 3)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != "bad" {
+		t.Errorf("expected a ParseError for bad, got %v", err)
+	}
+}
+
+func TestParseFiles_InvertAmounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "card.json")
+	os.WriteFile(path, []byte(`{"transactions": [
+		{"date": "2025-01-15", "text": "Netflix", "amount": 99, "balance": 500},
+		{"date": "2025-01-20", "text": "Refund", "amount": -20}
+	]}`), 0644)
+
+	results, err := ParseFiles(context.Background(), []SourceFile{
+		{Source: "simple-json", Path: path, InvertAmounts: true},
+		{Source: "simple-json", Path: path},
+	}, 0)
+	if err != nil {
+		t.Fatalf("ParseFiles: %v", err)
+	}
+	inverted, asExported := results[0], results[1]
+	if inverted[0].Amount != -99 || inverted[1].Amount != 20 || *inverted[0].Balance != 500 {
+		t.Errorf("expected negated amounts and the balance as exported, got %+v", inverted)
+	}
+	if asExported[0].Amount != 99 || asExported[1].Amount != -20 {
+		t.Errorf("expected amounts as exported without InvertAmounts, got %+v", asExported)
+	}
+}
+
+func TestParseFile_Panic(t *testing.T) {
+	RegisterParser("test-panic", ParserFunc(func(_ context.Context, path string) ([]Transaction, error) {
+		var rows []string
+		return []Transaction{{Text: rows[1]}}, nil // a parser bug on malformed input
+	}))
+	path := filepath.Join(t.TempDir(), "export.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ParseFile(context.Background(), "test-panic", path)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != path {
+		t.Fatalf("expected a ParseError for %s, got %v", path, err)
+	}
+}
+
+func TestParseSimpleJSONReader(t *testing.T) {
+	txs, err := ParseSimpleJSONReader(context.Background(), strings.NewReader(`{"transactions": [{"date": "2025-01-15", "text": "Netflix", "amount": -99}]}`))
+	if err != nil || len(txs) != 1 || txs[0].Text != "Netflix" {
+		t.Fatalf("unexpected result: %+v, %v", txs, err)
+	}
+}
+
+func FuzzParseSimpleJSONBytes(f *testing.F) {
+	f.Add([]byte(`{"transactions": [{"date": "2025-01-15", "text": "Netflix", "amount": -99.00}]}`))
+	f.Add([]byte(`{"transactions": [{"date": "2025-01-31T23:30:00+01:00", "text": "Spotify", "amount": -119, "balance": 10, "statement_day": 25}]}`))
+	f.Add([]byte(`{"transactions": [{"date": "2025-01-15", "text"`))
+	f.Add([]byte("\xff\xfe{\x00}\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Malformed input must return errors, not panic
+		ParseSimpleJSONBytes(context.Background(), data)
+	})
+}
@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification is a summary of subscription changes sent to a notifier
+type Notification struct {
+	Message  string               // human-readable summary (see FormatChangeSummary)
+	Since    time.Time            // time of the snapshot the changes are relative to
+	Changes  []SubscriptionChange // the changes the notifier is interested in
+	Currency Currency
+}
+
+// Notifier sends notifications for a notifier type that isn't built in. It gets the config of
+// the notifier entry it sends for, so one Notifier can serve several entries of its type.
+type Notifier interface {
+	Notify(ctx context.Context, cfg NotifierConfig, n Notification) error
+}
+
+// NotifierFunc is a function that implements Notifier
+type NotifierFunc func(ctx context.Context, cfg NotifierConfig, n Notification) error
+
+func (f NotifierFunc) Notify(ctx context.Context, cfg NotifierConfig, n Notification) error {
+	return f(ctx, cfg, n)
+}
+
+// Renderer writes detected subscriptions in an output format that isn't built in (--output NAME)
+type Renderer interface {
+	Render(ctx context.Context, w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) error
+}
+
+// RendererFunc is a function that implements Renderer
+type RendererFunc func(ctx context.Context, w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) error
+
+func (f RendererFunc) Render(ctx context.Context, w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) error {
+	return f(ctx, w, subs, cfg, opts)
+}
+
+// builtinNotifiers are the notifier types handled by NotifierConfig itself
+var builtinNotifiers = []string{"slack", "discord", "ntfy", "pushover", "telegram"}
+
+// BuiltinOutputs are the output formats of the main command that aren't renderers
+var BuiltinOutputs = []string{"table", "json", "yaml", "gsheet"}
+
+// plugins holds the registered notifiers and renderers. Like parsers, they may be registered
+// from init functions or at runtime, so access is guarded by a lock.
+var plugins = struct {
+	sync.RWMutex
+	notifiers map[string]Notifier
+	renderers map[string]Renderer
+}{notifiers: map[string]Notifier{}, renderers: map[string]Renderer{}}
+
+// RegisterNotifier registers a notifier for the notifier type name. Registering a name again
+// replaces the previous notifier; built-in types can't be replaced.
+func RegisterNotifier(name string, n Notifier) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	plugins.notifiers[name] = n
+}
+
+// GetNotifier returns the registered notifier for a notifier type
+func GetNotifier(name string) (Notifier, bool) {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	n, ok := plugins.notifiers[name]
+	return n, ok
+}
+
+// RegisterRenderer registers a renderer for the output format name. Registering a name again
+// replaces the previous renderer; built-in formats can't be replaced.
+func RegisterRenderer(name string, r Renderer) {
+	plugins.Lock()
+	defer plugins.Unlock()
+	plugins.renderers[name] = r
+}
+
+// GetRenderer returns the registered renderer for an output format
+func GetRenderer(name string) (Renderer, bool) {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	r, ok := plugins.renderers[name]
+	return r, ok
+}
+
+// RendererNames returns the sorted names of the registered renderers
+func RendererNames() []string {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	var names []string
+	for name := range plugins.renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Plugin types
+const (
+	PluginNotifier = "notifier"
+	PluginRenderer = "renderer"
+)
+
+// PluginProtocolVersion is the version of the JSON messages sent to external plugins
+const PluginProtocolVersion = 1
+
+// pluginTimeout limits how long an external plugin may run
+const pluginTimeout = time.Minute
+
+// Plugin is an external program that acts as a notifier type or output format. It gets a JSON
+// request on stdin (see PluginRequest); renderers write their output to stdout. A non-zero exit
+// status fails the plugin, with its stderr as the error.
+type Plugin struct {
+	Name    string   `yaml:"name"`    // notifier type or output format name
+	Type    string   `yaml:"type"`    // "notifier" or "renderer"
+	Command []string `yaml:"command"` // program and arguments; environment variables (${VAR}) are expanded
+}
+
+func (p Plugin) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch p.Type {
+	case PluginNotifier:
+		if slices.Contains(builtinNotifiers, p.Name) {
+			return fmt.Errorf("%q is a built-in notifier type", p.Name)
+		}
+	case PluginRenderer:
+		if slices.Contains(BuiltinOutputs, p.Name) {
+			return fmt.Errorf("%q is a built-in output format", p.Name)
+		}
+	default:
+		return fmt.Errorf("unknown type %q (expected notifier or renderer)", p.Type)
+	}
+	if len(p.Command) == 0 {
+		return fmt.Errorf("command is required")
+	}
+	return nil
+}
+
+// PluginRequest is the JSON request an external plugin gets on stdin
+type PluginRequest struct {
+	ProtocolVersion int                 `json:"protocol_version"`
+	Type            string              `json:"type"`                   // "notify" or "render"
+	Notification    *PluginNotification `json:"notification,omitempty"` // for notifiers
+	Config          *NotifierConfig     `json:"config,omitempty"`       // the notifier entry, for notifiers
+	Output          *JSONOutput         `json:"output,omitempty"`       // the JSON output, for renderers
+}
+
+// PluginNotification is the JSON format of a Notification
+type PluginNotification struct {
+	Message  string       `json:"message"`
+	Since    string       `json:"since"`
+	Changes  []JSONChange `json:"changes"`
+	Currency string       `json:"currency"`
+}
+
+// RegisterPlugins registers the external plugins of cfg as notifiers and renderers
+func (cfg *Config) RegisterPlugins() {
+	if cfg == nil {
+		return
+	}
+	for _, p := range cfg.Plugins {
+		switch p.Type {
+		case PluginNotifier:
+			RegisterNotifier(p.Name, execPlugin(p))
+		case PluginRenderer:
+			RegisterRenderer(p.Name, execPlugin(p))
+		}
+	}
+}
+
+// hasPlugin reports whether cfg defines an external plugin with the given name and type
+func (cfg *Config) hasPlugin(name, typ string) bool {
+	return slices.ContainsFunc(cfg.Plugins, func(p Plugin) bool { return p.Name == name && p.Type == typ })
+}
+
+// execPlugin runs an external plugin
+type execPlugin Plugin
+
+func (p execPlugin) Notify(ctx context.Context, cfg NotifierConfig, n Notification) error {
+	notification := &PluginNotification{
+		Message:  n.Message,
+		Since:    n.Since.Format(time.RFC3339),
+		Changes:  []JSONChange{},
+		Currency: n.Currency.Code,
+	}
+	for _, c := range n.Changes {
+		notification.Changes = append(notification.Changes, JSONChange{
+			Name:      c.Name,
+			Kind:      string(c.Kind),
+			OldAmount: n.Currency.Round(c.OldAmount),
+			NewAmount: n.Currency.Round(c.NewAmount),
+		})
+	}
+	options := make(map[string]string, len(cfg.Options))
+	for k, v := range cfg.Options {
+		options[k] = os.ExpandEnv(v)
+	}
+	cfg.Options = options
+	return p.run(ctx, io.Discard, PluginRequest{Type: "notify", Notification: notification, Config: &cfg})
+}
+
+func (p execPlugin) Render(ctx context.Context, w io.Writer, subs []Subscription, cfg *Config, opts OutputOptions) error {
+	output := BuildJSONOutput(subs, cfg, opts)
+	return p.run(ctx, w, PluginRequest{Type: "render", Output: &output})
+}
+
+// run runs the plugin with req on stdin, copying its stdout to w if it succeeds
+func (p execPlugin) run(ctx context.Context, w io.Writer, req PluginRequest) error {
+	req.ProtocolVersion = PluginProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+	args := make([]string, len(p.Command))
+	for i, arg := range p.Command {
+		args[i] = os.ExpandEnv(arg)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("plugin %s: %w: %s", p.Name, err, msg)
+		}
+		return fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	_, err = w.Write(stdout.Bytes())
+	return err
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as an external plugin: with TEST_PLUGIN set, TestPluginProcess
+// echoes the request it gets back as its output, and to the file TEST_PLUGIN_OUT if set (or
+// fails, for TEST_PLUGIN=fail)
+func TestPluginProcess(t *testing.T) {
+	mode := os.Getenv("TEST_PLUGIN")
+	if mode == "" {
+		return
+	}
+	if mode == "fail" {
+		fmt.Fprintln(os.Stderr, "out of cheese")
+		os.Exit(3)
+	}
+	var req PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(1)
+	}
+	data, _ := json.Marshal(req)
+	os.Stdout.Write(data)
+	if path := os.Getenv("TEST_PLUGIN_OUT"); path != "" {
+		os.WriteFile(path, data, 0644)
+	}
+	os.Exit(0)
+}
+
+// testPlugin returns a plugin that runs TestPluginProcess in the given mode
+func testPlugin(t *testing.T, name, typ, mode string) Plugin {
+	t.Setenv("TEST_PLUGIN", mode)
+	return Plugin{Name: name, Type: typ, Command: []string{os.Args[0], "-test.run=^TestPluginProcess$"}}
+}
+
+func TestExecPlugin_Render(t *testing.T) {
+	plugin := execPlugin(testPlugin(t, "echo", PluginRenderer, "echo"))
+	subs := []Subscription{{Name: "Netflix", Status: StatusActive, LatestAmount: -99, StartDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)}}
+
+	var out bytes.Buffer
+	if err := plugin.Render(context.Background(), &out, subs, nil, OutputOptions{Currency: GetCurrency("SEK")}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var req PluginRequest
+	if err := json.Unmarshal(out.Bytes(), &req); err != nil {
+		t.Fatalf("expected the echoed request, got %q: %v", out.String(), err)
+	}
+	if req.ProtocolVersion != PluginProtocolVersion || req.Type != "render" || req.Output == nil || len(req.Output.Subscriptions) != 1 || req.Output.Subscriptions[0].Name != "Netflix" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+}
+
+func TestExecPlugin_Notify(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "request.json")
+	t.Setenv("TEST_PLUGIN_OUT", out)
+	t.Setenv("TEST_PLUGIN_ROOM", "home")
+	plugin := execPlugin(testPlugin(t, "echo", PluginNotifier, "echo"))
+	notification := Notification{
+		Message:  "Subscription changes since 2025-11-01:\n• Subscription stopped: HBO Max",
+		Since:    time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		Changes:  []SubscriptionChange{{Name: "HBO Max", Kind: ChangeStopped}},
+		Currency: GetCurrency("SEK"),
+	}
+	cfg := NotifierConfig{Type: "echo", Options: map[string]string{"room": "${TEST_PLUGIN_ROOM}"}}
+	if err := plugin.Notify(context.Background(), cfg, notification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req PluginRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	n := req.Notification
+	if req.Type != "notify" || n == nil || n.Message != notification.Message || n.Since != "2025-11-01T00:00:00Z" || n.Currency != "SEK" {
+		t.Fatalf("unexpected request: %s", data)
+	}
+	if len(n.Changes) != 1 || n.Changes[0].Kind != "stopped" {
+		t.Errorf("expected the stopped change, got %+v", n.Changes)
+	}
+	if req.Config == nil || req.Config.Options["room"] != "home" {
+		t.Errorf("expected the notifier config with expanded options, got %+v", req.Config)
+	}
+}
+
+func TestExecPlugin_Failure(t *testing.T) {
+	plugin := execPlugin(testPlugin(t, "broken", PluginRenderer, "fail"))
+	var out bytes.Buffer
+	err := plugin.Render(context.Background(), &out, nil, nil, OutputOptions{Currency: GetCurrency("SEK")})
+	if err == nil || !strings.Contains(err.Error(), "plugin broken") || !strings.Contains(err.Error(), "out of cheese") {
+		t.Errorf("expected the plugin's stderr in the error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output from a failed plugin, got %q", out.String())
+	}
+}
+
+func TestSendNotifications_Plugin(t *testing.T) {
+	var got []Notification
+	RegisterNotifier("test-notifier", NotifierFunc(func(_ context.Context, cfg NotifierConfig, n Notification) error {
+		if cfg.Options["room"] != "home" {
+			return fmt.Errorf("unexpected config %+v", cfg)
+		}
+		got = append(got, n)
+		return nil
+	}))
+	report := &ChangeReport{
+		Since: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		Changes: []SubscriptionChange{
+			{Name: "Disney+", Kind: ChangeNew, NewAmount: 119},
+			{Name: "Spotify", Kind: ChangePriceIncrease, OldAmount: 119, NewAmount: 129},
+		},
+	}
+	notifiers := []NotifierConfig{{Type: "test-notifier", Kinds: []string{"price-increase"}, Options: map[string]string{"room": "home"}}}
+	if err := SendNotifications(context.Background(), nil, notifiers, report, GetCurrency("SEK")); err != nil {
+		t.Fatalf("SendNotifications: %v", err)
+	}
+	if len(got) != 1 || len(got[0].Changes) != 1 || got[0].Changes[0].Name != "Spotify" || !strings.Contains(got[0].Message, "Price increase: Spotify") {
+		t.Errorf("expected the price increase, got %+v", got)
+	}
+}
+
+func TestConfig_Plugins(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+plugins:
+  - name: matrix
+    type: notifier
+    command: [notify-matrix, --room, "${ROOM}"]
+  - name: html
+    type: renderer
+    command: [render-html]
+notify:
+  - type: matrix
+    options:
+      room: home
+`))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	cfg.RegisterPlugins()
+	if _, ok := GetRenderer("html"); !ok {
+		t.Error("expected the html renderer to be registered")
+	}
+	if _, ok := GetNotifier("matrix"); !ok {
+		t.Error("expected the matrix notifier to be registered")
+	}
+
+	invalid := map[string]string{
+		"unknown type":      "plugins:\n  - {name: x, type: parser, command: [x]}\n",
+		"missing command":   "plugins:\n  - {name: x, type: renderer}\n",
+		"built-in output":   "plugins:\n  - {name: json, type: renderer, command: [x]}\n",
+		"built-in notifier": "plugins:\n  - {name: slack, type: notifier, command: [x]}\n",
+		"duplicate":         "plugins:\n  - {name: x, type: renderer, command: [x]}\n  - {name: x, type: renderer, command: [y]}\n",
+		"unknown notifier":  "notify:\n  - type: carrier-pigeon\n",
+	}
+	for name, content := range invalid {
+		if _, err := parseConfig([]byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed data/prices.json
+var bundledPrices []byte
+
+// PriceMargin is how much more than the standard plan's list price a subscription must cost to be
+// reported by ComparePrices (0.1 = 10%), so that rounding and small local differences aren't
+const PriceMargin = 0.1
+
+// PriceDatabase lists the current list prices of popular services per country. A database is
+// bundled (see LoadPriceDatabase); a newer one in the same JSON format can be given in the config.
+type PriceDatabase struct {
+	Updated  string          `json:"updated"` // date the prices were checked (YYYY-MM-DD)
+	Services []ServicePrices `json:"services"`
+}
+
+// ServicePrices are the plans of a service
+type ServicePrices struct {
+	Name      string                   `json:"name"`
+	Pattern   string                   `json:"pattern"`   // regex matched against subscription names (case-insensitive)
+	Countries map[string]CountryPrices `json:"countries"` // by country code (ISO 3166, e.g. SE)
+
+	regex *regexp.Regexp
+}
+
+// CountryPrices are the plans of a service in a country
+type CountryPrices struct {
+	Currency string      `json:"currency"`
+	Plans    []PricePlan `json:"plans"`
+}
+
+// PricePlan is a plan of a service with its monthly list price
+type PricePlan struct {
+	Name     string  `json:"name"`
+	Monthly  float64 `json:"monthly"`
+	Standard bool    `json:"standard,omitempty"` // the plan that subscriptions are compared against
+}
+
+// LoadPriceDatabase reads a price database from a JSON file, or returns the bundled one if path is
+// empty
+func LoadPriceDatabase(path string) (*PriceDatabase, error) {
+	data := bundledPrices
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("reading price database: %w", err)
+		}
+	}
+	return parsePriceDatabase(data)
+}
+
+// parsePriceDatabase parses, validates and compiles a price database
+func parsePriceDatabase(data []byte) (*PriceDatabase, error) {
+	var db PriceDatabase
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("parsing price database: %w", err)
+	}
+	for i := range db.Services {
+		s := &db.Services[i]
+		re, err := regexp.Compile("(?i)" + s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q of %s in price database: %w", s.Pattern, s.Name, err)
+		}
+		s.regex = re
+		for country, prices := range s.Countries {
+			standard := 0
+			for _, plan := range prices.Plans {
+				if plan.Monthly <= 0 {
+					return nil, fmt.Errorf("price of %s %s in %s must be positive", s.Name, plan.Name, country)
+				}
+				if plan.Standard {
+					standard++
+				}
+			}
+			if standard != 1 {
+				return nil, fmt.Errorf("%s in %s must have exactly one standard plan", s.Name, country)
+			}
+		}
+	}
+	return &db, nil
+}
+
+// Lookup returns the service a subscription name matches and its prices in a country, or nil if
+// the database has no prices for it there
+func (db *PriceDatabase) Lookup(name, country string) (*ServicePrices, *CountryPrices) {
+	for i := range db.Services {
+		s := &db.Services[i]
+		if !s.regex.MatchString(name) {
+			continue
+		}
+		if prices, ok := s.Countries[strings.ToUpper(country)]; ok {
+			return s, &prices
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+// StandardPlan returns the standard plan of the prices
+func (p CountryPrices) StandardPlan() PricePlan {
+	for _, plan := range p.Plans {
+		if plan.Standard {
+			return plan
+		}
+	}
+	return PricePlan{}
+}
+
+// planTolerance is how close an amount must be to a list price to be that plan (0.01 = 1%), for
+// rounding and small local price differences
+const planTolerance = 0.01
+
+// PlanTransition returns the plans of a subscription's service in a country whose list prices two
+// amounts are, if they are different plans, e.g. "Individual" and "Family" for a Spotify payment
+// going from 129 to 209. Otherwise, such as for a price increase of the same plan, both are "".
+func (db *PriceDatabase) PlanTransition(name, country, currency string, oldAmount, newAmount float64) (oldPlan, newPlan string) {
+	if db == nil {
+		return "", ""
+	}
+	_, prices := db.Lookup(name, country)
+	if prices == nil || !strings.EqualFold(prices.Currency, currency) {
+		return "", ""
+	}
+	planAt := func(amount float64) string {
+		for _, plan := range prices.Plans {
+			if math.Abs(math.Abs(amount)-plan.Monthly) <= plan.Monthly*planTolerance {
+				return plan.Name
+			}
+		}
+		return ""
+	}
+	oldPlan, newPlan = planAt(oldAmount), planAt(newAmount)
+	if oldPlan == "" || newPlan == "" || oldPlan == newPlan {
+		return "", ""
+	}
+	return oldPlan, newPlan
+}
+
+// AnnotatePlanChanges sets the plans of the price changes among changes that are likely plan
+// changes rather than price changes (see PlanTransition)
+func AnnotatePlanChanges(changes []SubscriptionChange, db *PriceDatabase, country, currency string) {
+	for i := range changes {
+		c := &changes[i]
+		if c.Kind == ChangePriceIncrease || c.Kind == ChangePriceDecrease {
+			c.OldPlan, c.NewPlan = db.PlanTransition(c.Name, country, currency, c.OldAmount, c.NewAmount)
+		}
+	}
+}
+
+// AnnotateEventPlans sets the plans of the price_changed events that are likely plan changes
+// rather than price changes (see PlanTransition)
+func AnnotateEventPlans(events []Event, db *PriceDatabase, country, currency string) {
+	for i := range events {
+		e := &events[i]
+		if e.Kind == EventPriceChanged {
+			e.OldPlan, e.NewPlan = db.PlanTransition(e.Subscription, country, currency, e.OldAmount, e.NewAmount)
+		}
+	}
+}
+
+// countriesByCurrency are the countries of currencies used in a single country with prices in the
+// database, for when no country is given
+var countriesByCurrency = map[string]string{
+	"SEK": "SE", "NOK": "NO", "DKK": "DK", "USD": "US", "GBP": "GB", "CHF": "CH",
+	"CAD": "CA", "AUD": "AU", "NZD": "NZ", "JPY": "JP", "PLN": "PL",
+}
+
+// CountryForCurrency returns the country a currency is used in, or "" if it isn't a single one
+// (e.g., EUR)
+func CountryForCurrency(currency string) string {
+	return countriesByCurrency[strings.ToUpper(currency)]
+}
+
+// PriceComparison is a subscription that costs noticeably more than the list price of its
+// service's standard plan
+type PriceComparison struct {
+	Name      string  // subscription name
+	Service   string  // service in the price database
+	Plan      string  // name of the standard plan
+	ListPrice float64 // monthly list price of the standard plan
+	Paid      float64 // monthly cost of the subscription (the full cost, for shared ones)
+}
+
+// ComparePrices returns the active subscriptions that cost more than PriceMargin above the list
+// price of their service's standard plan in a country, most overpaid first. Prices in another
+// currency than the subscriptions' aren't compared. Shared subscriptions are compared by their
+// full cost.
+func ComparePrices(subs []Subscription, db *PriceDatabase, country, currency string) []PriceComparison {
+	comparisons := []PriceComparison{}
+	for _, sub := range subs {
+		if sub.Status != StatusActive {
+			continue
+		}
+		service, prices := db.Lookup(sub.Name, country)
+		if prices == nil || !strings.EqualFold(prices.Currency, currency) {
+			continue
+		}
+		paid := sub.MonthlyCost()
+		if sub.Share > 0 {
+			paid /= sub.Share
+		}
+		standard := prices.StandardPlan()
+		if paid > standard.Monthly*(1+PriceMargin) {
+			comparisons = append(comparisons, PriceComparison{Name: sub.Name, Service: service.Name, Plan: standard.Name, ListPrice: standard.Monthly, Paid: paid})
+		}
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].Paid-comparisons[i].ListPrice > comparisons[j].Paid-comparisons[j].ListPrice
+	})
+	return comparisons
+}
+
+// PrintPriceComparisons lists the subscriptions that cost more than their standard plan
+func PrintPriceComparisons(w io.Writer, comparisons []PriceComparison, opts OutputOptions) {
+	loc, currency := opts.Locale, opts.Currency
+	fmt.Fprint(w, loc.T("Paying more than the standard plan:\n"))
+	if len(comparisons) == 0 {
+		fmt.Fprint(w, loc.T("  None\n"))
+	}
+	for _, c := range comparisons {
+		fmt.Fprint(w, loc.Sprintf("  %s: %s/month, %s %s is %s/month (+%s)\n", c.Name, currency.Format(c.Paid),
+			c.Service, c.Plan, currency.Format(c.ListPrice), currency.Format(c.Paid-c.ListPrice)))
+	}
+}
+
+// JSONPriceComparison is the JSON output format of a subscription that costs more than its
+// standard plan
+type JSONPriceComparison struct {
+	Name      string  `json:"name"`
+	Service   string  `json:"service"`
+	Plan      string  `json:"plan"`
+	ListPrice float64 `json:"list_price"`
+	Paid      float64 `json:"paid"`
+}
+
+func buildJSONPriceComparisons(comparisons []PriceComparison, currency Currency) []JSONPriceComparison {
+	out := []JSONPriceComparison{}
+	for _, c := range comparisons {
+		out = append(out, JSONPriceComparison{Name: c.Name, Service: c.Service, Plan: c.Plan, ListPrice: currency.Round(c.ListPrice), Paid: currency.Round(c.Paid)})
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+const testPrices = `{"updated": "2025-09-01", "services": [
+	{"name": "Netflix", "pattern": "NETFLIX", "countries": {
+		"SE": {"currency": "SEK", "plans": [
+			{"name": "Standard with ads", "monthly": 99},
+			{"name": "Standard", "monthly": 149, "standard": true},
+			{"name": "Premium", "monthly": 219}
+		]}
+	}},
+	{"name": "Spotify", "pattern": "SPOTIFY", "countries": {
+		"SE": {"currency": "SEK", "plans": [
+			{"name": "Individual", "monthly": 129, "standard": true},
+			{"name": "Family", "monthly": 209}
+		]}
+	}}
+]}`
+
+func TestComparePrices(t *testing.T) {
+	db, err := parsePriceDatabase([]byte(testPrices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs := []Subscription{
+		{Name: "NETFLIX.COM", Status: StatusActive, LatestAmount: -219},
+		{Name: "Spotify", Status: StatusActive, LatestAmount: -135},                      // within the margin
+		{Name: "Spotify Family", Status: StatusActive, LatestAmount: -104.5, Share: 0.5}, // full cost 209
+		{Name: "Netflix old", Status: StatusStopped, LatestAmount: -219},
+		{Name: "HBO Max", Status: StatusActive, LatestAmount: -149}, // not in the database
+	}
+
+	comparisons := ComparePrices(subs, db, "se", "SEK")
+	if len(comparisons) != 2 {
+		t.Fatalf("expected 2 comparisons, got %+v", comparisons)
+	}
+	if c := comparisons[0]; c.Name != "Spotify Family" || c.Plan != "Individual" || c.ListPrice != 129 || c.Paid != 209 {
+		t.Errorf("expected the most overpaid first, with the full cost of a shared subscription, got %+v", c)
+	}
+	if c := comparisons[1]; c.Name != "NETFLIX.COM" || c.Service != "Netflix" || c.Paid != 219 {
+		t.Errorf("unexpected comparison %+v", c)
+	}
+
+	if got := ComparePrices(subs, db, "SE", "USD"); len(got) != 0 {
+		t.Errorf("expected no comparisons in another currency, got %+v", got)
+	}
+	if got := ComparePrices(subs, db, "US", "SEK"); len(got) != 0 {
+		t.Errorf("expected no comparisons without prices in the country, got %+v", got)
+	}
+
+	var buf bytes.Buffer
+	PrintPriceComparisons(&buf, comparisons, OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "NETFLIX.COM: ") || !strings.Contains(buf.String(), "Netflix Standard is") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestLoadPriceDatabase(t *testing.T) {
+	db, err := LoadPriceDatabase("")
+	if err != nil {
+		t.Fatalf("bundled price database: %v", err)
+	}
+	for _, country := range []string{"SE", "US"} {
+		if _, prices := db.Lookup("NETFLIX.COM", country); prices == nil {
+			t.Errorf("expected Netflix prices for %s in the bundled database", country)
+		}
+	}
+
+	if _, err := parsePriceDatabase([]byte(`{"services": [{"name": "X", "pattern": "X", "countries": {"SE": {"currency": "SEK", "plans": [{"name": "A", "monthly": 10}]}}}]}`)); err == nil {
+		t.Error("expected an error without a standard plan")
+	}
+}
+
+func TestPlanTransition(t *testing.T) {
+	db, err := parsePriceDatabase([]byte(testPrices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name              string
+		sub               string
+		currency          string
+		oldAmount, newAmt float64
+		wantOld, wantNew  string
+	}{
+		{"upgrade", "SPOTIFY AB", "SEK", -129, -209, "Individual", "Family"},
+		{"downgrade", "Netflix", "SEK", -149, -99, "Standard", "Standard with ads"},
+		{"rounded list price", "Spotify", "SEK", -129, -208.5, "Individual", "Family"},
+		{"price increase of the plan", "Spotify", "SEK", -119, -129, "", ""},
+		{"same plan", "Spotify", "SEK", -129, -129, "", ""},
+		{"other currency", "Spotify", "USD", -129, -209, "", ""},
+		{"unknown service", "HBO Max", "SEK", -129, -209, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldPlan, newPlan := db.PlanTransition(tt.sub, "SE", tt.currency, tt.oldAmount, tt.newAmt)
+			if oldPlan != tt.wantOld || newPlan != tt.wantNew {
+				t.Errorf("got %q → %q, want %q → %q", oldPlan, newPlan, tt.wantOld, tt.wantNew)
+			}
+		})
+	}
+
+	var none *PriceDatabase
+	if oldPlan, newPlan := none.PlanTransition("Spotify", "SE", "SEK", -129, -209); oldPlan != "" || newPlan != "" {
+		t.Errorf("expected no plans without a database, got %q → %q", oldPlan, newPlan)
+	}
+}
+
+func TestAnnotatePlanChanges(t *testing.T) {
+	db, err := parsePriceDatabase([]byte(testPrices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := []SubscriptionChange{
+		{Kind: ChangePriceIncrease, Name: "Spotify", OldAmount: 129, NewAmount: 209},
+		{Kind: ChangePriceIncrease, Name: "Netflix", OldAmount: 139, NewAmount: 149},
+		{Kind: ChangeNew, Name: "Netflix", NewAmount: 149},
+	}
+	AnnotatePlanChanges(changes, db, "SE", "SEK")
+	if c := changes[0]; c.OldPlan != "Individual" || c.NewPlan != "Family" {
+		t.Errorf("expected a plan change, got %+v", c)
+	}
+	for _, c := range changes[1:] {
+		if c.NewPlan != "" {
+			t.Errorf("expected no plan change, got %+v", c)
+		}
+	}
+
+	var buf bytes.Buffer
+	PrintChanges(&buf, &ChangeReport{Changes: changes[:1]}, OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "NEW PLAN") || !strings.Contains(buf.String(), "(Individual → Family)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	events := []Event{
+		{Kind: EventPriceChanged, Subscription: "Spotify", OldAmount: 209, NewAmount: 129},
+		{Kind: EventStarted, Subscription: "Spotify", NewAmount: 209},
+	}
+	AnnotateEventPlans(events, db, "SE", "SEK")
+	if e := events[0]; e.OldPlan != "Family" || e.NewPlan != "Individual" {
+		t.Errorf("expected a plan change event, got %+v", e)
+	}
+	if events[1].NewPlan != "" {
+		t.Errorf("expected no plans on a started event, got %+v", events[1])
+	}
+}
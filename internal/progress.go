@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressThreshold is the total size of the input files above which progress is shown on a
+// terminal without asking for it
+const ProgressThreshold = 50 << 20 // 50 MB
+
+const (
+	progressBarInterval = 200 * time.Millisecond // redraw interval on a terminal
+	progressLogInterval = 5 * time.Second        // log line interval otherwise
+	progressBarWidth    = 30
+)
+
+// Progress reports how far parsing and importing large inputs has come, either as a progress bar
+// redrawn on a terminal or as periodic log lines. Parsers report to the Progress in their context
+// with ReportProgress; it is safe for concurrent use by parsers running in parallel.
+type Progress struct {
+	w        io.Writer
+	bar      bool
+	interval time.Duration
+	start    time.Time
+
+	printMu sync.Mutex // serializes output
+
+	mu      sync.Mutex
+	step    string
+	files   map[string]*fileProgress
+	total   int64 // bytes in all files
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// fileProgress is the progress of one input file
+type fileProgress struct {
+	size         int64
+	fraction     float64 // share of the file parsed so far
+	transactions int     // transactions parsed so far
+	done         bool
+}
+
+// NewProgress creates a reporter writing to w: a progress bar redrawn in place if bar is set (for
+// terminals), otherwise a log line every few seconds. Call Start to begin reporting and Stop when
+// done.
+func NewProgress(w io.Writer, bar bool) *Progress {
+	p := &Progress{w: w, bar: bar, interval: progressLogInterval, files: make(map[string]*fileProgress)}
+	if bar {
+		p.interval = progressBarInterval
+	}
+	return p
+}
+
+// InputSize returns the total size in bytes of the files at paths (ignoring ones that can't be read)
+func InputSize(paths []string) int64 {
+	var total int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// AddFiles registers the files to be parsed, whose sizes make up the total progress
+func (p *Progress) AddFiles(paths ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, path := range paths {
+		if _, ok := p.files[path]; ok {
+			continue
+		}
+		size := InputSize([]string{path})
+		p.files[path] = &fileProgress{size: size}
+		p.total += size
+	}
+}
+
+// Start begins reporting, describing the current step (e.g., "Parsing")
+func (p *Progress) Start(step string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.step = step
+	if p.stop != nil {
+		return
+	}
+	p.start = time.Now()
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+	go p.run(p.stop, p.stopped)
+}
+
+// Step moves on to the next step (e.g., "Saving state"), printing it right away
+func (p *Progress) Step(step string) {
+	p.mu.Lock()
+	p.step = step
+	p.mu.Unlock()
+	p.print()
+}
+
+// Stop ends reporting, leaving the final state on screen
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	stop, stopped := p.stop, p.stopped
+	p.stop = nil
+	p.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-stopped
+	p.print()
+	if p.bar {
+		fmt.Fprintln(p.w)
+	}
+}
+
+func (p *Progress) run(stop, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.print()
+		}
+	}
+}
+
+// file returns the progress of the file at path, adding it if it wasn't registered. The caller
+// must hold p.mu.
+func (p *Progress) file(path string) *fileProgress {
+	f := p.files[path]
+	if f == nil {
+		f = &fileProgress{}
+		p.files[path] = f
+	}
+	return f
+}
+
+// report records the transactions parsed from a file so far and the share of it parsed
+// (negative if unknown)
+func (p *Progress) report(path string, transactions int, fraction float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.file(path)
+	f.transactions = transactions
+	if fraction >= 0 {
+		f.fraction = min(fraction, 1)
+	}
+}
+
+// fileDone marks a file as completely parsed
+func (p *Progress) fileDone(path string, transactions int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.file(path)
+	f.transactions = transactions
+	f.fraction = 1
+	f.done = true
+}
+
+// line describes the current progress
+func (p *Progress) line() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var parsed float64
+	done, transactions := 0, 0
+	for _, f := range p.files {
+		parsed += float64(f.size) * f.fraction
+		transactions += f.transactions
+		if f.done {
+			done++
+		}
+	}
+	percent := 100.0
+	if p.total > 0 {
+		percent = 100 * parsed / float64(p.total)
+	}
+	elapsed := time.Since(p.start).Round(time.Second)
+
+	details := fmt.Sprintf("%d/%d files, %d transactions, %s", done, len(p.files), transactions, elapsed)
+	if p.bar {
+		filled := int(percent / 100 * progressBarWidth)
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+		return fmt.Sprintf("%s [%s] %3.0f%% %s", p.step, bar, percent, details)
+	}
+	return fmt.Sprintf("%s: %.0f%% (%s)", p.step, percent, details)
+}
+
+func (p *Progress) print() {
+	line := p.line()
+	p.printMu.Lock()
+	defer p.printMu.Unlock()
+	if p.bar {
+		// Redraw in place, clearing what's left of a longer previous line
+		fmt.Fprintf(p.w, "\r%s\033[K", line)
+	} else {
+		fmt.Fprintln(p.w, line)
+	}
+}
+
+// Above runs print, which writes lines to the terminal, so that its output appears above the
+// progress bar rather than on the same line
+func (p *Progress) Above(print func()) {
+	if !p.bar {
+		print()
+		return
+	}
+	p.printMu.Lock()
+	fmt.Fprint(p.w, "\r\033[K")
+	print()
+	p.printMu.Unlock()
+	p.print()
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context whose parsers report to p
+func WithProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// progressFrom returns the Progress in ctx, or nil
+func progressFrom(ctx context.Context) *Progress {
+	p, _ := ctx.Value(progressKey{}).(*Progress)
+	return p
+}
+
+// ReportProgress tells the progress reporter in ctx, if any, that the parser of the file at path
+// has parsed the given number of transactions so far and read the given share of the file (0 to
+// 1, or negative if unknown). Parsers call it periodically, e.g. when checking for cancellation.
+func ReportProgress(ctx context.Context, path string, transactions int, fraction float64) {
+	if p := progressFrom(ctx); p != nil {
+		p.report(path, transactions, fraction)
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(a, make([]byte, 300), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := InputSize([]string{a, b, filepath.Join(dir, "missing")}); got != 400 {
+		t.Errorf("InputSize = %d, want 400", got)
+	}
+
+	var out bytes.Buffer
+	p := NewProgress(&out, false)
+	p.AddFiles(a, b)
+	p.Start("Parsing")
+	ctx := WithProgress(context.Background(), p)
+
+	ReportProgress(ctx, a, 10, 0.5)
+	if line := p.line(); !strings.HasPrefix(line, "Parsing: 38% (0/2 files, 10 transactions") {
+		t.Errorf("unexpected progress line %q", line)
+	}
+	// An unknown share keeps the previous one but updates the count
+	ReportProgress(ctx, a, 20, -1)
+	if line := p.line(); !strings.HasPrefix(line, "Parsing: 38% (0/2 files, 20 transactions") {
+		t.Errorf("unexpected progress line %q", line)
+	}
+
+	p.fileDone(a, 30)
+	p.fileDone(b, 5)
+	p.Step("Saving state")
+	p.Stop()
+	p.Stop() // stopping twice is harmless
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "Saving state: 100% (2/2 files, 35 transactions") {
+			t.Errorf("unexpected progress line %q", line)
+		}
+	}
+	if len(lines) != 2 {
+		t.Errorf("expected a line for the step and one when stopping, got %q", out.String())
+	}
+
+	// Without a Progress in the context, reporting does nothing
+	ReportProgress(context.Background(), a, 1, 1)
+}
+
+func TestProgress_Bar(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, true)
+	p.Start("Parsing")
+	p.Above(func() { out.WriteString("Loaded 3 transactions\n") })
+	p.Stop()
+
+	got := out.String()
+	if !strings.HasPrefix(got, "\r\033[KLoaded 3 transactions\n\rParsing [") {
+		t.Errorf("expected the message to clear and redraw the bar, got %q", got)
+	}
+	if !strings.Contains(got, "] 100% 0/0 files") || !strings.HasSuffix(got, "\n") {
+		t.Errorf("expected a full bar ending the output, got %q", got)
+	}
+}
+
+func TestProgress_ParseFile(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, false)
+	path := filepath.Join("..", "testdata", "sample.json")
+	p.AddFiles(path)
+	ctx := WithProgress(context.Background(), p)
+
+	transactions, err := ParseFile(ctx, "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.step = "Parsing"
+	want := fmt.Sprintf("Parsing: 100%% (1/1 files, %d transactions", len(transactions))
+	if line := p.line(); !strings.HasPrefix(line, want) {
+		t.Errorf("progress line = %q, want prefix %q", line, want)
+	}
+}
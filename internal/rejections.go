@@ -0,0 +1,55 @@
+package internal
+
+import "sort"
+
+// JSONRejection is a payee that was evaluated but not detected as a subscription, in JSON output
+type JSONRejection struct {
+	Payee        string                `json:"payee"`
+	Transactions int                   `json:"transactions"`
+	Reasons      []JSONRejectionReason `json:"reasons"`
+}
+
+// JSONRejectionReason is why one strategy (or the config) rejected a payee
+type JSONRejectionReason struct {
+	Strategy string        `json:"strategy,omitempty"` // empty for exclusions
+	Code     RejectionCode `json:"code"`
+	Reason   string        `json:"reason"`
+	Rule     string        `json:"rule,omitempty"` // exclude rule pattern, for exclusions
+}
+
+// RejectionReport is an Observer that collects the payees detection rejected, with the reasons
+// of every strategy that saw them, to tune the tolerance and groups from data
+type RejectionReport struct {
+	transactions map[string]int
+	reasons      map[string][]JSONRejectionReason
+}
+
+// NewRejectionReport returns an empty report; pass it to the detector with WithObserver
+func NewRejectionReport() *RejectionReport {
+	return &RejectionReport{
+		transactions: make(map[string]int),
+		reasons:      make(map[string][]JSONRejectionReason),
+	}
+}
+
+func (r *RejectionReport) Observe(e DetectionEvent) {
+	switch e.Kind {
+	case DetectionGrouped:
+		r.transactions[e.Payee]++
+	case DetectionRejected:
+		r.reasons[e.Payee] = append(r.reasons[e.Payee], JSONRejectionReason{Strategy: e.Strategy, Code: e.Code, Reason: e.Reason, Rule: e.Rule})
+	case DetectionAccepted:
+		// Rejected by an earlier strategy, but detected by a later one
+		delete(r.reasons, e.Payee)
+	}
+}
+
+// Rejections returns the rejected payees sorted by name
+func (r *RejectionReport) Rejections() []JSONRejection {
+	rejections := []JSONRejection{}
+	for payee, reasons := range r.reasons {
+		rejections = append(rejections, JSONRejection{Payee: payee, Transactions: r.transactions[payee], Reasons: reasons})
+	}
+	sort.Slice(rejections, func(i, j int) bool { return rejections[i].Payee < rejections[j].Payee })
+	return rejections
+}
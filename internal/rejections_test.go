@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRejectionReport(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-02-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-03-15"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-01-10"), Text: "Grocery", Amount: -150},
+		{Date: date("2025-01-25"), Text: "Grocery", Amount: -300},
+		{Date: date("2025-02-12"), Text: "Grocery", Amount: -200},
+		{Date: date("2025-01-05"), Text: "Power", Amount: -300},
+		{Date: date("2025-02-05"), Text: "Power", Amount: -900},
+		{Date: date("2025-03-05"), Text: "Power", Amount: -450},
+		{Date: date("2025-01-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-02-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-03-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-04-15"), Text: "Other", Amount: -10},
+	}
+	cfg, err := parseConfig([]byte("use_default_known: false\nexclude: [Gym]\nstrategies: [monthly, variable]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewRejectionReport()
+	if _, _, err := NewDetector(WithObserver(report)).Analyze(context.Background(), txs, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	codes := make(map[string][]RejectionCode)
+	for _, r := range report.Rejections() {
+		for _, reason := range r.Reasons {
+			codes[r.Payee] = append(codes[r.Payee], reason.Code)
+		}
+		if r.Payee == "Grocery" && r.Transactions != 3 {
+			t.Errorf("expected 3 transactions for Grocery, got %d", r.Transactions)
+		}
+	}
+	expected := map[string][]RejectionCode{
+		"Grocery": {RejectionMultipleInMonth, RejectionMultipleInMonth},
+		"Gym":     {RejectionExcluded},
+		"Other":   {RejectionTooFewOccurrences, RejectionTooFewOccurrences},
+	}
+	if len(codes) != len(expected) {
+		t.Errorf("expected rejections for %v, got %v", expected, codes)
+	}
+	for payee, want := range expected {
+		if got := codes[payee]; len(got) != len(want) || got[0] != want[0] || got[len(got)-1] != want[len(want)-1] {
+			t.Errorf("%s: expected %v, got %v", payee, want, got)
+		}
+	}
+	// Power is rejected by the monthly strategy, but detected by the variable one
+	if _, ok := codes["Power"]; ok {
+		t.Errorf("expected Power not to be reported, got %v", codes["Power"])
+	}
+}
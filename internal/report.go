@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// SpendPeriod is the actual subscription spend in a calendar month or year:
+// the sum of the payments matched to subscriptions, not the latest amount × 12
+type SpendPeriod struct {
+	Start          time.Time          // first day of the period (UTC)
+	Total          float64            // sum of absolute payment amounts
+	Payments       int                // number of payments
+	BySubscription map[string]float64 // spend per subscription name
+	Real           float64            // Total adjusted for inflation (see AdjustSpend)
+}
+
+// Label returns the period as YYYY-MM (months), YYYY-QN (quarters) or YYYY (years)
+func (p SpendPeriod) Label(by string) string {
+	switch by {
+	case "year":
+		return p.Start.Format("2006")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", p.Start.Year(), (int(p.Start.Month())+2)/3)
+	}
+	return p.Start.Format("2006-01")
+}
+
+// SpendByPeriod sums subscription payments per calendar month, quarter or year ("month",
+// "quarter" or "year"), oldest first. Periods without payments between the first and last payment
+// are included with zero spend.
+func SpendByPeriod(subs []Subscription, by string) []SpendPeriod {
+	periodStart := func(d time.Time) time.Time {
+		switch by {
+		case "year":
+			return time.Date(d.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		case "quarter":
+			return time.Date(d.Year(), (d.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	next := func(d time.Time) time.Time {
+		switch by {
+		case "year":
+			return d.AddDate(1, 0, 0)
+		case "quarter":
+			return d.AddDate(0, 3, 0)
+		}
+		return d.AddDate(0, 1, 0)
+	}
+
+	periods := make(map[time.Time]*SpendPeriod)
+	var first, last time.Time
+	for _, sub := range subs {
+		for _, tx := range sub.Transactions {
+			start := periodStart(tx.Date)
+			p, ok := periods[start]
+			if !ok {
+				p = &SpendPeriod{Start: start, BySubscription: make(map[string]float64)}
+				periods[start] = p
+			}
+			amount := math.Abs(tx.Amount)
+			p.Total += amount
+			p.Payments++
+			p.BySubscription[sub.Name] += amount
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if start.After(last) {
+				last = start
+			}
+		}
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+
+	var result []SpendPeriod
+	for start := first; !start.After(last); start = next(start) {
+		if p, ok := periods[start]; ok {
+			result = append(result, *p)
+		} else {
+			result = append(result, SpendPeriod{Start: start, BySubscription: map[string]float64{}})
+		}
+	}
+	return result
+}
+
+// PrintReportTable outputs spend per period as a table with the change from the previous period,
+// followed by the total and average per period
+func PrintReportTable(w io.Writer, periods []SpendPeriod, by string, opts OutputOptions) {
+	loc := opts.Locale
+	if len(periods) == 0 {
+		fmt.Fprintln(w, loc.T("No subscriptions detected."))
+		return
+	}
+
+	totals := make([]float64, len(periods))
+	sum := 0.0
+	for i, p := range periods {
+		totals[i] = p.Total
+		sum += p.Total
+	}
+	fmt.Fprint(w, loc.Sprintf("Subscription spend over %d period(s): %s\n\n", len(periods), Sparkline(totals)))
+	if opts.RealTerms {
+		fmt.Fprint(w, loc.Sprintf("Real amounts are adjusted for inflation to %d prices.\n\n", periods[len(periods)-1].Start.Year()))
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	header := table.Row{loc.T("Period"), loc.T("Subscriptions"), loc.T("Payments"), loc.T("Spend"), loc.T("Change")}
+	if opts.RealTerms {
+		header = append(header, loc.T("Real"), loc.T("Real Change"))
+	}
+	t.AppendHeader(append(header, loc.T("Largest")))
+	realSum := 0.0
+	for i, p := range periods {
+		change, realChange := "", ""
+		if i > 0 {
+			change = formatDelta(p.Total-periods[i-1].Total, opts.Currency)
+			realChange = formatDelta(p.Real-periods[i-1].Real, opts.Currency)
+		}
+		row := table.Row{p.Label(by), len(p.BySubscription), p.Payments, opts.Currency.Format(p.Total), change}
+		if opts.RealTerms {
+			row = append(row, opts.Currency.Format(p.Real), realChange)
+		}
+		t.AppendRow(append(row, largestSubscription(p, opts.Currency)))
+		realSum += p.Real
+	}
+	total := table.Row{loc.T("Total"), "", "", opts.Currency.Format(sum), ""}
+	average := table.Row{loc.T("Average"), "", "", opts.Currency.Format(sum / float64(len(periods))), ""}
+	if opts.RealTerms {
+		total = append(total, opts.Currency.Format(realSum), "")
+		average = append(average, opts.Currency.Format(realSum/float64(len(periods))), "")
+	}
+	t.AppendFooter(append(total, ""))
+	t.AppendFooter(append(average, ""))
+	styleTable(t, opts)
+	columns := []table.ColumnConfig{
+		{Number: 2, Align: text.AlignRight},
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight, AlignFooter: text.AlignRight},
+		{Number: 5, Align: text.AlignRight},
+	}
+	if opts.RealTerms {
+		columns = append(columns,
+			table.ColumnConfig{Number: 6, Align: text.AlignRight, AlignFooter: text.AlignRight},
+			table.ColumnConfig{Number: 7, Align: text.AlignRight},
+		)
+	}
+	t.SetColumnConfigs(columns)
+	t.Render()
+}
+
+// largestSubscription returns "Name (amount)" for the subscription with the most spend in the period
+func largestSubscription(p SpendPeriod, currency Currency) string {
+	names := sortedSubscriptionNames(p)
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", names[0], currency.Format(p.BySubscription[names[0]]))
+}
+
+// JSONReport is the JSON output format for the report subcommand
+type JSONReport struct {
+	SchemaVersion int                `json:"schema_version"`
+	By            string             `json:"by"`
+	Periods       []JSONReportPeriod `json:"periods"`
+	Total         float64            `json:"total"`
+	Average       float64            `json:"average"`
+	RealTotal     float64            `json:"real_total,omitempty"` // with --real, in the prices of the last period's year
+	Currency      string             `json:"currency"`
+}
+
+// JSONReportPeriod is the JSON output format for the spend in one period
+type JSONReportPeriod struct {
+	Period         string             `json:"period"` // YYYY-MM, YYYY-QN or YYYY
+	Total          float64            `json:"total"`
+	Payments       int                `json:"payments"`
+	BySubscription map[string]float64 `json:"by_subscription"`
+	RealTotal      float64            `json:"real_total,omitempty"` // with --real
+}
+
+// PrintReportJSON outputs spend per period in JSON format
+func PrintReportJSON(w io.Writer, periods []SpendPeriod, by string, currency Currency) {
+	output := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		By:            by,
+		Periods:       []JSONReportPeriod{},
+		Currency:      currency.Code,
+	}
+	for _, p := range periods {
+		output.Periods = append(output.Periods, JSONReportPeriod{
+			Period:         p.Label(by),
+			Total:          currency.Round(p.Total),
+			Payments:       p.Payments,
+			BySubscription: p.BySubscription,
+			RealTotal:      currency.Round(p.Real),
+		})
+		output.Total += p.Total
+		output.RealTotal += p.Real
+	}
+	if len(periods) > 0 {
+		output.Average = currency.Round(output.Total / float64(len(periods)))
+	}
+	output.Total = currency.Round(output.Total)
+	output.RealTotal = currency.Round(output.RealTotal)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
+
+// sortedSubscriptionNames returns the subscription names of a period, most spend first
+func sortedSubscriptionNames(p SpendPeriod) []string {
+	names := make([]string, 0, len(p.BySubscription))
+	for name := range p.BySubscription {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if p.BySubscription[names[i]] != p.BySubscription[names[j]] {
+			return p.BySubscription[names[i]] > p.BySubscription[names[j]]
+		}
+		if !strings.EqualFold(names[i], names[j]) {
+			return strings.ToLower(names[i]) < strings.ToLower(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,39 @@
+package internal
+
+import "testing"
+
+func TestSpendByPeriod(t *testing.T) {
+	subs := []Subscription{
+		{Name: "Netflix", Transactions: []Transaction{
+			{Date: date("2025-01-15"), Amount: -99},
+			{Date: date("2025-04-15"), Amount: -99},
+		}},
+		{Name: "Gym", Transactions: []Transaction{
+			{Date: date("2025-01-01"), Amount: -300},
+			{Date: date("2025-01-28"), Amount: -300}, // two payments in one month
+		}},
+	}
+
+	periods := SpendByPeriod(subs, "month")
+	if len(periods) != 4 {
+		t.Fatalf("expected January to April (with empty months), got %d periods", len(periods))
+	}
+	if jan := periods[0]; jan.Label("month") != "2025-01" || jan.Total != 699 || jan.Payments != 3 || jan.BySubscription["Gym"] != 600 {
+		t.Errorf("unexpected January: %+v", jan)
+	}
+	if feb := periods[1]; feb.Total != 0 || feb.Payments != 0 {
+		t.Errorf("expected no spend in February, got %+v", feb)
+	}
+	if name := largestSubscription(periods[0], GetCurrency("USD")); name != "Gym ($600)" {
+		t.Errorf("expected Gym as largest in January, got %q", name)
+	}
+
+	years := SpendByPeriod(subs, "year")
+	if len(years) != 1 || years[0].Label("year") != "2025" || years[0].Total != 798 {
+		t.Errorf("unexpected yearly spend: %+v", years)
+	}
+
+	if SpendByPeriod(nil, "month") != nil {
+		t.Error("expected no periods without subscriptions")
+	}
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// RuleSource explains where a subscription comes from, for debugging the config: the mechanism
+// that produced it and the config rules that touched it
+type RuleSource struct {
+	Mechanism string   // e.g. "detected (monthly)" or "known pattern NETFLIX"
+	Rules     []string // e.g. `renamed by "SPOTIFY"`, `group "Music" (^Spotify)`, "tags"
+}
+
+// String returns the mechanism followed by the rules, e.g. `detected (monthly); group "Music"
+// (^Spotify), tags`
+func (r RuleSource) String() string {
+	if len(r.Rules) == 0 {
+		return r.Mechanism
+	}
+	return r.Mechanism + "; " + strings.Join(r.Rules, ", ")
+}
+
+// ExplainRuleSource returns the mechanism that produced a subscription (the detection strategy,
+// and for known patterns which one) and the config rules that touched it: rename rules, groups
+// and amount bands that its transaction texts went through, its description, tags, category,
+// share and business settings, and exclude rules that match its name but not its dates
+func ExplainRuleSource(sub Subscription, cfg *Config) RuleSource {
+	source := RuleSource{Mechanism: fmt.Sprintf("detected (%s)", cmp.Or(sub.Strategy, "unknown strategy"))}
+	if sub.Strategy == StrategyKnownPatterns {
+		source.Mechanism = "known pattern"
+		if len(sub.Transactions) > 0 {
+			if known := cfg.MatchesKnown(sub.Transactions[len(sub.Transactions)-1]); known != nil {
+				source.Mechanism = "known pattern " + known.Pattern
+			}
+		}
+	}
+	if cfg == nil {
+		return source
+	}
+
+	// Texts as exported, and after rename rules
+	var renames, groups, bands []string
+	for _, tx := range sub.Transactions {
+		if tx.RawText == "" {
+			continue
+		}
+		text := cfg.renamed(tx.RawText)
+		if text != tx.RawText {
+			for name := range cfg.Rename {
+				if renameKey(name) == renameKey(tx.RawText) {
+					renames = append(renames, fmt.Sprintf("renamed by %q", name))
+				}
+			}
+		}
+		grouped := text
+		for _, group := range cfg.Groups {
+			for i, re := range group.regexes {
+				if re.MatchString(text) {
+					groups = append(groups, fmt.Sprintf("group %q (%s)", group.Name, group.Patterns[i]))
+					grouped = group.Name
+					break
+				}
+			}
+		}
+		for _, band := range cfg.splitByAmount[foldPayee(grouped)] {
+			if band.Name == tx.Text && band.Name != grouped {
+				bands = append(bands, fmt.Sprintf("split_by_amount %q of %q", band.Name, grouped))
+			}
+		}
+	}
+	source.Rules = append(source.Rules, sortedUnique(renames)...)
+	source.Rules = append(source.Rules, sortedUnique(groups)...)
+	source.Rules = append(source.Rules, sortedUnique(bands)...)
+
+	if cfg.GetDescription(sub.Name) != "" {
+		source.Rules = append(source.Rules, "description")
+	}
+	if len(cfg.GetTags(sub.Name)) > 0 {
+		source.Rules = append(source.Rules, "tags")
+	}
+	if _, ok := cfg.Categories[sub.Name]; ok {
+		source.Rules = append(source.Rules, "category")
+	}
+	if _, ok := cfg.Split[sub.Name]; ok {
+		source.Rules = append(source.Rules, "split")
+	}
+	if _, ok := cfg.SharedWith[sub.Name]; ok {
+		source.Rules = append(source.Rules, "shared_with")
+	}
+	if _, ok := cfg.Business[sub.Name]; ok {
+		source.Rules = append(source.Rules, "business")
+	}
+	for _, rule := range cfg.excludeRules {
+		if rule.regex.MatchString(sub.Name) && !rule.Matches(sub) {
+			source.Rules = append(source.Rules, fmt.Sprintf("exclude %q (outside its dates)", rule.Pattern))
+		}
+	}
+	return source
+}
+
+// sortedUnique returns the distinct strings of strs, sorted
+func sortedUnique(strs []string) []string {
+	sort.Strings(strs)
+	return slices.Compact(strs)
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExplainRuleSource(t *testing.T) {
+	cfg, err := parseConfig([]byte(`use_default_known: false
+rename:
+  "GOOGLE GSUITE": "Google Workspace"
+groups:
+  - name: Workspace
+    patterns: ["^Google Workspace$"]
+known:
+  - pattern: NETFLIX
+tags:
+  Workspace: [work]
+exclude:
+  - pattern: Workspace
+    before: "2025-01-01"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txs []Transaction
+	for _, month := range []string{"2025-01", "2025-02", "2025-03"} {
+		txs = append(txs,
+			Transaction{Date: date(month + "-05"), Text: "GOOGLE GSUITE " + month[5:], Amount: -72},
+			Transaction{Date: date(month + "-10"), Text: "Gym", Amount: -400},
+		)
+	}
+	txs = append(txs, Transaction{Date: date("2025-03-15"), Text: "NETFLIX.COM", Amount: -99})
+
+	subs, _, err := NewDetector().Analyze(context.Background(), txs, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]RuleSource)
+	for _, sub := range subs {
+		got[sub.Name] = ExplainRuleSource(sub, cfg)
+	}
+	want := map[string]RuleSource{
+		"Workspace": {Mechanism: "detected (monthly)", Rules: []string{
+			`renamed by "GOOGLE GSUITE"`, `group "Workspace" (^Google Workspace$)`, "tags", `exclude "Workspace" (outside its dates)`,
+		}},
+		"Gym":         {Mechanism: "detected (monthly)"},
+		"NETFLIX.COM": {Mechanism: "known pattern NETFLIX"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExplainRuleSource() = %+v, want %+v", got, want)
+	}
+	if s := want["Workspace"].String(); s != `detected (monthly); renamed by "GOOGLE GSUITE", group "Workspace" (^Google Workspace$), tags, exclude "Workspace" (outside its dates)` {
+		t.Errorf("unexpected String(): %s", s)
+	}
+}
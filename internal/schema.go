@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaID identifies the JSON Schema document describing the JSON output
+const JSONSchemaID = "https://github.com/gigurra/subscription-detector/schema/output.json"
+
+// GenerateJSONSchema returns a JSON Schema (draft 2020-12) document describing JSONOutput.
+// It is derived from the output types via reflection so it can't drift from the actual output.
+func GenerateJSONSchema() map[string]any {
+	schema := schemaForType(reflect.TypeOf(JSONOutput{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = JSONSchemaID
+	schema["title"] = "subscription-detector output"
+	schema["description"] = "JSON output of subscription-detector (--output json)"
+	schema["properties"].(map[string]any)["schema_version"] = map[string]any{
+		"type":  "integer",
+		"const": JSONSchemaVersion,
+	}
+	// --summary-only omits the subscriptions list
+	schema["required"] = []string{"schema_version", "summary"}
+	return schema
+}
+
+// schemaForType builds a JSON Schema fragment for a Go type, following encoding/json conventions
+func schemaForType(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaForType(field.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		// nil slices encode as null
+		return map[string]any{
+			"type":  []string{"array", "null"},
+			"items": schemaForType(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaForType(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema := GenerateJSONSchema()
+
+	props := schema["properties"].(map[string]any)
+	version := props["schema_version"].(map[string]any)
+	if version["const"] != JSONSchemaVersion {
+		t.Errorf("expected schema_version const %d, got %v", JSONSchemaVersion, version["const"])
+	}
+
+	subs := props["subscriptions"].(map[string]any)
+	subProps := subs["items"].(map[string]any)["properties"].(map[string]any)
+	for _, field := range []string{"name", "status", "latest_amount", "yearly_cost", "tags"} {
+		if _, ok := subProps[field]; !ok {
+			t.Errorf("expected subscription property %q in schema", field)
+		}
+	}
+	if got := subProps["typical_day"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("expected typical_day to be integer, got %v", got)
+	}
+}
+
+func TestJSONOutputMatchesSchemaProperties(t *testing.T) {
+	var buf bytes.Buffer
+	subs := []Subscription{{Name: "Netflix", Status: StatusActive, LatestAmount: -99}}
+	PrintSubscriptionsJSON(&buf, subs, nil, OutputOptions{Currency: GetCurrency("SEK")})
+
+	var output map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	props := GenerateJSONSchema()["properties"].(map[string]any)
+	for key := range output {
+		if _, ok := props[key]; !ok {
+			t.Errorf("output field %q is missing from schema", key)
+		}
+	}
+	if output["schema_version"] != float64(JSONSchemaVersion) {
+		t.Errorf("expected schema_version %d, got %v", JSONSchemaVersion, output["schema_version"])
+	}
+}
@@ -0,0 +1,384 @@
+package internal
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed web/*.html
+var webTemplates embed.FS
+
+var templates = template.Must(template.ParseFS(webTemplates, "web/*.html"))
+
+// maxUploadSize limits the size of uploaded export files
+const maxUploadSize = 32 << 20
+
+// Server is the web UI: a subscriptions table, per-subscription payment history
+// and an upload form that imports export files into the state file.
+// It also serves a JSON API under /api (see api.go).
+type Server struct {
+	StatePath string
+	Config    *Config
+	Tolerance float64
+	Currency  Currency
+	Locale    Locale
+
+	mu sync.Mutex // serializes state file access
+}
+
+// Handler returns the HTTP handler for the web UI
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /subscriptions/{name}", s.handleSubscription)
+	mux.HandleFunc("POST /upload", s.handleUpload)
+	mux.HandleFunc("GET /api/subscriptions", s.handleAPISubscriptions)
+	mux.HandleFunc("GET /api/subscriptions/{name}/transactions", s.handleAPITransactions)
+	mux.HandleFunc("POST /api/import", s.handleAPIImport)
+	mux.HandleFunc("GET /api/monthly-cost", s.handleAPIMonthlyCost)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("GET /calendar.ics", s.handleCalendar)
+	mux.HandleFunc("GET /feed.atom", s.handleFeed)
+	s.registerGrafana(mux)
+	return mux
+}
+
+// detect runs detection on all transactions imported into the state file. Detection stops
+// when ctx (usually the request's context) is cancelled.
+func (s *Server) detect(ctx context.Context) ([]Subscription, DateRange, int, error) {
+	s.mu.Lock()
+	state, err := LoadState(s.StatePath)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, DateRange{}, 0, err
+	}
+
+	transactions, err := state.StoredTransactions()
+	if err != nil {
+		return nil, DateRange{}, 0, err
+	}
+	if len(transactions) == 0 {
+		return nil, DateRange{}, 0, nil
+	}
+	subs, dateRange, err := NewDetector(WithTolerance(s.Tolerance)).Analyze(ctx, transactions, s.Config)
+	if err != nil {
+		return nil, DateRange{}, 0, err
+	}
+	return subs, dateRange, len(transactions), nil
+}
+
+type indexPage struct {
+	Show, Sort, Dir, Query, Tag string
+	ShowOptions                 []string
+	Message                     string
+	Headers                     []columnHeader
+	Rows                        []subscriptionRow
+	Tags                        []string
+	Formats                     []string
+	Total, Active, Stopped      int
+	MonthlyTotal, YearlyTotal   string
+	TransactionCount            int
+	DataRange                   string
+}
+
+type columnHeader struct {
+	Label string
+	URL   string // sort link (empty = not sortable)
+	Arrow string
+}
+
+type subscriptionRow struct {
+	Name, URL, Description, Tags string
+	Active                       bool
+	Status, Day                  string
+	Monthly, Yearly, TotalPaid   string
+	Started, LastSeen            string
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	subs, dateRange, txCount, err := s.detect(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	page := indexPage{
+		Show:             queryOr(q, "show", "active"),
+		Sort:             queryOr(q, "sort", "name"),
+		Dir:              queryOr(q, "dir", "asc"),
+		Query:            q.Get("q"),
+		Tag:              q.Get("tag"),
+		ShowOptions:      []string{"active", "stopped", "all"},
+		Message:          q.Get("msg"),
+		Formats:          AvailableSources(),
+		TransactionCount: txCount,
+	}
+	sort.Strings(page.Formats)
+	if txCount > 0 {
+		page.DataRange = fmt.Sprintf("%s – %s", s.Locale.FormatDate(dateRange.Start), s.Locale.FormatDate(dateRange.End))
+	}
+	page.Total = len(subs)
+	page.Active, page.Stopped = countByStatus(subs)
+
+	display := s.filterSubscriptions(subs, q)
+
+	monthly := ActiveMonthlyTotal(display)
+	page.MonthlyTotal = s.Currency.Format(monthly)
+	page.YearlyTotal = s.Currency.Format(monthly * 12)
+
+	tagSet := make(map[string]bool)
+	for _, sub := range subs {
+		for _, tag := range s.Config.GetTags(sub.Name) {
+			tagSet[tag] = true
+		}
+	}
+	for tag := range tagSet {
+		page.Tags = append(page.Tags, tag)
+	}
+	sort.Strings(page.Tags)
+
+	for _, col := range []struct{ label, field string }{
+		{"Name", "name"}, {"Description", "description"}, {"Tags", ""}, {"Status", ""}, {"Day", ""},
+		{"Monthly", "amount"}, {"Yearly", ""}, {"Total Paid", ""}, {"Started", ""}, {"Last Seen", ""},
+	} {
+		header := columnHeader{Label: col.label}
+		if col.field != "" {
+			dir := "asc"
+			if page.Sort == col.field && page.Dir == "asc" {
+				dir = "desc"
+			}
+			if page.Sort == col.field {
+				header.Arrow = map[string]string{"asc": "▲", "desc": "▼"}[page.Dir]
+			}
+			params := url.Values{"show": {page.Show}, "sort": {col.field}, "dir": {dir}}
+			if page.Query != "" {
+				params.Set("q", page.Query)
+			}
+			if page.Tag != "" {
+				params.Set("tag", page.Tag)
+			}
+			header.URL = "/?" + params.Encode()
+		}
+		page.Headers = append(page.Headers, header)
+	}
+
+	for _, sub := range display {
+		latest := math.Abs(sub.LatestAmount)
+		page.Rows = append(page.Rows, subscriptionRow{
+			Name:        sub.Name,
+			URL:         "/subscriptions/" + url.PathEscape(sub.Name),
+			Description: s.Config.GetDescription(sub.Name),
+			Tags:        strings.Join(s.Config.GetTags(sub.Name), ", "),
+			Active:      sub.Status == StatusActive,
+			Status:      strings.ToUpper(string(sub.Status)),
+			Day:         s.Locale.FormatDay(sub.TypicalDay),
+			Monthly:     s.Currency.Format(latest),
+			Yearly:      s.Currency.Format(latest * 12),
+			TotalPaid:   s.Currency.Format(sub.TotalPaid),
+			Started:     s.Locale.FormatDate(sub.StartDate),
+			LastSeen:    s.Locale.FormatDate(sub.LastDate),
+		})
+	}
+
+	render(w, "index.html", page)
+}
+
+type subscriptionPage struct {
+	Name, Description, Status string
+	Active                    bool
+	Chart                     paymentChart
+	Payments                  []paymentRow
+	TotalPaid                 string
+}
+
+type paymentRow struct {
+	Date, Text, Amount string
+}
+
+// paymentChart is a bar chart of payment amounts, rendered as inline SVG
+type paymentChart struct {
+	Width, Height int
+	Bars          []chartBar
+}
+
+type chartBar struct {
+	X, Y, W, H float64
+	Title      string
+}
+
+const (
+	chartWidth  = 720
+	chartHeight = 240
+)
+
+func (s *Server) handleSubscription(w http.ResponseWriter, r *http.Request) {
+	subs, _, _, err := s.detect(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sub := FindSubscription(subs, r.PathValue("name"))
+	if sub == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	txs := make([]Transaction, len(sub.Transactions))
+	copy(txs, sub.Transactions)
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Date.Before(txs[j].Date) })
+
+	page := subscriptionPage{
+		Name:        sub.Name,
+		Description: s.Config.GetDescription(sub.Name),
+		Status:      strings.ToUpper(string(sub.Status)),
+		Active:      sub.Status == StatusActive,
+		Chart:       s.buildChart(txs),
+		TotalPaid:   s.Currency.Format(sub.TotalPaid),
+	}
+	for i := len(txs) - 1; i >= 0; i-- {
+		page.Payments = append(page.Payments, paymentRow{
+			Date:   s.Locale.FormatDate(txs[i].Date),
+			Text:   txs[i].Text,
+			Amount: s.Currency.Format(math.Abs(txs[i].Amount)),
+		})
+	}
+
+	render(w, "subscription.html", page)
+}
+
+// buildChart lays out one bar per payment, scaled to the largest payment
+func (s *Server) buildChart(txs []Transaction) paymentChart {
+	chart := paymentChart{Width: chartWidth, Height: chartHeight}
+	if len(txs) == 0 {
+		return chart
+	}
+
+	maxAmount := 0.0
+	for _, tx := range txs {
+		maxAmount = math.Max(maxAmount, math.Abs(tx.Amount))
+	}
+	if maxAmount == 0 {
+		return chart
+	}
+
+	slot := float64(chartWidth) / float64(len(txs))
+	for i, tx := range txs {
+		h := math.Abs(tx.Amount) / maxAmount * (chartHeight - 10)
+		chart.Bars = append(chart.Bars, chartBar{
+			X:     float64(i)*slot + slot*0.1,
+			Y:     chartHeight - h,
+			W:     slot * 0.8,
+			H:     h,
+			Title: fmt.Sprintf("%s: %s", s.Locale.FormatDate(tx.Date), s.Currency.Format(math.Abs(tx.Amount))),
+		})
+	}
+	return chart
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	filename, result, status, err := s.importUpload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	msg := fmt.Sprintf("Imported %d new transactions from %s (%d already stored) at %s",
+		result.Added, filename, result.Duplicates, time.Now().Format("15:04:05"))
+	http.Redirect(w, r, "/?"+url.Values{"msg": {msg}}.Encode(), http.StatusSeeOther)
+}
+
+// importUpload imports the export file in the "file" form field into the state file.
+// The format is taken from the "format" form field, or guessed from the file extension.
+// On failure, it returns the HTTP status to respond with.
+func (s *Server) importUpload(w http.ResponseWriter, r *http.Request) (string, ImportResult, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", ImportResult{}, http.StatusBadRequest, fmt.Errorf("reading upload: %w", err)
+	}
+	defer file.Close()
+
+	// Parsers read from paths, so the upload is stored in a temporary file first
+	tmp, err := os.CreateTemp("", "subscription-detector-upload-*"+filepath.Ext(header.Filename))
+	if err != nil {
+		return "", ImportResult{}, http.StatusInternalServerError, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", ImportResult{}, http.StatusInternalServerError, fmt.Errorf("storing upload: %w", err)
+	}
+
+	format := r.FormValue("format")
+	if format == "" || format == "auto" {
+		if format, err = DetectFormat(tmp.Name()); err != nil {
+			return "", ImportResult{}, http.StatusInternalServerError, err
+		}
+	}
+	parser, err := GetParser(format)
+	if err != nil {
+		return "", ImportResult{}, http.StatusBadRequest, fmt.Errorf("cannot determine format of %s: %w", header.Filename, err)
+	}
+
+	txs, err := parser.Parse(r.Context(), tmp.Name())
+	if err != nil {
+		return "", ImportResult{}, http.StatusBadRequest, fmt.Errorf("parsing %s: %w", header.Filename, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, err := LoadState(s.StatePath)
+	if err != nil {
+		return "", ImportResult{}, http.StatusInternalServerError, err
+	}
+	result := state.ImportTransactions(txs)
+	if result.Added > 0 {
+		if err := state.Save(s.StatePath); err != nil {
+			return "", ImportResult{}, http.StatusInternalServerError, err
+		}
+	}
+	return header.Filename, result, http.StatusOK, nil
+}
+
+// filterSubscriptions applies the show, tag and q (name/description substring) query parameters
+// and sorts by the sort and dir query parameters
+func (s *Server) filterSubscriptions(subs []Subscription, q url.Values) []Subscription {
+	display := FilterByStatus(subs, queryOr(q, "show", "active"))
+	if tag := q.Get("tag"); tag != "" {
+		display = FilterByTags(display, []string{tag}, s.Config)
+	}
+	display = FilterByQuery(display, q.Get("q"), s.Config)
+	SortSubscriptions(display, queryOr(q, "sort", "name"), queryOr(q, "dir", "asc"), s.Config)
+	return display
+}
+
+func render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func queryOr(q url.Values, key, fallback string) string {
+	if v := q.Get(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	server := &Server{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Tolerance: 0.35,
+		Currency:  GetCurrency("USD"),
+	}
+	handler := server.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/"); !strings.Contains(rec.Body.String(), "No transactions imported yet") {
+		t.Errorf("expected empty state page, got: %s", rec.Body.String())
+	}
+
+	// Upload the sample export
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, uploadRequest(t, "/upload", "../testdata/sample.json"))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect after upload, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Location"), "Imported+27+new+transactions") {
+		t.Errorf("expected import message in redirect, got %s", rec.Header().Get("Location"))
+	}
+
+	page := get("/?sort=amount&dir=desc").Body.String()
+	spotify, netflix := strings.Index(page, ">Spotify<"), strings.Index(page, ">Netflix<")
+	if spotify == -1 || netflix == -1 || spotify > netflix {
+		t.Errorf("expected Spotify before Netflix when sorted by amount desc, got: %s", page)
+	}
+	if page := get("/?q=netf").Body.String(); strings.Contains(page, ">Spotify<") || !strings.Contains(page, ">Netflix<") {
+		t.Errorf("expected only Netflix when filtering by 'netf', got: %s", page)
+	}
+
+	detail := get("/subscriptions/spotify")
+	if detail.Code != http.StatusOK || strings.Count(detail.Body.String(), "<rect") != 12 {
+		t.Errorf("expected a chart with 12 payments, got %d: %s", detail.Code, detail.Body.String())
+	}
+	if rec := get("/subscriptions/unknown"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown subscription, got %d", rec.Code)
+	}
+}
+
+func TestServer_API(t *testing.T) {
+	server := &Server{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Tolerance: 0.35,
+		Currency:  GetCurrency("USD"),
+	}
+	handler := server.Handler()
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(uploadRequest(t, "/api/import", "../testdata/sample.json"))
+	var imported JSONImportResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &imported); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("import failed (%d): %s", rec.Code, rec.Body.String())
+	}
+	if imported.Added != 27 || imported.File != "sample.json" {
+		t.Errorf("expected 27 added from sample.json, got %+v", imported)
+	}
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/api/subscriptions?sort=amount&dir=desc", nil))
+	var subs JSONOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &subs); err != nil {
+		t.Fatalf("failed to parse subscriptions: %v\n%s", err, rec.Body.String())
+	}
+	if len(subs.Subscriptions) != 2 || subs.Subscriptions[0].Name != "Spotify" {
+		t.Errorf("expected Spotify and Netflix sorted by amount desc, got %+v", subs.Subscriptions)
+	}
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/api/subscriptions/netflix/transactions", nil))
+	var txs JSONTransactions
+	if err := json.Unmarshal(rec.Body.Bytes(), &txs); err != nil {
+		t.Fatalf("failed to parse transactions: %v\n%s", err, rec.Body.String())
+	}
+	if txs.Name != "Netflix" || len(txs.Transactions) != 12 || txs.Transactions[0].Date != "2025-01-15" || txs.Transactions[0].Amount != 99 {
+		t.Errorf("unexpected transactions response: %+v", txs)
+	}
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/api/subscriptions/unknown/transactions", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("expected JSON 404 for unknown subscription, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The format is detected from the contents when the extension is unknown
+	rec = serve(uploadRequest(t, "/api/import", "../testdata/sample.json", "notes.txt"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the JSON upload to be detected, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	notesPath := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notesPath, []byte("not transactions"), 0644)
+	rec = serve(uploadRequest(t, "/api/import", notesPath))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown file format, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServer_LeavesOutCommitments(t *testing.T) {
+	var txs []string
+	for month := 1; month <= 4; month++ {
+		txs = append(txs,
+			fmt.Sprintf(`{"date": "2025-%02d-01", "text": "HYRA BRF EKEN", "amount": -8500}`, month),
+			fmt.Sprintf(`{"date": "2025-%02d-15", "text": "Netflix", "amount": -99}`, month))
+	}
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [`+strings.Join(txs, ",")+`]}`), 0644)
+
+	server := &Server{StatePath: filepath.Join(t.TempDir(), "state.json"), Tolerance: 0.35, Currency: GetCurrency("USD")}
+	handler := server.Handler()
+	handler.ServeHTTP(httptest.NewRecorder(), uploadRequest(t, "/api/import", path))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/subscriptions", nil))
+	var subs JSONOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &subs); err != nil {
+		t.Fatalf("failed to parse subscriptions: %v\n%s", err, rec.Body.String())
+	}
+	if len(subs.Subscriptions) != 1 || subs.Summary.MonthlyTotal != 99 {
+		t.Errorf("expected only Netflix in the API and its totals, got %+v", subs)
+	}
+}
+
+// uploadRequest builds a multipart upload request for the file at path,
+// optionally under a different file name
+func uploadRequest(t *testing.T, target, path string, filename ...string) *http.Request {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Base(path)
+	if len(filename) > 0 {
+		name = filename[0]
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", name)
+	part.Write(data)
+	form.WriteField("format", "auto")
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestServer_Grafana(t *testing.T) {
+	server := &Server{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Tolerance: 0.35,
+		Currency:  GetCurrency("USD"),
+	}
+	handler := server.Handler()
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	serve(uploadRequest(t, "/api/import", "../testdata/sample.json"))
+
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/grafana/", nil)); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 from connection test, got %d", rec.Code)
+	}
+
+	query := `{"range": {"from": "2025-06-01T00:00:00Z", "to": "2025-12-31T00:00:00Z"},
+		"targets": [{"target": "monthly_cost", "refId": "A"}, {"target": "subscriptions", "refId": "B"}]}`
+	rec := serve(httptest.NewRequest(http.MethodPost, "/grafana/query", strings.NewReader(query)))
+	var response []json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || len(response) != 2 {
+		t.Fatalf("expected 2 query results, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var series grafanaTimeseries
+	json.Unmarshal(response[0], &series)
+	// June to December 2025: Netflix 99 + Spotify 119 in June, 99 + 129 afterwards
+	if len(series.Datapoints) != 7 || series.Datapoints[0][0] != 218 || series.Datapoints[6][0] != 228 {
+		t.Errorf("unexpected monthly cost series: %+v", series.Datapoints)
+	}
+
+	var table grafanaTable
+	json.Unmarshal(response[1], &table)
+	if table.Type != "table" || len(table.Rows) != 2 || table.Rows[0][0] != "Netflix" {
+		t.Errorf("unexpected subscriptions table: %+v", table)
+	}
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/api/monthly-cost", nil))
+	var monthly []JSONMonthlyCost
+	if err := json.Unmarshal(rec.Body.Bytes(), &monthly); err != nil || len(monthly) != 12 || monthly[0].Month != "2025-01" {
+		t.Errorf("unexpected monthly cost response: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Banks often put each month, year or account of an Excel export on its own sheet, so
+// spreadsheet parsers read all sheets of a workbook unless the parsing context restricts them
+// to some by name.
+
+type sheetsKey struct{}
+
+// WithSheets returns a context in which spreadsheet parsers only read the sheets with the given
+// names (case-insensitive). No names means all sheets.
+func WithSheets(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, sheetsKey{}, names)
+}
+
+// sheetsFrom returns the sheet names in ctx, nil for all sheets
+func sheetsFrom(ctx context.Context) []string {
+	names, _ := ctx.Value(sheetsKey{}).([]string)
+	return names
+}
+
+// selectSheets returns the sheets of a workbook to read, in workbook order: all of them, or
+// those named in ctx. It's an error if none of the named sheets exist.
+func selectSheets(ctx context.Context, sheets []string) ([]string, error) {
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("no sheets found in file")
+	}
+	names := sheetsFrom(ctx)
+	if len(names) == 0 {
+		return sheets, nil
+	}
+	var selected []string
+	for _, sheet := range sheets {
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSpace(name), sheet) {
+				selected = append(selected, sheet)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no sheet named %s (sheets: %s)", strings.Join(names, ", "), strings.Join(sheets, ", "))
+	}
+	return selected, nil
+}
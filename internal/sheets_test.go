@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSelectSheets(t *testing.T) {
+	sheets := []string{"Summary", "2024", "2025"}
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+		wantErr  bool
+	}{
+		{"all by default", nil, sheets, false},
+		{"by name, in workbook order", []string{"2025", "2024"}, []string{"2024", "2025"}, false},
+		{"case-insensitive", []string{"SUMMARY"}, []string{"Summary"}, false},
+		{"missing names are ignored", []string{"2025", "2023"}, []string{"2025"}, false},
+		{"none found", []string{"2023"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSheets(WithSheets(context.Background(), tt.names), sheets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectSheets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("selectSheets() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+	if _, err := selectSheets(context.Background(), nil); err == nil {
+		t.Error("expected an error for a workbook without sheets")
+	}
+}
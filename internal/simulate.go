@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Simulation is the effect of cancelling subscriptions on the monthly and yearly totals
+type Simulation struct {
+	Cancelled     []Subscription // active subscriptions matching the names to cancel
+	NotFound      []string       // names to cancel that match no active subscription
+	MonthlyBefore float64        // monthly total of all active subscriptions
+	MonthlyAfter  float64        // monthly total without the cancelled ones
+}
+
+// MonthlySavings returns what the cancellations save per month
+func (s Simulation) MonthlySavings() float64 {
+	return s.MonthlyBefore - s.MonthlyAfter
+}
+
+// SimulateCancellations recomputes the totals of subs without the active subscriptions whose
+// name contains any of names (case-insensitive). A name may cancel several subscriptions, e.g.
+// the same service paid by two household members.
+func SimulateCancellations(subs []Subscription, names []string) Simulation {
+	sim := Simulation{MonthlyBefore: ActiveMonthlyTotal(subs)}
+	cancelled := make(map[int]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for i, sub := range subs {
+			if sub.Status == StatusActive && strings.Contains(strings.ToLower(sub.Name), strings.ToLower(name)) {
+				cancelled[i] = true
+				found = true
+			}
+		}
+		if !found {
+			sim.NotFound = append(sim.NotFound, name)
+		}
+	}
+
+	var remaining []Subscription
+	for i, sub := range subs {
+		if cancelled[i] {
+			sim.Cancelled = append(sim.Cancelled, sub)
+		} else {
+			remaining = append(remaining, sub)
+		}
+	}
+	sim.MonthlyAfter = ActiveMonthlyTotal(remaining)
+	return sim
+}
+
+// PrintSimulationTable outputs the cancelled subscriptions with the totals before and after
+// cancelling them, and the change
+func PrintSimulationTable(w io.Writer, sim Simulation, opts OutputOptions) {
+	loc := opts.Locale
+	if len(sim.Cancelled) == 0 {
+		fmt.Fprintln(w, loc.T("No active subscriptions to cancel."))
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Cancel"), loc.T("Monthly"), loc.T("Yearly")})
+	for _, sub := range sim.Cancelled {
+		t.AppendRow(table.Row{sub.Name, opts.Currency.Format(sub.MonthlyCost()), opts.Currency.Format(sub.MonthlyCost() * 12)})
+	}
+	t.AppendSeparator()
+	t.AppendRow(table.Row{loc.T("Current total"), opts.Currency.Format(sim.MonthlyBefore), opts.Currency.Format(sim.MonthlyBefore * 12)})
+	t.AppendRow(table.Row{loc.T("After cancelling"), opts.Currency.Format(sim.MonthlyAfter), opts.Currency.Format(sim.MonthlyAfter * 12)})
+	t.AppendRow(table.Row{text.Bold.Sprint(loc.T("Change")), formatDelta(-sim.MonthlySavings(), opts.Currency), formatDelta(-sim.MonthlySavings()*12, opts.Currency)})
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{{Number: 2, Align: text.AlignRight}, {Number: 3, Align: text.AlignRight}})
+	t.Render()
+
+	fmt.Fprint(w, loc.Sprintf("\nCancelling %s saves %s/month (%s/year).\n",
+		loc.Sprintf("%d subscription(s)", len(sim.Cancelled)), opts.Currency.Format(sim.MonthlySavings()), opts.Currency.Format(sim.MonthlySavings()*12)))
+}
+
+// JSONSimulation is the JSON output format for the simulate subcommand
+type JSONSimulation struct {
+	SchemaVersion  int             `json:"schema_version"`
+	Cancelled      []JSONCancelled `json:"cancelled"`
+	NotFound       []string        `json:"not_found"`
+	MonthlyBefore  float64         `json:"monthly_before"`
+	MonthlyAfter   float64         `json:"monthly_after"`
+	MonthlySavings float64         `json:"monthly_savings"`
+	YearlyBefore   float64         `json:"yearly_before"`
+	YearlyAfter    float64         `json:"yearly_after"`
+	YearlySavings  float64         `json:"yearly_savings"`
+	Currency       string          `json:"currency"`
+}
+
+// JSONCancelled is the JSON output format for a cancelled subscription of a simulation
+type JSONCancelled struct {
+	Name    string  `json:"name"`
+	Monthly float64 `json:"monthly"`
+	Yearly  float64 `json:"yearly"`
+}
+
+// PrintSimulationJSON outputs a simulation in JSON format
+func PrintSimulationJSON(w io.Writer, sim Simulation, currency Currency) {
+	output := JSONSimulation{
+		SchemaVersion:  JSONSchemaVersion,
+		Cancelled:      []JSONCancelled{},
+		NotFound:       append([]string{}, sim.NotFound...),
+		MonthlyBefore:  currency.Round(sim.MonthlyBefore),
+		MonthlyAfter:   currency.Round(sim.MonthlyAfter),
+		MonthlySavings: currency.Round(sim.MonthlySavings()),
+		YearlyBefore:   currency.Round(sim.MonthlyBefore * 12),
+		YearlyAfter:    currency.Round(sim.MonthlyAfter * 12),
+		YearlySavings:  currency.Round(sim.MonthlySavings() * 12),
+		Currency:       currency.Code,
+	}
+	for _, sub := range sim.Cancelled {
+		output.Cancelled = append(output.Cancelled, JSONCancelled{
+			Name:    sub.Name,
+			Monthly: currency.Round(sub.MonthlyCost()),
+			Yearly:  currency.Round(sub.MonthlyCost() * 12),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestSimulateCancellations(t *testing.T) {
+	subs := []Subscription{
+		{Name: "NETFLIX.COM", Status: StatusActive, LatestAmount: -149},
+		{Name: "HBO MAX", Status: StatusActive, LatestAmount: -109},
+		{Name: "Spotify", Status: StatusActive, LatestAmount: -129},
+		{Name: "Gym", Status: StatusActive, LatestAmount: -3000, Interval: IntervalYearly},
+		{Name: "Hbo old", Status: StatusStopped, LatestAmount: -99},
+	}
+
+	sim := SimulateCancellations(subs, []string{"netflix", " hbo", "gym", "Disney", ""})
+	if len(sim.Cancelled) != 3 || sim.Cancelled[0].Name != "NETFLIX.COM" || sim.Cancelled[1].Name != "HBO MAX" || sim.Cancelled[2].Name != "Gym" {
+		t.Errorf("expected the active matches cancelled in order, got %+v", sim.Cancelled)
+	}
+	if len(sim.NotFound) != 1 || sim.NotFound[0] != "Disney" {
+		t.Errorf("expected Disney not found, got %v", sim.NotFound)
+	}
+	if sim.MonthlyBefore != 637 || sim.MonthlyAfter != 129 || sim.MonthlySavings() != 508 {
+		t.Errorf("unexpected totals: before %v, after %v", sim.MonthlyBefore, sim.MonthlyAfter)
+	}
+
+	var buf bytes.Buffer
+	PrintSimulationTable(&buf, sim, OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "After cancelling") || !strings.Contains(buf.String(), "Cancelling 3 subscription(s) saves") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	PrintSimulationTable(&buf, SimulateCancellations(subs, []string{"old"}), OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "No active subscriptions to cancel.") {
+		t.Errorf("expected stopped subscriptions not to be cancelled, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// State is the persisted history of detection runs (snapshots), lifecycle events and imported transactions
+type State struct {
+	Snapshots    []Snapshot          `json:"snapshots"`
+	Events       []Event             `json:"events,omitempty"`
+	Transactions []StoredTransaction `json:"transactions,omitempty"`
+}
+
+// Snapshot records the detected subscriptions of a single run
+type Snapshot struct {
+	Timestamp     time.Time              `json:"timestamp"`
+	DataEnd       string                 `json:"data_end"` // last transaction date in the analyzed data
+	Currency      string                 `json:"currency"`
+	Subscriptions []SnapshotSubscription `json:"subscriptions"`
+}
+
+// SnapshotSubscription is the persisted form of a detected subscription
+type SnapshotSubscription struct {
+	Name         string  `json:"name"`
+	Status       string  `json:"status"`
+	LatestAmount float64 `json:"latest_amount"`
+	StartDate    string  `json:"start_date"`
+	LastDate     string  `json:"last_date"`
+}
+
+// ChangeKind describes how a subscription changed between two snapshots
+type ChangeKind string
+
+const (
+	ChangeNew           ChangeKind = "new"
+	ChangeRemoved       ChangeKind = "removed" // no longer detected at all
+	ChangeStopped       ChangeKind = "stopped"
+	ChangeResumed       ChangeKind = "resumed"
+	ChangePriceIncrease ChangeKind = "price_increase"
+	ChangePriceDecrease ChangeKind = "price_decrease"
+)
+
+// SubscriptionChange is a single difference between two snapshots
+type SubscriptionChange struct {
+	Name      string
+	Kind      ChangeKind
+	OldAmount float64 // latest amount in the previous snapshot (0 for new subscriptions)
+	NewAmount float64 // latest amount in the current snapshot (0 for removed subscriptions)
+
+	// Plans of the service before and after a price change that is likely a plan change, e.g.
+	// "Individual" and "Family" (see AnnotatePlanChanges); "" otherwise
+	OldPlan string
+	NewPlan string
+}
+
+// ChangeReport holds the changes compared to a previous snapshot
+type ChangeReport struct {
+	Since   time.Time // timestamp of the snapshot compared against
+	Changes []SubscriptionChange
+}
+
+// DefaultStatePath returns the default state file path (~/.subscription-detector/state.json)
+func DefaultStatePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".subscription-detector", "state.json")
+}
+
+// LoadState reads the state file. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing state file: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file, creating parent directories if needed
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
+
+// LastSnapshot returns the most recently saved snapshot, or nil if there is none
+func (s *State) LastSnapshot() *Snapshot {
+	if len(s.Snapshots) == 0 {
+		return nil
+	}
+	return &s.Snapshots[len(s.Snapshots)-1]
+}
+
+// AddSnapshot appends a snapshot to the state
+func (s *State) AddSnapshot(snap Snapshot) {
+	s.Snapshots = append(s.Snapshots, snap)
+}
+
+// NewSnapshot creates a snapshot of the given subscriptions
+func NewSnapshot(subs []Subscription, dateRange DateRange, currency string, now time.Time) Snapshot {
+	snap := Snapshot{
+		Timestamp: now,
+		DataEnd:   dateRange.End.Format("2006-01-02"),
+		Currency:  currency,
+	}
+	for _, sub := range subs {
+		snap.Subscriptions = append(snap.Subscriptions, SnapshotSubscription{
+			Name:         sub.Name,
+			Status:       string(sub.Status),
+			LatestAmount: math.Abs(sub.LatestAmount),
+			StartDate:    sub.StartDate.Format("2006-01-02"),
+			LastDate:     sub.LastDate.Format("2006-01-02"),
+		})
+	}
+	sort.Slice(snap.Subscriptions, func(i, j int) bool {
+		return strings.ToLower(snap.Subscriptions[i].Name) < strings.ToLower(snap.Subscriptions[j].Name)
+	})
+	return snap
+}
+
+// CompareSnapshots returns the changes from prev to curr, sorted by name.
+// Subscriptions are matched by name (case-insensitive).
+func CompareSnapshots(prev, curr Snapshot) []SubscriptionChange {
+	prevByName := make(map[string]SnapshotSubscription)
+	for _, sub := range prev.Subscriptions {
+		prevByName[strings.ToLower(sub.Name)] = sub
+	}
+	currByName := make(map[string]SnapshotSubscription)
+	for _, sub := range curr.Subscriptions {
+		currByName[strings.ToLower(sub.Name)] = sub
+	}
+
+	var changes []SubscriptionChange
+	for key, sub := range currByName {
+		old, existed := prevByName[key]
+		if !existed {
+			changes = append(changes, SubscriptionChange{Name: sub.Name, Kind: ChangeNew, NewAmount: sub.LatestAmount})
+			continue
+		}
+
+		if old.Status != sub.Status {
+			kind := ChangeStopped
+			if sub.Status == string(StatusActive) {
+				kind = ChangeResumed
+			}
+			changes = append(changes, SubscriptionChange{Name: sub.Name, Kind: kind, NewAmount: sub.LatestAmount})
+		}
+
+		// Compare at cent precision to avoid float noise
+		if math.Round(old.LatestAmount*100) != math.Round(sub.LatestAmount*100) {
+			kind := ChangePriceIncrease
+			if sub.LatestAmount < old.LatestAmount {
+				kind = ChangePriceDecrease
+			}
+			changes = append(changes, SubscriptionChange{
+				Name:      sub.Name,
+				Kind:      kind,
+				OldAmount: old.LatestAmount,
+				NewAmount: sub.LatestAmount,
+			})
+		}
+	}
+	for key, old := range prevByName {
+		if _, exists := currByName[key]; !exists {
+			changes = append(changes, SubscriptionChange{Name: old.Name, Kind: ChangeRemoved, OldAmount: old.LatestAmount})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !strings.EqualFold(changes[i].Name, changes[j].Name) {
+			return strings.ToLower(changes[i].Name) < strings.ToLower(changes[j].Name)
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompareSnapshots(t *testing.T) {
+	prev := Snapshot{Subscriptions: []SnapshotSubscription{
+		{Name: "Netflix", Status: "active", LatestAmount: 99},
+		{Name: "Spotify", Status: "active", LatestAmount: 119},
+		{Name: "HBO Max", Status: "active", LatestAmount: 89},
+		{Name: "Gym", Status: "active", LatestAmount: 300},
+		{Name: "Old Service", Status: "stopped", LatestAmount: 50},
+	}}
+	curr := Snapshot{Subscriptions: []SnapshotSubscription{
+		{Name: "Netflix", Status: "active", LatestAmount: 99},
+		{Name: "SPOTIFY", Status: "active", LatestAmount: 129},
+		{Name: "HBO Max", Status: "stopped", LatestAmount: 89},
+		{Name: "Old Service", Status: "active", LatestAmount: 50},
+		{Name: "Disney+", Status: "active", LatestAmount: 119},
+	}}
+
+	changes := CompareSnapshots(prev, curr)
+
+	expected := []struct {
+		name string
+		kind ChangeKind
+	}{
+		{"Disney+", ChangeNew},
+		{"Gym", ChangeRemoved},
+		{"HBO Max", ChangeStopped},
+		{"Old Service", ChangeResumed},
+		{"SPOTIFY", ChangePriceIncrease},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+	for i, e := range expected {
+		if changes[i].Name != e.name || changes[i].Kind != e.kind {
+			t.Errorf("change %d: expected %s %s, got %s %s", i, e.name, e.kind, changes[i].Name, changes[i].Kind)
+		}
+	}
+	if changes[4].OldAmount != 119 || changes[4].NewAmount != 129 {
+		t.Errorf("expected price change 119 -> 129, got %.0f -> %.0f", changes[4].OldAmount, changes[4].NewAmount)
+	}
+}
+
+func TestState_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	// Missing file yields empty state
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if state.LastSnapshot() != nil {
+		t.Error("expected no snapshots in new state")
+	}
+
+	subs := []Subscription{{
+		Name: "Netflix", Status: StatusActive, LatestAmount: -99,
+		StartDate: date("2025-01-15"), LastDate: date("2025-06-15"),
+	}}
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	state.AddSnapshot(NewSnapshot(subs, DateRange{End: date("2025-06-20")}, "SEK", now))
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	last := loaded.LastSnapshot()
+	if last == nil || len(last.Subscriptions) != 1 {
+		t.Fatalf("expected 1 snapshot with 1 subscription, got %+v", loaded)
+	}
+	if !last.Timestamp.Equal(now) || last.DataEnd != "2025-06-20" {
+		t.Errorf("unexpected snapshot metadata: %+v", last)
+	}
+	if last.Subscriptions[0].LatestAmount != 99 {
+		t.Errorf("expected absolute latest amount 99, got %.0f", last.Subscriptions[0].LatestAmount)
+	}
+}
+
+func TestState_ImportTransactions(t *testing.T) {
+	day := func(d string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", d)
+		return parsed
+	}
+	first := []Transaction{
+		{Date: day("2025-01-05"), Text: "Netflix", Amount: -99},
+		{Date: day("2025-01-10"), Text: "Coffee", Amount: -35},
+		{Date: day("2025-01-10"), Text: "Coffee", Amount: -35},
+	}
+	var state State
+	result := state.ImportTransactions(first)
+	if result.Added != 3 || result.Duplicates != 0 {
+		t.Fatalf("first import: expected 3 added, 0 duplicates, got %+v", result)
+	}
+
+	// Overlapping export: one new coffee on the same day and a new month
+	second := []Transaction{
+		{Date: day("2025-01-10"), Text: "Coffee", Amount: -35},
+		{Date: day("2025-01-10"), Text: "Coffee", Amount: -35},
+		{Date: day("2025-01-10"), Text: "Coffee", Amount: -35},
+		{Date: day("2025-02-05"), Text: "Netflix", Amount: -99},
+		{Date: day("2025-01-05"), Text: "Netflix", Amount: -99},
+	}
+	result = state.ImportTransactions(second)
+	if result.Added != 2 || result.Duplicates != 3 {
+		t.Errorf("second import: expected 2 added, 3 duplicates, got %+v", result)
+	}
+
+	txs, err := state.StoredTransactions()
+	if err != nil {
+		t.Fatalf("StoredTransactions() error = %v", err)
+	}
+	if len(txs) != 5 {
+		t.Fatalf("expected 5 stored transactions, got %d", len(txs))
+	}
+	if !txs[len(txs)-1].Date.Equal(day("2025-02-05")) {
+		t.Errorf("expected stored transactions sorted by date, got last %v", txs[len(txs)-1].Date)
+	}
+}
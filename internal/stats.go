@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// MonthStats summarizes the raw transactions of a calendar month
+type MonthStats struct {
+	Month        time.Time // first day of the month (UTC)
+	Transactions int
+	Expenses     float64 // sum of negative amounts, as a positive number
+	Income       float64 // sum of positive amounts
+	Complete     bool    // see AnalyzeDataCoverage
+}
+
+// PayeeStats summarizes the expenses to a single payee (case-insensitive transaction text)
+type PayeeStats struct {
+	Text     string
+	Count    int
+	Expenses float64 // sum of expenses, as a positive number
+}
+
+// DataGap is a period without any transactions, which may indicate a missing export
+type DataGap struct {
+	From time.Time // date of the last transaction before the gap
+	To   time.Time // date of the first transaction after the gap
+	Days int
+}
+
+// DatasetStats describes a set of raw transactions, before any detection
+type DatasetStats struct {
+	Transactions int
+	Range        DateRange
+	Expenses     float64
+	Income       float64
+	Months       []MonthStats // every month in the range, including months without transactions
+	TopPayees    []PayeeStats // payees with the largest expenses
+	Gaps         []DataGap    // periods of at least gapDays days without transactions
+}
+
+// ComputeStats computes dataset statistics: totals and transaction counts per month,
+// the top payees by expenses and gaps of at least gapDays days without transactions
+func ComputeStats(txs []Transaction, topPayees int, gapDays int) DatasetStats {
+	stats := DatasetStats{Transactions: len(txs)}
+	if len(txs) == 0 {
+		return stats
+	}
+
+	completeMonths, dateRange := AnalyzeDataCoverage(txs)
+	stats.Range = dateRange
+	complete := make(map[string]bool)
+	for _, m := range completeMonths {
+		complete[m] = true
+	}
+
+	// Every month in the range, so months without transactions show up
+	monthIndex := make(map[string]int)
+	endMonth := time.Date(dateRange.End.Year(), dateRange.End.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for m := time.Date(dateRange.Start.Year(), dateRange.Start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(endMonth); m = m.AddDate(0, 1, 0) {
+		key := m.Format("2006-01")
+		monthIndex[key] = len(stats.Months)
+		stats.Months = append(stats.Months, MonthStats{Month: m, Complete: complete[key]})
+	}
+
+	payees := make(map[string]*PayeeStats)
+	for _, tx := range txs {
+		month := &stats.Months[monthIndex[tx.Date.Format("2006-01")]]
+		month.Transactions++
+		if tx.Amount < 0 {
+			month.Expenses -= tx.Amount
+			stats.Expenses -= tx.Amount
+
+			key := foldPayee(tx.Text)
+			p, ok := payees[key]
+			if !ok {
+				p = &PayeeStats{Text: tx.Text}
+				payees[key] = p
+			}
+			p.Count++
+			p.Expenses -= tx.Amount
+		} else {
+			month.Income += tx.Amount
+			stats.Income += tx.Amount
+		}
+	}
+
+	for _, p := range payees {
+		stats.TopPayees = append(stats.TopPayees, *p)
+	}
+	sort.Slice(stats.TopPayees, func(i, j int) bool {
+		if stats.TopPayees[i].Expenses != stats.TopPayees[j].Expenses {
+			return stats.TopPayees[i].Expenses > stats.TopPayees[j].Expenses
+		}
+		return strings.ToLower(stats.TopPayees[i].Text) < strings.ToLower(stats.TopPayees[j].Text)
+	})
+	if topPayees >= 0 && len(stats.TopPayees) > topPayees {
+		stats.TopPayees = stats.TopPayees[:topPayees]
+	}
+
+	stats.Gaps = findDataGaps(txs, gapDays)
+	return stats
+}
+
+// findDataGaps returns the periods of at least gapDays days between consecutive transaction dates
+func findDataGaps(txs []Transaction, gapDays int) []DataGap {
+	if gapDays <= 0 {
+		return nil
+	}
+	dates := make([]time.Time, len(txs))
+	for i, tx := range txs {
+		dates[i] = tx.Date
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var gaps []DataGap
+	for i := 1; i < len(dates); i++ {
+		days := int(math.Round(dates[i].Sub(dates[i-1]).Hours() / 24))
+		if days >= gapDays {
+			gaps = append(gaps, DataGap{From: dates[i-1], To: dates[i], Days: days})
+		}
+	}
+	return gaps
+}
+
+// PrintStatsTable outputs dataset statistics as an overview followed by tables
+// for months, top payees and data gaps
+func PrintStatsTable(w io.Writer, stats DatasetStats, opts OutputOptions) {
+	loc := opts.Locale
+	if stats.Transactions == 0 {
+		fmt.Fprintln(w, loc.T("No transactions."))
+		return
+	}
+
+	completeCount := 0
+	for _, m := range stats.Months {
+		if m.Complete {
+			completeCount++
+		}
+	}
+	fmt.Fprint(w, loc.Sprintf("Transactions: %d\n", stats.Transactions))
+	fmt.Fprint(w, loc.Sprintf("Date range: %s to %s (%d months, %d complete)\n",
+		loc.FormatDate(stats.Range.Start), loc.FormatDate(stats.Range.End), len(stats.Months), completeCount))
+	fmt.Fprint(w, loc.Sprintf("Expenses: %s\n", opts.Currency.Format(stats.Expenses)))
+	fmt.Fprint(w, loc.Sprintf("Income: %s\n", opts.Currency.Format(stats.Income)))
+	fmt.Fprint(w, loc.Sprintf("Net: %s\n\n", opts.Currency.Format(stats.Income-stats.Expenses)))
+
+	mt := table.NewWriter()
+	mt.SetOutputMirror(w)
+	mt.AppendHeader(table.Row{loc.T("Month"), loc.T("Transactions"), loc.T("Expenses"), loc.T("Income"), ""})
+	for _, m := range stats.Months {
+		note := ""
+		switch {
+		case m.Transactions == 0:
+			note = text.FgRed.Sprint(loc.T("no transactions"))
+		case !m.Complete:
+			note = text.FgYellow.Sprint(loc.T("incomplete"))
+		}
+		mt.AppendRow(table.Row{m.Month.Format("2006-01"), m.Transactions, opts.Currency.Format(m.Expenses), opts.Currency.Format(m.Income), note})
+	}
+	styleTable(mt, opts)
+	mt.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 2, Align: text.AlignRight},
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, Align: text.AlignRight},
+	})
+	mt.Render()
+
+	if len(stats.TopPayees) > 0 {
+		fmt.Fprintln(w)
+		pt := table.NewWriter()
+		pt.SetOutputMirror(w)
+		pt.AppendHeader(table.Row{loc.T("Payee"), loc.T("Transactions"), loc.T("Expenses")})
+		for _, p := range stats.TopPayees {
+			pt.AppendRow(table.Row{p.Text, p.Count, opts.Currency.Format(p.Expenses)})
+		}
+		styleTable(pt, opts)
+		pt.SetColumnConfigs([]table.ColumnConfig{
+			{Number: 2, Align: text.AlignRight},
+			{Number: 3, Align: text.AlignRight},
+		})
+		pt.Render()
+	}
+
+	if len(stats.Gaps) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, loc.T("Gaps without transactions (missing exports?):"))
+		for _, g := range stats.Gaps {
+			fmt.Fprint(w, loc.Sprintf("  %s to %s (%d days)\n", loc.FormatDate(g.From), loc.FormatDate(g.To), g.Days))
+		}
+	}
+}
+
+// JSONStats is the JSON output format for the stats subcommand
+type JSONStats struct {
+	SchemaVersion int              `json:"schema_version"`
+	Transactions  int              `json:"transactions"`
+	Start         string           `json:"start,omitempty"`
+	End           string           `json:"end,omitempty"`
+	Expenses      float64          `json:"expenses"`
+	Income        float64          `json:"income"`
+	Months        []JSONMonthStats `json:"months"`
+	TopPayees     []JSONPayeeStats `json:"top_payees"`
+	Gaps          []JSONDataGap    `json:"gaps"`
+	Currency      string           `json:"currency"`
+}
+
+// JSONMonthStats is the JSON output format for a month of raw transactions
+type JSONMonthStats struct {
+	Month        string  `json:"month"` // YYYY-MM
+	Transactions int     `json:"transactions"`
+	Expenses     float64 `json:"expenses"`
+	Income       float64 `json:"income"`
+	Complete     bool    `json:"complete"`
+}
+
+// JSONPayeeStats is the JSON output format for a payee's expenses
+type JSONPayeeStats struct {
+	Text     string  `json:"text"`
+	Count    int     `json:"count"`
+	Expenses float64 `json:"expenses"`
+}
+
+// JSONDataGap is the JSON output format for a period without transactions
+type JSONDataGap struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Days int    `json:"days"`
+}
+
+// PrintStatsJSON outputs dataset statistics in JSON format
+func PrintStatsJSON(w io.Writer, stats DatasetStats, currency Currency) {
+	output := JSONStats{
+		SchemaVersion: JSONSchemaVersion,
+		Transactions:  stats.Transactions,
+		Expenses:      stats.Expenses,
+		Income:        stats.Income,
+		Months:        []JSONMonthStats{},
+		TopPayees:     []JSONPayeeStats{},
+		Gaps:          []JSONDataGap{},
+		Currency:      currency.Code,
+	}
+	if stats.Transactions > 0 {
+		output.Start = stats.Range.Start.Format("2006-01-02")
+		output.End = stats.Range.End.Format("2006-01-02")
+	}
+	for _, m := range stats.Months {
+		output.Months = append(output.Months, JSONMonthStats{
+			Month:        m.Month.Format("2006-01"),
+			Transactions: m.Transactions,
+			Expenses:     m.Expenses,
+			Income:       m.Income,
+			Complete:     m.Complete,
+		})
+	}
+	for _, p := range stats.TopPayees {
+		output.TopPayees = append(output.TopPayees, JSONPayeeStats{Text: p.Text, Count: p.Count, Expenses: p.Expenses})
+	}
+	for _, g := range stats.Gaps {
+		output.Gaps = append(output.Gaps, JSONDataGap{From: g.From.Format("2006-01-02"), To: g.To.Format("2006-01-02"), Days: g.Days})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
@@ -0,0 +1,43 @@
+package internal
+
+import "testing"
+
+func TestComputeStats(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-05"), Text: "Salary", Amount: 30000},
+		{Date: date("2025-01-10"), Text: "ICA", Amount: -500},
+		{Date: date("2025-01-20"), Text: "ica", Amount: -300},
+		{Date: date("2025-01-25"), Text: "Netflix", Amount: -99},
+		// February missing
+		{Date: date("2025-03-10"), Text: "Netflix", Amount: -99},
+		{Date: date("2025-03-20"), Text: "ICA", Amount: -200},
+		{Date: date("2025-03-31"), Text: "Refund", Amount: 50},
+	}
+
+	stats := ComputeStats(txs, 1, 21)
+
+	if stats.Transactions != 7 || stats.Expenses != 1198 || stats.Income != 30050 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if len(stats.Months) != 3 {
+		t.Fatalf("expected 3 months including empty February, got %d", len(stats.Months))
+	}
+	if feb := stats.Months[1]; feb.Transactions != 0 || feb.Month.Format("2006-01") != "2025-02" {
+		t.Errorf("expected empty February, got %+v", feb)
+	}
+	if jan := stats.Months[0]; jan.Transactions != 4 || jan.Expenses != 899 || jan.Income != 30000 {
+		t.Errorf("unexpected January: %+v", jan)
+	}
+	if !stats.Months[2].Complete {
+		t.Error("expected March to be complete (data until the last day)")
+	}
+
+	// Payees are grouped case-insensitively and limited to the top N
+	if len(stats.TopPayees) != 1 || stats.TopPayees[0].Text != "ICA" || stats.TopPayees[0].Count != 3 || stats.TopPayees[0].Expenses != 1000 {
+		t.Errorf("unexpected top payees: %+v", stats.TopPayees)
+	}
+
+	if len(stats.Gaps) != 1 || stats.Gaps[0].Days != 44 || stats.Gaps[0].From != date("2025-01-25") {
+		t.Errorf("expected one 44-day gap after January 25, got %+v", stats.Gaps)
+	}
+}
@@ -0,0 +1,452 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Built-in detection strategy names, as used in the config's strategies list
+const (
+	StrategyKnownPatterns = "known-patterns"
+	StrategyMonthly       = "monthly"
+	StrategyQuarterly     = "quarterly"
+	StrategyAnnual        = "annual"
+	StrategyVariable      = "variable"
+)
+
+// Strategy is a detection heuristic. Strategies run in a chain: each one is given the payees that
+// earlier strategies didn't claim, and claims the payees of the transactions in the subscriptions
+// it returns. Strategies report why they passed over a payee with GroupedTransactions.Reject.
+type Strategy interface {
+	Name() string
+	Match(groups *GroupedTransactions) []Subscription
+}
+
+// GroupedTransactions is the input of a strategy: expenses grouped by payee, and the settings and
+// dates to evaluate them with
+type GroupedTransactions struct {
+	Payees         []PayeeGroup
+	DateRange      DateRange
+	AsOf           time.Time // date that subscription status is evaluated against
+	Config         *Config   // may be nil
+	Tolerance      float64   // max price change between consecutive payments
+	MinOccurrences int       // payments required for pattern detection
+	GraceDays      int       // days after an expected payment before a subscription is stopped
+
+	observer Observer        // receives rejections (may be nil)
+	strategy string          // name of the running strategy, for rejections
+	gaps     map[string]bool // months without data that status ignores (see WithIgnoreDataGaps)
+}
+
+// setDataGaps sets the months without data that subscription status ignores
+func (in *GroupedTransactions) setDataGaps(months []string) {
+	in.gaps = make(map[string]bool, len(months))
+	for _, m := range months {
+		in.gaps[m] = true
+	}
+}
+
+// bridgeGaps returns the month of a last payment, moved forward past the months without data
+// that follow it: payments that would have been in those months are missing with the data, not
+// because the subscription stopped. Only the month of the result is meaningful.
+func (in *GroupedTransactions) bridgeGaps(lastPayment time.Time) time.Time {
+	month := time.Date(lastPayment.Year(), lastPayment.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for in.gaps[month.AddDate(0, 1, 0).Format("2006-01")] {
+		month = month.AddDate(0, 1, 0)
+	}
+	return month
+}
+
+// PayeeGroup holds the expenses of one payee (compared ignoring case and diacritics), sorted by date
+type PayeeGroup struct {
+	Key          string        // folded payee (see payeeKey)
+	Name         string        // display name (the most recent spelling)
+	Transactions []Transaction // all expenses, including the current (incomplete) month
+	Complete     []Transaction // expenses in complete months (see WithPartialMonths), used for pattern checks
+}
+
+// StrategyNames returns the names of the built-in strategies
+func StrategyNames() []string {
+	return []string{StrategyKnownPatterns, StrategyMonthly, StrategyQuarterly, StrategyAnnual, StrategyVariable}
+}
+
+// StrategyByName returns the built-in strategy with the given name
+func StrategyByName(name string) (Strategy, error) {
+	switch name {
+	case StrategyKnownPatterns:
+		return KnownPatternsStrategy{}, nil
+	case StrategyMonthly:
+		return IntervalStrategy{Interval: IntervalMonthly}, nil
+	case StrategyQuarterly:
+		return IntervalStrategy{Interval: IntervalQuarterly}, nil
+	case StrategyAnnual:
+		return IntervalStrategy{Interval: IntervalYearly}, nil
+	case StrategyVariable:
+		return VariableStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q (available: %s)", name, strings.Join(StrategyNames(), ", "))
+}
+
+// groupByPayee groups the expenses in transactions by payee. The display name of a payee is its
+// last spelling in transactions (income included).
+func groupByPayee(transactions []Transaction, completeMonths []string) []PayeeGroup {
+	complete := make(map[string]bool)
+	for _, m := range completeMonths {
+		complete[m] = true
+	}
+
+	byKey := make(map[string]*PayeeGroup)
+	var keys []string
+	for _, tx := range transactions {
+		key := payeeKey(tx)
+		g := byKey[key]
+		if g == nil {
+			g = &PayeeGroup{Key: key}
+			byKey[key] = g
+			keys = append(keys, key)
+		}
+		g.Name = tx.Text
+		if tx.Amount >= 0 {
+			continue
+		}
+		g.Transactions = append(g.Transactions, tx)
+		if complete[billingMonth(tx).Format("2006-01")] {
+			g.Complete = append(g.Complete, tx)
+		}
+	}
+
+	sort.Strings(keys)
+	groups := make([]PayeeGroup, 0, len(keys))
+	for _, key := range keys {
+		g := byKey[key]
+		if len(g.Transactions) == 0 {
+			continue
+		}
+		sortByDate(g.Transactions)
+		sortByDate(g.Complete)
+		groups = append(groups, *g)
+	}
+	return groups
+}
+
+// payeeKey identifies the payee of a transaction: its folded text (see foldPayee), prefixed by
+// the person for labeled exports so that each household member's payments are detected separately
+func payeeKey(tx Transaction) string {
+	if tx.Person != "" {
+		return tx.Person + "\x00" + foldPayee(tx.Text)
+	}
+	return foldPayee(tx.Text)
+}
+
+// foldPayee returns a payee text case-folded and without diacritics, so that spellings such as
+// "Försäkring", "FÖRSÄKRING" and "Forsakring" (or the same text in decomposed Unicode) are the
+// same payee
+func foldPayee(text string) string {
+	ascii := true
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return strings.ToLower(text)
+	}
+	// Transformers keep state, so the chain is built for each call
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), cases.Fold(), norm.NFC), text)
+	if err != nil {
+		return strings.ToLower(text)
+	}
+	return folded
+}
+
+func sortByDate(txs []Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Date.Before(txs[j].Date) })
+}
+
+// claimedPayees returns the payee keys of the transactions in subs
+func claimedPayees(subs []Subscription) map[string]bool {
+	claimed := make(map[string]bool)
+	for _, sub := range subs {
+		for _, tx := range sub.Transactions {
+			claimed[payeeKey(tx)] = true
+		}
+	}
+	return claimed
+}
+
+// statusOrder orders statuses for sortSubscriptions: active, possible, then stopped
+var statusOrder = map[SubscriptionStatus]int{StatusActive: 0, StatusPossible: 1, StatusStopped: 2}
+
+// sortSubscriptions orders subscriptions active first, then by amount (highest first)
+func sortSubscriptions(subs []Subscription) {
+	sort.SliceStable(subs, func(i, j int) bool {
+		if subs[i].Status != subs[j].Status {
+			return statusOrder[subs[i].Status] < statusOrder[subs[j].Status]
+		}
+		return math.Abs(subs[i].AvgAmount) > math.Abs(subs[j].AvgAmount)
+	})
+}
+
+// newSubscription builds a subscription from the transactions used for pattern checks (stats)
+// and all of its transactions (lifecycle). Both must be sorted by date.
+func newSubscription(name string, stats, all []Transaction, interval Interval, in *GroupedTransactions) Subscription {
+	minAmount, maxAmount := CalculateAmountRange(stats)
+	typicalDay := CalculateTypicalDay(stats)
+	last := all[len(all)-1]
+	return Subscription{
+		Name:         name,
+		AvgAmount:    CalculateAverageAmount(stats),
+		LatestAmount: last.Amount,
+		MinAmount:    minAmount,
+		MaxAmount:    maxAmount,
+		TotalPaid:    CalculateTotalPaid(all),
+		Transactions: all,
+		StartDate:    all[0].Date,
+		LastDate:     last.Date,
+		TypicalDay:   typicalDay,
+		Interval:     interval,
+		Status:       determineStatus(in.bridgeGaps(last.Date), typicalDay, interval, in.GraceDays, in.AsOf),
+		Person:       last.Person,
+	}
+}
+
+// KnownPatternsStrategy detects the known subscriptions from the config, even from a single
+// payment and including the current (incomplete) month. With the config's
+// KnownRequireRecurrence, those with a single payment are only possible subscriptions.
+type KnownPatternsStrategy struct{}
+
+func (KnownPatternsStrategy) Name() string { return StrategyKnownPatterns }
+
+func (KnownPatternsStrategy) Match(in *GroupedTransactions) []Subscription {
+	if in.Config == nil || len(in.Config.Known) == 0 {
+		return nil
+	}
+
+	// Group matching transactions by the known subscription pattern (per person)
+	byPattern := make(map[string][]Transaction)
+	knownBy := make(map[string]*KnownSubscription)
+	var patterns []string
+	for _, payee := range in.Payees {
+		for _, tx := range payee.Transactions {
+			known := in.Config.MatchesKnown(tx)
+			if known == nil {
+				continue
+			}
+			key := tx.Person + "\x00" + known.Pattern
+			if _, ok := byPattern[key]; !ok {
+				patterns = append(patterns, key)
+				knownBy[key] = known
+			}
+			byPattern[key] = append(byPattern[key], tx)
+		}
+	}
+
+	var subscriptions []Subscription
+	for _, pattern := range patterns {
+		txs := byPattern[pattern]
+		sortByDate(txs)
+		// Use the most recent transaction text as the display name
+		sub := newSubscription(txs[len(txs)-1].Text, txs, txs, knownInterval(knownBy[pattern], txs), in)
+		if len(txs) == 1 && in.Config.KnownRequireRecurrence {
+			sub.Status = StatusPossible
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	sortSubscriptions(subscriptions)
+	return subscriptions
+}
+
+// knownInterval returns the billing interval of a known subscription: the one configured for it,
+// else the typical gap between its payments if that is a quarter or a year, else monthly
+func knownInterval(known *KnownSubscription, txs []Transaction) Interval {
+	if known.interval > 0 {
+		return known.interval
+	}
+	switch gap := Interval(medianMonthGap(txs)); gap {
+	case IntervalQuarterly, IntervalYearly:
+		return gap
+	}
+	return IntervalMonthly
+}
+
+// processorPrefix matches the prefix payment processors put before the merchant in transaction
+// texts (e.g., "PAYPAL *DISNEYPLUS", "SQ *CAFE")
+var processorPrefix = regexp.MustCompile(`(?i)^(PAYPAL|PP|SQ|SUMUP|ZETTLE|IZ|KLARNA)\s*\*\s*`)
+
+// compactName returns a payee name without a payment processor prefix, spaces and punctuation
+// (e.g., "PAYPAL *Disney Plus" becomes "DisneyPlus"), to match known patterns against
+func compactName(name string) string {
+	name = processorPrefix.ReplaceAllString(name, "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// mergeKnownDuplicates merges subscriptions detected by pattern that are the same service as a
+// known subscription under a different text, e.g. "Disney Plus" paid directly and
+// "PAYPAL *DISNEYPLUS" matching the DISNEYPLUS pattern, so the service isn't listed twice. A
+// subscription is a duplicate if the known pattern matches its compacted name (see compactName)
+// and it has no payments in the same billing months, which would make them separate subscriptions.
+func mergeKnownDuplicates(subs []Subscription, in *GroupedTransactions) []Subscription {
+	if in.Config == nil || len(in.Config.Known) == 0 {
+		return subs
+	}
+	known := make([]*KnownSubscription, len(subs))
+	for i, sub := range subs {
+		known[i] = in.Config.MatchesKnown(sub.Transactions[len(sub.Transactions)-1])
+	}
+
+	merged := make([]bool, len(subs))
+	for i := range subs {
+		if known[i] == nil || known[i].regex == nil {
+			continue
+		}
+		for j := range subs {
+			other := subs[j]
+			if known[j] != nil || merged[j] || other.Person != subs[i].Person || !known[i].regex.MatchString(compactName(other.Name)) || sharesBillingMonth(subs[i].Transactions, other.Transactions) {
+				continue
+			}
+			txs := append(slices.Clone(subs[i].Transactions), other.Transactions...)
+			sortByDate(txs)
+			strategy := subs[i].Strategy
+			subs[i] = newSubscription(txs[len(txs)-1].Text, txs, txs, knownInterval(known[i], txs), in)
+			subs[i].Strategy = strategy
+			merged[j] = true
+		}
+	}
+
+	result := subs[:0]
+	for i, sub := range subs {
+		if !merged[i] {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// sharesBillingMonth reports whether a and b have payments in the same billing month
+func sharesBillingMonth(a, b []Transaction) bool {
+	months := make(map[int]bool, len(a))
+	for _, tx := range a {
+		months[monthIndex(billingMonth(tx))] = true
+	}
+	return slices.ContainsFunc(b, func(tx Transaction) bool { return months[monthIndex(billingMonth(tx))] })
+}
+
+// IntervalStrategy detects payees paid at most once per calendar month, with amounts within the
+// tolerance of each other. For intervals longer than a month, the typical (median) gap between
+// payments must equal the interval; the monthly strategy accepts any remaining such payee, so
+// longer intervals must run before it in the chain. The monthly strategy also accepts payees
+// with a much larger yearly charge on top (see matchWithAnnualCharges).
+type IntervalStrategy struct {
+	Interval Interval
+}
+
+func (s IntervalStrategy) Name() string {
+	switch s.Interval {
+	case IntervalMonthly:
+		return StrategyMonthly
+	case IntervalQuarterly:
+		return StrategyQuarterly
+	case IntervalYearly:
+		return StrategyAnnual
+	}
+	return fmt.Sprintf("every-%d-months", s.Interval)
+}
+
+func (s IntervalStrategy) Match(in *GroupedTransactions) []Subscription {
+	var subscriptions []Subscription
+	for _, payee := range in.Payees {
+		// Need at least minOccurrences payments in complete months to be a subscription
+		if len(payee.Complete) < in.MinOccurrences {
+			in.Reject(payee, RejectionTooFewOccurrences, "%d payments in complete months (need %d)", len(payee.Complete), in.MinOccurrences)
+			continue
+		}
+		// A monthly payment may come with a much larger yearly charge, such as an annual fee
+		if s.Interval == IntervalMonthly {
+			if sub, ok := matchWithAnnualCharges(payee, in); ok {
+				subscriptions = append(subscriptions, sub)
+				continue
+			}
+		}
+		// If there are ever 2+ payments in any month, it's not a subscription
+		if !IsMonthlyPattern(payee.Transactions) {
+			in.Reject(payee, RejectionMultipleInMonth, "more than one payment in a month")
+			continue
+		}
+		if gap := medianMonthGap(payee.Transactions); s.Interval != IntervalMonthly && gap != int(s.Interval) {
+			in.Reject(payee, RejectionIntervalMismatch, "typical gap of %d months between payments (need %d)", gap, s.Interval)
+			continue
+		}
+		// Check if amounts are within tolerance of each other (using complete months data)
+		if !AmountsWithinTolerance(payee.Complete, in.Tolerance) {
+			in.Reject(payee, RejectionToleranceExceeded, "amounts change more than %.0f%% between payments", in.Tolerance*100)
+			continue
+		}
+		subscriptions = append(subscriptions, newSubscription(payee.Name, payee.Complete, payee.Transactions, s.Interval, in))
+	}
+	sortSubscriptions(subscriptions)
+	return subscriptions
+}
+
+// variableMinPayments is the minimum number of payments for variable-amount subscriptions
+const variableMinPayments = 3
+
+// VariableStrategy detects monthly bills with varying amounts (e.g., electricity or phone bills):
+// one payment in every complete month from the first to the last, at least 3 of them, regardless
+// of how much the amounts differ
+type VariableStrategy struct{}
+
+func (VariableStrategy) Name() string { return StrategyVariable }
+
+func (VariableStrategy) Match(in *GroupedTransactions) []Subscription {
+	var subscriptions []Subscription
+	for _, payee := range in.Payees {
+		if need := max(in.MinOccurrences, variableMinPayments); len(payee.Complete) < need {
+			in.Reject(payee, RejectionTooFewOccurrences, "%d payments in complete months (need %d)", len(payee.Complete), need)
+			continue
+		}
+		if !IsMonthlyPattern(payee.Transactions) {
+			in.Reject(payee, RejectionMultipleInMonth, "more than one payment in a month")
+			continue
+		}
+		first, last := billingMonth(payee.Complete[0]), billingMonth(payee.Complete[len(payee.Complete)-1])
+		if monthIndex(last)-monthIndex(first)+1 != len(payee.Complete) {
+			in.Reject(payee, RejectionMissingMonth, "a month without a payment")
+			continue
+		}
+		subscriptions = append(subscriptions, newSubscription(payee.Name, payee.Complete, payee.Transactions, IntervalMonthly, in))
+	}
+	sortSubscriptions(subscriptions)
+	return subscriptions
+}
+
+// medianMonthGap returns the median gap in months (billing cycles for credit card transactions)
+// between consecutive payments (0 for fewer than 2 payments). txs must be sorted by date.
+func medianMonthGap(txs []Transaction) int {
+	if len(txs) < 2 {
+		return 0
+	}
+	gaps := make([]int, 0, len(txs)-1)
+	for i := 1; i < len(txs); i++ {
+		gaps = append(gaps, monthIndex(billingMonth(txs[i]))-monthIndex(billingMonth(txs[i-1])))
+	}
+	slices.Sort(gaps)
+	return gaps[(len(gaps)-1)/2]
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// payeeStrategy claims a single payee as a monthly subscription
+type payeeStrategy struct{ payee string }
+
+func (s payeeStrategy) Name() string { return "payee" }
+
+func (s payeeStrategy) Match(in *GroupedTransactions) []Subscription {
+	for _, payee := range in.Payees {
+		if payee.Name == s.payee {
+			return []Subscription{newSubscription(payee.Name, payee.Transactions, payee.Transactions, IntervalMonthly, in)}
+		}
+	}
+	return nil
+}
+
+func TestStrategyChain(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2024-03-05"), Text: "Domain", Amount: -150},
+		{Date: date("2025-03-05"), Text: "Domain", Amount: -150},
+		{Date: date("2025-01-28"), Text: "Power Co", Amount: -400},
+		{Date: date("2025-02-27"), Text: "Power Co", Amount: -900},
+		{Date: date("2025-03-28"), Text: "Power Co", Amount: -650},
+		{Date: date("2025-01-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-02-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-03-20"), Text: "Gym", Amount: -300},
+		{Date: date("2025-04-15"), Text: "Other", Amount: -10}, // just to set date range
+	}
+	analyze := func(t *testing.T, cfg *Config, opts ...DetectorOption) map[string]Subscription {
+		t.Helper()
+		subs, _, err := NewDetector(opts...).Analyze(context.Background(), txs, cfg)
+		if err != nil {
+			t.Fatalf("Analyze: %v", err)
+		}
+		byName := make(map[string]Subscription)
+		for _, sub := range subs {
+			byName[sub.Name] = sub
+		}
+		return byName
+	}
+
+	// Default chain: known patterns and monthly; Power Co varies too much
+	subs := analyze(t, nil)
+	if _, ok := subs["Power Co"]; ok || subs["Domain"].Interval != IntervalMonthly || len(subs) != 2 {
+		t.Errorf("unexpected default detection: %+v", subs)
+	}
+
+	cfg, err := parseConfig([]byte("strategies: [annual, monthly, variable]\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	subs = analyze(t, cfg)
+	if subs["Domain"].Interval != IntervalYearly || subs["Gym"].Interval != IntervalMonthly || len(subs) != 3 {
+		t.Errorf("expected Domain (annual), Gym (monthly) and Power Co (variable), got %+v", subs)
+	}
+
+	// Order matters: monthly claims the yearly payee before annual sees it
+	cfg, _ = parseConfig([]byte("strategies: [monthly, annual]\n"))
+	if subs = analyze(t, cfg); subs["Domain"].Interval != IntervalMonthly {
+		t.Errorf("expected monthly to claim Domain first, got %+v", subs["Domain"])
+	}
+
+	// WithStrategies overrides the config; a claimed payee is not matched again
+	subs = analyze(t, cfg, WithStrategies(payeeStrategy{"Gym"}, IntervalStrategy{Interval: IntervalYearly}))
+	if len(subs) != 2 || subs["Domain"].Interval != IntervalYearly || subs["Gym"].Name != "Gym" {
+		t.Errorf("unexpected custom chain result: %+v", subs)
+	}
+
+	for _, bad := range []string{"strategies: [weekly]\n", "strategies: [monthly, monthly]\n"} {
+		if _, err := parseConfig([]byte(bad)); err == nil || !strings.Contains(err.Error(), "strategy") {
+			t.Errorf("expected a strategy error for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestStrategyByName(t *testing.T) {
+	for _, name := range StrategyNames() {
+		strategy, err := StrategyByName(name)
+		if err != nil || strategy.Name() != name {
+			t.Errorf("StrategyByName(%q) = %v, %v", name, strategy, err)
+		}
+	}
+}
+
+func TestMergeKnownDuplicates(t *testing.T) {
+	cfg, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	detect := func(t *testing.T, txs []Transaction) []Subscription {
+		t.Helper()
+		txs = append(txs, Transaction{Date: date("2025-06-30"), Text: "Other", Amount: -10}) // just to set date range
+		subs, _, err := NewDetector().Analyze(context.Background(), txs, cfg)
+		if err != nil {
+			t.Fatalf("Analyze: %v", err)
+		}
+		return subs
+	}
+
+	// Paid directly, then through PayPal (matching the DISNEYPLUS pattern)
+	subs := detect(t, []Transaction{
+		{Date: date("2025-01-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-02-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-03-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-04-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+		{Date: date("2025-05-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+		{Date: date("2025-06-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+	})
+	if len(subs) != 1 || subs[0].Name != "PAYPAL *DISNEYPLUS" || len(subs[0].Transactions) != 6 || subs[0].Status != StatusActive {
+		t.Errorf("expected one merged Disney subscription, got %+v", subs)
+	}
+
+	// Paid through PayPal, then directly
+	subs = detect(t, []Transaction{
+		{Date: date("2025-01-10"), Text: "PAYPAL *DISNEY PLUS", Amount: -109},
+		{Date: date("2025-02-10"), Text: "PAYPAL *DISNEY PLUS", Amount: -109},
+		{Date: date("2025-03-10"), Text: "PAYPAL *DISNEY PLUS", Amount: -109},
+		{Date: date("2025-04-12"), Text: "DISNEYPLUS.COM", Amount: -119},
+		{Date: date("2025-05-12"), Text: "DISNEYPLUS.COM", Amount: -119},
+	})
+	if len(subs) != 1 || subs[0].Name != "DISNEYPLUS.COM" || len(subs[0].Transactions) != 5 || !subs[0].StartDate.Equal(date("2025-01-10")) {
+		t.Errorf("expected one merged Disney subscription, got %+v", subs)
+	}
+
+	// Payments in the same months are separate subscriptions (e.g., two accounts)
+	subs = detect(t, []Transaction{
+		{Date: date("2025-04-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-05-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-06-10"), Text: "Disney Plus", Amount: -109},
+		{Date: date("2025-04-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+		{Date: date("2025-05-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+		{Date: date("2025-06-12"), Text: "PAYPAL *DISNEYPLUS", Amount: -119},
+	})
+	if len(subs) != 2 {
+		t.Errorf("expected two Disney subscriptions, got %+v", subs)
+	}
+}
+
+func TestCompactName(t *testing.T) {
+	for name, want := range map[string]string{
+		"PAYPAL *Disney Plus": "DisneyPlus",
+		"SQ *CAFE NERO":       "CAFENERO",
+		"Disney+ (Sweden)":    "Disney+Sweden",
+		"Spotify AB":          "SpotifyAB",
+	} {
+		if got := compactName(name); got != want {
+			t.Errorf("compactName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFoldPayee(t *testing.T) {
+	same := [][]string{
+		{"Telia", "TELIA", "telia"},
+		{"Försäkring", "FÖRSÄKRING", "Forsakring", "Försäkring"},
+		{"Café Strauß", "CAFE STRAUSS"},
+	}
+	for _, spellings := range same {
+		for _, s := range spellings[1:] {
+			if foldPayee(s) != foldPayee(spellings[0]) {
+				t.Errorf("expected %q and %q to be the same payee (%q vs %q)", spellings[0], s, foldPayee(spellings[0]), foldPayee(s))
+			}
+		}
+	}
+	if foldPayee("Telia") == foldPayee("Tele2") {
+		t.Error("expected Telia and Tele2 to be different payees")
+	}
+}
+
+func TestGroupByPayee_Diacritics(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-25"), Text: "LÄNSFÖRSÄKRINGAR", Amount: -412},
+		{Date: date("2025-02-25"), Text: "Lansforsakringar", Amount: -412},
+		{Date: date("2025-03-25"), Text: "Länsförsäkringar", Amount: -412},
+	}
+	groups := groupByPayee(txs, []string{"2025-01", "2025-02", "2025-03"})
+	if len(groups) != 1 || len(groups[0].Transactions) != 3 || groups[0].Name != "Länsförsäkringar" {
+		t.Errorf("expected one payee with the latest spelling, got %+v", groups)
+	}
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+)
+
+// TransactionStream folds transactions into per-payee aggregates as they are added, so that
+// detection on very large exports doesn't need all transactions in memory. A payee keeps at most
+// one expense per month, plus one that is annualChargeFactor times larger or smaller (a possible
+// yearly charge on a monthly subscription, see splitAnnualCharges); once it has another payment in
+// a month it can't be a pattern-detected subscription, and only its known-pattern matches (plus
+// the two payments proving it isn't monthly) are kept. The result is the same as Detector.Analyze on the same transactions, except
+// that custom strategies only see these retained transactions.
+type TransactionStream struct {
+	detector *Detector
+	config   *Config
+	payees   map[string]*payeeAggregate
+	usage    *configUsage // config entries the transactions went through, with a config
+	months   map[int]bool // months with any transactions
+	later    bool         // transactions after the detector's clock were ignored
+	count    int
+	start    time.Time
+	end      time.Time
+}
+
+// payeeAggregate is what a TransactionStream keeps of a payee
+type payeeAggregate struct {
+	name    string              // display name (the most recent spelling)
+	months  map[int]Transaction // the expense of each month, while there is at most one per month
+	extra   map[int]Transaction // a much larger or smaller second expense of a month, if any
+	witness []Transaction       // two expenses in the same month, once there are
+	known   []Transaction       // expenses matching known patterns, once there are two in a month
+}
+
+// Stream starts a streaming detection with the given config (may be nil). Add transactions in
+// any order, then call Detect.
+func (d *Detector) Stream(cfg *Config) *TransactionStream {
+	s := &TransactionStream{detector: d, config: cfg, payees: make(map[string]*payeeAggregate), months: make(map[int]bool)}
+	if cfg != nil {
+		s.usage = newConfigUsage(cfg)
+	}
+	return s
+}
+
+// Add folds a transaction into the stream, applying the config's renames, groups and amount
+// bands. Transactions after the date of the detector's clock are ignored (see WithClock).
+func (s *TransactionStream) Add(tx Transaction) {
+	if s.usage != nil {
+		s.usage.add(tx)
+	}
+	if s.detector.clock != nil && tx.Date.After(day(s.detector.clock())) {
+		s.later = true
+		return
+	}
+	if s.config != nil {
+		grouped, _ := s.config.ApplyGroups([]Transaction{tx})
+		tx = grouped[0]
+	}
+
+	if s.count == 0 || tx.Date.Before(s.start) {
+		s.start = tx.Date
+	}
+	if s.count == 0 || tx.Date.After(s.end) {
+		s.end = tx.Date
+	}
+	s.count++
+	s.months[monthIndex(tx.Date)] = true
+
+	key := payeeKey(tx)
+	p := s.payees[key]
+	if p == nil {
+		p = &payeeAggregate{months: make(map[int]Transaction), extra: make(map[int]Transaction)}
+		s.payees[key] = p
+	}
+	p.name = tx.Text
+	if tx.Amount >= 0 {
+		return
+	}
+
+	if p.witness != nil {
+		if s.config.MatchesKnown(tx) != nil {
+			p.known = append(p.known, tx)
+		}
+		return
+	}
+	month := monthIndex(billingMonth(tx))
+	prev, ok := p.months[month]
+	if !ok {
+		p.months[month] = tx
+		return
+	}
+	if _, ok := p.extra[month]; !ok && annualChargeRatio(prev, tx) {
+		p.extra[month] = tx
+		return
+	}
+
+	// A second payment in a month: keep only what known patterns and the monthly check need
+	p.witness = []Transaction{prev, tx}
+	for _, m := range p.months {
+		if s.config.MatchesKnown(m) != nil {
+			p.known = append(p.known, m)
+		}
+	}
+	for _, m := range p.extra {
+		if s.config.MatchesKnown(m) != nil {
+			p.known = append(p.known, m)
+		}
+	}
+	if s.config.MatchesKnown(tx) != nil {
+		p.known = append(p.known, tx)
+	}
+	p.months, p.extra = nil, nil
+}
+
+// annualChargeRatio reports whether one of two expenses is at least annualChargeFactor times the
+// other, so the larger one may be a yearly charge billed in the same month as a monthly payment
+func annualChargeRatio(a, b Transaction) bool {
+	small, large := math.Abs(a.Amount), math.Abs(b.Amount)
+	if small > large {
+		small, large = large, small
+	}
+	return large >= small*annualChargeFactor
+}
+
+// Len returns the number of transactions added
+func (s *TransactionStream) Len() int {
+	return s.count
+}
+
+// Coverage returns the complete months and the date range of the transactions added so far
+func (s *TransactionStream) Coverage() ([]string, DateRange) {
+	if s.count == 0 {
+		return nil, DateRange{}
+	}
+	end := s.end
+	if s.later {
+		end = day(s.detector.clock()) // the data continues after the clock's date
+	}
+	return completeMonthsBetween(s.start, end), DateRange{Start: s.start, End: end}
+}
+
+// UnusedConfigEntries returns the config entries that matched none of the transactions added so
+// far, including those after the detector's clock (see UnusedConfigEntries)
+func (s *TransactionStream) UnusedConfigEntries() []UnusedEntry {
+	if s.usage == nil {
+		return nil
+	}
+	return s.usage.unused()
+}
+
+// MissingMonths returns the months without transactions among those added so far (see
+// MissingMonths)
+func (s *TransactionStream) MissingMonths() []string {
+	return missingMonths(s.months)
+}
+
+// Detect runs the strategy chain on the aggregated payees and applies the config's exclusions.
+// It returns ctx.Err() if the context is cancelled.
+func (s *TransactionStream) Detect(ctx context.Context) ([]Subscription, error) {
+	completeMonths, dateRange := s.Coverage()
+	complete := make(map[string]bool)
+	for _, m := range s.detector.patternMonths(completeMonths, dateRange) {
+		complete[m] = true
+	}
+
+	keys := make([]string, 0, len(s.payees))
+	for key := range s.payees {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var payees []PayeeGroup
+	for _, key := range keys {
+		g := s.payees[key].group(key)
+		if len(g.Transactions) == 0 {
+			continue
+		}
+		for _, tx := range g.Transactions {
+			if complete[billingMonth(tx).Format("2006-01")] {
+				g.Complete = append(g.Complete, tx)
+			}
+		}
+		payees = append(payees, g)
+	}
+
+	d := s.detector
+	in := d.input(payees, dateRange, s.config)
+	if d.ignoreGaps {
+		in.setDataGaps(s.MissingMonths())
+	}
+	subscriptions, err := d.runChain(ctx, d.chain(s.config), in)
+	if err != nil {
+		return nil, err
+	}
+	return applyShares(d.exclude(subscriptions, s.config), s.config), nil
+}
+
+// group returns the retained expenses of the payee, sorted by date
+func (p *payeeAggregate) group(key string) PayeeGroup {
+	g := PayeeGroup{Key: key, Name: p.name}
+	if p.witness == nil {
+		for _, tx := range p.months {
+			g.Transactions = append(g.Transactions, tx)
+		}
+		for _, tx := range p.extra {
+			g.Transactions = append(g.Transactions, tx)
+		}
+	} else {
+		g.Transactions = append(g.Transactions, p.known...)
+		for _, tx := range p.witness {
+			if !slices.Contains(p.known, tx) {
+				g.Transactions = append(g.Transactions, tx)
+			}
+		}
+	}
+	sortByDate(g.Transactions)
+	return g
+}
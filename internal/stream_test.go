@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionStream(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-03-15"), Text: "NETFLIX.COM", Amount: -99},
+		{Date: date("2025-01-15"), Text: "Netflix.com", Amount: -99},
+		{Date: date("2025-02-15"), Text: "NETFLIX.COM", Amount: -99},
+		{Date: date("2025-02-20"), Text: "NETFLIX.COM", Amount: -49}, // second Netflix payment in a month
+		{Date: date("2025-01-10"), Text: "Grocery", Amount: -150},
+		{Date: date("2025-01-25"), Text: "Grocery", Amount: -300},
+		{Date: date("2025-02-12"), Text: "Grocery", Amount: -200},
+		{Date: date("2025-01-20"), Text: "GYM 123", Amount: -300},
+		{Date: date("2025-02-20"), Text: "GYM 456", Amount: -300},
+		{Date: date("2025-03-20"), Text: "GYM 789", Amount: -300},
+		{Date: date("2025-04-20"), Text: "GYM 789", Amount: -300}, // current month
+		{Date: date("2025-01-25"), Text: "Employer", Amount: 30000},
+		{Date: date("2025-02-05"), Text: "Old Service", Amount: -50},
+		{Date: date("2025-03-05"), Text: "Old Service", Amount: -50},
+		{Date: date("2025-04-22"), Text: "Other", Amount: -10},
+	}
+	cfg, err := parseConfig([]byte("groups:\n  - name: Gym\n    patterns: [\"GYM \\\\d+\"]\nexclude: [Old Service]\n"))
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+
+	d := NewDetector()
+	expected, expectedRange, err := d.Analyze(context.Background(), txs, cfg)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	stream := d.Stream(cfg)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	if stream.Len() != len(txs) {
+		t.Errorf("expected %d transactions, got %d", len(txs), stream.Len())
+	}
+	if _, dateRange := stream.Coverage(); dateRange != expectedRange {
+		t.Errorf("expected date range %v, got %v", expectedRange, dateRange)
+	}
+	subs, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if len(subs) != 2 || !reflect.DeepEqual(subs, expected) {
+		t.Errorf("streaming detection differs from Analyze:\n got %+v\nwant %+v", subs, expected)
+	}
+
+	// Payees with more than one payment in a month only keep what detection needs
+	if p := stream.payees["grocery"]; p.months != nil || len(p.witness) != 2 || len(p.known) != 0 {
+		t.Errorf("expected only a witness for Grocery, got %+v", p)
+	}
+	if p := stream.payees["netflix.com"]; len(p.known) != 4 {
+		t.Errorf("expected all 4 known Netflix payments to be kept, got %+v", p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := stream.Detect(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTransactionStream_AnnualCharges(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-05"), Text: "Card Plus", Amount: -29},
+		{Date: date("2025-02-05"), Text: "Card Plus", Amount: -29},
+		{Date: date("2025-03-05"), Text: "Card Plus", Amount: -29},
+		{Date: date("2025-03-06"), Text: "Card Plus", Amount: -495}, // yearly fee
+		{Date: date("2025-04-05"), Text: "Card Plus", Amount: -29},
+		{Date: date("2025-05-05"), Text: "Card Plus", Amount: -29},
+		{Date: date("2025-01-10"), Text: "Grocery", Amount: -100},
+		{Date: date("2025-01-20"), Text: "Grocery", Amount: -400}, // much larger, but then a third
+		{Date: date("2025-01-25"), Text: "Grocery", Amount: -120},
+		{Date: date("2025-06-20"), Text: "Other", Amount: -10},
+	}
+
+	d := NewDetector()
+	expected, _, err := d.Analyze(context.Background(), txs, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	stream := d.Stream(nil)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	subs, err := stream.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if len(subs) != 1 || len(subs[0].AnnualCharges) != 1 || !reflect.DeepEqual(subs, expected) {
+		t.Errorf("streaming detection differs from Analyze:\n got %+v\nwant %+v", subs, expected)
+	}
+
+	// A much larger payment is kept beside the month's payment, but not a third one
+	if p := stream.payees["card plus"]; len(p.months) != 5 || len(p.extra) != 1 || p.witness != nil {
+		t.Errorf("expected the yearly fee kept for Card Plus, got %+v", p)
+	}
+	if p := stream.payees["grocery"]; p.months != nil || p.extra != nil || len(p.witness) != 2 {
+		t.Errorf("expected only a witness for Grocery, got %+v", p)
+	}
+}
+
+func TestStreamSimpleJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txs.json")
+	os.WriteFile(path, []byte(`{"source": {"bank": "x"}, "transactions": [
+		{"date": "2025-01-15", "text": "Netflix", "amount": -99, "account": "Checking"},
+		{"date": "2025-02-15", "text": "Netflix", "amount": -99}
+	]}`), 0644)
+
+	var streamed []Transaction
+	err := StreamFile(context.Background(), "simple-json", path, func(tx Transaction) error {
+		streamed = append(streamed, tx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamFile: %v", err)
+	}
+	parsed, _ := ParseSimpleJSON(context.Background(), path)
+	if !reflect.DeepEqual(streamed, parsed) {
+		t.Errorf("streamed %+v, parsed %+v", streamed, parsed)
+	}
+
+	os.WriteFile(path, []byte(`{"transactions": [{"date": "15/01/2025", "text": "Netflix", "amount": -99}]}`), 0644)
+	err = StreamFile(context.Background(), "simple-json", path, func(Transaction) error { return nil })
+	if perr, ok := err.(*ParseError); !ok || perr.File != path {
+		t.Errorf("expected a ParseError for %s, got %v", path, err)
+	}
+}
@@ -1,10 +1,17 @@
 package internal
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"io"
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
 )
 
 // GroupSuggestion represents a suggested grouping of transactions
@@ -28,17 +35,16 @@ func SuggestGroups(txs []Transaction, tolerance float64) []GroupSuggestion {
 		byName[tx.Text] = append(byName[tx.Text], tx)
 	}
 
-	// Find names that appear only 1-2 times (not enough for subscription detection alone)
-	// These are candidates for grouping
-	var orphanNames []string
-	for name, txList := range byName {
-		if len(txList) <= 2 {
-			orphanNames = append(orphanNames, name)
-		}
+	// All names are candidates, also those that already recur on their own, so vendors with
+	// some variants detected and others not (e.g., "SPOTIFY" and "SPOTIFY P3A8AC") are caught
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
 	}
+	sort.Strings(names) // map order is random; keep suggestions and their names stable
 
-	// Try to find common prefixes among orphan names
-	prefixGroups := findPrefixGroups(orphanNames, byName)
+	// Try to find common prefixes among the names
+	prefixGroups := findPrefixGroups(names, byName)
 
 	// Filter to only groups that look like subscriptions
 	var suggestions []GroupSuggestion
@@ -53,14 +59,39 @@ func SuggestGroups(txs []Transaction, tolerance float64) []GroupSuggestion {
 
 	// Sort by number of months (descending)
 	sort.Slice(suggestions, func(i, j int) bool {
-		return suggestions[i].MonthCount > suggestions[j].MonthCount
+		if suggestions[i].MonthCount != suggestions[j].MonthCount {
+			return suggestions[i].MonthCount > suggestions[j].MonthCount
+		}
+		return suggestions[i].Prefix < suggestions[j].Prefix
 	})
 
 	return suggestions
 }
 
-// findPrefixGroups groups transaction names by common prefixes
+// findPrefixGroups groups transaction names by common prefixes: the names with variable suffixes
+// stripped (see variantStem), the longest common prefixes of names starting with the same token
+// (see commonPrefixes), and their first words or characters
 func findPrefixGroups(names []string, txsByName map[string][]Transaction) []GroupSuggestion {
+	// Stems and common prefixes are strong signals, so two names are enough for them. They are
+	// matched case-insensitively, like group patterns, and spelled as in the first name.
+	stemPrefixes := make(map[string][]string)
+	stemSpellings := make(map[string]string)
+	addStem := func(prefix string, names ...string) {
+		key := strings.ToUpper(prefix)
+		if _, ok := stemSpellings[key]; !ok {
+			stemSpellings[key] = prefix
+		}
+		stemPrefixes[key] = append(stemPrefixes[key], names...)
+	}
+	for _, name := range names {
+		if stem := variantStem(name); stem != "" {
+			addStem(stem, name)
+		}
+	}
+	for _, common := range commonPrefixes(names) {
+		addStem(common.prefix, common.names...)
+	}
+
 	// Track word-based vs character-based prefixes separately
 	wordPrefixes := make(map[string][]string)  // word-based prefixes (preferred)
 	charPrefixes := make(map[string][]string)  // character-based prefixes (fallback)
@@ -91,9 +122,18 @@ func findPrefixGroups(names []string, txsByName map[string][]Transaction) []Grou
 		}
 	}
 
-	// Combine: word prefixes first (sorted by length), then char prefixes
+	// Combine: stems first, then word prefixes (sorted by length), then char prefixes
 	var sortedPrefixes []string
 
+	var stemKeys []string
+	for k := range stemPrefixes {
+		stemKeys = append(stemKeys, k)
+	}
+	sort.Strings(stemKeys)
+	for _, k := range stemKeys {
+		sortedPrefixes = append(sortedPrefixes, stemSpellings[k])
+	}
+
 	var wordKeys []string
 	for k := range wordPrefixes {
 		wordKeys = append(wordKeys, k)
@@ -120,26 +160,28 @@ func findPrefixGroups(names []string, txsByName map[string][]Transaction) []Grou
 
 	// Merge the maps for lookup
 	prefixCandidates := make(map[string][]string)
+	minNames := make(map[string]int)
+	for k, v := range stemPrefixes {
+		prefixCandidates[stemSpellings[k]] = v
+		minNames[stemSpellings[k]] = 2
+	}
 	for k, v := range wordPrefixes {
-		prefixCandidates[k] = v
+		prefixCandidates[k] = append(prefixCandidates[k], v...)
 	}
 	for k, v := range charPrefixes {
 		prefixCandidates[k] = append(prefixCandidates[k], v...)
 	}
 
-	// Convert to GroupSuggestions, only keeping groups with 3+ unique names
+	// Convert to GroupSuggestions, only keeping groups with 3+ unique names (2+ for stems)
 	var groups []GroupSuggestion
 	seen := make(map[string]bool) // avoid duplicate suggestions
 
 	for _, prefix := range sortedPrefixes {
-		matchedNames := prefixCandidates[prefix]
-		if len(matchedNames) < 3 {
-			continue
-		}
+		required := cmp.Or(minNames[prefix], 3)
 
 		// Deduplicate names
-		uniqueNames := uniqueStrings(matchedNames)
-		if len(uniqueNames) < 3 {
+		uniqueNames := uniqueStrings(prefixCandidates[prefix])
+		if len(uniqueNames) < required {
 			continue
 		}
 
@@ -180,6 +222,95 @@ func findPrefixGroups(names []string, txsByName map[string][]Transaction) []Grou
 	return groups
 }
 
+// variantStem strips what varies between the charges of one vendor from the end of a payee name:
+// words with digits (references, order or store numbers like "P3A8AC" or "1001"), digits glued
+// to the last word and trailing punctuation, e.g. "SPOTIFY" for "SPOTIFY P3A8AC". Returns "" if
+// nothing is stripped or less than 3 characters remain.
+func variantStem(name string) string {
+	stem := strings.TrimRightFunc(name, isSeparator)
+	for {
+		i := strings.LastIndexFunc(stem, isSeparator)
+		if i < 0 {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(stem[i:])
+		if !strings.ContainsFunc(stem[i+size:], unicode.IsDigit) {
+			break
+		}
+		stem = strings.TrimRightFunc(stem[:i], isSeparator)
+	}
+	stem = strings.TrimRightFunc(strings.TrimRightFunc(stem, unicode.IsDigit), isSeparator)
+	if len(stem) < 3 || stem == name {
+		return ""
+	}
+	return stem
+}
+
+// commonPrefix is a prefix shared by several payee names
+type commonPrefix struct {
+	prefix string
+	names  []string
+}
+
+// commonPrefixes counts how many names start with each token (word of letters and digits) and
+// returns the longest common prefix of the names of each token shared by more than one, compared
+// case-insensitively and cut back to a whole token, e.g. "GOOGLE *GSUITE" for "GOOGLE *GSUITE
+// 1001" and "Google *GSuite_". names must be sorted.
+func commonPrefixes(names []string) []commonPrefix {
+	byToken := make(map[string][]string)
+	var tokens []string
+	for _, name := range names {
+		fields := strings.FieldsFunc(name, isSeparator)
+		if len(fields) == 0 {
+			continue
+		}
+		token := strings.ToUpper(fields[0])
+		if _, ok := byToken[token]; !ok {
+			tokens = append(tokens, token)
+		}
+		byToken[token] = append(byToken[token], name)
+	}
+
+	var prefixes []commonPrefix
+	for _, token := range tokens {
+		shared := byToken[token]
+		if len(shared) < 2 {
+			continue
+		}
+		if prefix := longestCommonPrefix(shared); len(prefix) >= 3 {
+			prefixes = append(prefixes, commonPrefix{prefix: prefix, names: shared})
+		}
+	}
+	return prefixes
+}
+
+// longestCommonPrefix returns the longest case-insensitive common prefix of names, spelled as in
+// the first one and cut back to the end of a token
+func longestCommonPrefix(names []string) string {
+	prefix := []rune(names[0])
+	for _, name := range names[1:] {
+		other := []rune(name)
+		n := 0
+		for n < len(prefix) && n < len(other) && unicode.ToUpper(prefix[n]) == unicode.ToUpper(other[n]) {
+			n++
+		}
+		// Don't end in the middle of a token, e.g. "SPOTIFY P" for "SPOTIFY P1" and "SPOTIFY P2"
+		midToken := n < len(other) && !isSeparator(other[n]) || n < len(prefix) && !isSeparator(prefix[n])
+		if n > 0 && midToken && !isSeparator(prefix[n-1]) {
+			for n > 0 && !isSeparator(prefix[n-1]) {
+				n--
+			}
+		}
+		prefix = prefix[:n]
+	}
+	return strings.TrimRightFunc(string(prefix), isSeparator)
+}
+
+// isSeparator reports whether r separates the tokens of a payee name (anything but letters and digits)
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
 // isLikelySubscription checks if transactions look like a subscription
 func isLikelySubscription(txs []Transaction, tolerance float64) bool {
 	if len(txs) < 3 {
@@ -222,7 +353,10 @@ func deduplicateSuggestions(suggestions []GroupSuggestion) []GroupSuggestion {
 
 	// Sort by prefix length (shorter first) so we prefer cleaner names
 	sort.Slice(suggestions, func(i, j int) bool {
-		return len(suggestions[i].Prefix) < len(suggestions[j].Prefix)
+		if len(suggestions[i].Prefix) != len(suggestions[j].Prefix) {
+			return len(suggestions[i].Prefix) < len(suggestions[j].Prefix)
+		}
+		return suggestions[i].Prefix < suggestions[j].Prefix
 	})
 
 	var result []GroupSuggestion
@@ -263,26 +397,26 @@ func uniqueStrings(strs []string) []string {
 }
 
 // PrintGroupSuggestions displays suggested groups in a user-friendly format
-func PrintGroupSuggestions(suggestions []GroupSuggestion) {
+func PrintGroupSuggestions(w io.Writer, suggestions []GroupSuggestion) {
 	if len(suggestions) == 0 {
-		fmt.Println("No group suggestions found.")
+		fmt.Fprintln(w, "No group suggestions found.")
 		return
 	}
 
-	fmt.Printf("Found %d potential group(s):\n\n", len(suggestions))
+	fmt.Fprintf(w, "Found %d potential group(s):\n\n", len(suggestions))
 
 	for _, s := range suggestions {
-		fmt.Printf("  \"%s\" (%d months, %d transactions)\n", s.Prefix, s.MonthCount, len(s.Transactions))
-		fmt.Printf("    Names: %s\n", strings.Join(truncateStrings(s.Names, 3), ", "))
+		fmt.Fprintf(w, "  \"%s\" (%d months, %d transactions)\n", s.Prefix, s.MonthCount, len(s.Transactions))
+		fmt.Fprintf(w, "    Names: %s\n", strings.Join(truncateStrings(s.Names, 3), ", "))
 		if len(s.Names) > 3 {
-			fmt.Printf("           ... and %d more\n", len(s.Names)-3)
+			fmt.Fprintf(w, "           ... and %d more\n", len(s.Names)-3)
 		}
-		fmt.Println()
-		fmt.Println("    Add to config:")
-		fmt.Printf("      - name: \"%s\"\n", s.Prefix)
-		fmt.Println("        patterns:")
-		fmt.Printf("          - \"%s\"\n", s.Pattern)
-		fmt.Println()
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "    Add to config:")
+		fmt.Fprintf(w, "      - name: \"%s\"\n", s.Prefix)
+		fmt.Fprintln(w, "        patterns:")
+		fmt.Fprintf(w, "          - \"%s\"\n", s.Pattern)
+		fmt.Fprintln(w)
 	}
 }
 
@@ -293,3 +427,67 @@ func truncateStrings(strs []string, n int) []string {
 	}
 	return strs[:n]
 }
+
+// Group returns the config group that the suggestion proposes
+func (s GroupSuggestion) Group() Group {
+	return Group{Name: s.Prefix, Patterns: []string{s.Pattern}}
+}
+
+// JSONGroupSuggestion is a group suggestion in JSON and YAML output
+type JSONGroupSuggestion struct {
+	Prefix       string   `json:"prefix" yaml:"prefix"`
+	Pattern      string   `json:"pattern" yaml:"pattern"`
+	Names        []string `json:"names" yaml:"names"`
+	Months       int      `json:"months" yaml:"months"`
+	Transactions int      `json:"transactions" yaml:"transactions"`
+
+	// Samples are the latest transactions of the suggestion (see suggestionSamples)
+	Samples []JSONSuggestionSample `json:"samples" yaml:"samples"`
+}
+
+// JSONSuggestionSample is a transaction of a group suggestion in JSON and YAML output
+type JSONSuggestionSample struct {
+	Date   string  `json:"date" yaml:"date"`
+	Text   string  `json:"text" yaml:"text"`
+	Amount float64 `json:"amount" yaml:"amount"`
+}
+
+// suggestionSamples is the number of transactions listed per suggestion in JSON and YAML output
+const suggestionSamples = 3
+
+// JSONGroupSuggestions is the JSON and YAML output of --suggest-groups
+type JSONGroupSuggestions struct {
+	Suggestions []JSONGroupSuggestion `json:"suggestions" yaml:"suggestions"`
+}
+
+// WriteGroupSuggestions writes suggested groups as JSON or YAML (format "json" or "yaml")
+func WriteGroupSuggestions(w io.Writer, suggestions []GroupSuggestion, format string) error {
+	output := JSONGroupSuggestions{Suggestions: []JSONGroupSuggestion{}}
+	for _, s := range suggestions {
+		samples := []JSONSuggestionSample{}
+		txs := sortedByDate(s.Transactions)
+		for _, tx := range txs[max(len(txs)-suggestionSamples, 0):] {
+			samples = append(samples, JSONSuggestionSample{Date: tx.Date.Format("2006-01-02"), Text: tx.Text, Amount: tx.Amount})
+		}
+		output.Suggestions = append(output.Suggestions, JSONGroupSuggestion{
+			Prefix:       s.Prefix,
+			Pattern:      s.Pattern,
+			Names:        s.Names,
+			Months:       s.MonthCount,
+			Transactions: len(s.Transactions),
+			Samples:      samples,
+		})
+	}
+
+	if format == "yaml" {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(output); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(output)
+}
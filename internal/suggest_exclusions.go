@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ExclusionSuggestion is a detected subscription that looks like a false positive, such as rent,
+// a loan payment or a transfer to savings, with the exclude rule that removes it
+type ExclusionSuggestion struct {
+	Subscription Subscription
+	Pattern      string
+	Reasons      []string
+}
+
+// transferKeywords are words in payment texts of rent, loans and transfers between own accounts
+// (English and Swedish), which recur monthly like subscriptions but aren't
+var transferKeywords = regexp.MustCompile(`(?i)(^|[^\pL])(rent|hyra|loan|lån|bolån|mortgage|amortering|amortization|interest|ränta|savings?|sparande|sparkonto|transfer|överföring|överf|own account|eget konto|isk|standing order)([^\pL]|$)`)
+
+// largeFactor is how many times the median subscription's monthly cost a payment must be to
+// count as very large
+const largeFactor = 5
+
+// SuggestExclusions returns the subscriptions that look like false positives: texts typical of
+// rent, loans and transfers between own accounts, or very large amounts (compared to the other
+// subscriptions) that are round in every payment. Suggestions are sorted by monthly cost, highest
+// first.
+func SuggestExclusions(subs []Subscription) []ExclusionSuggestion {
+	var costs []float64
+	for _, s := range subs {
+		costs = append(costs, s.MonthlyCost())
+	}
+	median := 0.0
+	if len(costs) >= 3 {
+		median = medianOf(costs)
+	}
+
+	var suggestions []ExclusionSuggestion
+	for _, s := range subs {
+		var reasons []string
+		keyword := transferKeywords.MatchString(s.Name)
+		if !keyword {
+			for _, tx := range s.Transactions {
+				if transferKeywords.MatchString(tx.Text) {
+					keyword = true
+					break
+				}
+			}
+		}
+		if keyword {
+			reasons = append(reasons, "text suggests rent, a loan or a transfer")
+		}
+		large := median > 0 && s.MonthlyCost() >= largeFactor*median
+		if large {
+			reasons = append(reasons, fmt.Sprintf("%.0fx the median subscription", s.MonthlyCost()/median))
+		}
+		round := roundAmounts(s.Transactions)
+		if round {
+			reasons = append(reasons, "round amount")
+		}
+		if !keyword && !(large && round) {
+			continue
+		}
+		suggestions = append(suggestions, ExclusionSuggestion{
+			Subscription: s,
+			Pattern:      ExcludePatternFor(s.Name),
+			Reasons:      reasons,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		ci, cj := suggestions[i].Subscription.MonthlyCost(), suggestions[j].Subscription.MonthlyCost()
+		if ci != cj {
+			return ci > cj
+		}
+		return suggestions[i].Subscription.Name < suggestions[j].Subscription.Name
+	})
+	return suggestions
+}
+
+// roundAmounts reports whether all payments are whole multiples of 100, as transfers often are
+// and subscription prices (99, 149, 12.99) rarely
+func roundAmounts(txs []Transaction) bool {
+	if len(txs) == 0 {
+		return false
+	}
+	for _, tx := range txs {
+		if math.Abs(tx.Amount) < 100 || math.Abs(math.Remainder(tx.Amount, 100)) > 0.005 {
+			return false
+		}
+	}
+	return true
+}
+
+// medianOf returns the median of values, which must not be empty
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// PrintExclusionSuggestions displays suggested exclusions with exclude rules to paste into the config
+func PrintExclusionSuggestions(w io.Writer, suggestions []ExclusionSuggestion, currency Currency) {
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "No exclusion suggestions found.")
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d likely false positive(s):\n\n", len(suggestions))
+
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "  \"%s\" (%s/month, %d payments)\n", s.Subscription.Name, currency.Format(s.Subscription.MonthlyCost()), len(s.Subscription.Transactions))
+		fmt.Fprintf(w, "    Why: %s\n", strings.Join(s.Reasons, ", "))
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Add to config:")
+	fmt.Fprintln(w, "  exclude:")
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "    - pattern: %q\n", s.Pattern)
+	}
+}
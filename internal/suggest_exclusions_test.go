@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSuggestExclusions(t *testing.T) {
+	sub := func(name string, amounts ...float64) Subscription {
+		s := Subscription{Name: name}
+		for i, a := range amounts {
+			s.Transactions = append(s.Transactions, Transaction{Date: date("2025-01-15").AddDate(0, i, 0), Text: name, Amount: a})
+			s.LatestAmount = a
+		}
+		return s
+	}
+	subs := []Subscription{
+		sub("Netflix", -99, -99, -99),
+		sub("Spotify", -119, -119, -119),
+		sub("Gym", -300, -300, -300),              // round, but not large
+		sub("Fastighets AB", -8500, -8500, -8500), // large and round
+		sub("Electricity", -900, -1250, -1100),    // large, but not round
+		sub("Överföring ISK", -50, -50, -50),      // transfer to savings
+	}
+
+	suggestions := SuggestExclusions(subs)
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.Subscription.Name)
+	}
+	if strings.Join(names, ",") != "Fastighets AB,Överföring ISK" {
+		t.Fatalf("expected Fastighets AB and Överföring ISK, got %v", names)
+	}
+	rule, err := NewExcludeRule(suggestions[0].Pattern, "", "")
+	if err != nil || !rule.Matches(subs[3]) || rule.Matches(subs[0]) {
+		t.Errorf("expected a pattern excluding only Fastighets AB, got %q (%v)", suggestions[0].Pattern, err)
+	}
+
+	var buf bytes.Buffer
+	PrintExclusionSuggestions(&buf, suggestions, GetCurrency("SEK"))
+	for _, want := range []string{`"Fastighets AB"`, "round amount", `- pattern: "^Fastighets AB$"`, "text suggests rent"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// KnownSuggestion is a payee seen only once whose name looks like a digital service, with the
+// known subscription pattern that would detect it from its first payment
+type KnownSuggestion struct {
+	Transaction Transaction
+	Pattern     string
+	Reason      string
+}
+
+// digitalServiceHints recognize merchant names of online services, which often are subscriptions
+// even when a single payment is in the data (e.g., a new or yearly subscription)
+var digitalServiceHints = []struct {
+	re     *regexp.Regexp
+	reason string
+}{
+	{appStorePattern, "app store"},
+	{regexp.MustCompile(`(?i)PAYPAL|\*`), "payment processor"},
+	{regexp.MustCompile(`(?i)\.(com|net|io|app|tv)\b`), "web address"},
+}
+
+// SuggestKnown returns the expense payees with a single transaction whose names look like
+// digital services (app stores, payment processors like "PAYPAL *NEWSSITE", web addresses),
+// as candidates for known subscriptions. Payees the config already knows are skipped.
+// Suggestions are sorted by name.
+func SuggestKnown(txs []Transaction, cfg *Config) []KnownSuggestion {
+	byName := make(map[string][]Transaction)
+	for _, tx := range FilterExpenses(txs) {
+		byName[tx.Text] = append(byName[tx.Text], tx)
+	}
+
+	var suggestions []KnownSuggestion
+	for name, list := range byName {
+		if len(list) != 1 || cfg.MatchesKnown(list[0]) != nil {
+			continue
+		}
+		for _, hint := range digitalServiceHints {
+			if hint.re.MatchString(name) {
+				suggestions = append(suggestions, KnownSuggestion{
+					Transaction: list[0],
+					Pattern:     generatePattern(name),
+					Reason:      hint.reason,
+				})
+				break
+			}
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Transaction.Text < suggestions[j].Transaction.Text
+	})
+	return suggestions
+}
+
+// PrintKnownSuggestions displays candidate known subscriptions with config entries to review
+func PrintKnownSuggestions(w io.Writer, suggestions []KnownSuggestion, currency Currency) {
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "No known subscription suggestions found.")
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d payee(s) seen once that look like digital services:\n\n", len(suggestions))
+
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "  \"%s\" (%s, %s)\n", s.Transaction.Text, s.Transaction.Date.Format("2006-01-02"), currency.Format(-s.Transaction.Amount))
+		fmt.Fprintf(w, "    Why: %s\n", s.Reason)
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Add the ones that are subscriptions to config:")
+	fmt.Fprintln(w, "  known:")
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "    - pattern: %q\n", s.Pattern)
+	}
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSuggestKnown(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-03-02"), Text: "PAYPAL *NEWSSITE", Amount: -79},
+		{Date: date("2025-03-05"), Text: "APPLE.COM/BILL", Amount: -29},
+		{Date: date("2025-03-08"), Text: "Cloudstore.io", Amount: -49},
+		{Date: date("2025-03-10"), Text: "ICA Kvantum", Amount: -450}, // not a digital service
+		{Date: date("2025-03-12"), Text: "NETFLIX.COM", Amount: -99},  // already known
+		{Date: date("2025-03-15"), Text: "Refund.com", Amount: 100},   // not an expense
+		{Date: date("2025-03-20"), Text: "STEAM*GAMES", Amount: -199}, // seen twice
+		{Date: date("2025-04-20"), Text: "STEAM*GAMES", Amount: -99},
+	}
+	cfg, err := NewDefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions := SuggestKnown(txs, cfg)
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.Transaction.Text+" ("+s.Reason+")")
+	}
+	if got := strings.Join(names, ", "); got != "APPLE.COM/BILL (app store), Cloudstore.io (web address), PAYPAL *NEWSSITE (payment processor)" {
+		t.Fatalf("unexpected suggestions: %s", got)
+	}
+
+	known := KnownSubscription{Pattern: suggestions[2].Pattern, regex: regexp.MustCompile(suggestions[2].Pattern)}
+	if !known.Matches(Transaction{Text: "PAYPAL *NEWSSITE", Amount: -79}) {
+		t.Errorf("expected pattern %q to match its payee", suggestions[2].Pattern)
+	}
+
+	var buf bytes.Buffer
+	PrintKnownSuggestions(&buf, suggestions, GetCurrency("SEK"))
+	if !strings.Contains(buf.String(), `- pattern: "^PAYPAL \\*NEWSSITE"`) {
+		t.Errorf("expected a known pattern in output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// RenameSuggestion is a set of payee names that differ only by case, whitespace or trailing
+// digits, with the name that a rename rule would give them
+type RenameSuggestion struct {
+	Name         string   // the most common spelling, without trailing digits
+	Names        []string // sorted
+	Transactions int      // number of transactions with any of the names
+}
+
+// Rule returns the rename rule entry (name and new name) for the config
+func (s RenameSuggestion) Rule() (string, string) {
+	return renameKey(s.Name), s.Name
+}
+
+// SuggestRenames returns the expense payees whose names differ only by case, whitespace or
+// trailing digits (e.g., "SPOTIFY 1234" and "Spotify  5678"), sorted by name. Unlike group
+// suggestions they don't need a pattern, and they are listed whether or not the payments look
+// like a subscription.
+func SuggestRenames(txs []Transaction) []RenameSuggestion {
+	byKey := make(map[string]map[string]int)
+	for _, tx := range FilterExpenses(txs) {
+		key := renameKey(tx.Text)
+		if key == "" {
+			continue
+		}
+		if byKey[key] == nil {
+			byKey[key] = make(map[string]int)
+		}
+		byKey[key][tx.Text]++
+	}
+
+	var suggestions []RenameSuggestion
+	for _, counts := range byKey {
+		if len(counts) < 2 {
+			continue
+		}
+		s := RenameSuggestion{}
+		mostCommon := ""
+		for name, n := range counts {
+			s.Names = append(s.Names, name)
+			s.Transactions += n
+			if mostCommon == "" || n > counts[mostCommon] || n == counts[mostCommon] && name < mostCommon {
+				mostCommon = name
+			}
+		}
+		sort.Strings(s.Names)
+		s.Name = trimTrailingDigits(mostCommon)
+		suggestions = append(suggestions, s)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Name < suggestions[j].Name })
+	return suggestions
+}
+
+// PrintRenameSuggestions displays near-duplicate payee names with rename rules for the config
+func PrintRenameSuggestions(w io.Writer, suggestions []RenameSuggestion) {
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "No rename suggestions found.")
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d payee(s) with names differing only by case, whitespace or trailing digits:\n\n", len(suggestions))
+
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "  \"%s\" (%d names, %d transactions)\n", s.Name, len(s.Names), s.Transactions)
+		fmt.Fprintf(w, "    Names: %s\n", strings.Join(truncateStrings(s.Names, 3), ", "))
+		if len(s.Names) > 3 {
+			fmt.Fprintf(w, "           ... and %d more\n", len(s.Names)-3)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Add to config:")
+	fmt.Fprintln(w, "  rename:")
+	for _, s := range suggestions {
+		name, to := s.Rule()
+		fmt.Fprintf(w, "    %q: %q\n", name, to)
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestRenames(t *testing.T) {
+	txs := []Transaction{
+		{Date: date("2025-01-01"), Text: "SPOTIFY 1234", Amount: -119},
+		{Date: date("2025-02-01"), Text: "Spotify  5678", Amount: -119},
+		{Date: date("2025-03-01"), Text: "SPOTIFY 9012", Amount: -119},
+		{Date: date("2025-03-01"), Text: "SPOTIFY 9012", Amount: -119},
+		{Date: date("2025-03-05"), Text: "ICA Kvantum", Amount: -450},
+		{Date: date("2025-03-06"), Text: "ICA KVANTUM ", Amount: -210},
+		{Date: date("2025-03-10"), Text: "Netflix", Amount: -99}, // one name only
+		{Date: date("2025-03-11"), Text: "1234", Amount: -10},    // only digits
+		{Date: date("2025-03-12"), Text: "5678", Amount: -10},
+	}
+
+	suggestions := SuggestRenames(txs)
+	want := []RenameSuggestion{
+		{Name: "ICA KVANTUM", Names: []string{"ICA KVANTUM ", "ICA Kvantum"}, Transactions: 2},
+		{Name: "SPOTIFY", Names: []string{"SPOTIFY 1234", "SPOTIFY 9012", "Spotify  5678"}, Transactions: 4},
+	}
+	if !reflect.DeepEqual(suggestions, want) {
+		t.Fatalf("SuggestRenames() = %+v, want %+v", suggestions, want)
+	}
+
+	var buf bytes.Buffer
+	PrintRenameSuggestions(&buf, suggestions)
+	if !strings.Contains(buf.String(), "  rename:\n    \"ICA KVANTUM\": \"ICA KVANTUM\"\n    \"SPOTIFY\": \"SPOTIFY\"\n") {
+		t.Errorf("expected rename rules in output:\n%s", buf.String())
+	}
+}
+
+func TestConfig_Rename(t *testing.T) {
+	cfg, err := parseConfig([]byte("rename:\n  spotify: Spotify\ngroups:\n  - name: Music\n    patterns: [\"^Spotify$\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, _ := cfg.ApplyGroups([]Transaction{{Text: "SPOTIFY 1234"}, {Text: "Spotify Family"}, {Text: "Netflix"}})
+	if renamed[0].Text != "Music" || renamed[0].RawText != "SPOTIFY 1234" {
+		t.Errorf("expected the renamed text to be grouped, got %+v", renamed[0])
+	}
+	if renamed[1].Text != "Spotify Family" || renamed[2].Text != "Netflix" {
+		t.Errorf("expected other names unchanged, got %+v", renamed[1:])
+	}
+
+	if _, err := parseConfig([]byte("rename:\n  \"123\": Spotify\n")); err == nil {
+		t.Error("expected an error for a rename of digits only")
+	}
+}
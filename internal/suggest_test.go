@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSuggestGroups_Deterministic(t *testing.T) {
+	// Monthly payments whose text changes every month, for two services with the same amount
+	var txs []Transaction
+	for month := 1; month <= 6; month++ {
+		txs = append(txs,
+			Transaction{Date: date(fmt.Sprintf("2025-%02d-05", month)), Text: fmt.Sprintf("APPLE.COM/BILL %d", 1000+month), Amount: -29},
+			Transaction{Date: date(fmt.Sprintf("2025-%02d-12", month)), Text: fmt.Sprintf("GOOGLE *STORAGE %d", 2000+month), Amount: -29},
+		)
+	}
+
+	expected := SuggestGroups(txs, 0.35)
+	if len(expected) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", expected)
+	}
+	for range 10 {
+		reversed := slices.Clone(txs)
+		slices.Reverse(reversed)
+		for _, input := range [][]Transaction{txs, reversed} {
+			if got := SuggestGroups(input, 0.35); !reflect.DeepEqual(suggestionNames(got), suggestionNames(expected)) {
+				t.Fatalf("expected %v, got %v", suggestionNames(expected), suggestionNames(got))
+			}
+		}
+	}
+}
+
+func suggestionNames(suggestions []GroupSuggestion) []string {
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.Prefix+": "+fmt.Sprint(s.Names))
+	}
+	return names
+}
+
+func TestWriteGroupSuggestions(t *testing.T) {
+	suggestions := []GroupSuggestion{{
+		Prefix:     "Spotify",
+		Pattern:    "^Spotify",
+		Names:      []string{"Spotify P1", "Spotify P2", "Spotify P3"},
+		MonthCount: 3,
+		Transactions: []Transaction{
+			{Date: date("2025-03-01"), Text: "Spotify P3", Amount: -119},
+			{Date: date("2025-01-01"), Text: "Spotify P1", Amount: -109},
+			{Date: date("2025-04-01"), Text: "Spotify P2", Amount: -119},
+			{Date: date("2025-02-01"), Text: "Spotify P2", Amount: -119},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteGroupSuggestions(&buf, suggestions, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var output JSONGroupSuggestions
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Suggestions) != 1 || output.Suggestions[0].Pattern != "^Spotify" || output.Suggestions[0].Months != 3 || len(output.Suggestions[0].Names) != 3 {
+		t.Errorf("unexpected JSON output: %s", buf.String())
+	}
+	if samples := output.Suggestions[0].Samples; len(samples) != 3 || samples[0].Date != "2025-02-01" || samples[2].Text != "Spotify P2" || samples[2].Amount != -119 {
+		t.Errorf("expected the 3 latest transactions as samples, got %+v", samples)
+	}
+
+	buf.Reset()
+	if err := WriteGroupSuggestions(&buf, suggestions, "yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "- prefix: Spotify\n    pattern: ^Spotify\n") {
+		t.Errorf("unexpected YAML output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	WriteGroupSuggestions(&buf, nil, "json")
+	if !strings.Contains(buf.String(), `"suggestions": []`) {
+		t.Errorf("expected an empty list without suggestions, got %s", buf.String())
+	}
+}
+
+func TestVariantStem(t *testing.T) {
+	tests := map[string]string{
+		"SPOTIFY P3A8AC":     "SPOTIFY",
+		"GOOGLE*GSUITE 1001": "GOOGLE*GSUITE",
+		"Google GSUITE_":     "Google GSUITE",
+		"NETFLIX123":         "NETFLIX",
+		"HBO MAX 2 #4411":    "HBO MAX",
+		"NETFLIX.COM":        "",
+		"P3A8AC":             "",
+		"AB 12":              "",
+	}
+	for name, want := range tests {
+		if got := variantStem(name); got != want {
+			t.Errorf("variantStem(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLongestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"GOOGLE *GSUITE 1001", "Google *GSuite_"}, "GOOGLE *GSUITE"},
+		{[]string{"SPOTIFY P1", "SPOTIFY P2"}, "SPOTIFY"},
+		{[]string{"SPOTIFY", "SPOTIFYAB"}, ""},
+		{[]string{"NETFLIX.COM", "Netflix"}, "NETFLIX"},
+	}
+	for _, tt := range tests {
+		if got := longestCommonPrefix(tt.names); got != tt.want {
+			t.Errorf("longestCommonPrefix(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestGroups_PartiallyGrouped(t *testing.T) {
+	// The plain name recurs and is detected on its own; the variants of other months are not
+	var txs []Transaction
+	for month := 1; month <= 6; month++ {
+		text := "SPOTIFY"
+		if month > 3 {
+			text = fmt.Sprintf("SPOTIFY P%dA8AC", month)
+		}
+		txs = append(txs, Transaction{Date: date(fmt.Sprintf("2025-%02d-01", month)), Text: text, Amount: -119})
+	}
+
+	suggestions := SuggestGroups(txs, 0.35)
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %v", suggestionNames(suggestions))
+	}
+	if s := suggestions[0]; s.Pattern != "^SPOTIFY" || s.MonthCount != 6 || len(s.Names) != 4 {
+		t.Errorf("expected ^SPOTIFY covering all 6 months and 4 names, got %+v", s)
+	}
+}
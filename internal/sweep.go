@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// SweepTolerances are the tolerances of a tolerance sweep: 5% to 60% in steps of 5%, plus
+// current if it's between the steps
+func SweepTolerances(current float64) []float64 {
+	var tolerances []float64
+	for i := 1; i <= 12; i++ {
+		tolerances = append(tolerances, float64(i*5)/100)
+	}
+	if !slices.ContainsFunc(tolerances, func(t float64) bool { return math.Abs(t-current) < 1e-9 }) {
+		tolerances = append(tolerances, current)
+		sort.Float64s(tolerances)
+	}
+	return tolerances
+}
+
+// SweepStep is the detection result at one tolerance of a tolerance sweep
+type SweepStep struct {
+	Tolerance     float64
+	Subscriptions []Subscription
+	Appeared      []string // subscriptions detected at this tolerance but not the previous one
+	Disappeared   []string // subscriptions detected at the previous tolerance but not this one
+}
+
+// ToleranceSweep runs detection at each of tolerances (ascending) with the other detector
+// options, and reports which subscriptions appear and disappear from one step to the next.
+// Recurring commitments are left out, as they aren't subscriptions (see SplitCommitments).
+func ToleranceSweep(ctx context.Context, transactions []Transaction, completeMonths []string, dateRange DateRange, cfg *Config, tolerances []float64, opts ...DetectorOption) ([]SweepStep, error) {
+	var steps []SweepStep
+	var previous map[string]bool
+	for _, tolerance := range tolerances {
+		detector := NewDetector(append(slices.Clone(opts), WithTolerance(tolerance))...)
+		subs, err := detector.DetectAll(ctx, transactions, completeMonths, dateRange, cfg)
+		if err != nil {
+			return nil, err
+		}
+		subs, _ = SplitCommitments(subs, cfg)
+
+		step := SweepStep{Tolerance: tolerance, Subscriptions: subs}
+		names := make(map[string]bool, len(subs))
+		for _, sub := range subs {
+			names[sweepName(sub)] = true
+		}
+		if previous != nil {
+			for name := range names {
+				if !previous[name] {
+					step.Appeared = append(step.Appeared, name)
+				}
+			}
+			for name := range previous {
+				if !names[name] {
+					step.Disappeared = append(step.Disappeared, name)
+				}
+			}
+			sort.Strings(step.Appeared)
+			sort.Strings(step.Disappeared)
+		}
+		steps = append(steps, step)
+		previous = names
+	}
+	return steps, nil
+}
+
+// sweepName identifies a subscription across the steps of a sweep, with the household member
+// paying it for labeled exports
+func sweepName(sub Subscription) string {
+	if sub.Person != "" {
+		return fmt.Sprintf("%s (%s)", sub.Name, sub.Person)
+	}
+	return sub.Name
+}
+
+// formatTolerance formats a tolerance as a percentage, e.g. "35%"
+func formatTolerance(tolerance float64) string {
+	return strconv.FormatFloat(math.Round(tolerance*1000)/10, 'f', -1, 64) + "%"
+}
+
+// PrintToleranceSweep outputs the steps of a tolerance sweep as a table, marking the current
+// tolerance
+func PrintToleranceSweep(w io.Writer, steps []SweepStep, current float64, opts OutputOptions) {
+	loc := opts.Locale
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{loc.T("Tolerance"), loc.T("Active"), loc.T("Monthly"), loc.T("Appeared"), loc.T("Disappeared")})
+	for _, step := range steps {
+		tolerance := formatTolerance(step.Tolerance)
+		if math.Abs(step.Tolerance-current) < 1e-9 {
+			tolerance = text.Bold.Sprint(tolerance + " *")
+		}
+		active := 0
+		for _, sub := range step.Subscriptions {
+			if sub.Status == StatusActive {
+				active++
+			}
+		}
+		t.AppendRow(table.Row{
+			tolerance,
+			active,
+			opts.Currency.Format(ActiveMonthlyTotal(step.Subscriptions)),
+			text.FgGreen.Sprint(strings.Join(step.Appeared, ", ")),
+			text.FgRed.Sprint(strings.Join(step.Disappeared, ", ")),
+		})
+	}
+	styleTable(t, opts)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignRight},
+		{Number: 2, Align: text.AlignRight},
+		{Number: 3, Align: text.AlignRight},
+		{Number: 4, WidthMax: 50},
+		{Number: 5, WidthMax: 50},
+	})
+	t.Render()
+	fmt.Fprint(w, loc.Sprintf("* current tolerance (--tolerance %s)\n", strconv.FormatFloat(current, 'f', -1, 64)))
+}
+
+// JSONSweep is the JSON output format of a tolerance sweep
+type JSONSweep struct {
+	SchemaVersion    int             `json:"schema_version"`
+	CurrentTolerance float64         `json:"current_tolerance"`
+	Steps            []JSONSweepStep `json:"steps"`
+	Currency         string          `json:"currency"`
+}
+
+// JSONSweepStep is the JSON output format of a step of a tolerance sweep
+type JSONSweepStep struct {
+	Tolerance     float64  `json:"tolerance"`
+	Subscriptions []string `json:"subscriptions"`
+	Active        int      `json:"active"`
+	MonthlyTotal  float64  `json:"monthly_total"`
+	Appeared      []string `json:"appeared"`
+	Disappeared   []string `json:"disappeared"`
+}
+
+// PrintToleranceSweepJSON outputs the steps of a tolerance sweep in JSON format
+func PrintToleranceSweepJSON(w io.Writer, steps []SweepStep, current float64, currency Currency) {
+	output := JSONSweep{
+		SchemaVersion:    JSONSchemaVersion,
+		CurrentTolerance: current,
+		Steps:            []JSONSweepStep{},
+		Currency:         currency.Code,
+	}
+	for _, step := range steps {
+		s := JSONSweepStep{
+			Tolerance:     step.Tolerance,
+			Subscriptions: []string{},
+			MonthlyTotal:  currency.Round(ActiveMonthlyTotal(step.Subscriptions)),
+			Appeared:      append([]string{}, step.Appeared...),
+			Disappeared:   append([]string{}, step.Disappeared...),
+		}
+		for _, sub := range step.Subscriptions {
+			s.Subscriptions = append(s.Subscriptions, sweepName(sub))
+			if sub.Status == StatusActive {
+				s.Active++
+			}
+		}
+		sort.Strings(s.Subscriptions)
+		output.Steps = append(output.Steps, s)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(output)
+}
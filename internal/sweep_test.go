@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestSweepTolerances(t *testing.T) {
+	tolerances := SweepTolerances(0.35)
+	if len(tolerances) != 12 || tolerances[0] != 0.05 || tolerances[11] != 0.6 {
+		t.Errorf("unexpected tolerances %v", tolerances)
+	}
+	tolerances = SweepTolerances(0.12)
+	if len(tolerances) != 13 || tolerances[2] != 0.12 {
+		t.Errorf("expected the current tolerance between the steps, got %v", tolerances)
+	}
+}
+
+func TestToleranceSweep(t *testing.T) {
+	var txs []Transaction
+	for i, day := range []string{"2025-01-10", "2025-02-10", "2025-03-10", "2025-04-10", "2025-05-10", "2025-06-10"} {
+		txs = append(txs, Transaction{Date: date(day), Text: "Steady", Amount: -100})
+		jumpy := -100.0
+		if i%2 == 1 {
+			jumpy = -120
+		}
+		txs = append(txs, Transaction{Date: date(day), Text: "Jumpy", Amount: jumpy})
+	}
+	txs = append(txs, Transaction{Date: date("2025-06-30"), Text: "Grocery", Amount: -50})
+	completeMonths, dateRange := AnalyzeDataCoverage(txs)
+
+	steps, err := ToleranceSweep(context.Background(), txs, completeMonths, dateRange, nil, []float64{0.1, 0.25, 0.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(steps))
+	}
+	if len(steps[0].Subscriptions) != 1 || steps[0].Appeared != nil {
+		t.Errorf("expected only Steady at 10%%, got %+v", steps[0])
+	}
+	if len(steps[1].Appeared) != 1 || steps[1].Appeared[0] != "Jumpy" || steps[1].Disappeared != nil {
+		t.Errorf("expected Jumpy to appear at 25%%, got %+v", steps[1])
+	}
+	if steps[2].Appeared != nil || steps[2].Disappeared != nil {
+		t.Errorf("expected no changes at 30%%, got %+v", steps[2])
+	}
+
+	var buf bytes.Buffer
+	PrintToleranceSweep(&buf, steps, 0.25, OutputOptions{Currency: GetCurrency("SEK"), Locale: GetLocale(language.AmericanEnglish)})
+	if !strings.Contains(buf.String(), "25% *") || !strings.Contains(buf.String(), "Jumpy") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A tag filter (--tags) is a list of terms. A subscription matches if it matches any of the terms,
+// like with plain tags. A term joins tags with '+' (all of them) and '!' negates a tag, so
+// "entertainment+streaming" needs both tags and "!music" that the tag is missing. Terms with only
+// negated tags apply to all other terms: "entertainment,!gaming" is entertainment but not gaming.
+// Tags compare case-insensitively.
+
+// tagFactor is a tag, or its negation, in a term
+type tagFactor struct {
+	tag    string
+	negate bool
+}
+
+// TagExpression is a parsed tag filter
+type TagExpression struct {
+	terms      [][]tagFactor // any of these must match, unless there are none
+	exclusions [][]tagFactor // terms with only negated tags; all of these must match
+}
+
+// ParseTagExpression parses a tag filter from the values of --tags. Each value may also hold
+// several terms separated by commas.
+func ParseTagExpression(values []string) (TagExpression, error) {
+	var expr TagExpression
+	for _, value := range values {
+		for _, term := range strings.Split(value, ",") {
+			if strings.TrimSpace(term) == "" {
+				continue
+			}
+			var factors []tagFactor
+			positive := false
+			for _, f := range strings.Split(term, "+") {
+				f = strings.TrimSpace(f)
+				negate := strings.HasPrefix(f, "!")
+				tag := strings.TrimSpace(strings.TrimPrefix(f, "!"))
+				if tag == "" {
+					return TagExpression{}, fmt.Errorf("invalid tag filter %q: empty tag", term)
+				}
+				factors = append(factors, tagFactor{tag: tag, negate: negate})
+				positive = positive || !negate
+			}
+			if positive {
+				expr.terms = append(expr.terms, factors)
+			} else {
+				expr.exclusions = append(expr.exclusions, factors)
+			}
+		}
+	}
+	return expr, nil
+}
+
+// Matches reports whether a subscription with tags matches the expression
+func (e TagExpression) Matches(tags []string) bool {
+	for _, term := range e.exclusions {
+		if !termMatches(term, tags) {
+			return false
+		}
+	}
+	if len(e.terms) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(e.terms, func(term []tagFactor) bool { return termMatches(term, tags) })
+}
+
+// termMatches reports whether tags have all of the term's tags and none of its negated ones
+func termMatches(term []tagFactor, tags []string) bool {
+	for _, f := range term {
+		has := slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, f.tag) })
+		if has == f.negate {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package internal
+
+import "testing"
+
+func TestTagExpression(t *testing.T) {
+	tests := []struct {
+		filter []string
+		tags   []string
+		want   bool
+	}{
+		{[]string{"entertainment"}, []string{"Entertainment", "music"}, true},
+		{[]string{"entertainment", "insurance"}, []string{"insurance"}, true},
+		{[]string{"entertainment,insurance"}, []string{"news"}, false},
+		{[]string{"entertainment", "!music"}, []string{"entertainment", "music"}, false},
+		{[]string{"entertainment,!music"}, []string{"entertainment", "video"}, true},
+		{[]string{"entertainment,!music"}, []string{"insurance"}, false},
+		{[]string{"!gaming"}, nil, true},
+		{[]string{"!gaming"}, []string{"gaming"}, false},
+		{[]string{"entertainment+streaming"}, []string{"entertainment"}, false},
+		{[]string{"entertainment+streaming"}, []string{"streaming", "entertainment"}, true},
+		{[]string{"entertainment+!music", "news"}, []string{"entertainment", "music"}, false},
+		{[]string{"entertainment+!music", "news"}, []string{"news", "music"}, true},
+	}
+	for _, tt := range tests {
+		expr, err := ParseTagExpression(tt.filter)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.filter, err)
+		}
+		if got := expr.Matches(tt.tags); got != tt.want {
+			t.Errorf("%v matching %v = %v, want %v", tt.filter, tt.tags, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"entertainment+", "!", "a+!+b"} {
+		if _, err := ParseTagExpression([]string{invalid}); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+	if expr, _ := ParseTagExpression(nil); !expr.Matches(nil) {
+		t.Error("expected an empty filter to match everything")
+	}
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transaction dates are calendar days, represented as midnight UTC. Exports that contain times
+// with a zone (e.g., "2025-01-31T23:30:00Z") are converted to the timezone in the parsing context
+// before taking the day, so a payment just before midnight lands in the month it was made in
+// where the user lives, not in the next one.
+
+// dateTimeLayouts are the timestamp layouts ParseDate accepts besides plain dates. Layouts
+// without a zone are wall-clock times of the bank, whose day is taken as written.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+type timezoneKey struct{}
+
+// WithTimezone returns a context in which parsed dates are normalized to loc
+func WithTimezone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, timezoneKey{}, loc)
+}
+
+// timezoneFrom returns the timezone in ctx, defaulting to the local timezone
+func timezoneFrom(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(timezoneKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+// LoadTimezone returns the location for an IANA name (e.g., "Europe/Stockholm"), "UTC" or
+// "Local" (also the default for an empty name)
+func LoadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// ParseDate parses a transaction date: a plain date (2006-01-02) or a timestamp, optionally
+// with a zone. Timestamps with a zone are converted to the timezone in ctx first. The result is
+// the calendar day at midnight UTC, like all transaction dates.
+func ParseDate(ctx context.Context, s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(layout, "Z07:00") {
+			t = t.In(timezoneFrom(ctx))
+		}
+		return day(t), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q (expected YYYY-MM-DD or an RFC 3339 timestamp)", s)
+}
+
+// normalizeDate turns a date returned by a parser into a calendar day at midnight UTC. Dates
+// that already are midnight UTC are plain days; other times are converted to loc first.
+func normalizeDate(t time.Time, loc *time.Location) time.Time {
+	if t.Location() == time.UTC && t.Equal(day(t)) {
+		return t
+	}
+	return day(t.In(loc))
+}
+
+// normalizeDates normalizes the dates of txs in place (see normalizeDate)
+func normalizeDates(txs []Transaction, loc *time.Location) {
+	for i := range txs {
+		txs[i].Date = normalizeDate(txs[i].Date, loc)
+	}
+}
+
+// day returns midnight UTC of the calendar day of t in its own location
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	newYork, err := LoadTimezone("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		loc      *time.Location
+		expected string
+	}{
+		{"plain date", "2025-01-31", newYork, "2025-01-31"},
+		{"wall-clock time keeps its day", "2025-01-31 23:30", time.UTC, "2025-01-31"},
+		{"wall-clock time with seconds", "2025-01-31T23:30:00", time.UTC, "2025-01-31"},
+		{"UTC timestamp in New York", "2025-02-01T02:30:00Z", newYork, "2025-01-31"},
+		{"UTC timestamp in UTC", "2025-02-01T02:30:00Z", time.UTC, "2025-02-01"},
+		{"offset timestamp in UTC", "2025-01-31T23:30:00-05:00", time.UTC, "2025-02-01"},
+		{"offset timestamp in its own zone", "2025-01-31T23:30:00-05:00", newYork, "2025-01-31"},
+		{"space-separated with offset", "2025-02-01 00:30:00+01:00", time.UTC, "2025-01-31"},
+		{"fractional seconds", "2025-02-01T00:30:00.123+01:00", time.UTC, "2025-01-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(WithTimezone(context.Background(), tt.loc), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(date(tt.expected)) || got.Location() != time.UTC {
+				t.Errorf("ParseDate(%q) = %v, want %s at midnight UTC", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "31/01/2025", "2025-13-01", "yesterday"} {
+		if _, err := ParseDate(context.Background(), input); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestParseFile_NormalizesDates(t *testing.T) {
+	newYork, err := LoadTimezone("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	// A custom parser returning timestamps, and one returning plain days
+	RegisterParser("test-timestamps", ParserFunc(func(_ context.Context, path string) ([]Transaction, error) {
+		return []Transaction{
+			{Date: time.Date(2025, 2, 1, 2, 30, 0, 0, time.UTC), Text: "Late", Amount: -10},
+			{Date: date("2025-02-01"), Text: "Day", Amount: -10},
+		}, nil
+	}))
+	ctx := WithTimezone(context.Background(), newYork)
+
+	txs, err := ParseFile(ctx, "test-timestamps", "unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txs[0].Date.Equal(date("2025-01-31")) || !txs[1].Date.Equal(date("2025-02-01")) {
+		t.Errorf("expected 2025-01-31 and 2025-02-01, got %v and %v", txs[0].Date, txs[1].Date)
+	}
+
+	var streamed []Transaction
+	if err := StreamFile(ctx, "test-timestamps", "unused", func(tx Transaction) error {
+		streamed = append(streamed, tx)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !streamed[0].Date.Equal(txs[0].Date) || !streamed[1].Date.Equal(txs[1].Date) {
+		t.Errorf("expected streamed dates like parsed ones, got %v and %v", streamed[0].Date, streamed[1].Date)
+	}
+
+	// Built-in parsers accept timestamps too
+	path := filepath.Join(t.TempDir(), "tx.json")
+	os.WriteFile(path, []byte(`{"transactions": [{"date": "2025-02-01T02:30:00Z", "text": "Netflix", "amount": -99}]}`), 0644)
+	txs, err = ParseFile(ctx, "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txs[0].Date.Equal(date("2025-01-31")) {
+		t.Errorf("expected 2025-01-31 in New York, got %v", txs[0].Date)
+	}
+	txs, err = ParseFile(WithTimezone(context.Background(), time.UTC), "simple-json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txs[0].Date.Equal(date("2025-02-01")) {
+		t.Errorf("expected 2025-02-01 in UTC, got %v", txs[0].Date)
+	}
+}
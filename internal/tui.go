@@ -0,0 +1,426 @@
+//go:build !(js && wasm)
+
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-runewidth"
+)
+
+// tuiMode is what keystrokes currently do in the dashboard
+type tuiMode int
+
+const (
+	tuiBrowse         tuiMode = iota
+	tuiFilter                 // typing a filter query
+	tuiTag                    // typing a tag for the selected subscription
+	tuiConfirmExclude         // waiting for y/n to exclude the selected subscription
+)
+
+var (
+	tuiShowOptions = []string{"active", "stopped", "all"}
+	tuiSortFields  = []string{"name", "description", "amount"}
+)
+
+const tuiHelp = "↑/↓ move  / filter  f show  s sort  r reverse  t tag  x exclude  q quit"
+
+// tuiModel is the interactive dashboard: a subscription list with a detail pane
+type tuiModel struct {
+	subs       []Subscription // all subscriptions (minus those excluded in this session)
+	visible    []Subscription // filtered and sorted
+	cfg        *Config
+	configPath string // where tags and exclusions are saved ("" = read-only)
+	opts       OutputOptions
+
+	show      string
+	sortField string
+	sortDir   string
+	filter    string
+
+	cursor, offset int
+	width, height  int
+
+	mode   tuiMode
+	input  string
+	status string
+}
+
+// RunTUI starts the full-screen dashboard. Tags and exclusions are written to the config file
+// at configPath; an empty configPath makes the dashboard read-only.
+func RunTUI(subs []Subscription, cfg *Config, configPath string, opts OutputOptions) error {
+	_, err := tea.NewProgram(newTUIModel(subs, cfg, configPath, opts), tea.WithAltScreen()).Run()
+	return err
+}
+
+func newTUIModel(subs []Subscription, cfg *Config, configPath string, opts OutputOptions) *tuiModel {
+	m := &tuiModel{
+		subs:       subs,
+		cfg:        cfg,
+		configPath: configPath,
+		opts:       opts,
+		show:       "active",
+		sortField:  "name",
+		sortDir:    "asc",
+		width:      100,
+		height:     30,
+	}
+	if len(FilterByStatus(subs, "active")) == 0 {
+		m.show = "all"
+	}
+	m.refresh()
+	return m
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+// refresh recomputes the visible subscriptions and keeps the cursor in range
+func (m *tuiModel) refresh() {
+	display := make([]Subscription, 0, len(m.subs))
+	display = append(display, FilterByStatus(m.subs, m.show)...)
+	display = FilterByQuery(display, m.filter, m.cfg)
+	SortSubscriptions(display, m.sortField, m.sortDir, m.cfg)
+	m.visible = display
+
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.scroll()
+}
+
+// listRows is the number of subscriptions that fit in the list
+func (m *tuiModel) listRows() int {
+	return max(m.height-5, 1) // title, blank line, list header, status and help lines
+}
+
+// scroll keeps the cursor within the visible part of the list
+func (m *tuiModel) scroll() {
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(min(m.offset, len(m.visible)-rows), 0)
+}
+
+func (m *tuiModel) selected() *Subscription {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return nil
+	}
+	return &m.visible[m.cursor]
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case tuiFilter, tuiTag:
+			m.updateInput(msg)
+		case tuiConfirmExclude:
+			m.updateConfirmExclude(msg)
+		default:
+			return m.updateBrowse(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m *tuiModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.visible) - 1
+	case "/":
+		m.mode, m.input = tuiFilter, m.filter
+	case "f":
+		m.show = nextOption(tuiShowOptions, m.show)
+	case "s":
+		m.sortField = nextOption(tuiSortFields, m.sortField)
+	case "r":
+		m.sortDir = nextOption([]string{"asc", "desc"}, m.sortDir)
+	case "t", "x":
+		switch {
+		case m.selected() == nil:
+		case m.configPath == "":
+			m.status = "Read-only: no config file to save to (use --config)"
+		case msg.String() == "t":
+			m.mode, m.input = tuiTag, ""
+		default:
+			m.mode = tuiConfirmExclude
+		}
+	}
+	m.cursor = max(min(m.cursor, len(m.visible)-1), 0)
+	m.refresh()
+	return m, nil
+}
+
+// updateInput handles typing in the filter and tag prompts
+func (m *tuiModel) updateInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		if m.mode == tuiFilter {
+			m.filter = ""
+			m.refresh()
+		}
+		m.mode = tuiBrowse
+		return
+	case tea.KeyEnter:
+		mode := m.mode
+		m.mode, m.input = tuiBrowse, strings.TrimSpace(m.input)
+		if mode == tuiFilter {
+			m.filter = m.input
+			m.refresh()
+		} else if m.input != "" {
+			m.addTag(m.input)
+		}
+		return
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+	if m.mode == tuiFilter {
+		// Filter as you type
+		m.filter = m.input
+		m.refresh()
+	}
+}
+
+// addTag saves tag for the selected subscription in the config file
+func (m *tuiModel) addTag(tag string) {
+	sub := m.selected()
+	if sub == nil {
+		return
+	}
+	if err := AddConfigTag(m.configPath, sub.Name, tag); err != nil {
+		m.status = fmt.Sprintf("Error saving tag: %v", err)
+		return
+	}
+	if m.cfg.Tags == nil {
+		m.cfg.Tags = make(map[string][]string)
+	}
+	if !containsString(m.cfg.Tags[sub.Name], tag) {
+		m.cfg.Tags[sub.Name] = append(m.cfg.Tags[sub.Name], tag)
+	}
+	m.status = fmt.Sprintf("Tagged %s with %q in %s", sub.Name, tag, m.configPath)
+}
+
+func (m *tuiModel) updateConfirmExclude(msg tea.KeyMsg) {
+	m.mode = tuiBrowse
+	sub := m.selected()
+	if msg.String() != "y" || sub == nil {
+		m.status = "Not excluded"
+		return
+	}
+	name := sub.Name
+	if err := AddConfigExclude(m.configPath, ExcludeRule{Pattern: ExcludePatternFor(name)}); err != nil {
+		m.status = fmt.Sprintf("Error saving exclusion: %v", err)
+		return
+	}
+	var remaining []Subscription
+	for _, s := range m.subs {
+		if s.Name != name {
+			remaining = append(remaining, s)
+		}
+	}
+	m.subs = remaining
+	m.refresh()
+	m.status = fmt.Sprintf("Excluded %s in %s", name, m.configPath)
+}
+
+func (m *tuiModel) View() string {
+	listWidth := max(min(m.width*45/100, 60), 30)
+	detailWidth := max(m.width-listWidth-3, 10)
+
+	dir := "↑"
+	if m.sortDir == "desc" {
+		dir = "↓"
+	}
+	title := fmt.Sprintf("Subscriptions: %d of %d (%s) · sort: %s %s · monthly total: %s",
+		len(m.visible), len(m.subs), m.show, m.sortField, dir, m.opts.Currency.Format(ActiveMonthlyTotal(m.visible)))
+	if m.filter != "" {
+		title += fmt.Sprintf(" · filter: %q", m.filter)
+	}
+
+	left := m.listLines(listWidth)
+	right := m.detailLines(detailWidth)
+
+	var b strings.Builder
+	b.WriteString(text.Bold.Sprint(fit(title, m.width)) + "\n\n")
+	for i := 0; i < m.listRows()+1; i++ {
+		l, r := strings.Repeat(" ", listWidth), ""
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		b.WriteString(l + text.FgHiBlack.Sprint(" │ ") + r + "\n")
+	}
+
+	switch m.mode {
+	case tuiFilter:
+		b.WriteString("Filter: " + m.input + "█\n")
+	case tuiTag:
+		b.WriteString(fmt.Sprintf("Tag for %s: %s█\n", m.selected().Name, m.input))
+	case tuiConfirmExclude:
+		b.WriteString(text.FgYellow.Sprintf("Exclude %s in %s? (y/n)", m.selected().Name, m.configPath) + "\n")
+	default:
+		b.WriteString(fit(m.status, m.width) + "\n")
+	}
+	b.WriteString(text.FgHiBlack.Sprint(fit(tuiHelp, m.width)))
+	return b.String()
+}
+
+// listLines renders the list header and the visible rows, each exactly width columns wide
+func (m *tuiModel) listLines(width int) []string {
+	loc := m.opts.Locale
+	amountWidth, statusWidth := 12, 9
+	nameWidth := max(width-amountWidth-statusWidth-2, 5)
+
+	row := func(name, status, amount string) string {
+		return fit(name, nameWidth) + " " + fit(status, statusWidth) + " " + runewidth.FillLeft(runewidth.Truncate(amount, amountWidth, ""), amountWidth)
+	}
+
+	lines := []string{text.Bold.Sprint(row(loc.T("Name"), loc.T("Status"), loc.T("Monthly")))}
+	if len(m.visible) == 0 {
+		return append(lines, fit(loc.T("No subscriptions detected."), width))
+	}
+	end := min(m.offset+m.listRows(), len(m.visible))
+	for i := m.offset; i < end; i++ {
+		sub := m.visible[i]
+		status := loc.T("ACTIVE")
+		switch sub.Status {
+		case StatusStopped:
+			status = loc.T("STOPPED")
+		case StatusPossible:
+			status = loc.T("POSSIBLE")
+		}
+		line := row(sub.Name, status, m.opts.Currency.Format(math.Abs(sub.LatestAmount)))
+		switch {
+		case i == m.cursor:
+			line = text.ReverseVideo.Sprint(line)
+		case sub.Status == StatusStopped:
+			line = text.FgHiBlack.Sprint(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// detailLines renders the selected subscription: its details and payment history with amount bars
+func (m *tuiModel) detailLines(width int) []string {
+	sub := m.selected()
+	if sub == nil {
+		return nil
+	}
+	loc, currency := m.opts.Locale, m.opts.Currency
+
+	status := text.FgGreen.Sprint(loc.T("ACTIVE"))
+	switch sub.Status {
+	case StatusStopped:
+		status = text.FgRed.Sprint(loc.T("STOPPED"))
+	case StatusPossible:
+		status = text.FgYellow.Sprint(loc.T("POSSIBLE"))
+	}
+	amounts := currency.Format(math.Abs(sub.LatestAmount))
+	if sub.MinAmount != sub.MaxAmount {
+		amounts += " (" + currency.FormatRange(sub.MinAmount, sub.MaxAmount) + ")"
+	}
+
+	lines := []string{
+		text.Bold.Sprint(fit(sub.Name, width)),
+		fit(loc.T("Description")+": "+m.cfg.GetDescription(sub.Name), width),
+		fit(loc.T("Tags")+": "+strings.Join(m.cfg.GetTags(sub.Name), ", "), width),
+		fit(loc.T("Category")+": "+m.cfg.GetCategory(sub.Name), width),
+		loc.T("Status") + ": " + status + "  " + loc.T("Day") + ": " + loc.FormatDay(sub.TypicalDay),
+		fit(fmt.Sprintf("%s: %s  %s: %s", loc.T("Started"), loc.FormatDate(sub.StartDate), loc.T("Last Seen"), loc.FormatDate(sub.LastDate)), width),
+		fit(fmt.Sprintf("%s: %s  %s: %s", loc.T("Monthly"), amounts, loc.T("Yearly"), currency.Format(sub.MonthlyCost()*12)), width),
+		fit(fmt.Sprintf("%s: %s (%d payments)", loc.T("Total Paid"), currency.Format(sub.TotalPaid), len(sub.Transactions)), width),
+		"",
+		text.Bold.Sprint(loc.T("Payment history")),
+	}
+
+	// Newest payments first, as many as fit
+	txs := make([]Transaction, len(sub.Transactions))
+	copy(txs, sub.Transactions)
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Date.After(txs[j].Date) })
+	maxAmount := 0.0
+	for _, tx := range txs {
+		maxAmount = math.Max(maxAmount, math.Abs(tx.Amount))
+	}
+	dateWidth, amountWidth := 10, 12
+	barWidth := max(width-dateWidth-amountWidth-2, 0)
+	for _, tx := range txs {
+		if len(lines) >= m.listRows()+1 {
+			break
+		}
+		bar := ""
+		if maxAmount > 0 {
+			bar = strings.Repeat("█", int(math.Round(math.Abs(tx.Amount)/maxAmount*float64(barWidth))))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			fit(loc.FormatDate(tx.Date), dateWidth),
+			runewidth.FillLeft(currency.Format(math.Abs(tx.Amount)), amountWidth),
+			text.FgCyan.Sprint(bar)))
+	}
+	return lines
+}
+
+// fit truncates or pads s to exactly width columns
+func fit(s string, width int) string {
+	return runewidth.FillRight(runewidth.Truncate(s, width, "…"), width)
+}
+
+// nextOption returns the option after current, wrapping around
+func nextOption(options []string, current string) string {
+	for i, o := range options {
+		if o == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+//go:build !(js && wasm)
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func tuiTestSubscriptions() []Subscription {
+	return []Subscription{
+		{Name: "Spotify", Status: StatusActive, AvgAmount: -124, LatestAmount: -129, MinAmount: 119, MaxAmount: 129, TypicalDay: 1,
+			StartDate: date("2025-01-01"), LastDate: date("2025-02-01"),
+			Transactions: []Transaction{{Date: date("2025-01-01"), Amount: -119}, {Date: date("2025-02-01"), Amount: -129}}},
+		{Name: "Netflix", Status: StatusActive, AvgAmount: -99, LatestAmount: -99, MinAmount: 99, MaxAmount: 99, TypicalDay: 15,
+			StartDate: date("2025-01-15"), LastDate: date("2025-02-15"),
+			Transactions: []Transaction{{Date: date("2025-01-15"), Amount: -99}, {Date: date("2025-02-15"), Amount: -99}}},
+		{Name: "Gym", Status: StatusStopped, AvgAmount: -300, LatestAmount: -300, MinAmount: 300, MaxAmount: 300, TypicalDay: 5,
+			StartDate: date("2024-01-05"), LastDate: date("2024-06-05")},
+	}
+}
+
+func tuiKeys(m *tuiModel, keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m.Update(msg)
+	}
+}
+
+func TestTUI_Browse(t *testing.T) {
+	m := newTUIModel(tuiTestSubscriptions(), &Config{}, "", OutputOptions{Currency: GetCurrency("USD")})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	if len(m.visible) != 2 || m.visible[0].Name != "Netflix" {
+		t.Fatalf("expected active subscriptions sorted by name, got %+v", m.visible)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Payment history") || !strings.Contains(view, "Netflix") {
+		t.Errorf("expected detail pane for Netflix, got:\n%s", view)
+	}
+
+	tuiKeys(m, "down")
+	if m.selected().Name != "Spotify" {
+		t.Errorf("expected Spotify after moving down, got %s", m.selected().Name)
+	}
+
+	tuiKeys(m, "f", "f") // active -> stopped -> all
+	if m.show != "all" || len(m.visible) != 3 {
+		t.Errorf("expected all 3 subscriptions, got %s with %d", m.show, len(m.visible))
+	}
+
+	tuiKeys(m, "s", "s") // name -> description -> amount
+	if m.sortField != "amount" || m.visible[0].Name != "Netflix" {
+		t.Errorf("expected cheapest first when sorted by amount, got %s first", m.visible[0].Name)
+	}
+
+	tuiKeys(m, "/", "s", "p", "o")
+	if len(m.visible) != 1 || m.visible[0].Name != "Spotify" {
+		t.Errorf("expected filter to match only Spotify, got %+v", m.visible)
+	}
+	tuiKeys(m, "esc")
+	if m.filter != "" || len(m.visible) != 3 {
+		t.Errorf("expected esc to clear the filter, got %q", m.filter)
+	}
+
+	// Without a config path, tagging is disabled
+	tuiKeys(m, "t")
+	if m.mode != tuiBrowse || !strings.Contains(m.status, "Read-only") {
+		t.Errorf("expected read-only status, got mode %d, status %q", m.mode, m.status)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("expected q to quit")
+	}
+}
+
+func TestTUI_TagAndExclude(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("# my config\ncurrency: SEK\n"), 0644)
+	cfg := &Config{}
+	m := newTUIModel(tuiTestSubscriptions(), cfg, configPath, OutputOptions{Currency: GetCurrency("SEK")})
+
+	tuiKeys(m, "t", "s", "t", "r", "e", "a", "m", "enter")
+	if tags := cfg.GetTags("Netflix"); len(tags) != 1 || tags[0] != "stream" {
+		t.Errorf("expected Netflix to be tagged in memory, got %v", tags)
+	}
+
+	tuiKeys(m, "x", "n")
+	if len(m.subs) != 3 {
+		t.Error("expected n to cancel the exclusion")
+	}
+	tuiKeys(m, "x", "y")
+	if len(m.subs) != 2 || m.selected().Name != "Spotify" {
+		t.Errorf("expected Netflix to be excluded, got %+v", m.visible)
+	}
+
+	loaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load edited config: %v", err)
+	}
+	if tags := loaded.GetTags("Netflix"); len(tags) != 1 || tags[0] != "stream" {
+		t.Errorf("expected tag in config file, got %v", tags)
+	}
+	if !loaded.ShouldExclude(Subscription{Name: "Netflix"}) || loaded.ShouldExclude(Subscription{Name: "Netflix Kids"}) {
+		t.Error("expected an exact-name exclusion for Netflix in the config file")
+	}
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), "# my config") || loaded.Currency != "SEK" {
+		t.Errorf("expected existing content to be kept, got:\n%s", data)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build js && wasm
+
+package internal
+
+import "errors"
+
+// RunTUI is not available in the browser, which has no terminal
+func RunTUI(subs []Subscription, cfg *Config, configPath string, opts OutputOptions) error {
+	return errors.New("the dashboard needs a terminal and is not available in WebAssembly builds")
+}
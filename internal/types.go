@@ -1,11 +1,30 @@
 package internal
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 type Transaction struct {
 	Date   time.Time
 	Text   string
 	Amount float64
+
+	// Optional fields, set by parsers when the export contains them
+	ID       string   // bank's transaction ID or reference
+	Account  string   // account name or number
+	Currency string   // currency code of Amount (ISO 4217)
+	Category string   // bank's category
+	Balance  *float64 // account balance after the transaction
+	RawText  string   // text as exported, when Text was cleaned up or renamed by a group
+
+	// StatementDay is the statement cut-off day (1-31) of the credit card the transaction was
+	// made with, so monthly patterns follow its billing cycles; 0 for calendar months
+	StatementDay int
+
+	// Person is the household member whose export the transaction is from (see
+	// SourceFile.Person); payees are detected separately per person
+	Person string
 }
 
 type SubscriptionStatus string
@@ -13,6 +32,10 @@ type SubscriptionStatus string
 const (
 	StatusActive  SubscriptionStatus = "active"
 	StatusStopped SubscriptionStatus = "stopped"
+
+	// StatusPossible is a known subscription with a single payment, which may be a one-off
+	// purchase (see Config.KnownRequireRecurrence); it isn't counted in totals
+	StatusPossible SubscriptionStatus = "possible"
 )
 
 type Subscription struct {
@@ -21,11 +44,37 @@ type Subscription struct {
 	LatestAmount float64 // most recent payment amount (used for totals)
 	MinAmount    float64
 	MaxAmount    float64
+	TotalPaid    float64 // sum of all payments (absolute), i.e. lifetime spend
 	Transactions []Transaction
 	StartDate    time.Time
 	LastDate     time.Time
-	TypicalDay   int // typical day of month for payment
+	TypicalDay   int      // typical day of month for payment
+	Interval     Interval // months between payments (0 is treated as monthly)
 	Status       SubscriptionStatus
+	Share        float64 // your share of a shared subscription (0 = not shared); amounts are already your share
+	Person       string  // household member paying it, for labeled exports (see Transaction.Person)
+	Strategy     string  // name of the detection strategy that found it (see StrategyByName)
+	Commitment   string  // kind of recurring commitment, e.g. CommitmentLoan; "" for subscriptions (see SplitCommitments)
+
+	// AnnualCharges are much larger yearly charges billed on top of the monthly payments, such
+	// as a card's annual fee; they aren't in Transactions (see matchWithAnnualCharges)
+	AnnualCharges []Transaction
+}
+
+// MonthlyCost returns the latest amount (absolute) spread over the months of the billing interval,
+// plus the latest yearly charge spread over a year
+func (s Subscription) MonthlyCost() float64 {
+	cost := math.Abs(s.LatestAmount)
+	if s.Interval > 1 {
+		cost /= float64(s.Interval)
+	}
+	return cost + s.AnnualCharge()/12
+}
+
+// AgeMonths returns the months from the first to the last payment, i.e. how long the
+// subscription has been (or was) paid for
+func (s Subscription) AgeMonths() int {
+	return max(monthIndex(s.LastDate)-monthIndex(s.StartDate), 0)
 }
 
 type DateRange struct {
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// UnusedEntry is a config entry that matched none of the transactions
+type UnusedEntry struct {
+	Section string // config section, e.g. "descriptions" or "exclude"
+	Entry   string // name, pattern or group name
+}
+
+// UnusedConfigEntries returns the config entries that matched none of txs (as parsed, before
+// renames and groups): rename rules, groups and amount bands that no transaction went through,
+// exclude and commitment patterns that match no payee, and descriptions, tags, categories,
+// split, shared_with and business entries for names of no payee. Banks change their transaction texts
+// now and then, which silently turns such entries stale. Known patterns aren't checked, since
+// most are built in. Entries are sorted by section and entry.
+func UnusedConfigEntries(cfg *Config, txs []Transaction) []UnusedEntry {
+	if cfg == nil {
+		return nil
+	}
+	usage := newConfigUsage(cfg)
+	for _, tx := range txs {
+		usage.add(tx)
+	}
+	return usage.unused()
+}
+
+// configUsage collects what config entries transactions went through, one transaction at a
+// time, so that streaming detection can report unused entries too (see UnusedConfigEntries)
+type configUsage struct {
+	cfg     *Config
+	renames map[string]bool
+	groups  map[string]bool
+	bands   map[*AmountBand]bool
+	payees  map[string]bool // folded names after renames and groups
+	names   []string
+}
+
+func newConfigUsage(cfg *Config) *configUsage {
+	return &configUsage{
+		cfg:     cfg,
+		renames: make(map[string]bool),
+		groups:  make(map[string]bool),
+		bands:   make(map[*AmountBand]bool),
+		payees:  make(map[string]bool),
+	}
+}
+
+// add records the config entries a transaction (as parsed) goes through
+func (u *configUsage) add(tx Transaction) {
+	cfg := u.cfg
+	text := cfg.renamed(tx.Text)
+	if text != tx.Text {
+		u.renames[renameKey(tx.Text)] = true
+	}
+	for _, group := range cfg.Groups {
+		if slices.ContainsFunc(group.regexes, func(re *regexp.Regexp) bool { return re.MatchString(text) }) {
+			u.groups[group.Name] = true
+			text = group.Name
+		}
+	}
+	if band := cfg.amountBand(text, tx.Amount); band != nil {
+		u.bands[band] = true
+		u.payees[foldPayee(text)] = true
+		text = band.Name
+	}
+	if key := foldPayee(text); !u.payees[key] {
+		u.payees[key] = true
+		u.names = append(u.names, text)
+	}
+}
+
+// unused returns the config entries none of the added transactions went through, sorted by
+// section and entry
+func (u *configUsage) unused() []UnusedEntry {
+	cfg, names, payees := u.cfg, u.names, u.payees
+	var unused []UnusedEntry
+	for name := range cfg.Rename {
+		if !u.renames[renameKey(name)] {
+			unused = append(unused, UnusedEntry{Section: "rename", Entry: name})
+		}
+	}
+	for _, group := range cfg.Groups {
+		if !u.groups[group.Name] {
+			unused = append(unused, UnusedEntry{Section: "groups", Entry: group.Name})
+		}
+	}
+	for name := range cfg.SplitByAmount {
+		bands := cfg.splitByAmount[foldPayee(name)]
+		for i := range bands {
+			if !u.bands[&bands[i]] {
+				unused = append(unused, UnusedEntry{Section: "split_by_amount", Entry: name + ": " + bands[i].Name})
+			}
+		}
+	}
+	for _, rule := range cfg.excludeRules {
+		matched := false
+		for _, name := range names {
+			if rule.regex.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unused = append(unused, UnusedEntry{Section: "exclude", Entry: rule.Pattern})
+		}
+	}
+	for _, rule := range cfg.Commitments {
+		if !slices.ContainsFunc(names, rule.regex.MatchString) {
+			unused = append(unused, UnusedEntry{Section: "commitments", Entry: rule.Pattern})
+		}
+	}
+	byName := func(section string, entries []string) {
+		for _, name := range entries {
+			if !payees[foldPayee(name)] {
+				unused = append(unused, UnusedEntry{Section: section, Entry: name})
+			}
+		}
+	}
+	byName("descriptions", mapKeys(cfg.Descriptions))
+	byName("tags", mapKeys(cfg.Tags))
+	byName("categories", mapKeys(cfg.Categories))
+	byName("split", mapKeys(cfg.Split))
+	byName("shared_with", mapKeys(cfg.SharedWith))
+	byName("business", mapKeys(cfg.Business))
+
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Section != unused[j].Section {
+			return unused[i].Section < unused[j].Section
+		}
+		return unused[i].Entry < unused[j].Entry
+	})
+	return unused
+}
+
+// mapKeys returns the keys of m
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// PrintUnusedConfigEntries lists config entries that matched nothing (see UnusedConfigEntries)
+func PrintUnusedConfigEntries(w io.Writer, unused []UnusedEntry) {
+	if len(unused) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Warning: %d config entries matched no transactions (stale after a bank changed its texts?):\n", len(unused))
+	for _, e := range unused {
+		fmt.Fprintf(w, "  %s: %q\n", e.Section, e.Entry)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnusedConfigEntries(t *testing.T) {
+	cfg, err := parseConfig([]byte(`descriptions:
+  NETFLIX: Movies
+  Old Gym: Training
+tags:
+  Music: [music]
+business:
+  Viaplay: 0.25
+rename:
+  spotify: Spotify
+  hbo max: HBO Max
+groups:
+  - name: Music
+    patterns: ["^Spotify$"]
+  - name: Phone
+    patterns: ["^TELIA"]
+exclude:
+  - Netflix
+  - "^Mobile"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := []Transaction{
+		{Date: date("2025-01-01"), Text: "SPOTIFY 1234", Amount: -119},
+		{Date: date("2025-01-15"), Text: "Netflix", Amount: -99},
+	}
+
+	want := []UnusedEntry{
+		{Section: "business", Entry: "Viaplay"},
+		{Section: "descriptions", Entry: "Old Gym"},
+		{Section: "exclude", Entry: "^Mobile"},
+		{Section: "groups", Entry: "Phone"},
+		{Section: "rename", Entry: "hbo max"},
+	}
+	unused := UnusedConfigEntries(cfg, txs)
+	if !reflect.DeepEqual(unused, want) {
+		t.Fatalf("UnusedConfigEntries() = %+v, want %+v", unused, want)
+	}
+
+	stream := NewDetector().Stream(cfg)
+	for _, tx := range txs {
+		stream.Add(tx)
+	}
+	if streamed := stream.UnusedConfigEntries(); !reflect.DeepEqual(streamed, want) {
+		t.Errorf("streamed UnusedConfigEntries() = %+v, want %+v", streamed, want)
+	}
+
+	var buf bytes.Buffer
+	PrintUnusedConfigEntries(&buf, unused)
+	if !strings.Contains(buf.String(), "Warning: 5 config entries matched no transactions") || !strings.Contains(buf.String(), "  groups: \"Phone\"\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	PrintUnusedConfigEntries(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("expected no output without unused entries, got:\n%s", buf.String())
+	}
+}
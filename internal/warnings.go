@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ParseWarning is a row that a parser skipped because it couldn't be read
+type ParseWarning struct {
+	File   string
+	Line   int // 1-based line (or spreadsheet row), 0 if unknown
+	Reason string
+}
+
+// ParseWarnings collects the rows skipped while parsing, from any number of parsers at once.
+// With strict set, skipping a row fails the parse instead (see SkipRow).
+type ParseWarnings struct {
+	strict   bool
+	mu       sync.Mutex
+	warnings []ParseWarning
+}
+
+// NewParseWarnings creates an empty collection of parse warnings
+func NewParseWarnings(strict bool) *ParseWarnings {
+	return &ParseWarnings{strict: strict}
+}
+
+// Warnings returns the collected warnings by file and line
+func (w *ParseWarnings) Warnings() []ParseWarning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	warnings := append([]ParseWarning(nil), w.warnings...)
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].File != warnings[j].File {
+			return warnings[i].File < warnings[j].File
+		}
+		return warnings[i].Line < warnings[j].Line
+	})
+	return warnings
+}
+
+// ByFile returns the collected warnings grouped by file, and the files in order
+func (w *ParseWarnings) ByFile() ([]string, map[string][]ParseWarning) {
+	var files []string
+	byFile := make(map[string][]ParseWarning)
+	for _, warning := range w.Warnings() {
+		if _, ok := byFile[warning.File]; !ok {
+			files = append(files, warning.File)
+		}
+		byFile[warning.File] = append(byFile[warning.File], warning)
+	}
+	return files, byFile
+}
+
+// has reports whether any warnings were collected for the file at path
+func (w *ParseWarnings) has(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, warning := range w.warnings {
+		if warning.File == path {
+			return true
+		}
+	}
+	return false
+}
+
+type parseWarningsKey struct{}
+
+// WithParseWarnings returns a context whose parsers report skipped rows to w
+func WithParseWarnings(ctx context.Context, w *ParseWarnings) context.Context {
+	return context.WithValue(ctx, parseWarningsKey{}, w)
+}
+
+// parseWarningsFrom returns the ParseWarnings in ctx, or nil
+func parseWarningsFrom(ctx context.Context) *ParseWarnings {
+	w, _ := ctx.Value(parseWarningsKey{}).(*ParseWarnings)
+	return w
+}
+
+// SkipRow reports that the parser of the file at path skipped a row it couldn't read (e.g., an
+// invalid date or amount), so that the data loss isn't silent. Parsers call it instead of just
+// skipping the row and return its error, which is a *ParseError when strict parsing is enabled.
+// Rows that aren't transactions, such as blank lines, are skipped without a warning.
+func SkipRow(ctx context.Context, path string, line int, reason string) error {
+	w := parseWarningsFrom(ctx)
+	if w == nil {
+		return nil
+	}
+	if w.strict {
+		return &ParseError{File: path, Line: line, Err: errors.New(reason)}
+	}
+	w.mu.Lock()
+	w.warnings = append(w.warnings, ParseWarning{File: path, Line: line, Reason: reason})
+	w.mu.Unlock()
+	return nil
+}
@@ -1,8 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/GiGurra/boa/pkg/boa"
 	"github.com/gigurra/subscription-detector/internal"
@@ -10,20 +23,78 @@ import (
 )
 
 type Params struct {
-	Source        string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
-	Files         []string `descr:"Path(s) to transaction file(s)" positional:"true"`
-	Config        string   `descr:"Path to config file (YAML)" optional:"true"`
-	InitConfig    string   `descr:"Generate config template and save to path" optional:"true"`
-	Show          string   `descr:"Which subscriptions to show" default:"active" alts:"active,stopped,all" strict:"true"`
-	Sort          string   `descr:"Sort field for output" default:"name" alts:"name,description,amount" strict:"true"`
-	SortDir       string   `descr:"Sort direction" default:"asc" alts:"asc,desc" strict:"true"`
-	Output        string   `descr:"Output format" default:"table" alts:"table,json" strict:"true"`
-	Tolerance     float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
-	SuggestGroups bool     `descr:"Analyze and suggest potential transaction groups" optional:"true"`
-	Tags          []string `descr:"Filter by tags (e.g., entertainment, insurance)" optional:"true"`
-	Currency      string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Source                 string   `descr:"Default format (or use format:path syntax)" alts:"handelsbanken-xlsx,simple-json" optional:"true"`
+	InvertAmounts          []string `descr:"Formats or files (path patterns) that list charges as positive amounts; their amounts are negated" optional:"true"`
+	StatementDay           []string `descr:"Statement cut-off day of credit card exports, so monthly payments are matched per billing cycle: DAY for all files, or FORMAT=DAY or PATTERN=DAY" optional:"true"`
+	Person                 []string `descr:"Label the exports of household members for a combined report with per-person subtotals: NAME=PATTERN (path pattern or format, e.g., alice=alice/*.xlsx)" optional:"true"`
+	Files                  []string `descr:"Path(s) to transaction file(s)" positional:"true" optional:"true"`
+	Config                 string   `descr:"Path to config file (YAML)" optional:"true"`
+	InitConfig             string   `descr:"Generate config template and save to path" optional:"true"`
+	Show                   string   `descr:"Which subscriptions to show" default:"active" alts:"active,stopped,all" strict:"true"`
+	Sort                   string   `descr:"Sort field for output" default:"name" alts:"name,description,amount" strict:"true"`
+	SortDir                string   `descr:"Sort direction" default:"asc" alts:"asc,desc" strict:"true"`
+	Out                    string   `descr:"Write output to file instead of stdout" short:"o" optional:"true"`
+	Output                 string   `descr:"Output format (gsheet = write to a Google spreadsheet, see --sheet-id; yaml only with --suggest-groups; or a renderer plugin from the config)" default:"table" alts:"table,json,yaml,gsheet" strict:"false"`
+	Tolerance              float64  `descr:"Max price change between months (0.35 = 35%)" default:"0.35"`
+	ToleranceSweep         bool     `descr:"Run detection at tolerances from 5% to 60% and show which subscriptions appear and disappear at each step, to pick a --tolerance" optional:"true"`
+	IncludePartialMonths   bool     `descr:"Also use incomplete months (e.g., the current one) for pattern detection, for data covering only a few months" optional:"true"`
+	IgnoreDataGaps         bool     `descr:"Don't count months without any transactions (missing exports) when deciding whether a subscription has stopped" optional:"true"`
+	AsOf                   string   `descr:"Evaluate subscription status as of this date (YYYY-MM-DD) instead of the end of the data; later transactions are ignored" optional:"true"`
+	SuggestGroups          bool     `descr:"Analyze and suggest potential transaction groups" optional:"true"`
+	ApplySuggestions       bool     `descr:"Add the suggested groups to the config file (asking for each one on a terminal)" optional:"true"`
+	SuggestExclusions      bool     `descr:"List detected subscriptions that look like rent, loans or transfers, with exclude rules for the config" optional:"true"`
+	SuggestKnown           bool     `descr:"List payees seen only once that look like digital services, with known subscription patterns for the config" optional:"true"`
+	SuggestRenames         bool     `descr:"List payees whose names differ only by case, whitespace or trailing digits, with rename rules for the config" optional:"true"`
+	AppStoreCharges        bool     `descr:"Cluster APPLE.COM/BILL and GOOGLE PLAY charges by amount and cadence into probable subscriptions, with split_by_amount bands for the config" optional:"true"`
+	KnownRequireRecurrence bool     `descr:"List known subscriptions with a single payment apart as possible subscriptions, not counted in totals (they may be one-off purchases)" optional:"true"`
+	Tags                   []string `descr:"Filter by tags: any of TAG,TAG; all of TAG+TAG; !TAG to exclude (e.g., entertainment,!gaming)" optional:"true"`
+	Filter                 string   `descr:"Only show subscriptions whose name or description matches this regex (case-insensitive, e.g., \"spotify|netflix\")" optional:"true"`
+	Currency               string   `descr:"Currency code (e.g., USD, EUR, SEK)" optional:"true"`
+	Precision              int      `descr:"Decimals in displayed amounts (e.g., 2 for 9.99 instead of 10; default: config, else 0)" optional:"true"`
+	Locale                 string   `descr:"Locale for dates, numbers and labels (e.g., sv-SE, en-US); auto-detected if not set" optional:"true"`
+	Sparkline              bool     `descr:"Show a sparkline of payment amounts over time" optional:"true"`
+	Color                  string   `descr:"When to use colors in table output" default:"auto" alts:"auto,always,never" strict:"true"`
+	NoColor                bool     `descr:"Disable colors (same as --color never)" optional:"true"`
+	MaxWidth               int      `descr:"Max table width in characters (0 = unlimited)" default:"0"`
+	SummaryOnly            bool     `descr:"Only print subscription counts and totals" optional:"true"`
+	GroupBy                string   `descr:"Split the table into sections with subtotals by tag, category, status, account or interval" alts:"tag,category,status,account,interval" optional:"true"`
+	Top                    int      `descr:"Only list the N most expensive active subscriptions, plus a row summing up the others" optional:"true"`
+	KPIs                   bool     `name:"kpis" descr:"Also show key figures (average and most expensive subscription, started and stopped this year) and savings opportunities" optional:"true"`
+	ComparePrices          bool     `descr:"Also list subscriptions costing noticeably more than the list price of their service's standard plan (see price_database in the config)" optional:"true"`
+	OlderThan              int      `descr:"Also list active subscriptions paid for at least this many years, to review long-forgotten charges (0 = off)" optional:"true"`
+	Country                string   `descr:"Country code of the list prices for --compare-prices (e.g., SE; default: config, else the country of the currency)" optional:"true"`
+	Quiet                  bool     `descr:"Suppress informational messages" optional:"true"`
+	PrintSchema            bool     `descr:"Print the JSON Schema of the JSON output and exit" optional:"true"`
+	State                  string   `descr:"Path to state file (default ~/.subscription-detector/state.json)" optional:"true"`
+	SaveSnapshot           bool     `descr:"Save detected subscriptions as a snapshot in the state file" optional:"true"`
+	CompareWithLast        bool     `descr:"Highlight changes since the last saved snapshot" optional:"true"`
+	Imported               bool     `descr:"Include transactions stored with the import subcommand" optional:"true"`
+	Events                 bool     `descr:"Include subscription lifecycle events in JSON output" optional:"true"`
+	ReportRejections       bool     `descr:"Include the payees that were evaluated but rejected, with reason codes, in JSON output" optional:"true"`
+	ShowRuleSource         bool     `descr:"Show the mechanism (detection strategy, known pattern) and config rules (renames, groups, exclusions, ...) behind each subscription, to debug the config" optional:"true"`
+	Notify                 bool     `descr:"Send changes since the last snapshot to the notifiers in the config (implies --compare-with-last)" optional:"true"`
+	FailIfMonthlyOver      float64  `descr:"Exit with code 2 if the monthly total of active subscriptions exceeds this amount (0 = disabled)" default:"0"`
+	FailOnNew              bool     `descr:"Exit with code 2 if new subscriptions appeared since the last snapshot (implies --compare-with-last)" optional:"true"`
+	FailOnPriceIncrease    bool     `descr:"Exit with code 2 if a price increased since the last snapshot (implies --compare-with-last)" optional:"true"`
+	SheetID                string   `name:"sheet-id" descr:"Google spreadsheet ID for --output gsheet (from the sheet URL)" optional:"true"`
+	SheetCredentials       string   `descr:"Service account key file for --output gsheet (default $GOOGLE_APPLICATION_CREDENTIALS)" optional:"true"`
+	Stream                 bool     `descr:"Detect without loading all transactions into memory (for very large exports)" optional:"true"`
+	Progress               bool     `descr:"Log parsing progress to stderr every few seconds (a progress bar is shown on terminals for large inputs)" optional:"true"`
+	Cache                  bool     `descr:"Cache parsed transactions by file content, so parsing unchanged files again is instant" optional:"true"`
+	CacheDir               string   `descr:"Directory for --cache (default ~/.subscription-detector/cache)" optional:"true"`
+	Stable                 bool     `descr:"Omit fields that change between runs on the same data (e.g., snapshot timestamps), for golden-file tests and diffs" optional:"true"`
+	Timezone               string   `descr:"Timezone that timestamps in exports are converted to before taking their date (e.g., UTC, Europe/Stockholm; default local)" optional:"true"`
+	XlsxSheets             []string `descr:"Only read these sheets of Excel workbooks (default: all sheets with transactions)" optional:"true"`
+	StrictParse            bool     `descr:"Fail if a row of an export can't be parsed (e.g., an invalid date or amount) instead of skipping it with a warning" optional:"true"`
 }
 
+// exitCodeThresholdExceeded is the exit code when a --fail-* condition triggers
+const exitCodeThresholdExceeded = 2
+
+// exitCode is set by commands that complete normally but should still exit non-zero.
+// Commands set it instead of calling os.Exit so deferred cleanup (e.g., closing --out files) runs.
+var exitCode int
+
 func main() {
 	boa.CmdT[Params]{
 		Use:   "subscription-detector",
@@ -34,72 +105,151 @@ func main() {
 			boa.ParamEnricherShort,
 			boa.ParamEnricherBool,
 		),
-		RunFunc: run,
+		SubCmds: boa.SubCmds(
+			historyCmd(),
+			importCmd(),
+			eventsCmd(),
+			reportCmd(),
+			budgetCmd(),
+			simulateCmd(),
+			statsCmd(),
+			convertCmd(),
+			exportPaymentsCmd(),
+			anonymizeCmd(),
+			generateCmd(),
+			tuiCmd(),
+			tagCmd(),
+			describeCmd(),
+			excludeCmd(),
+			listSourcesCmd(),
+			watchCmd(),
+			serveCmd(),
+		),
+		RunFunc: withErrors(run),
 	}.Run()
+	os.Exit(exitCode)
 }
 
-func run(params *Params, _ *cobra.Command, _ []string) {
-	// Helper to print info messages (suppressed in JSON mode)
-	info := func(format string, args ...any) {
-		if params.Output != "json" {
-			fmt.Printf(format, args...)
+// errNoFiles is returned by commands that need transaction files but got none
+var errNoFiles = errors.New("no transaction files given (or use --imported)")
+
+// withErrors adapts a command that returns an error to boa's RunFunc. The error is printed and
+// the exit code set to 1, after deferred cleanup in the command (e.g., closing --out files) ran.
+// The command's context (cmd.Context()) is cancelled on Ctrl+C.
+func withErrors[T any](run func(params *T, cmd *cobra.Command, args []string) error) func(*T, *cobra.Command, []string) {
+	return func(params *T, cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		cmd.SetContext(ctx)
+
+		if err := run(params, cmd, args); err != nil {
+			if errors.Is(err, errNoFiles) {
+				cmd.Usage()
+				fmt.Fprintln(os.Stderr)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = 1
 		}
 	}
+}
 
-	var transactions []internal.Transaction
-	for _, fileArg := range params.Files {
-		format, filePath := internal.ParseFileArg(fileArg)
-		if format == "" {
-			format = params.Source // Fall back to --source flag
-		}
-		if format == "" {
-			fmt.Fprintf(os.Stderr, "Error: no format specified for %s (use format:path or --source)\n", filePath)
-			os.Exit(1)
+func run(params *Params, cmd *cobra.Command, _ []string) (err error) {
+	if params.PrintSchema {
+		internal.PrintJSONSchema(os.Stdout)
+		return nil
+	}
+	if len(params.Files) == 0 && !params.Imported {
+		return errNoFiles
+	}
+	if params.Output == "gsheet" && params.SheetID == "" {
+		return errors.New("--output gsheet requires --sheet-id")
+	}
+	if params.Output == "yaml" && !params.SuggestGroups {
+		return errors.New("--output yaml is only supported with --suggest-groups")
+	}
+	if params.ApplySuggestions && !params.SuggestGroups {
+		return errors.New("--apply-suggestions requires --suggest-groups")
+	}
+	if params.Top < 0 {
+		return errors.New("--top must be a positive number")
+	}
+	if params.OlderThan < 0 {
+		return errors.New("--older-than must be a positive number of years")
+	}
+	if _, err := internal.ParseTagExpression(params.Tags); err != nil {
+		return err
+	}
+	filter, err := compileFilter(params.Filter)
+	if err != nil {
+		return err
+	}
+
+	// Helper to print info messages (suppressed in JSON, YAML, plugin output and quiet mode)
+	info := func(format string, args ...any) {
+		if (params.Output == "table" || params.Output == "gsheet") && !params.Quiet {
+			fmt.Printf(format, args...)
 		}
+	}
 
-		parser, err := internal.GetParser(format)
+	if params.Stream && params.SuggestGroups {
+		return errors.New("--suggest-groups needs all transactions and can't be combined with --stream")
+	}
+	if params.Stream && params.SuggestKnown {
+		return errors.New("--suggest-known needs all transactions and can't be combined with --stream")
+	}
+	if params.Stream && params.SuggestRenames {
+		return errors.New("--suggest-renames needs all transactions and can't be combined with --stream")
+	}
+	if params.Stream && params.AppStoreCharges {
+		return errors.New("--app-store-charges needs all transactions and can't be combined with --stream")
+	}
+	if params.Stream && params.ToleranceSweep {
+		return errors.New("--tolerance-sweep needs all transactions and can't be combined with --stream")
+	}
+	detectorOpts := []internal.DetectorOption{internal.WithTolerance(params.Tolerance), internal.WithPartialMonths(params.IncludePartialMonths), internal.WithIgnoreDataGaps(params.IgnoreDataGaps)}
+	var asOf time.Time
+	if params.AsOf != "" {
+		asOf, err = time.Parse("2006-01-02", params.AsOf)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("invalid --as-of %q (expected YYYY-MM-DD)", params.AsOf)
 		}
+		detectorOpts = append(detectorOpts, internal.WithClock(internal.FixedClock(asOf)))
+	}
+	var rejections *internal.RejectionReport
+	if params.ReportRejections || params.ShowRuleSource {
+		rejections = internal.NewRejectionReport()
+		detectorOpts = append(detectorOpts, internal.WithObserver(rejections))
+	}
 
-		txs, err := parser.Parse(filePath)
+	ctx, err := withTimezone(withParseCache(internal.WithSheets(cmd.Context(), params.XlsxSheets), params.Cache, params.CacheDir), params.Timezone)
+	if err != nil {
+		return err
+	}
+	parseWarnings := internal.NewParseWarnings(params.StrictParse)
+	ctx = internal.WithParseWarnings(ctx, parseWarnings)
+	ctx, progress := startProgress(ctx, params.Files, params.Progress, params.Quiet)
+	var transactions []internal.Transaction
+	if !params.Stream {
+		transactions, err = loadAllTransactions(ctx, params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay, persons: params.Person}, params.Imported, params.State, aboveProgress(progress, info))
+		stopProgress(progress)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing file %s: %v\n", filePath, err)
-			os.Exit(1)
+			return err
 		}
-		info("Loaded %d transactions from %s\n", len(txs), filePath)
-		transactions = append(transactions, txs...)
+		info("Total: %d transactions from %d file(s)\n", len(transactions), len(params.Files))
+		printParseWarnings(parseWarnings)
 	}
 
-	info("Total: %d transactions from %d file(s)\n", len(transactions), len(params.Files))
-
 	// Load config (from provided path or default location)
-	var cfg *internal.Config
-	configPath := params.Config
-	if configPath == "" {
-		// Try default config path
-		defaultPath := internal.DefaultConfigPath()
-		if _, err := os.Stat(defaultPath); err == nil {
-			configPath = defaultPath
-		}
+	cfg, err := loadConfig(params.Config, info)
+	if err != nil {
+		return err
 	}
-	if configPath != "" {
-		var err error
-		cfg, err = internal.LoadConfig(configPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
-		}
-		info("Loaded config from %s\n", configPath)
-	} else {
-		// No config file - use default config with built-in known subscriptions
-		var err error
-		cfg, err = internal.NewDefaultConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating default config: %v\n", err)
-			os.Exit(1)
-		}
+	if params.KnownRequireRecurrence {
+		cfg.KnownRequireRecurrence = true
+	}
+	renderer, err := resolveRenderer(params.Output)
+	if err != nil {
+		return err
 	}
 
 	// Resolve currency with precedence: CLI > config > locale > USD
@@ -107,67 +257,242 @@ func run(params *Params, _ *cobra.Command, _ []string) {
 	if currencyCode == "" && cfg != nil {
 		currencyCode = cfg.Currency
 	}
-	if currencyCode == "" {
-		currencyCode = internal.DetectSystemCurrency()
-	}
-	if currencyCode == "" {
-		currencyCode = "USD"
+	currency, locale, err := resolveCurrencyAndLocale(currencyCode, params.Locale)
+	if err != nil {
+		return err
 	}
-	currency := internal.GetCurrency(currencyCode)
+	currency = currency.WithPrecision(resolvePrecision(cmd, params.Precision, cfg))
+	cfg.ScaleKnownBounds(currency.Code)
 
-	// Apply grouping from config (combines transactions with different names into one)
-	transactions, _ = cfg.ApplyGroups(transactions)
+	detector := internal.NewDetector(detectorOpts...)
+	var stream *internal.TransactionStream
+	var completeMonths []string
+	var dateRange internal.DateRange
+	var missingMonths []string
+	var unusedConfig []internal.UnusedEntry
+	if params.Stream {
+		// Fold transactions into per-payee aggregates while parsing
+		stream = detector.Stream(cfg)
+		err := streamAllTransactions(ctx, params.Files, params.Source, sourceOptions{invert: params.InvertAmounts, statementDays: params.StatementDay, persons: params.Person}, params.Imported, params.State, stream, aboveProgress(progress, info))
+		stopProgress(progress)
+		if err != nil {
+			return err
+		}
+		info("Total: %d transactions from %d file(s)\n", stream.Len(), len(params.Files))
+		printParseWarnings(parseWarnings)
+		completeMonths, dateRange = stream.Coverage()
+		missingMonths = stream.MissingMonths()
+		unusedConfig = stream.UnusedConfigEntries()
+	} else {
+		// Apply grouping from config (combines transactions with different names into one)
+		unusedConfig = internal.UnusedConfigEntries(cfg, transactions)
+		transactions, _ = cfg.ApplyGroups(transactions)
 
-	// Check data coverage
-	completeMonths, dateRange := internal.AnalyzeDataCoverage(transactions)
+		// Check data coverage (up to --as-of, if set)
+		if params.AsOf != "" {
+			transactions, completeMonths, dateRange = internal.TransactionsAsOf(transactions, asOf)
+		} else {
+			completeMonths, dateRange = internal.AnalyzeDataCoverage(transactions)
+		}
+		missingMonths = internal.MissingMonths(transactions)
+	}
 	info("Data range: %s to %s\n", dateRange.Start.Format("2006-01-02"), dateRange.End.Format("2006-01-02"))
-	info("Complete months: %d\n\n", len(completeMonths))
+	if params.AsOf != "" {
+		info("Status as of: %s (later transactions are ignored)\n", params.AsOf)
+	}
+	if params.IncludePartialMonths {
+		info("Complete months: %d (partial months are used for detection too)\n\n", len(completeMonths))
+	} else {
+		info("Complete months: %d\n\n", len(completeMonths))
+	}
 
 	if len(completeMonths) < 3 {
 		fmt.Fprintf(os.Stderr, "Warning: Less than 3 complete months of data. Subscription detection may be unreliable.\n\n")
 	}
+	if len(missingMonths) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: No transactions in %s. An export may be missing", strings.Join(missingMonths, ", "))
+		if params.IgnoreDataGaps {
+			fmt.Fprintf(os.Stderr, "; these months are ignored for subscription status.\n\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "; subscriptions paid in these months may show as stopped (see --ignore-data-gaps).\n\n")
+		}
+	}
+	internal.PrintUnusedConfigEntries(os.Stderr, unusedConfig)
 
-	// Detect known subscriptions first (these can match even with 1 occurrence)
-	knownSubs, matchedTexts := internal.DetectKnownSubscriptions(transactions, dateRange, cfg)
-
-	// Filter out transactions that matched known subscriptions from regular detection
-	regularTxs := internal.FilterOutMatched(transactions, matchedTexts)
-
-	// Filter to only complete months for pattern detection
-	filtered := internal.FilterToCompleteMonths(regularTxs, completeMonths)
-	subscriptions := internal.DetectSubscriptions(filtered, regularTxs, dateRange, params.Tolerance)
-
-	// Merge known and detected subscriptions
-	subscriptions = append(knownSubs, subscriptions...)
-
-	// Apply exclusion filters from config
-	subscriptions = internal.FilterByExclusions(subscriptions, cfg)
+	var subscriptions []internal.Subscription
+	if stream != nil {
+		subscriptions, err = stream.Detect(cmd.Context())
+	} else {
+		subscriptions, err = detector.DetectAll(cmd.Context(), transactions, completeMonths, dateRange, cfg)
+	}
+	if err != nil {
+		return err
+	}
+	// Transfers and loan payments are listed apart, and left out of totals, snapshots and checks
+	subscriptions, commitments := internal.SplitCommitments(subscriptions, cfg)
 
 	// Generate config template if requested
 	if params.InitConfig != "" {
 		template := internal.GenerateConfigTemplate(subscriptions)
 		if err := template.Save(params.InitConfig); err != nil {
-			fmt.Fprintf(os.Stderr, "Error saving config template: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("saving config template: %w", err)
 		}
 		fmt.Printf("Config template saved to %s\n", params.InitConfig)
-		return
+		return nil
+	}
+
+	// Rendered output goes to stdout unless --out is given
+	var out io.Writer = os.Stdout
+	if params.Out != "" {
+		f, err := createOutputFile(params.Out)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("writing output file: %w", closeErr)
+			}
+			if err == nil {
+				info("Output written to %s\n", params.Out)
+			}
+		}()
+		out = f
 	}
 
+	configureColors(params.Color, params.NoColor, out)
+
 	// Suggest groups if requested
 	if params.SuggestGroups {
 		suggestions := internal.SuggestGroups(transactions, params.Tolerance)
-		internal.PrintGroupSuggestions(suggestions)
-		return
+		if params.Output == "json" || params.Output == "yaml" {
+			if err := internal.WriteGroupSuggestions(out, suggestions, params.Output); err != nil {
+				return fmt.Errorf("writing suggestions: %w", err)
+			}
+		} else {
+			internal.PrintGroupSuggestions(out, suggestions)
+		}
+		if params.ApplySuggestions {
+			return applyGroupSuggestions(params.Config, suggestions, info)
+		}
+		return nil
+	}
+	if params.SuggestKnown {
+		suggestions := internal.SuggestKnown(transactions, cfg)
+		internal.PrintKnownSuggestions(out, suggestions, currency)
+		return nil
+	}
+	if params.SuggestRenames {
+		internal.PrintRenameSuggestions(out, internal.SuggestRenames(transactions))
+		return nil
+	}
+	if params.AppStoreCharges {
+		internal.PrintAppStoreClusters(out, internal.ClusterAppStoreCharges(transactions), currency)
+		return nil
+	}
+	if params.SuggestExclusions {
+		suggestions := internal.SuggestExclusions(subscriptions)
+		internal.PrintExclusionSuggestions(out, suggestions, currency)
+		return nil
+	}
+	if params.ToleranceSweep {
+		steps, err := internal.ToleranceSweep(cmd.Context(), transactions, completeMonths, dateRange, cfg, internal.SweepTolerances(params.Tolerance), detectorOpts...)
+		if err != nil {
+			return err
+		}
+		if params.Output == "json" {
+			internal.PrintToleranceSweepJSON(out, steps, params.Tolerance, currency)
+		} else {
+			internal.PrintToleranceSweep(out, steps, params.Tolerance, internal.OutputOptions{Currency: currency, Locale: locale, MaxWidth: params.MaxWidth})
+		}
+		return nil
 	}
 
-	if len(subscriptions) == 0 {
+	opts := internal.OutputOptions{
+		ShowFilter: params.Show,
+		TagFilter:  params.Tags,
+		Filter:     params.Filter,
+		SortField:  params.Sort,
+		SortDir:    params.SortDir,
+		Currency:   currency,
+		Sparkline:  params.Sparkline,
+		MaxWidth:   params.MaxWidth,
+		Locale:     locale,
+		Stable:     params.Stable,
+		RuleSource: params.ShowRuleSource,
+		GroupBy:    params.GroupBy,
+		Top:        params.Top,
+		OlderThan:  params.OlderThan,
+	}
+	opts.Commitments = internal.FilterByStatus(commitments, params.Show)
+
+	// List prices, for --compare-prices and to tell plan changes from price changes
+	country := cmp.Or(params.Country, cfg.Country, internal.CountryForCurrency(currency.Code))
+	var prices *internal.PriceDatabase
+	if country != "" {
+		if prices, err = internal.LoadPriceDatabase(cfg.PriceDatabase); err != nil {
+			return err
+		}
+	}
+
+	// Compare with and/or save snapshots in the state file
+	compare := params.CompareWithLast || params.Notify || params.FailOnNew || params.FailOnPriceIncrease
+	if params.SaveSnapshot || compare {
+		statePath := resolveStatePath(params.State)
+		state, err := internal.LoadState(statePath)
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+
+		snapshot := internal.NewSnapshot(subscriptions, dateRange, currency.Code, time.Now())
+		if compare {
+			if last := state.LastSnapshot(); last != nil {
+				opts.Changes = &internal.ChangeReport{
+					Since:   last.Timestamp,
+					Changes: internal.CompareSnapshots(*last, snapshot),
+				}
+				internal.AnnotatePlanChanges(opts.Changes.Changes, prices, country, currency.Code)
+			} else {
+				info("No previous snapshot in %s to compare with\n\n", statePath)
+			}
+		}
+		if params.Notify {
+			notify(cmd.Context(), cfg, opts.Changes, currency, info)
+		}
+		if params.SaveSnapshot {
+			state.AddSnapshot(snapshot)
+			events := internal.DetectEvents(subscriptions)
+			internal.AnnotateEventPlans(events, prices, country, currency.Code)
+			state.RecordEvents(events)
+			if err := state.Save(statePath); err != nil {
+				return fmt.Errorf("saving state: %w", err)
+			}
+			info("Snapshot saved to %s\n\n", statePath)
+		}
+	}
+
+	if rejections != nil {
+		opts.Rejections = rejections.Rejections()
+		if !params.ReportRejections {
+			// Only the exclusions are rule sources
+			opts.Rejections = excludedOnly(opts.Rejections)
+		}
+	}
+
+	if len(subscriptions) == 0 && !params.SummaryOnly && params.Output != "gsheet" && renderer == nil {
 		if params.Output == "json" {
-			internal.PrintSubscriptionsJSON(os.Stdout, nil, cfg, currency)
+			internal.PrintSubscriptionsJSON(out, nil, cfg, opts)
 		} else {
-			fmt.Println("No subscriptions detected.")
+			fmt.Fprintln(out, locale.T("No subscriptions detected."))
+			if len(opts.Commitments) > 0 {
+				fmt.Fprintln(out)
+				internal.PrintCommitments(out, opts.Commitments, opts, cfg)
+			}
+			if opts.Changes != nil {
+				fmt.Fprintln(out)
+				internal.PrintChanges(out, opts.Changes, opts)
+			}
 		}
-		return
+		return nil
 	}
 
 	// Filter by status for display (but show total counts first)
@@ -177,17 +502,559 @@ func run(params *Params, _ *cobra.Command, _ []string) {
 	if len(params.Tags) > 0 {
 		displaySubs = internal.FilterByTags(displaySubs, params.Tags, cfg)
 	}
+	if filter != nil {
+		displaySubs = internal.FilterByPattern(displaySubs, filter, cfg)
+	}
+
+	if params.Events {
+		opts.Events = internal.DetectEvents(displaySubs)
+		internal.AnnotateEventPlans(opts.Events, prices, country, currency.Code)
+	}
+	if params.KPIs {
+		// Key figures cover stopped subscriptions too, whatever --show is
+		kpiSubs := internal.FilterByTags(subscriptions, params.Tags, cfg)
+		if filter != nil {
+			kpiSubs = internal.FilterByPattern(kpiSubs, filter, cfg)
+		}
+		kpis := internal.ComputeKPIs(kpiSubs, cfg, dateRange.End)
+		opts.KPIs = &kpis
+	}
+	if params.ComparePrices {
+		if prices == nil {
+			return fmt.Errorf("no country for the list prices of %s; give --country or set country in the config", currency.Code)
+		}
+		opts.PriceComparisons = internal.ComparePrices(displaySubs, prices, country, currency.Code)
+	}
+
+	if failures := checkThresholds(params, displaySubs, opts.Changes); len(failures) > 0 {
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "Threshold exceeded: %s\n", failure)
+		}
+		exitCode = exitCodeThresholdExceeded
+	}
+
+	if params.Output == "gsheet" {
+		return exportSheet(cmd.Context(), params, displaySubs, cfg, currency, info)
+	}
+	if renderer != nil {
+		return renderer.Render(cmd.Context(), out, displaySubs, cfg, opts)
+	}
+
+	if params.SummaryOnly {
+		if params.Output == "json" {
+			internal.PrintSummaryJSON(out, displaySubs, opts)
+		} else {
+			internal.PrintSummary(out, subscriptions, displaySubs, opts)
+		}
+		return nil
+	}
 
 	if params.Output == "json" {
-		internal.PrintSubscriptionsJSON(os.Stdout, displaySubs, cfg, currency)
+		internal.PrintSubscriptionsJSON(out, displaySubs, cfg, opts)
 	} else {
-		opts := internal.OutputOptions{
-			ShowFilter: params.Show,
-			TagFilter:  params.Tags,
-			SortField:  params.Sort,
-			SortDir:    params.SortDir,
-			Currency:   currency,
+		internal.PrintSubscriptionsTable(out, subscriptions, displaySubs, opts, cfg)
+	}
+	return nil
+}
+
+// loadConfig loads the config from path, or from the default location if path is empty.
+// Without a config file, the default config with built-in known subscriptions is used.
+func loadConfig(path string, info func(format string, args ...any)) (*internal.Config, error) {
+	if path == "" {
+		// Try default config path
+		defaultPath := internal.DefaultConfigPath()
+		if _, err := os.Stat(defaultPath); err == nil {
+			path = defaultPath
+		}
+	}
+	if path == "" {
+		// No config file - use default config with built-in known subscriptions
+		return internal.NewDefaultConfig()
+	}
+
+	cfg, err := internal.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading config %s: %w", path, err)
+	}
+	cfg.RegisterPlugins()
+	info("Loaded config from %s\n", path)
+	return cfg, nil
+}
+
+// loadTransactions parses all transaction files concurrently. Files use the format:path syntax,
+// falling back to source and then to the format detected from the file for files without a format prefix.
+// The files' transactions get the per-file options in opts.
+func loadTransactions(ctx context.Context, files []string, source string, opts sourceOptions, info func(format string, args ...any)) ([]internal.Transaction, error) {
+	sources := make([]internal.SourceFile, len(files))
+	for i, fileArg := range files {
+		format, filePath, err := resolveFormat(fileArg, source)
+		if err != nil {
+			return nil, err
+		}
+		if sources[i], err = opts.sourceFile(format, filePath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse concurrently; results (and messages) keep the order of the files
+	results, err := internal.ParseFiles(ctx, sources, 0)
+	if err != nil {
+		return nil, err
+	}
+	var transactions []internal.Transaction
+	for i, txs := range results {
+		info("Loaded %d transactions from %s\n", len(txs), sources[i].Path)
+		if !slices.ContainsFunc(txs, func(tx internal.Transaction) bool { return tx.Amount < 0 }) {
+			hintInvertAmounts(sources[i].Path, len(txs), info)
+		}
+		transactions = append(transactions, txs...)
+	}
+	return transactions, nil
+}
+
+// hintInvertAmounts points out --invert-amounts for a file without negative amounts, whose
+// charges would otherwise all be ignored as income
+func hintInvertAmounts(path string, count int, info func(format string, args ...any)) {
+	if count > 0 {
+		info("Note: %s has no negative amounts; if it lists charges as positive numbers, use --invert-amounts\n", path)
+	}
+}
+
+// sourceOptions are the per-file options given on the command line, each for the files of a
+// format or matching a path pattern (see matchesFile)
+type sourceOptions struct {
+	invert        []string // --invert-amounts: formats or patterns whose amounts are negated
+	statementDays []string // --statement-day: [FORMAT=|PATTERN=]DAY cut-off days of card exports
+	persons       []string // --person: NAME=PATTERN household members the exports belong to
+}
+
+// sourceFile returns a file to parse with the options that apply to it
+func (o sourceOptions) sourceFile(format, filePath string) (internal.SourceFile, error) {
+	day, err := statementDay(o.statementDays, format, filePath)
+	if err != nil {
+		return internal.SourceFile{}, err
+	}
+	person, err := filePerson(o.persons, format, filePath)
+	if err != nil {
+		return internal.SourceFile{}, err
+	}
+	return internal.SourceFile{Source: format, Path: filePath, InvertAmounts: invertsAmounts(o.invert, format, filePath), StatementDay: day, Person: person}, nil
+}
+
+// invertsAmounts reports whether --invert-amounts names the format of a file, or has a pattern
+// matching its path or file name
+func invertsAmounts(invert []string, format, filePath string) bool {
+	return slices.ContainsFunc(invert, func(v string) bool { return matchesFile(v, format, filePath) })
+}
+
+// statementDay returns the statement cut-off day --statement-day gives a file, 0 if none: the
+// first value that is a plain day or whose format or pattern matches the file
+func statementDay(values []string, format, filePath string) (int, error) {
+	for _, v := range values {
+		pattern, dayStr, found := strings.Cut(v, "=")
+		if !found {
+			pattern, dayStr = "", v
+		}
+		day, err := strconv.Atoi(strings.TrimSpace(dayStr))
+		if err != nil || day < 1 || day > 31 {
+			return 0, fmt.Errorf("invalid --statement-day %q (expected DAY, FORMAT=DAY or PATTERN=DAY with a day from 1 to 31)", v)
+		}
+		if pattern == "" || matchesFile(pattern, format, filePath) {
+			return day, nil
+		}
+	}
+	return 0, nil
+}
+
+// filePerson returns the household member --person labels a file with, "" if none: the name of
+// the first value whose format or pattern matches the file
+func filePerson(values []string, format, filePath string) (string, error) {
+	for _, v := range values {
+		name, pattern, found := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || pattern == "" {
+			return "", fmt.Errorf("invalid --person %q (expected NAME=PATTERN)", v)
+		}
+		if matchesFile(pattern, format, filePath) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// matchesFile reports whether v names the format of a file, or is a pattern matching its path or
+// file name
+func matchesFile(v, format, filePath string) bool {
+	if v == format {
+		return true
+	}
+	if ok, _ := filepath.Match(filepath.Clean(v), filepath.Clean(filePath)); ok {
+		return true
+	}
+	ok, _ := filepath.Match(v, filepath.Base(filePath))
+	return ok
+}
+
+// maxParseWarningRows is how many skipped rows printParseWarnings lists per file
+const maxParseWarningRows = 5
+
+// printParseWarnings prints a summary of the rows skipped while parsing to stderr, per file
+func printParseWarnings(w *internal.ParseWarnings) {
+	files, byFile := w.ByFile()
+	for _, file := range files {
+		warnings := byFile[file]
+		fmt.Fprintf(os.Stderr, "Warning: Skipped %d row(s) of %s that couldn't be parsed (use --strict-parse to fail instead):\n", len(warnings), file)
+		for _, warning := range warnings[:min(len(warnings), maxParseWarningRows)] {
+			if warning.Line > 0 {
+				fmt.Fprintf(os.Stderr, "  row %d: %s\n", warning.Line, warning.Reason)
+			} else {
+				fmt.Fprintf(os.Stderr, "  %s\n", warning.Reason)
+			}
+		}
+		if len(warnings) > maxParseWarningRows {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(warnings)-maxParseWarningRows)
+		}
+	}
+	if len(files) > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// withParseCache returns ctx with a cache of parsed transactions in dir (or the default
+// directory) if enabled
+func withParseCache(ctx context.Context, enabled bool, dir string) context.Context {
+	if !enabled {
+		return ctx
+	}
+	if dir == "" {
+		dir = internal.DefaultCacheDir()
+	}
+	return internal.WithParseCache(ctx, internal.NewParseCache(dir))
+}
+
+// withTimezone returns ctx with the timezone dates are parsed in, if one is given
+func withTimezone(ctx context.Context, name string) (context.Context, error) {
+	if name == "" {
+		return ctx, nil
+	}
+	loc, err := internal.LoadTimezone(name)
+	if err != nil {
+		return nil, err
+	}
+	return internal.WithTimezone(ctx, loc), nil
+}
+
+// compileFilter compiles the --filter pattern, case-insensitively (nil if not set)
+func compileFilter(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --filter %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// applyGroupSuggestions adds suggested groups to the config file. On a terminal each one is
+// confirmed first; otherwise (e.g., in scripts) all are added.
+func applyGroupSuggestions(configPath string, suggestions []internal.GroupSuggestion, info func(string, ...any)) error {
+	path, err := configPathForEdit(configPath)
+	if err != nil {
+		return err
+	}
+	confirm := internal.IsTerminal(os.Stdin)
+	answers := bufio.NewReader(os.Stdin)
+	added := 0
+	for _, s := range suggestions {
+		if confirm {
+			fmt.Fprintf(os.Stderr, "Add group %q (pattern %q)? [y/N] ", s.Prefix, s.Pattern)
+			answer, _ := answers.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+		if err := internal.AddConfigGroup(path, s.Group()); err != nil {
+			return fmt.Errorf("updating config: %w", err)
+		}
+		added++
+	}
+	info("Added %d group(s) to %s\n", added, path)
+	return nil
+}
+
+// startProgress starts reporting parsing progress on stderr: as log lines with --progress (force),
+// or as a progress bar when stderr is a terminal and the files are larger than
+// internal.ProgressThreshold (unless quiet). It returns the context for parsing and the reporter
+// to stop afterwards (nil if progress isn't shown).
+func startProgress(ctx context.Context, files []string, force, quiet bool) (context.Context, *internal.Progress) {
+	paths := make([]string, len(files))
+	for i, fileArg := range files {
+		_, paths[i] = internal.ParseFileArg(fileArg)
+	}
+	bar := internal.IsTerminal(os.Stderr)
+	if !force && (quiet || !bar || internal.InputSize(paths) < internal.ProgressThreshold) {
+		return ctx, nil
+	}
+
+	progress := internal.NewProgress(os.Stderr, bar && !force)
+	progress.AddFiles(paths...)
+	progress.Start("Parsing")
+	return internal.WithProgress(ctx, progress), progress
+}
+
+// aboveProgress wraps info so its messages are printed above the progress bar, if any
+func aboveProgress(progress *internal.Progress, info func(format string, args ...any)) func(format string, args ...any) {
+	if progress == nil {
+		return info
+	}
+	return func(format string, args ...any) {
+		progress.Above(func() { info(format, args...) })
+	}
+}
+
+// stopProgress stops a reporter from startProgress, if any
+func stopProgress(progress *internal.Progress) {
+	if progress != nil {
+		progress.Stop()
+	}
+}
+
+// resolveFormat splits a file argument into its format and path. Files without a format prefix
+// use source, and then the format detected from the file.
+func resolveFormat(fileArg, source string) (format, filePath string, err error) {
+	format, filePath = internal.ParseFileArg(fileArg)
+	if format == "" {
+		format = source // Fall back to --source flag
+	}
+	if format == "" {
+		detected, err := internal.DetectFormat(filePath)
+		if err != nil {
+			return "", "", err
+		}
+		if detected == "" {
+			return "", "", fmt.Errorf("%w: could not detect the format of %s (use format:path or --source)", internal.ErrUnknownSource, filePath)
+		}
+		format = detected
+	}
+	return format, filePath, nil
+}
+
+// streamAllTransactions adds the transactions of all files (and the imported ones, if imported
+// is set) to stream without keeping them in memory
+func streamAllTransactions(ctx context.Context, files []string, source string, opts sourceOptions, imported bool, statePath string, stream *internal.TransactionStream, info func(format string, args ...any)) error {
+	for _, fileArg := range files {
+		format, filePath, err := resolveFormat(fileArg, source)
+		if err != nil {
+			return err
+		}
+		src, err := opts.sourceFile(format, filePath)
+		if err != nil {
+			return err
+		}
+		count, expenses := 0, 0
+		err = internal.StreamFile(ctx, format, filePath, func(tx internal.Transaction) error {
+			tx = src.Apply(tx)
+			if tx.Amount < 0 {
+				expenses++
+			}
+			stream.Add(tx)
+			count++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		info("Loaded %d transactions from %s\n", count, filePath)
+		if expenses == 0 {
+			hintInvertAmounts(filePath, count, info)
+		}
+	}
+	if !imported {
+		return nil
+	}
+	stored, err := loadImportedTransactions(statePath, info)
+	if err != nil {
+		return err
+	}
+	for _, tx := range stored {
+		stream.Add(tx)
+	}
+	return nil
+}
+
+// loadImportedTransactions returns the transactions stored in the state file with the import subcommand
+func loadImportedTransactions(statePath string, info func(format string, args ...any)) ([]internal.Transaction, error) {
+	state, err := internal.LoadState(resolveStatePath(statePath))
+	if err != nil {
+		return nil, fmt.Errorf("loading state: %w", err)
+	}
+	stored, err := state.StoredTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("loading imported transactions: %w", err)
+	}
+	info("Loaded %d imported transactions\n", len(stored))
+	return stored, nil
+}
+
+// loadAllTransactions parses the transaction files and, if imported is set, adds the
+// transactions stored in the state file
+func loadAllTransactions(ctx context.Context, files []string, source string, opts sourceOptions, imported bool, statePath string, info func(format string, args ...any)) ([]internal.Transaction, error) {
+	transactions, err := loadTransactions(ctx, files, source, opts, info)
+	if err != nil || !imported {
+		return transactions, err
+	}
+	stored, err := loadImportedTransactions(statePath, info)
+	if err != nil {
+		return nil, err
+	}
+	return append(transactions, stored...), nil
+}
+
+// checkThresholds returns a description of each --fail-* condition that triggered
+func checkThresholds(params *Params, subs []internal.Subscription, changes *internal.ChangeReport) []string {
+	var failures []string
+	if params.FailIfMonthlyOver > 0 {
+		if total := internal.ActiveMonthlyTotal(subs); total > params.FailIfMonthlyOver {
+			failures = append(failures, fmt.Sprintf("monthly total %.2f is over %.2f", total, params.FailIfMonthlyOver))
+		}
+	}
+	if changes != nil {
+		for _, c := range changes.Changes {
+			switch {
+			case params.FailOnNew && c.Kind == internal.ChangeNew:
+				failures = append(failures, fmt.Sprintf("new subscription %s", c.Name))
+			case params.FailOnPriceIncrease && c.Kind == internal.ChangePriceIncrease:
+				failures = append(failures, fmt.Sprintf("price increase for %s (%.2f -> %.2f)", c.Name, c.OldAmount, c.NewAmount))
+			}
+		}
+	}
+	return failures
+}
+
+// resolveRenderer returns the renderer plugin for --output, or nil for a built-in format
+func resolveRenderer(output string) (internal.Renderer, error) {
+	if slices.Contains(internal.BuiltinOutputs, output) {
+		return nil, nil
+	}
+	renderer, ok := internal.GetRenderer(output)
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (available: %s)", output, strings.Join(slices.Concat(internal.BuiltinOutputs, internal.RendererNames()), ", "))
+	}
+	return renderer, nil
+}
+
+// notify sends the changes to the notifiers configured in cfg.
+// Failures are reported as warnings so they don't break scheduled runs.
+func notify(ctx context.Context, cfg *internal.Config, report *internal.ChangeReport, currency internal.Currency, info func(format string, args ...any)) {
+	if len(cfg.Notify) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --notify given but no notifiers configured (see 'notify' in the config file)\n")
+		return
+	}
+	if report == nil || len(report.Changes) == 0 {
+		return
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	if err := internal.SendNotifications(ctx, client, cfg.Notify, report, currency); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	info("Sent change notifications\n")
+}
+
+// exportSheet writes the subscriptions to the Google spreadsheet given by --sheet-id
+func exportSheet(ctx context.Context, params *Params, subs []internal.Subscription, cfg *internal.Config, currency internal.Currency, info func(format string, args ...any)) error {
+	credentials := params.SheetCredentials
+	if credentials == "" {
+		credentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if credentials == "" {
+		return errors.New("--output gsheet requires --sheet-credentials or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	sa, err := internal.LoadServiceAccount(credentials)
+	if err != nil {
+		return fmt.Errorf("loading service account key: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if err := internal.ExportToSheet(ctx, client, sa, params.SheetID, subs, cfg, currency, time.Now()); err != nil {
+		return fmt.Errorf("exporting to Google Sheets: %w", err)
+	}
+	info("Exported %d subscriptions to Google Sheets\n", len(subs))
+	return nil
+}
+
+// resolveCurrencyAndLocale resolves the output currency and locale.
+// An empty currencyCode falls back to the system locale's currency, then USD.
+// An empty localeName falls back to the system locale, then English.
+func resolveCurrencyAndLocale(currencyCode, localeName string) (internal.Currency, internal.Locale, error) {
+	if currencyCode == "" {
+		currencyCode = internal.DetectSystemCurrency()
+	}
+	if currencyCode == "" {
+		currencyCode = "USD"
+	}
+	currency := internal.GetCurrency(currencyCode)
+
+	locale, err := internal.ResolveLocale(localeName)
+	if err != nil {
+		return internal.Currency{}, internal.Locale{}, err
+	}
+	if localeName != "" {
+		currency = internal.GetCurrencyWithLocale(currencyCode, locale.Tag)
+	}
+	return currency, locale, nil
+}
+
+// excludedOnly returns the rejections by exclude rules, without the reasons of strategies
+func excludedOnly(rejections []internal.JSONRejection) []internal.JSONRejection {
+	excluded := []internal.JSONRejection{}
+	for _, r := range rejections {
+		var reasons []internal.JSONRejectionReason
+		for _, reason := range r.Reasons {
+			if reason.Code == internal.RejectionExcluded {
+				reasons = append(reasons, reason)
+			}
+		}
+		if len(reasons) > 0 {
+			r.Reasons = reasons
+			excluded = append(excluded, r)
+		}
+	}
+	return excluded
+}
+
+// resolvePrecision returns the decimals shown in amounts, with precedence: CLI > config > whole units
+func resolvePrecision(cmd *cobra.Command, precision int, cfg *internal.Config) int {
+	if !cmd.Flags().Changed("precision") && cfg != nil {
+		return cfg.Precision
+	}
+	return precision
+}
+
+// resolveStatePath returns the state file path, defaulting to ~/.subscription-detector/state.json
+func resolveStatePath(path string) string {
+	if path == "" {
+		return internal.DefaultStatePath()
+	}
+	return path
+}
+
+// configureColors applies --color/--no-color for output written to w
+func configureColors(mode string, noColor bool, w io.Writer) {
+	if noColor {
+		mode = "never"
+	}
+	internal.ConfigureColors(mode, w)
+}
+
+// createOutputFile creates (or truncates) the file at path, creating parent directories as needed
+func createOutputFile(path string) (*os.File, error) {
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating directory %s: %w", dir, err)
 		}
-		internal.PrintSubscriptionsTable(os.Stdout, subscriptions, displaySubs, opts, cfg)
 	}
+	return os.Create(path)
 }
@@ -0,0 +1,76 @@
+//go:build js && wasm
+
+// Command wasm exposes the detector to JavaScript, for the client-side demo in docs/demo.
+// Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o docs/demo/subscription-detector.wasm ./wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" docs/demo/
+//
+// It registers a global subscriptionDetector object with two functions, both taking strings and
+// returning the result as a parsed JSON value, or {error: "..."} on failure:
+//
+//	subscriptionDetector.detectSubscriptions(simpleJSON, configYAML?, currency?) // --output json format
+//	subscriptionDetector.parseSimpleJSON(simpleJSON)                            // {transactions: [...]}
+package main
+
+import (
+	"bytes"
+	"context"
+	"syscall/js"
+
+	"github.com/gigurra/subscription-detector/internal"
+)
+
+func main() {
+	js.Global().Set("subscriptionDetector", js.ValueOf(map[string]any{
+		"detectSubscriptions": js.FuncOf(detectSubscriptions),
+		"parseSimpleJSON":     js.FuncOf(parseSimpleJSON),
+	}))
+	// Keep the functions available to JavaScript
+	select {}
+}
+
+func detectSubscriptions(_ js.Value, args []js.Value) any {
+	if len(args) == 0 {
+		return jsError("detectSubscriptions(simpleJSON, configYAML?, currency?) needs the transactions")
+	}
+	data := []byte(args[0].String())
+	var config []byte
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		config = []byte(args[1].String())
+	}
+	currency := ""
+	if len(args) > 2 && args[2].Type() == js.TypeString {
+		currency = args[2].String()
+	}
+
+	out, err := internal.AnalyzeJSON(context.Background(), data, config, currency)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return jsonValue(out)
+}
+
+func parseSimpleJSON(_ js.Value, args []js.Value) any {
+	if len(args) == 0 {
+		return jsError("parseSimpleJSON(simpleJSON) needs the transactions")
+	}
+	transactions, err := internal.ParseSimpleJSONBytes(context.Background(), []byte(args[0].String()))
+	if err != nil {
+		return jsError(err.Error())
+	}
+	var buf bytes.Buffer
+	if err := internal.WriteSimpleJSON(&buf, transactions); err != nil {
+		return jsError(err.Error())
+	}
+	return jsonValue(buf.Bytes())
+}
+
+// jsonValue parses JSON output into a JavaScript value
+func jsonValue(data []byte) js.Value {
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+func jsError(message string) any {
+	return map[string]any{"error": message}
+}